@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/moamenhredeen/oas/internal/asyncapi"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/tester"
+	"github.com/spf13/cobra"
+)
+
+var (
+	asyncChannel  string
+	asyncFile     string
+	asyncURL      string
+	asyncSend     []string
+	asyncCount    int
+	asyncDuration time.Duration
+)
+
+// asyncCmd groups the commands for AsyncAPI documents
+var asyncCmd = &cobra.Command{
+	Use:   "async",
+	Short: "Validate messages of event-driven APIs described by AsyncAPI",
+	Long: `Validate the messages of event-driven APIs against the payload schemas of an
+AsyncAPI 2.x or 3.x document (JSON Schema payloads).
+
+Messages are read from a WebSocket channel with "oas async ws", or as
+newline-delimited JSON with "oas async validate", so that Kafka, AMQP or MQTT
+messages can be piped in from the broker's console consumer.`,
+}
+
+var asyncChannelsCmd = &cobra.Command{
+	Use:   "channels [asyncapi-spec-file]",
+	Short: "List the channels and messages of an AsyncAPI document",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rejectFlags(cmd, requestFlags...)
+		doc := parseAsyncSpec(args[0])
+
+		fmt.Printf("%s (AsyncAPI %s)\n", doc.Title, doc.Version)
+		for _, s := range doc.Servers {
+			fmt.Printf("  server %s: %s (%s)\n", s.Name, s.URL, s.Protocol)
+		}
+		for _, c := range doc.Channels {
+			fmt.Printf("\n%s\n", c.Address)
+			if c.Name != c.Address {
+				fmt.Printf("  id: %s\n", c.Name)
+			}
+			for _, msg := range c.Messages {
+				line := "  - " + msg.Name
+				if msg.ContentType != "" {
+					line += " (" + msg.ContentType + ")"
+				}
+				if msg.Unsupported != "" {
+					line += " [not validated: " + msg.Unsupported + "]"
+				}
+				fmt.Println(line)
+			}
+		}
+	},
+}
+
+var asyncValidateCmd = &cobra.Command{
+	Use:   "validate [asyncapi-spec-file]",
+	Short: "Validate newline-delimited JSON messages against a channel",
+	Long: `Validate messages against the payload schemas of a channel. Messages are read
+one JSON document per line from --file or standard input, which is how Kafka,
+AMQP or MQTT messages are checked: pipe them from the broker's consumer.`,
+	Example: `  kcat -C -b localhost:9092 -t user.signedup -e | oas async validate events.yaml --channel user.signedup
+  oas async validate events.yaml --channel user/signedup --file captured.jsonl`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rejectFlags(cmd, requestFlags...)
+		doc := parseAsyncSpec(args[0])
+		channel := asyncSpecChannel(doc)
+
+		in := io.Reader(os.Stdin)
+		if asyncFile != "" {
+			f, err := os.Open(asyncFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		check := newMessageCheck(channel)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			check.message(line)
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading messages: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		check.finish(0)
+	},
+}
+
+var asyncWSCmd = &cobra.Command{
+	Use:   "ws [asyncapi-spec-file]",
+	Short: "Validate the messages received on a WebSocket channel",
+	Long: `Connect to a WebSocket channel, optionally send messages (e.g. a subscription
+request), and validate the messages received until --count messages arrived
+or --duration passed. The URL defaults to the first ws or wss server of the
+document joined with the channel address.`,
+	Example: `  oas async ws events.yaml --channel /prices --count 10
+  oas async ws events.yaml --channel /prices --url wss://staging.example.com/prices --send '{"subscribe":"EURUSD"}' --duration 30s`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rejectFlags(cmd, requestFlags...)
+		doc := parseAsyncSpec(args[0])
+		channel := asyncSpecChannel(doc)
+
+		url := asyncURL
+		if url == "" {
+			server, ok := doc.ServerURL("ws", "wss")
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: the document has no ws or wss server, pass --url\n")
+				exit(exitFailed, nil)
+			}
+			url = parser.JoinURL(server, channel.Address)
+		}
+
+		header := http.Header{}
+		header.Set("User-Agent", userAgent())
+		conn, _, err := websocket.DefaultDialer.Dial(url, header)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error connecting to %s: %s\n", url, mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		defer conn.Close()
+		fmt.Printf("Connected to %s\n", url)
+
+		for _, msg := range asyncSend {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending message: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
+		}
+
+		check := newMessageCheck(channel)
+		conn.SetReadDeadline(time.Now().Add(asyncDuration))
+		for asyncCount <= 0 || check.total < asyncCount {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure) && !isTimeout(err) {
+					fmt.Fprintf(os.Stderr, "Error reading message: %s\n", mask.String(err.Error()))
+				}
+				break
+			}
+			check.message(data)
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		check.finish(asyncCount)
+	},
+}
+
+// parseAsyncSpec parses an AsyncAPI document, exiting on error
+func parseAsyncSpec(specFile string) *asyncapi.Document {
+	doc, err := asyncapi.ParseFile(specFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing AsyncAPI file: %s\n", mask.String(err.Error()))
+		exit(exitFailed, nil)
+	}
+	return doc
+}
+
+// asyncSpecChannel returns the channel selected with --channel, exiting if
+// it doesn't exist
+func asyncSpecChannel(doc *asyncapi.Document) *asyncapi.Channel {
+	if asyncChannel == "" {
+		fmt.Fprintf(os.Stderr, "Error: --channel is required\n")
+		exit(exitFailed, nil)
+	}
+	channel, err := doc.Channel(asyncChannel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s (see oas async channels)\n", err)
+		exit(exitFailed, nil)
+	}
+	return channel
+}
+
+// isTimeout reports whether a read ended because --duration passed
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// messageCheck validates messages of a channel and prints a line per message
+type messageCheck struct {
+	channel   *asyncapi.Channel
+	validator *tester.Validator
+	total     int
+	failed    int
+}
+
+func newMessageCheck(channel *asyncapi.Channel) *messageCheck {
+	return &messageCheck{channel: channel, validator: tester.NewValidator()}
+}
+
+func (c *messageCheck) message(data []byte) {
+	c.total++
+	name, validationErrors := c.channel.Validate(c.validator, data)
+	if len(validationErrors) == 0 {
+		fmt.Printf("%s #%d %s\n", green("✓ PASS"), c.total, name)
+		return
+	}
+
+	c.failed++
+	fmt.Printf("%s #%d\n", red("✗ FAIL"), c.total)
+	for _, ve := range validationErrors {
+		fmt.Printf("    %s: %s\n", ve.Field, mask.String(ve.Message))
+	}
+	if opts.Verbose {
+		fmt.Printf("    message: %s\n", mask.String(string(data)))
+	}
+}
+
+// finish prints the summary and exits with an error if a message failed or
+// fewer than want messages arrived
+func (c *messageCheck) finish(want int) {
+	fmt.Printf("\nMessages: %d, Passed: %s, Failed: %s\n", c.total, green(c.total-c.failed), red(c.failed))
+	if want > 0 && c.total < want {
+		fmt.Fprintf(os.Stderr, "Expected %d messages, received %d\n", want, c.total)
+		exit(exitFailed, nil)
+	}
+	if c.failed > 0 || c.total == 0 {
+		exit(exitFailed, nil)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(asyncCmd)
+	asyncCmd.AddCommand(asyncChannelsCmd, asyncValidateCmd, asyncWSCmd)
+
+	for _, cmd := range []*cobra.Command{asyncValidateCmd, asyncWSCmd} {
+		cmd.Flags().StringVar(&asyncChannel, "channel", "", "Channel name or address whose messages are validated")
+	}
+	asyncValidateCmd.Flags().StringVar(&asyncFile, "file", "", "Read messages from this file instead of standard input")
+	asyncWSCmd.Flags().StringVar(&asyncURL, "url", "", "WebSocket URL (default: first ws/wss server joined with the channel address)")
+	asyncWSCmd.Flags().StringArrayVar(&asyncSend, "send", nil, "Send this text message after connecting (repeatable)")
+	asyncWSCmd.Flags().IntVar(&asyncCount, "count", 0, "Stop after N messages; fewer fails the run (0 = until --duration)")
+	asyncWSCmd.Flags().DurationVar(&asyncDuration, "duration", 30*time.Second, "Stop listening after this long")
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/auth"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/tester"
+	"github.com/spf13/viper"
+)
+
+// buildAuthenticator assembles the authenticators configured under [auth]
+// in config.toml. The test profile name selects per-profile overrides. It
+// returns nil when no authentication is configured.
+func buildAuthenticator(profile string) (auth.Authenticator, error) {
+	var chain auth.Chain
+
+	if viper.IsSet("auth.oidc") {
+		oidc, err := buildOIDC(profile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, oidc)
+	}
+
+	if viper.IsSet("auth.sigv4") {
+		signer, err := buildSigV4()
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, signer)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// buildSigV4 creates an AWS SigV4 signer from [auth.sigv4], resolving
+// credentials from the default chain when they are not configured explicitly
+func buildSigV4() (*auth.SigV4, error) {
+	region := viper.GetString("auth.sigv4.region")
+	service := viper.GetString("auth.sigv4.service")
+	if region == "" || service == "" {
+		return nil, fmt.Errorf("auth.sigv4 requires region and service")
+	}
+
+	creds := auth.AWSCredentials{
+		AccessKeyID:     viper.GetString("auth.sigv4.access_key_id"),
+		SecretAccessKey: viper.GetString("auth.sigv4.secret_access_key"),
+		SessionToken:    viper.GetString("auth.sigv4.session_token"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		var err error
+		creds, err = auth.DefaultAWSCredentials(viper.GetString("auth.sigv4.profile"))
+		if err != nil {
+			return nil, fmt.Errorf("auth.sigv4: %w", err)
+		}
+		mask.Register(creds.SecretAccessKey, creds.SessionToken)
+	}
+
+	return auth.NewSigV4(region, service, creds), nil
+}
+
+// buildOIDC creates an OIDC token authenticator from [auth.oidc]. Settings in
+// [auth.oidc.profiles.<profile>] override the base block, so e.g. a smoke run
+// can use a CI account with the password flow while strict runs use the
+// device flow.
+func buildOIDC(profile string) (*auth.OIDC, error) {
+	get := func(key string) string {
+		if profile != "" {
+			if override := "auth.oidc.profiles." + profile + "." + key; viper.IsSet(override) {
+				return viper.GetString(override)
+			}
+		}
+		return viper.GetString("auth.oidc." + key)
+	}
+
+	oidc, err := auth.NewOIDC(auth.OIDCConfig{
+		Flow:             get("flow"),
+		Issuer:           get("issuer"),
+		TokenURL:         get("token_url"),
+		DeviceAuthURL:    get("device_auth_url"),
+		ClientID:         get("client_id"),
+		ClientSecret:     get("client_secret"),
+		Scope:            get("scope"),
+		Audience:         get("audience"),
+		Username:         get("username"),
+		Password:         get("password"),
+		SubjectToken:     get("subject_token"),
+		SubjectTokenType: get("subject_token_type"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth.oidc: %w", err)
+	}
+	return oidc, nil
+}
+
+// digestCredentials returns the [auth.digest] credentials, or nil when digest
+// authentication is not configured
+func digestCredentials() *auth.UserCredentials {
+	if !viper.IsSet("auth.digest") {
+		return nil
+	}
+	return &auth.UserCredentials{
+		Username: viper.GetString("auth.digest.username"),
+		Password: viper.GetString("auth.digest.password"),
+	}
+}
+
+// loginSession runs the [auth.login] step, if configured, and returns the
+// cookie jar holding the session. It returns nil when no login is configured.
+func loginSession(p *parser.Parser, baseURL string, timeout time.Duration) (http.CookieJar, error) {
+	if !viper.IsSet("auth.login") {
+		return nil, nil
+	}
+
+	login := tester.LoginConfig{
+		OperationID: viper.GetString("auth.login.operation_id"),
+		Body:        viper.GetString("auth.login.body"),
+		ContentType: viper.GetString("auth.login.content_type"),
+		UserAgent:   userAgent(),
+		HostHeader:  hostHeader,
+	}
+	if login.OperationID == "" {
+		return nil, fmt.Errorf("auth.login requires operation_id")
+	}
+
+	cookies, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	jar := tester.HostCookieJar(cookies, hostHeader)
+	if err := tester.Login(p, baseURL, login, jar, timeout); err != nil {
+		return nil, err
+	}
+	return jar, nil
+}
+
+// secretKeys lists config keys whose values are credentials
+var secretKeys = []string{
+	"auth.sigv4.secret_access_key",
+	"auth.sigv4.session_token",
+	"auth.oidc.client_secret",
+	"auth.oidc.password",
+	"auth.oidc.subject_token",
+	"auth.digest.password",
+	"auth.login.body",
+}
+
+// registerSecrets marks configured credentials, including per-profile OIDC
+// overrides and the values listed under `secrets`, so they are masked in all
+// output
+func registerSecrets() {
+	for _, key := range secretKeys {
+		mask.Register(viper.GetString(key))
+	}
+	for profile := range viper.GetStringMap("auth.oidc.profiles") {
+		for _, key := range []string{"client_secret", "password", "subject_token"} {
+			mask.Register(viper.GetString("auth.oidc.profiles." + profile + "." + key))
+		}
+	}
+	mask.Register(viper.GetStringSlice("secrets")...)
+}
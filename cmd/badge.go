@@ -0,0 +1,87 @@
+/*
+Copyright © 2026 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/badge"
+	"github.com/spf13/cobra"
+)
+
+var (
+	badgeMetric string
+	badgeOutput string
+	badgeFormat string
+	badgeLimit  float64
+)
+
+// badgeCmd represents the badge command
+var badgeCmd = &cobra.Command{
+	Use:   "badge [results.json]",
+	Short: "Generate a status badge from results",
+	Long: `Generate a README or dashboard badge from a test or benchmark summary exported with -o json.
+
+Benchmark metrics: avg, p50, p90, p99 (worst endpoint, ms), rps, errors (%)
+Test metrics:      passed, pass-rate, coverage
+
+The badge is written as SVG, or as a shields.io endpoint JSON document with
+--format json (or an output file ending in .json).`,
+	Example: `  oas badge benchmark.json --metric p99 -o badge.svg
+  oas badge benchmark.json --metric p99 --limit 200 -o badge.svg
+  oas badge results.json --metric passed --format json -o badge.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		b, err := badge.FromFile(args[0], badgeMetric, badgeLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(exitFailed, nil)
+		}
+
+		format := badgeFormat
+		if format == "" {
+			format = "svg"
+			if strings.EqualFold(filepath.Ext(badgeOutput), ".json") {
+				format = "json"
+			}
+		}
+
+		var data []byte
+		switch format {
+		case "svg":
+			data = b.SVG()
+		case "json":
+			data, err = b.ShieldsJSON()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(exitFailed, nil)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid format '%s': must be 'svg' or 'json'\n", format)
+			exit(exitFailed, nil)
+		}
+
+		if badgeOutput == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(badgeOutput, data, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing badge: %v\n", err)
+			exit(exitFailed, nil)
+		}
+		fmt.Printf("Badge written to: %s (%s: %s)\n", badgeOutput, b.Label, b.Message)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(badgeCmd)
+
+	badgeCmd.Flags().StringVarP(&badgeMetric, "metric", "m", "p99", "Metric to show (see above)")
+	badgeCmd.Flags().StringVarP(&badgeOutput, "output", "o", "", "Write the badge to a file (default: stdout)")
+	badgeCmd.Flags().StringVar(&badgeFormat, "format", "", "Badge format: svg, json (default: from output file extension)")
+	badgeCmd.Flags().Float64Var(&badgeLimit, "limit", 0, "Color the badge green or red against this limit (ms, req/s or %)")
+}
@@ -4,10 +4,12 @@ Copyright © 2026 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"context"
 	"fmt"
+	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -15,10 +17,15 @@ import (
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/moamenhredeen/oas/internal/benchmarker"
+	"github.com/moamenhredeen/oas/internal/dashboard"
+	"github.com/moamenhredeen/oas/internal/mask"
 	"github.com/moamenhredeen/oas/internal/models"
 	"github.com/moamenhredeen/oas/internal/output"
 	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/report"
+	"github.com/moamenhredeen/oas/internal/tester"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
@@ -26,13 +33,35 @@ var (
 	benchIterations   int
 	benchConcurrency  int
 	benchWarmup       int
+	benchWarmupDur    time.Duration
+	benchGlobalWarmup bool
+	benchReportWarmup bool
+	benchMutations    bool
+	benchCleanup      bool
+	benchOrder        string
+	benchSeed         int64
+	benchDashboard    string
+	benchPprof        string
 	benchRateLimit    float64
-	benchTimeout      int
+	benchBurst        int
+	benchPerWorker    bool
+	benchJitter       float64
+	benchAdaptive     bool
+	benchMaxConc      int
+	benchAdaptiveP99  time.Duration
+	benchAdaptiveErr  float64
 	benchNoKeepAlive  bool
-	benchOutputFormat string
-	benchOutputFile   string
-
-	// Shared flags (reuse serverURL, filter, tags, verbose from test.go)
+	benchColdHot      bool
+	benchBudgets      []string
+	benchMaxErrors    []string
+	benchDNSCache     bool
+	benchMaxIdleConns int
+	benchMaxConnsHost int
+	benchIdleTimeout  time.Duration
+	benchBaseline     string
+	benchTraceSlow    time.Duration
+	benchTraceFile    string
+	benchOptParams    float64
 
 	// Color helpers
 	cyan   = color.New(color.FgCyan, color.Bold).SprintFunc()
@@ -61,7 +90,10 @@ Examples:
   oas benchmark api-spec.json -n 500 --rate 50
 
   # Export results to JSON
-  oas benchmark api-spec.json -o json --output-file results.json`,
+  oas benchmark api-spec.json -o json --output-file results.json
+
+  # Markdown report with the changes from an earlier run
+  oas benchmark api-spec.json --tee report.md --baseline results.json`,
 	Args: cobra.ExactArgs(1),
 	Run:  runBenchmark,
 }
@@ -70,41 +102,160 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 	specFile := args[0]
 
 	// Parse OpenAPI spec
-	p, err := parser.ParseFile(specFile)
+	p, err := parser.ParseFileWithRefs(specFile, opts.refs())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing OpenAPI file: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error parsing OpenAPI file: %s\n", mask.String(err.Error()))
+		exit(exitFailed, nil)
 	}
 
 	// Get server URLs
 	serverURLs, err := p.GetServerURLs()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting server URLs: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error getting server URLs: %s\n", mask.String(err.Error()))
+		exit(exitFailed, nil)
 	}
 
 	// Use provided server URL or first from spec
-	baseURL := serverURL
+	baseURL := opts.Server
 	if baseURL == "" && len(serverURLs) > 0 {
 		baseURL = serverURLs[0]
 	}
 	if baseURL == "" {
 		baseURL = "http://localhost"
 	}
+	if opts.BasePath != "" {
+		baseURL, err = parser.WithBasePath(baseURL, opts.BasePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+	}
 
 	// Get all operations
 	operations, err := p.GetOperations(baseURL)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting operations: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error getting operations: %s\n", mask.String(err.Error()))
+		exit(exitFailed, nil)
 	}
 
 	// Filter operations (reuse from test command)
-	filteredOps := filterOperations(operations, filter, tags)
+	filteredOps := filterOperations(operations, opts.Filter, opts.Tags)
+	skipped := models.SkipOperations(models.Exclude(operations, filteredOps), models.SkipFiltered)
+	filteredOps, skipped = skipOperations(filteredOps, skipped, models.SkipUnsupported, func(op models.Operation) bool {
+		return op.Unsupported == ""
+	})
+	if skipDeprecated {
+		filteredOps, skipped = skipOperations(filteredOps, skipped, models.SkipDeprecated, func(op models.Operation) bool {
+			return !op.Deprecated
+		})
+	}
+
+	// Only hammer write endpoints when explicitly asked to
+	if !benchMutations {
+		before := len(filteredOps)
+		filteredOps, skipped = skipOperations(filteredOps, skipped, models.SkipMutating, func(op models.Operation) bool {
+			return benchmarker.IsSafeMethod(op.Method)
+		})
+		if n := before - len(filteredOps); n > 0 {
+			fmt.Printf("Skipping %d mutating operation(s) (use --include-mutations to benchmark them)\n", n)
+		}
+	}
 
 	if len(filteredOps) == 0 {
 		fmt.Println("No operations found matching the criteria")
-		os.Exit(0)
+		exit(exitOK, nil)
+	}
+
+	vars := outputPathVars("", p.SpecVersion(), append([]string{opts.OutputFile}, opts.Tee...)...)
+	dests, err := output.Destinations(opts.Output, opts.OutputFile, opts.Tee, vars)
+	if err == nil {
+		err = checkCIDestinations(dests)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+		exit(exitUsage, nil)
+	}
+	for _, dest := range dests {
+		switch dest.Format {
+		case output.FormatDetailCSV, output.FormatAllure, output.FormatSonar:
+			fmt.Fprintf(os.Stderr, "Error: %s output is only available for tests\n", dest.Format)
+			exit(exitUsage, nil)
+		}
+	}
+
+	// Loaded up front, so a missing baseline doesn't surface after the run
+	var baseline *models.BenchmarkSummary
+	if benchBaseline != "" {
+		baseline, err = report.LoadBenchmark(benchBaseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
+	}
+
+	if (benchBurst > 0 || benchPerWorker || benchJitter > 0) && benchRateLimit <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --burst, --per-worker-rate and --jitter need a --rate\n")
+		exit(exitUsage, nil)
+	}
+	if benchJitter < 0 || benchJitter > 1 {
+		fmt.Fprintf(os.Stderr, "Error: --jitter must be between 0 and 1, got %g\n", benchJitter)
+		exit(exitUsage, nil)
+	}
+	// Only POST requests create resources to clean up
+	if benchCleanup && !benchMutations {
+		fmt.Fprintln(os.Stderr, "Error: --cleanup requires --include-mutations")
+		exit(exitUsage, nil)
+	}
+	if benchOptParams < 0 || benchOptParams > 1 {
+		fmt.Fprintf(os.Stderr, "Error: --include-optional-params must be between 0 and 1, got %g\n", benchOptParams)
+		exit(exitUsage, nil)
+	}
+	if benchAdaptive && benchMaxConc < benchConcurrency {
+		fmt.Fprintf(os.Stderr, "Error: --max-concurrency (%d) is below the starting --concurrency (%d)\n", benchMaxConc, benchConcurrency)
+		exit(exitUsage, nil)
+	}
+
+	order, err := benchmarker.ParseOrder(benchOrder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+		exit(exitUsage, nil)
+	}
+	seed := benchSeed
+	if order == benchmarker.OrderRandom && seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	scripts, err := tester.CompileScripts(viper.GetStringMapStringSlice("scripts"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+		exit(exitFailed, nil)
+	}
+
+	budgets, err := latencyBudgets()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		exit(exitUsage, nil)
+	}
+	var errorLimits []benchmarker.ErrorLimit
+	for _, s := range benchMaxErrors {
+		limit, err := benchmarker.ParseErrorLimit(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitUsage, nil)
+		}
+		errorLimits = append(errorLimits, limit)
+	}
+
+	authenticator, err := buildAuthenticator("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring authentication: %s\n", mask.String(err.Error()))
+		exit(exitFailed, nil)
+	}
+
+	jar, err := loginSession(p, baseURL, time.Duration(opts.Timeout)*time.Second)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error logging in: %s\n", mask.String(err.Error()))
+		exit(exitFailed, nil)
 	}
 
 	// Create benchmark configuration
@@ -112,31 +263,136 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 		Iterations:       benchIterations,
 		Concurrency:      benchConcurrency,
 		WarmupRuns:       benchWarmup,
+		WarmupDuration:   benchWarmupDur,
+		GlobalWarmup:     benchGlobalWarmup,
+		ReportWarmup:     benchReportWarmup,
 		RateLimit:        benchRateLimit,
-		Timeout:          time.Duration(benchTimeout) * time.Second,
+		Burst:            benchBurst,
+		PerWorkerRate:    benchPerWorker,
+		Jitter:           benchJitter,
+		Timeout:          time.Duration(opts.Timeout) * time.Second,
 		DisableKeepAlive: benchNoKeepAlive,
+		ColdHot:          benchColdHot,
+		MaxIdleConns:     benchMaxIdleConns,
+		MaxConnsPerHost:  benchMaxConnsHost,
+		IdleTimeout:      benchIdleTimeout,
+		ParamValues:      viper.GetStringMap("params"),
+		Scripts:          scripts,
+		OptionalParams:   benchOptParams,
+		Authenticator:    authenticator,
+		DigestAuth:       digestCredentials(),
+		CookieJar:        jar,
+
+		Adaptive:          benchAdaptive,
+		MaxConcurrency:    benchMaxConc,
+		AdaptiveP99:       benchAdaptiveP99,
+		AdaptiveErrorRate: benchAdaptiveErr,
+
+		CorrelationHeader: correlationHeader,
+		UserAgent:         userAgent(),
+		HostHeader:        hostHeader,
+		Accept:            acceptHeader,
+		AddressFamily:     addressFamily(),
+		DNSCache:          benchDNSCache,
+		Cleanup:           benchCleanup,
+		Order:             order,
+		Seed:              seed,
+	}
+	if benchTraceSlow > 0 {
+		traceFile, err := os.Create(benchTraceFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create trace file: %s\n", err)
+			exit(exitFailed, nil)
+		}
+		defer traceFile.Close()
+		config.TraceSlow = benchTraceSlow
+		config.SlowTraces = traceFile
 	}
 
 	// Print benchmark info
 	fmt.Printf("\n%s\n", white("=== Benchmark Configuration ==="))
 	fmt.Printf("Endpoints:   %d\n", len(filteredOps))
 	fmt.Printf("Iterations:  %d per endpoint\n", config.Iterations)
-	fmt.Printf("Concurrency: %d\n", config.Concurrency)
-	fmt.Printf("Warmup:      %d iterations\n", config.WarmupRuns)
+	if config.Adaptive {
+		limits := fmt.Sprintf("errors <= %g%%", config.AdaptiveErrorRate)
+		if config.AdaptiveP99 > 0 {
+			limits = fmt.Sprintf("p99 <= %v, %s", config.AdaptiveP99, limits)
+		}
+		fmt.Printf("Concurrency: adaptive, %d to %d while %s\n", config.Concurrency, config.MaxConcurrency, limits)
+	} else {
+		fmt.Printf("Concurrency: %d\n", config.Concurrency)
+	}
+	if config.WarmupDuration > 0 {
+		fmt.Printf("Warmup:      %v\n", config.WarmupDuration)
+	} else {
+		fmt.Printf("Warmup:      %d iterations\n", config.WarmupRuns)
+	}
+	if config.GlobalWarmup {
+		fmt.Printf("Global Warmup: one request per endpoint before measuring\n")
+	}
 	if config.RateLimit > 0 {
-		fmt.Printf("Rate Limit:  %.0f req/sec\n", config.RateLimit)
+		line := fmt.Sprintf("%.0f req/sec", config.RateLimit)
+		if config.Burst > 0 {
+			line += fmt.Sprintf(", burst %d", config.Burst)
+		}
+		if config.PerWorkerRate {
+			line += fmt.Sprintf(", split across %d workers", config.Concurrency)
+		}
+		if config.Jitter > 0 {
+			line += fmt.Sprintf(", %.0f%% jitter", config.Jitter*100)
+		}
+		fmt.Printf("Rate Limit:  %s\n", line)
 	}
 	fmt.Printf("Timeout:     %v\n", config.Timeout)
 	fmt.Printf("Keep-Alive:  %v\n", !config.DisableKeepAlive)
+	if config.ColdHot {
+		fmt.Printf("Cold/Hot:    %d requests per endpoint over fresh connections before warmup\n", config.Iterations)
+	}
+	if config.TraceSlow > 0 {
+		fmt.Printf("Trace Slow:  requests over %v to %s\n", config.TraceSlow, benchTraceFile)
+	}
+	if config.MaxConnsPerHost > 0 {
+		fmt.Printf("Conns/Host:  %d max\n", config.MaxConnsPerHost)
+	}
+	if config.HostHeader != "" {
+		fmt.Printf("Host:        %s\n", config.HostHeader)
+	}
+	if config.AddressFamily != tester.AddressFamilyAny {
+		fmt.Printf("IP Family:   %s\n", config.AddressFamily)
+	}
+	switch config.Order {
+	case benchmarker.OrderRandom:
+		fmt.Printf("Order:       random (seed %d)\n", config.Seed)
+	case benchmarker.OrderSlowestFirst:
+		fmt.Printf("Order:       slowest first (one probe request per endpoint)\n")
+	}
+	fmt.Printf("Run ID:      %s\n", runID)
 	fmt.Println()
 
 	// Create benchmarker
 	bench := benchmarker.NewBenchmarker(config)
 
-	// Setup context with signal handling
-	ctx, cancel := context.WithCancel(context.Background())
+	// Setup context with signal handling and the --deadline
+	ctx, cancel := runContext()
 	defer cancel()
 
+	// Resolve hostnames up front so lookups aren't part of measured latency
+	if config.DNSCache {
+		var serverURLs []string
+		for _, op := range filteredOps {
+			serverURLs = append(serverURLs, op.ServerURL)
+		}
+		resolved, err := bench.Resolve(ctx, serverURLs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving hosts: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		for host, addrs := range resolved {
+			fmt.Printf("Resolved %s: %s\n", host, strings.Join(addrs, ", "))
+		}
+		fmt.Println()
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
@@ -147,6 +403,23 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 
 	var s *spinner.Spinner
 	var currentPhase string
+
+	// Interim summary rewritten to the output file after each endpoint
+	interim := models.BenchmarkSummary{
+		Iterations:  config.Iterations,
+		Concurrency: config.Concurrency,
+		WarmupRuns:  config.WarmupRuns,
+		Partial:     true,
+		Order:       config.Order,
+		Build:       buildInfo(),
+		Baseline:    baseline,
+
+		SkippedOperations: skipped,
+	}
+	if config.Order == benchmarker.OrderRandom {
+		interim.Seed = config.Seed
+	}
+	runStart := time.Now()
 	var phaseStartTime time.Time
 
 	// Create event handler for live output
@@ -160,16 +433,33 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 				s.Suffix = fmt.Sprintf(" [%d/%d] %s %s - Warming up...",
 					event.Index+1, event.Total, event.Operation.Method, event.Operation.Path)
 				s.Start()
+			} else if config.WarmupDuration > 0 {
+				fmt.Printf("[%d/%d] %s %s - Warming up (%v)...\n",
+					event.Index+1, event.Total, event.Operation.Method, event.Operation.Path, config.WarmupDuration)
 			} else {
 				fmt.Printf("[%d/%d] %s %s - Warming up (%d iterations)...\n",
 					event.Index+1, event.Total, event.Operation.Method, event.Operation.Path, event.MaxIter)
 			}
 
+		case benchmarker.EventGlobalWarmupStarting:
+			phaseStartTime = time.Now()
+			fmt.Printf("Global warmup: touching %d endpoints...\n", event.Total)
+
+		case benchmarker.EventGlobalWarmupCompleted:
+			fmt.Printf("%s Global warmup completed in %v\n\n",
+				yellow("●"), time.Since(phaseStartTime).Round(time.Millisecond))
+
 		case benchmarker.EventWarmupProgress:
 			if isTTY && s != nil {
-				s.Suffix = fmt.Sprintf(" [%d/%d] %s %s - Warmup %d/%d",
-					event.Index+1, event.Total, event.Operation.Method, event.Operation.Path,
-					event.Progress, event.MaxIter)
+				if config.WarmupDuration > 0 {
+					s.Suffix = fmt.Sprintf(" [%d/%d] %s %s - Warmup %d requests",
+						event.Index+1, event.Total, event.Operation.Method, event.Operation.Path,
+						event.Progress)
+				} else {
+					s.Suffix = fmt.Sprintf(" [%d/%d] %s %s - Warmup %d/%d",
+						event.Index+1, event.Total, event.Operation.Method, event.Operation.Path,
+						event.Progress, event.MaxIter)
+				}
 			}
 
 		case benchmarker.EventWarmupCompleted:
@@ -180,6 +470,26 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 			fmt.Printf("[%d/%d] %s Warmup completed in %v\n",
 				event.Index+1, event.Total, yellow("●"), elapsed.Round(time.Millisecond))
 
+		case benchmarker.EventColdStarting:
+			currentPhase = "cold"
+			phaseStartTime = time.Now()
+			if isTTY {
+				s = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+				s.Suffix = fmt.Sprintf(" [%d/%d] %s %s - Measuring cold connections...",
+					event.Index+1, event.Total, event.Operation.Method, event.Operation.Path)
+				s.Start()
+			} else {
+				fmt.Printf("[%d/%d] %s %s - Measuring cold connections (%d iterations)...\n",
+					event.Index+1, event.Total, event.Operation.Method, event.Operation.Path, event.MaxIter)
+			}
+
+		case benchmarker.EventColdCompleted:
+			if isTTY && s != nil {
+				s.Stop()
+			}
+			fmt.Printf("[%d/%d] %s Cold phase completed in %v\n",
+				event.Index+1, event.Total, yellow("●"), time.Since(phaseStartTime).Round(time.Millisecond))
+
 		case benchmarker.EventBenchmarkStarting:
 			currentPhase = "benchmark"
 			phaseStartTime = time.Now()
@@ -210,6 +520,22 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 			elapsed := time.Since(phaseStartTime)
 			prefix := fmt.Sprintf("[%d/%d]", event.Index+1, event.Total)
 
+			// Flush interim results so an interrupted or crashed run leaves
+			// usable data. Special files such as /dev/stderr or a FIFO can't be
+			// rewritten and only get the final results.
+			if len(dests) > 0 {
+				interim.AddResult(*result)
+				interim.Finalize(time.Since(runStart))
+				for _, dest := range dests {
+					if dest.Path == "" || !output.Rewritable(dest.Path) {
+						continue
+					}
+					if err := output.ExportBenchmarkSummary(interim, dest.Format, dest.Path); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to write interim results: %s\n", mask.String(err.Error()))
+					}
+				}
+			}
+
 			// Status indicator based on error rate
 			var status string
 			if result.ErrorRate == 0 {
@@ -230,8 +556,20 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 				avgMs, p99Ms, result.RequestsPerSec,
 				result.ErrorCount, result.ErrorRate)
 
+			if result.Cold != nil {
+				displayColdHot(*result)
+			}
+			if a := result.Adaptive; a != nil {
+				sustained := red("no concurrency stayed within the limits")
+				if a.Sustainable > 0 {
+					sustained = fmt.Sprintf("sustained %s workers", green(a.Sustainable))
+				}
+				fmt.Printf("    Adaptive: %s | ended at %d | %d windows, %d back-offs\n",
+					sustained, a.Final, len(a.Steps), a.Backoffs)
+			}
+
 			// Verbose output: show all details
-			if verbose {
+			if opts.Verbose {
 				minMs := float64(result.MinTime.Microseconds()) / 1000
 				maxMs := float64(result.MaxTime.Microseconds()) / 1000
 				p50Ms := float64(result.P50Time.Microseconds()) / 1000
@@ -239,8 +577,54 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 
 				fmt.Printf("    Latency:  min=%.2fms | p50=%.2fms | p90=%.2fms | max=%.2fms\n",
 					minMs, p50Ms, p90Ms, maxMs)
+				if c := result.Corrected; c != nil {
+					fmt.Printf("    Corrected: p50=%.2fms | p90=%.2fms | p99=%.2fms | max=%.2fms (from intended send time)\n",
+						float64(c.P50Time.Microseconds())/1000,
+						float64(c.P90Time.Microseconds())/1000,
+						float64(c.P99Time.Microseconds())/1000,
+						float64(c.MaxTime.Microseconds())/1000)
+				}
 				fmt.Printf("    Duration: %v | Success: %d | Errors: %d\n",
 					elapsed.Round(time.Millisecond), result.SuccessCount, result.ErrorCount)
+				fmt.Printf("    Connections: %d new | %d reused\n", result.NewConns, result.ReusedConns)
+				if w := result.Workers; w != nil && len(w.WorkerRequests) > 0 {
+					line := fmt.Sprintf("    Workers:  %d | requests per worker min=%d max=%d",
+						len(w.WorkerRequests), slices.Min(w.WorkerRequests), slices.Max(w.WorkerRequests))
+					if config.RateLimit > 0 {
+						line += fmt.Sprintf(" | queue wait avg=%.2fms | rate limit wait avg=%.2fms (%.0f%%)",
+							float64(w.AvgQueueWait.Microseconds())/1000,
+							float64(w.AvgLimiterWait.Microseconds())/1000,
+							w.LimiterWaitPct)
+					}
+					fmt.Println(line)
+					if w.LimiterWaitPct >= 50 {
+						fmt.Printf("    %s\n", yellow("Throughput was capped by --rate, not by the server"))
+					}
+				}
+				if result.CleanedUp > 0 || result.CleanupFailed > 0 {
+					fmt.Printf("    Cleanup:  %d deleted | %d failed\n", result.CleanedUp, result.CleanupFailed)
+				}
+				if w := result.Warmup; w != nil {
+					fmt.Printf("    Warmup:   %d requests | avg=%.2fms | p50=%.2fms | p99=%.2fms | errors=%d\n",
+						w.Requests,
+						float64(w.AvgTime.Microseconds())/1000,
+						float64(w.P50Time.Microseconds())/1000,
+						float64(w.P99Time.Microseconds())/1000,
+						w.ErrorCount)
+				}
+				if result.AddressFamily != "" {
+					fmt.Printf("    Address family: %s\n", result.AddressFamily)
+				}
+				for _, ip := range result.PerIP {
+					fmt.Printf("    %s: %d requests | avg=%.2fms | p50=%.2fms | p99=%.2fms\n",
+						ip.IP, ip.Requests,
+						float64(ip.AvgTime.Microseconds())/1000,
+						float64(ip.P50Time.Microseconds())/1000,
+						float64(ip.P99Time.Microseconds())/1000)
+				}
+				if result.SlowestRequestID != "" {
+					fmt.Printf("    Slowest:  %s\n", result.SlowestRequestID)
+				}
 
 				if len(result.StatusCodes) > 0 {
 					var codes []string
@@ -261,33 +645,82 @@ func runBenchmark(cmd *cobra.Command, args []string) {
 		_ = currentPhase // silence unused warning
 	}
 
-	// Run benchmarks
-	summary := bench.BenchmarkOperations(ctx, filteredOps, p, onEvent)
+	// Expose the Go profiler for inspecting the load generator itself
+	if benchPprof != "" {
+		go func() {
+			if err := http.ListenAndServe(benchPprof, pprofHandler()); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: pprof server stopped: %v\n", err)
+			}
+		}()
+		fmt.Printf("pprof:       http://%s/debug/pprof/\n", benchPprof)
+	}
 
-	// Handle output format
-	if benchOutputFormat != "" {
-		format, err := output.ParseFormat(benchOutputFormat)
+	// Serve the live dashboard alongside the terminal output
+	run := benchmarker.OnBenchmarkEvent(onEvent)
+	if benchDashboard != "" {
+		dash := dashboard.New(benchDashboard)
+		url, err := dash.Start()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
 		}
+		defer dash.Close()
+		fmt.Printf("Dashboard:   %s\n\n", url)
 
-		if err := output.ExportBenchmarkSummary(summary, format, benchOutputFile); err != nil {
-			fmt.Fprintf(os.Stderr, "Error exporting results: %v\n", err)
-			os.Exit(1)
+		run = func(event benchmarker.BenchmarkEvent) {
+			dash.Handle(event)
+			onEvent(event)
 		}
+	}
 
-		// If writing to file, still show summary
-		if benchOutputFile != "" {
-			fmt.Printf("\nResults exported to: %s\n", benchOutputFile)
-			displayBenchmarkSummary(summary)
+	// Run benchmarks
+	summary := bench.BenchmarkOperations(ctx, filteredOps, p, ciBenchmarkEvents(run))
+	summary.SkippedOperations = append(skipped, summary.SkippedOperations...)
+	summary.Budgets = benchmarker.EvaluateBudgets(summary.Results, budgets)
+	summary.ErrorLimits = benchmarker.EvaluateErrorLimits(summary, errorLimits)
+	summary.Build = buildInfo()
+	summary.Baseline = baseline
+
+	// Handle output destinations
+	for _, dest := range dests {
+		if err := output.ExportBenchmarkSummary(summary, dest.Format, dest.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting results: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
 		}
+	}
+	if len(dests) > 0 {
 		// If writing to stdout, skip display (already output)
-		return
+		if !output.WritesStdout(dests) {
+			fmt.Println()
+			for _, dest := range dests {
+				fmt.Printf("Results exported to: %s\n", dest.Path)
+			}
+			displayBenchmarkSummary(summary)
+		}
+	} else {
+		displayBenchmarkSummary(summary)
 	}
 
-	// Display summary
-	displayBenchmarkSummary(summary)
+	// Only --ci fails a benchmark whose API never answered, exceeded budgets
+	// and error limits fail in every mode
+	code := exitOK
+	if ciMode || summary.DeadlineExceeded || !summary.BudgetsMet() || !summary.ErrorLimitsMet() {
+		code = benchmarkExitCode(summary)
+	}
+	exit(code, summary)
+}
+
+// displayColdHot shows the latency over fresh connections next to the pooled,
+// warmed up latency, so the cost of connection setup is visible
+func displayColdHot(result models.BenchmarkResult) {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	cold := result.Cold
+	fmt.Printf("    %-6s p50=%.2fms | p90=%.2fms | p99=%.2fms | max=%.2fms | errors: %d\n",
+		"Cold:", ms(cold.P50Time), ms(cold.P90Time), ms(cold.P99Time), ms(cold.MaxTime), cold.ErrorCount)
+	fmt.Printf("    %-6s p50=%.2fms | p90=%.2fms | p99=%.2fms | max=%.2fms | errors: %d\n",
+		"Hot:", ms(result.P50Time), ms(result.P90Time), ms(result.P99Time), ms(result.MaxTime), result.ErrorCount)
+	fmt.Printf("    %-6s p50=%+.2fms | p90=%+.2fms | p99=%+.2fms (connection setup cost)\n",
+		"Setup:", ms(cold.P50Time-result.P50Time), ms(cold.P90Time-result.P90Time), ms(cold.P99Time-result.P99Time))
 }
 
 func displayBenchmarkSummary(summary models.BenchmarkSummary) {
@@ -296,6 +729,9 @@ func displayBenchmarkSummary(summary models.BenchmarkSummary) {
 	fmt.Printf("Total Endpoints:    %d\n", summary.TotalEndpoints)
 	fmt.Printf("Total Requests:     %d\n", summary.TotalRequests)
 	fmt.Printf("Total Duration:     %v\n", summary.TotalDuration.Round(time.Millisecond))
+	if summary.DeadlineExceeded {
+		fmt.Printf("%s\n", red(fmt.Sprintf("Deadline of %v exceeded, partial results", deadline)))
+	}
 	fmt.Printf("Overall Throughput: %s\n", cyan(fmt.Sprintf("%.1f req/sec", summary.OverallReqsPerSec)))
 	fmt.Println()
 
@@ -306,6 +742,22 @@ func displayBenchmarkSummary(summary models.BenchmarkSummary) {
 	fmt.Printf("  Max: %.2fms\n", float64(summary.OverallMaxTime.Microseconds())/1000)
 	fmt.Println()
 
+	// Load generator resource usage
+	if c := summary.Client; c != nil {
+		cpu := fmt.Sprintf("%.1f%% of %d cores", c.CPUPercent, c.NumCPU)
+		if c.CPUPercent >= 80 {
+			cpu = red(cpu + " (client may be the bottleneck)")
+		}
+		fmt.Printf("%s\n", white("Client Resources:"))
+		fmt.Printf("  CPU:        %s\n", cpu)
+		fmt.Printf("  Peak Heap:  %.1f MiB\n", float64(c.PeakHeapBytes)/(1<<20))
+		fmt.Printf("  Goroutines: %d peak\n", c.PeakGoroutines)
+		if c.PeakOpenFDs > 0 {
+			fmt.Printf("  Open FDs:   %d peak\n", c.PeakOpenFDs)
+		}
+		fmt.Println()
+	}
+
 	// Error summary
 	if summary.TotalErrors > 0 {
 		fmt.Printf("%s\n", white("Error Summary:"))
@@ -316,9 +768,23 @@ func displayBenchmarkSummary(summary models.BenchmarkSummary) {
 		fmt.Printf("Errors: %s\n", green("0"))
 		fmt.Println()
 	}
+	if len(summary.Errors) > 0 {
+		fmt.Printf("Failed Requests by Class: %s\n", formatErrorClasses(summary.Errors))
+		fmt.Println()
+	}
+
+	// Slow request traces
+	if benchTraceSlow > 0 {
+		slow := 0
+		for _, r := range summary.Results {
+			slow += r.SlowRequests
+		}
+		fmt.Printf("Slow Requests: %d over %v traced to %s\n", slow, benchTraceSlow, benchTraceFile)
+		fmt.Println()
+	}
 
 	// Per-endpoint table (if verbose or few endpoints)
-	if verbose || len(summary.Results) <= 10 {
+	if opts.Verbose || len(summary.Results) <= 10 {
 		fmt.Printf("%s\n", white("Per-Endpoint Results:"))
 		fmt.Printf("%-8s %-40s %10s %10s %10s %10s\n",
 			"METHOD", "PATH", "AVG(ms)", "P99(ms)", "REQ/S", "ERR%")
@@ -337,26 +803,139 @@ func displayBenchmarkSummary(summary models.BenchmarkSummary) {
 				r.ErrorRate)
 		}
 	}
+
+	displayBudgets(summary.Budgets)
+	displayErrorLimits(summary.ErrorLimits)
+	displaySkippedOperations(summary.SkippedOperations, summary.TotalEndpoints)
+}
+
+// displayBudgets shows how much of each tag's latency budget its slowest
+// endpoint used
+func displayBudgets(budgets []models.BudgetResult) {
+	if len(budgets) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", white("Latency Budgets:"))
+	for _, b := range budgets {
+		if b.Endpoints == 0 {
+			fmt.Printf("  %s %s %s: no benchmarked endpoints\n", yellow("-"), b.Tag, b.Budget)
+			continue
+		}
+		status := green("✓")
+		if !b.Passed {
+			status = red("✗")
+		}
+		fmt.Printf("  %s %s %s: %.2fms (%.0f%% of budget, slowest %s)\n",
+			status, b.Tag, b.Budget,
+			float64(b.Actual.Microseconds())/1000, b.Utilization, b.Endpoint)
+	}
+}
+
+// displayErrorLimits shows the failed requests of each limited error class
+func displayErrorLimits(limits []models.ErrorLimitResult) {
+	if len(limits) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", white("Error Limits:"))
+	for _, l := range limits {
+		status := green("✓")
+		if !l.Passed {
+			status = red("✗")
+		}
+		fmt.Printf("  %s %s <= %s: %d (%.2f%% of requests)\n", status, l.Class, l.Limit, l.Actual, l.Rate)
+	}
+}
+
+// pprofHandler serves the Go profiles of --pprof on a mux of its own, so
+// nothing else registered on http.DefaultServeMux is exposed with them
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// latencyBudgets collects the per-tag latency budgets from --budget and the
+// [budgets] config section, whose values are an expression or a list of them
+func latencyBudgets() ([]benchmarker.Budget, error) {
+	var budgets []benchmarker.Budget
+	for tag, value := range viper.GetStringMap("budgets") {
+		var exprs []string
+		switch v := value.(type) {
+		case string:
+			exprs = []string{v}
+		case []interface{}:
+			for _, item := range v {
+				exprs = append(exprs, fmt.Sprint(item))
+			}
+		default:
+			return nil, fmt.Errorf("invalid budget for tag %s: expected an expression such as \"p99<300ms\" or a list of them", tag)
+		}
+		for _, expr := range exprs {
+			budget, err := benchmarker.ParseBudget(tag, expr)
+			if err != nil {
+				return nil, err
+			}
+			budgets = append(budgets, budget)
+		}
+	}
+	for _, s := range benchBudgets {
+		budget, err := benchmarker.ParseTagBudget(s)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
 }
 
 func init() {
 	rootCmd.AddCommand(benchmarkCmd)
 
 	// Reuse shared flags from test command
-	benchmarkCmd.Flags().StringVar(&serverURL, "server", "", "Override server URL from OpenAPI spec")
-	benchmarkCmd.Flags().StringVar(&filter, "filter", "", "Filter endpoints by path pattern or operation ID")
-	benchmarkCmd.Flags().StringSliceVar(&tags, "tags", []string{}, "Filter by OpenAPI tags")
-	benchmarkCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed output")
+	benchmarkCmd.Flags().StringVar(&correlationHeader, "correlation-header", "", "Send a unique id per request in this header (e.g. X-Request-Id)")
 
 	// Benchmark-specific flags
 	benchmarkCmd.Flags().IntVarP(&benchIterations, "iterations", "n", 100, "Number of requests per endpoint")
 	benchmarkCmd.Flags().IntVarP(&benchConcurrency, "concurrency", "c", 1, "Number of concurrent requests")
 	benchmarkCmd.Flags().IntVarP(&benchWarmup, "warmup", "w", 5, "Number of warmup iterations (discarded from stats)")
+	benchmarkCmd.Flags().DurationVar(&benchWarmupDur, "warmup-duration", 0, "Warm up each endpoint for this long instead of a number of iterations (e.g. 10s)")
+	benchmarkCmd.Flags().BoolVar(&benchGlobalWarmup, "global-warmup", false, "Send one request to every endpoint before measuring any of them")
+	benchmarkCmd.Flags().BoolVar(&benchReportWarmup, "report-warmup", false, "Report warmup samples separately instead of discarding them")
 	benchmarkCmd.Flags().Float64VarP(&benchRateLimit, "rate", "r", 0, "Max requests per second (0 = unlimited)")
-	benchmarkCmd.Flags().IntVarP(&benchTimeout, "timeout", "t", 30, "Request timeout in seconds")
+	benchmarkCmd.Flags().IntVar(&benchBurst, "burst", 0, "Requests --rate lets through at once (default: one second's worth)")
+	benchmarkCmd.Flags().BoolVar(&benchPerWorker, "per-worker-rate", false, "Split --rate and --burst evenly across the workers, each with its own limiter")
+	benchmarkCmd.Flags().Float64Var(&benchJitter, "jitter", 0, "Delay each rate-limited request by a random part of the request interval, up to this fraction (0-1)")
 	benchmarkCmd.Flags().BoolVar(&benchNoKeepAlive, "no-keepalive", false, "Disable HTTP connection reuse")
+	benchmarkCmd.Flags().BoolVar(&benchAdaptive, "adaptive", false, "Raise the concurrency from --concurrency while error rate and p99 stay within limits, halve it when they don't")
+	benchmarkCmd.Flags().IntVar(&benchMaxConc, "max-concurrency", benchmarker.DefaultMaxConcurrency, "Highest concurrency --adaptive tries")
+	benchmarkCmd.Flags().DurationVar(&benchAdaptiveP99, "adaptive-p99", 0, "p99 latency --adaptive allows, e.g. 300ms (0 = no latency limit)")
+	benchmarkCmd.Flags().Float64Var(&benchAdaptiveErr, "adaptive-errors", benchmarker.DefaultAdaptiveErrorRate, "Percentage of transport errors, 429 and 5xx responses --adaptive allows")
+	benchmarkCmd.Flags().BoolVar(&benchColdHot, "cold-hot", false, "Also measure each endpoint over fresh connections before warmup and compare cold with hot percentiles")
+	benchmarkCmd.MarkFlagsMutuallyExclusive("cold-hot", "no-keepalive")
+	benchmarkCmd.Flags().IntVar(&benchMaxIdleConns, "max-idle-conns", 100, "Max idle connections kept in the pool (0 = unlimited)")
+	benchmarkCmd.Flags().IntVar(&benchMaxConnsHost, "max-conns-per-host", 0, "Max connections per host, including active ones (0 = unlimited)")
+	benchmarkCmd.Flags().DurationVar(&benchIdleTimeout, "idle-timeout", 90*time.Second, "How long idle connections are kept open")
+	benchmarkCmd.Flags().BoolVar(&skipDeprecated, "skip-deprecated", false, "Skip operations marked deprecated in the spec")
+	benchmarkCmd.Flags().BoolVar(&benchMutations, "include-mutations", false, "Also benchmark non-idempotent operations (POST, PUT, PATCH, DELETE)")
+	benchmarkCmd.Flags().Float64Var(&benchOptParams, "include-optional-params", 0, "Also send optional query parameters, each with this probability (0-1)")
+	benchmarkCmd.Flags().Lookup("include-optional-params").NoOptDefVal = "1"
+	benchmarkCmd.Flags().BoolVar(&benchCleanup, "cleanup", false, "Delete resources created by POST requests after each endpoint (with --include-mutations)")
+	benchmarkCmd.Flags().StringVar(&benchOrder, "order", "spec", "Endpoint order: spec, random, slowest-first")
+	benchmarkCmd.Flags().Int64Var(&benchSeed, "seed", 0, "Seed for --order random (default: random, printed for reproduction)")
+	benchmarkCmd.Flags().StringVar(&benchDashboard, "dashboard", "", "Serve a live web dashboard on this address (e.g. :8089)")
+	benchmarkCmd.Flags().StringVar(&benchPprof, "pprof", "", "Serve Go pprof profiles of the load generator on this address (e.g. 127.0.0.1:6060)")
+	benchmarkCmd.Flags().StringArrayVar(&benchBudgets, "budget", nil, "Latency budget for the endpoints of a tag, e.g. payments:p99<300ms (repeatable; see [budgets] in config.toml)")
+	benchmarkCmd.Flags().StringSliceVar(&benchMaxErrors, "max-errors", nil, "Fail the run if an error class exceeds a request count or percentage, e.g. 5xx=0,timeout=0.5% (classes: connection, dns, tls, timeout, 4xx, 5xx, other)")
+	benchmarkCmd.Flags().DurationVar(&benchTraceSlow, "trace-slow", 0, "Trace requests slower than this (e.g. 500ms) with their timing breakdown and headers to --trace-file")
+	benchmarkCmd.Flags().StringVar(&benchTraceFile, "trace-file", "slow-requests.jsonl", "File --trace-slow writes a JSON line per slow request to")
+	benchmarkCmd.Flags().StringVar(&benchBaseline, "baseline", "", "Annotate markdown and html reports with the changes from this earlier run (exported with -o json)")
+	benchmarkCmd.Flags().BoolVar(&benchDNSCache, "dns-cache", false, "Resolve hostnames once before the benchmark and reuse the addresses")
 
 	// Output flags
-	benchmarkCmd.Flags().StringVarP(&benchOutputFormat, "output", "o", "", "Output format: json, csv")
-	benchmarkCmd.Flags().StringVar(&benchOutputFile, "output-file", "", "Write output to file (default: stdout)")
 }
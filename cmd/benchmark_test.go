@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPprofHandler(t *testing.T) {
+	http.HandleFunc("/debug/unrelated", func(w http.ResponseWriter, r *http.Request) {})
+
+	handler := pprofHandler()
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/debug/pprof/", want: http.StatusOK},
+		{path: "/debug/pprof/heap", want: http.StatusOK},
+		{path: "/debug/pprof/cmdline", want: http.StatusOK},
+		{path: "/debug/pprof/symbol", want: http.StatusOK},
+		{path: "/debug/unrelated", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/moamenhredeen/oas/internal/benchmarker"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/output"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+// Exit codes in --ci mode. Without --ci every failure exits with exitFailed.
+const (
+	exitOK          = 0
+	exitFailed      = 1 // tests failed, thresholds not met or an error occurred
+	exitUsage       = 2 // invalid flags or arguments
+	exitUnreachable = 3 // no request got a response from the API
+	exitDeadline    = 4 // --deadline passed before the run finished
+)
+
+// deadlineGrace is how long a command may take to stop and report partial
+// results once --deadline passed before it is aborted
+const deadlineGrace = 10 * time.Second
+
+var (
+	ciMode     bool
+	deadline   time.Duration
+	deadlineAt time.Time
+
+	// ciEvents receives the NDJSON event stream: the process's real stdout,
+	// while human-readable output is moved to stderr
+	ciEvents   io.Writer
+	ciEventsMu sync.Mutex
+)
+
+// ciEvent is a line of the --ci event stream
+type ciEvent struct {
+	Event       string      `json:"event"`
+	Time        time.Time   `json:"time"`
+	RunID       string      `json:"run_id,omitempty"`
+	Command     string      `json:"command,omitempty"`
+	Version     string      `json:"version,omitempty"`
+	Spec        string      `json:"spec,omitempty"`
+	Index       *int        `json:"index,omitempty"`
+	Total       int         `json:"total,omitempty"`
+	Method      string      `json:"method,omitempty"`
+	Path        string      `json:"path,omitempty"`
+	OperationID string      `json:"operation_id,omitempty"`
+	Completed   int         `json:"completed,omitempty"`
+	Iterations  int         `json:"iterations,omitempty"`
+	Result      interface{} `json:"result,omitempty"`
+	Summary     interface{} `json:"summary,omitempty"`
+	ExitCode    *int        `json:"exit_code,omitempty"`
+	Message     string      `json:"message,omitempty"`
+}
+
+// setupCI switches to machine mode: no color or spinners regardless of what
+// the terminal detection says, NDJSON events on stdout and human output on
+// stderr. The deadline is enforced in every mode: runs cancel their requests
+// through runContext, and anything still going after deadlineGrace (e.g. a
+// login or a command without a context) is aborted. The --control-socket is
+// opened in every mode too.
+func setupCI(command string) {
+	if controlSocket != "" {
+		if err := startControl(command); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitUsage, nil)
+		}
+	}
+	if deadline > 0 {
+		deadlineAt = time.Now().Add(deadline)
+		time.AfterFunc(deadline+deadlineGrace, func() {
+			fmt.Fprintf(os.Stderr, "Error: deadline of %v exceeded\n", deadline)
+			exit(exitDeadline, nil)
+		})
+	}
+	if !ciMode {
+		return
+	}
+
+	color.NoColor = true
+	isTTY = false
+	ciEvents = os.Stdout
+	os.Stdout = os.Stderr
+
+	emitEvent(ciEvent{Event: "run_started", RunID: runID, Command: command, Version: build.Version})
+}
+
+// runContext returns the context of a run, done when --deadline passes or
+// the run is cancelled through the --control-socket
+func runContext() (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if deadlineAt.IsZero() {
+		ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		ctx, cancel = context.WithDeadline(context.Background(), deadlineAt)
+	}
+	if control != nil {
+		control.setCancel(cancel)
+	}
+	return ctx, cancel
+}
+
+// eventsEnabled reports whether run events are reported, to the --ci stream
+// or the --control-socket
+func eventsEnabled() bool {
+	return ciMode || control != nil
+}
+
+// emitEvent writes an event to the --ci stream and the --control-socket, if
+// enabled
+func emitEvent(e ciEvent) {
+	if !eventsEnabled() {
+		return
+	}
+	e.Time = time.Now().UTC()
+	if control != nil {
+		control.broadcast(e)
+	}
+	if ciEvents == nil {
+		return
+	}
+
+	ciEventsMu.Lock()
+	defer ciEventsMu.Unlock()
+	json.NewEncoder(ciEvents).Encode(e)
+}
+
+// ciFlagSet reports whether args turn --ci on, as --ci or --ci=<bool>. It
+// backs up flag parsing, which may stop before reaching the flag.
+func ciFlagSet(args []string) bool {
+	enabled := false
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--ci" {
+			enabled = true
+		} else if value, ok := strings.CutPrefix(arg, "--ci="); ok {
+			enabled, _ = strconv.ParseBool(value)
+		}
+	}
+	return enabled
+}
+
+// exit ends the run, reporting the summary and exit code in --ci mode.
+// Without --ci every non-zero code becomes exitFailed.
+func exit(code int, summary interface{}) {
+	if !ciMode && code != exitOK {
+		code = exitFailed
+	}
+	if code == exitDeadline {
+		emitEvent(ciEvent{Event: "deadline_exceeded", Message: fmt.Sprintf("deadline of %v exceeded", deadline)})
+	}
+	emitEvent(ciEvent{Event: "run_completed", Summary: summary, ExitCode: &code})
+	if control != nil {
+		control.close()
+	}
+	os.Exit(code)
+}
+
+// checkCIDestinations rejects output written to stdout in --ci mode, where
+// stdout carries the event stream, including paths such as /dev/stdout
+func checkCIDestinations(dests []output.Destination) error {
+	if ciMode && output.WritesStdout(dests) {
+		return fmt.Errorf("--ci writes events to stdout, pass an --output-file other than stdout for results")
+	}
+	return nil
+}
+
+// ciTestEvents forwards test events of a spec to the --ci stream and the
+// --control-socket before passing them on
+func ciTestEvents(spec string, next tester.OnTestEvent) tester.OnTestEvent {
+	if !eventsEnabled() {
+		return next
+	}
+	return func(event tester.TestEvent) {
+		index := event.Index
+		e := ciEvent{
+			Spec:        spec,
+			Index:       &index,
+			Total:       event.Total,
+			Method:      event.Operation.Method,
+			Path:        event.Operation.Path,
+			OperationID: event.Operation.OperationID,
+		}
+		switch event.Type {
+		case tester.EventStarting:
+			e.Event = "test_started"
+		case tester.EventCompleted:
+			e.Event = "test_completed"
+			e.Result = event.Result
+		}
+		emitEvent(e)
+		next(event)
+	}
+}
+
+// ciBenchmarkEvents forwards the phases of a benchmark to the --ci stream and
+// the --control-socket before passing them on. Progress events only go to the
+// control socket, keeping the --ci stream small.
+func ciBenchmarkEvents(next benchmarker.OnBenchmarkEvent) benchmarker.OnBenchmarkEvent {
+	if !eventsEnabled() {
+		return next
+	}
+	names := map[benchmarker.EventType]string{
+		benchmarker.EventGlobalWarmupStarting:  "global_warmup_started",
+		benchmarker.EventGlobalWarmupCompleted: "global_warmup_completed",
+		benchmarker.EventColdStarting:          "cold_started",
+		benchmarker.EventColdCompleted:         "cold_completed",
+		benchmarker.EventWarmupStarting:        "warmup_started",
+		benchmarker.EventWarmupCompleted:       "warmup_completed",
+		benchmarker.EventBenchmarkStarting:     "benchmark_started",
+		benchmarker.EventBenchmarkCompleted:    "benchmark_completed",
+	}
+	return func(event benchmarker.BenchmarkEvent) {
+		if name, ok := names[event.Type]; ok {
+			index := event.Index
+			e := ciEvent{
+				Event:       name,
+				Index:       &index,
+				Total:       event.Total,
+				Method:      event.Operation.Method,
+				Path:        event.Operation.Path,
+				OperationID: event.Operation.OperationID,
+			}
+			if event.Result != nil {
+				e.Result = event.Result
+			}
+			emitEvent(e)
+		} else if event.Type == benchmarker.EventBenchmarkProgress && control != nil {
+			control.broadcast(ciEvent{
+				Event:       "benchmark_progress",
+				Time:        time.Now().UTC(),
+				Index:       &event.Index,
+				Total:       event.Total,
+				Method:      event.Operation.Method,
+				Path:        event.Operation.Path,
+				OperationID: event.Operation.OperationID,
+				Completed:   event.Progress,
+				Iterations:  event.MaxIter,
+			})
+		}
+		next(event)
+	}
+}
+
+// testExitCode maps a test summary to an exit code
+func testExitCode(summary models.TestSummary) int {
+	if summary.DeadlineExceeded {
+		return exitDeadline
+	}
+	if summary.TotalTests > 0 {
+		reached := false
+		for _, r := range summary.Results {
+			if r.StatusCode != 0 || r.Passed {
+				reached = true
+				break
+			}
+		}
+		if !reached {
+			return exitUnreachable
+		}
+	}
+	if summary.Failed > 0 || !summary.CoverageMet() || (failUnsupported && summary.Unsupported > 0) {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// benchmarkExitCode maps a benchmark summary to an exit code. Error rates
+// don't fail a benchmark, but an API that never answered, an exceeded
+// latency budget or an exceeded error limit does.
+func benchmarkExitCode(summary models.BenchmarkSummary) int {
+	if summary.DeadlineExceeded {
+		return exitDeadline
+	}
+	reached := len(summary.Results) == 0
+	for _, r := range summary.Results {
+		if len(r.StatusCodes) > 0 || r.SuccessCount > 0 {
+			reached = true
+			break
+		}
+	}
+	if !reached {
+		return exitUnreachable
+	}
+	if !summary.BudgetsMet() || !summary.ErrorLimitsMet() {
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestTestExitCode(t *testing.T) {
+	passed := models.TestResult{Passed: true, StatusCode: 200}
+	failed := models.TestResult{StatusCode: 500}
+	refused := models.TestResult{Error: "request failed: connection refused"}
+
+	tests := []struct {
+		name            string
+		summary         models.TestSummary
+		failUnsupported bool
+		want            int
+	}{
+		{name: "all passed", summary: models.TestSummary{TotalTests: 1, Passed: 1, Results: []models.TestResult{passed}}, want: exitOK},
+		{name: "no tests", summary: models.TestSummary{}, want: exitOK},
+		{name: "failure", summary: models.TestSummary{TotalTests: 2, Passed: 1, Failed: 1, Results: []models.TestResult{passed, failed}}, want: exitFailed},
+		{name: "unreachable", summary: models.TestSummary{TotalTests: 1, Failed: 1, Results: []models.TestResult{refused}}, want: exitUnreachable},
+		{name: "deadline", summary: models.TestSummary{TotalTests: 1, Failed: 1, Results: []models.TestResult{refused}, DeadlineExceeded: true}, want: exitDeadline},
+		{name: "coverage below minimum", summary: models.TestSummary{TotalTests: 1, Passed: 1, Results: []models.TestResult{passed}, Coverage: 50, MinCoverage: 80}, want: exitFailed},
+		{name: "unsupported allowed", summary: models.TestSummary{TotalTests: 1, Passed: 1, Results: []models.TestResult{passed}, Unsupported: 1}, want: exitOK},
+		{name: "unsupported failing", summary: models.TestSummary{TotalTests: 1, Passed: 1, Results: []models.TestResult{passed}, Unsupported: 1}, failUnsupported: true, want: exitFailed},
+	}
+
+	defer func(v bool) { failUnsupported = v }(failUnsupported)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failUnsupported = tt.failUnsupported
+			if got := testExitCode(tt.summary); got != tt.want {
+				t.Errorf("Expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBenchmarkExitCode(t *testing.T) {
+	answered := models.BenchmarkResult{SuccessCount: 10, StatusCodes: map[int]int{200: 10}}
+	errored := models.BenchmarkResult{StatusCodes: map[int]int{503: 10}}
+	silent := models.BenchmarkResult{ErrorCount: 10}
+
+	tests := []struct {
+		name    string
+		summary models.BenchmarkSummary
+		want    int
+	}{
+		{name: "answered", summary: models.BenchmarkSummary{Results: []models.BenchmarkResult{answered}}, want: exitOK},
+		{name: "error statuses still reach the API", summary: models.BenchmarkSummary{Results: []models.BenchmarkResult{errored}}, want: exitOK},
+		{name: "no results", summary: models.BenchmarkSummary{}, want: exitOK},
+		{name: "unreachable", summary: models.BenchmarkSummary{Results: []models.BenchmarkResult{silent}}, want: exitUnreachable},
+		{name: "deadline", summary: models.BenchmarkSummary{Results: []models.BenchmarkResult{silent}, DeadlineExceeded: true}, want: exitDeadline},
+		{name: "budget exceeded", summary: models.BenchmarkSummary{Results: []models.BenchmarkResult{answered}, Budgets: []models.BudgetResult{{Passed: false}}}, want: exitFailed},
+		{name: "budget met", summary: models.BenchmarkSummary{Results: []models.BenchmarkResult{answered}, Budgets: []models.BudgetResult{{Passed: true}}}, want: exitOK},
+		{name: "error limit exceeded", summary: models.BenchmarkSummary{Results: []models.BenchmarkResult{answered}, ErrorLimits: []models.ErrorLimitResult{{Passed: false}}}, want: exitFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := benchmarkExitCode(tt.summary); got != tt.want {
+				t.Errorf("Expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCIFlagSet(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"test", "api.json", "--ci"}, true},
+		{[]string{"test", "--ci=true", "api.json"}, true},
+		{[]string{"test", "--ci=1"}, true},
+		{[]string{"test", "--ci=false"}, false},
+		{[]string{"test", "--ci", "--ci=false"}, false},
+		{[]string{"test", "--", "--ci"}, false},
+		{[]string{"test", "--city"}, false},
+	}
+	for _, tt := range tests {
+		if got := ciFlagSet(tt.args); got != tt.want {
+			t.Errorf("ciFlagSet(%q) = %v, expected %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestEmitEvent(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(mode bool) { ciMode, ciEvents = mode, nil }(ciMode)
+	ciMode, ciEvents = true, &buf
+
+	index := 0
+	emitEvent(ciEvent{Event: "test_started", Index: &index, Total: 2, Method: "GET", Path: "/pets"})
+	code := exitFailed
+	emitEvent(ciEvent{Event: "run_completed", ExitCode: &code})
+
+	// One JSON object per line
+	if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != 2 {
+		t.Fatalf("Expected 2 lines, got %d:\n%s", lines, buf.String())
+	}
+	dec := json.NewDecoder(&buf)
+	var events []map[string]interface{}
+	for dec.More() {
+		var e map[string]interface{}
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("Invalid event: %v", err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0]["event"] != "test_started" || events[0]["index"] != float64(0) || events[0]["path"] != "/pets" {
+		t.Errorf("Unexpected first event: %v", events[0])
+	}
+	if _, ok := events[0]["exit_code"]; ok {
+		t.Errorf("Expected no exit code on a test event: %v", events[0])
+	}
+	if events[1]["event"] != "run_completed" || events[1]["exit_code"] != float64(exitFailed) || events[1]["time"] == nil {
+		t.Errorf("Unexpected last event: %v", events[1])
+	}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// controlWriteTimeout bounds how long a slow subscriber may hold up the run
+// before it is disconnected
+const controlWriteTimeout = time.Second
+
+var (
+	controlSocket string
+
+	// control serves --control-socket, nil when not enabled
+	control *controlServer
+)
+
+// controlServer is a JSON-RPC 2.0 endpoint on a local socket, for IDE plugins
+// and orchestration tools wrapping a run. Requests and responses are JSON
+// objects, one per line:
+//
+//	subscribe  receive the run's events as "event" notifications
+//	cancel     stop the run, reporting partial results as on an interrupt
+type controlServer struct {
+	listener net.Listener
+	command  string
+
+	mu          sync.Mutex
+	subscribers map[*controlConn]bool
+	cancel      context.CancelFunc // cancels the run, nil until it starts
+	cancelled   bool               // cancel was requested, possibly before the run started
+}
+
+// controlConn is a client connection to the control socket
+type controlConn struct {
+	conn net.Conn
+	mu   sync.Mutex // serializes writes
+}
+
+// rpcRequest is a JSON-RPC 2.0 request or notification
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+}
+
+// rpcMessage is a JSON-RPC 2.0 response or notification sent to a client
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+)
+
+// startControl listens on the --control-socket. An existing file at the path
+// is never removed, so a socket still served by another run is not stolen.
+func startControl(command string) error {
+	listener, err := net.Listen("unix", controlSocket)
+	if err != nil {
+		return fmt.Errorf("listening on control socket: %w", err)
+	}
+	control = &controlServer{
+		listener:    listener,
+		command:     command,
+		subscribers: make(map[*controlConn]bool),
+	}
+	go control.serve()
+	return nil
+}
+
+// serve accepts clients until the listener is closed
+func (s *controlServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(&controlConn{conn: conn})
+	}
+}
+
+// handle answers the requests of a client until it disconnects
+func (s *controlServer) handle(c *controlConn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, c)
+		s.mu.Unlock()
+		c.conn.Close()
+	}()
+
+	dec := json.NewDecoder(c.conn)
+	for {
+		var req rpcRequest
+		if err := dec.Decode(&req); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				c.send(rpcMessage{Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+			}
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "subscribe":
+			s.mu.Lock()
+			s.subscribers[c] = true
+			s.mu.Unlock()
+			result = map[string]string{"run_id": runID, "command": s.command, "version": build.Version}
+		case "cancel":
+			s.requestCancel()
+			result = true
+		default:
+			if req.ID != nil {
+				c.send(rpcMessage{ID: req.ID, Error: &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method '%s' not found", req.Method)}})
+			}
+			continue
+		}
+		// Notifications (requests without an id) get no response
+		if req.ID != nil {
+			c.send(rpcMessage{ID: req.ID, Result: result})
+		}
+	}
+}
+
+// requestCancel cancels the run, or the run about to start
+func (s *controlServer) requestCancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancelled {
+		return
+	}
+	s.cancelled = true
+	fmt.Fprintln(os.Stderr, "\nCancelled through the control socket, generating partial results...")
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// setCancel registers the cancel function of the run
+func (s *controlServer) setCancel(cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancel = cancel
+	if s.cancelled {
+		cancel()
+	}
+}
+
+// cancelRequested reports whether a client cancelled the run
+func (s *controlServer) cancelRequested() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancelled
+}
+
+// broadcast sends an event to every subscriber, disconnecting those that
+// don't keep up
+func (s *controlServer) broadcast(e ciEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.subscribers {
+		if err := c.send(rpcMessage{Method: "event", Params: e}); err != nil {
+			delete(s.subscribers, c)
+			c.conn.Close()
+		}
+	}
+}
+
+// close stops accepting clients and disconnects the connected ones, removing
+// the socket file
+func (s *controlServer) close() {
+	s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.subscribers {
+		c.conn.Close()
+	}
+}
+
+// send writes a message to the client
+func (c *controlConn) send(m rpcMessage) error {
+	m.JSONRPC = "2.0"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(controlWriteTimeout))
+	return json.NewEncoder(c.conn).Encode(m)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configSection returns the config.toml section holding the flag defaults of
+// a command: "test" for oas test, "generate.data" for oas generate data
+func configSection(cmd *cobra.Command) string {
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())
+	return strings.Join(strings.Fields(path), ".")
+}
+
+// applyConfigDefaults sets the flags of a command that were not given on the
+// command line from its config.toml section, e.g. [test] or [benchmark].
+// Keys are flag names, with dashes or underscores. Lists set repeatable and
+// comma-separated flags. Flags given on the command line take precedence.
+func applyConfigDefaults(cmd *cobra.Command) error {
+	section := configSection(cmd)
+	if section == "" || !viper.IsSet(section) {
+		return nil
+	}
+
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed {
+			return
+		}
+		key := section + "." + flag.Name
+		if !viper.IsSet(key) {
+			key = section + "." + strings.ReplaceAll(flag.Name, "-", "_")
+			if !viper.IsSet(key) {
+				return
+			}
+		}
+		if setErr := setFlagFromConfig(flag, viper.Get(key)); setErr != nil {
+			err = fmt.Errorf("config [%s] %s: %w", section, flag.Name, setErr)
+		}
+	})
+	return err
+}
+
+// setFlagFromConfig sets a flag to a config value. It is marked as changed,
+// so the command treats it like a value given on the command line.
+func setFlagFromConfig(flag *pflag.Flag, value interface{}) error {
+	list, isList := value.([]interface{})
+	if !isList {
+		if err := flag.Value.Set(fmt.Sprint(value)); err != nil {
+			return err
+		}
+		flag.Changed = true
+		return nil
+	}
+
+	slice, ok := flag.Value.(pflag.SliceValue)
+	if !ok {
+		return fmt.Errorf("expected a single value, got a list")
+	}
+	items := make([]string, len(list))
+	for i, item := range list {
+		items[i] = fmt.Sprint(item)
+	}
+	if err := slice.Replace(items); err != nil {
+		return err
+	}
+	flag.Changed = true
+	return nil
+}
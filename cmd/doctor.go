@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/doctor"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var doctorTimeout time.Duration
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [openapi-spec-file]",
+	Short: "Diagnose the spec, network and credentials before a run",
+	Long: `Check that a run can work before spending time on it: that the spec
+parses, the server's host name resolves, a TCP connection and TLS handshake
+succeed, the server's clock agrees with the local one, and the credentials
+configured under [auth] are acquired and accepted by the API.
+
+Each problem is printed with a suggested fix. Checks that depend on a failed
+one are skipped. The command fails if any check fails; warnings, such as a
+certificate about to expire, don't fail it.
+
+Credentials are tried on the first GET operation without path parameters
+that requires authentication.`,
+	Example: `  oas doctor api-spec.json --server https://staging.example.com
+  oas doctor api-spec.json -o json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if opts.Output != "" && opts.Output != "json" {
+			fmt.Fprintf(os.Stderr, "Error: invalid output format '%s': must be 'json'\n", opts.Output)
+			exit(exitUsage, nil)
+		}
+
+		ctx, cancel := runContext()
+		defer cancel()
+
+		specCheck, baseURL, probeURL := checkSpec(args[0])
+		checks := []doctor.Check{specCheck}
+		if baseURL != "" {
+			config := doctor.Config{
+				BaseURL:       baseURL,
+				HostHeader:    hostHeader,
+				AddressFamily: addressFamily(),
+				Timeout:       doctorTimeout,
+				UserAgent:     userAgent(),
+				ProbeURL:      probeURL,
+			}
+			authenticator, authErr := buildAuthenticator("")
+			config.Authenticator = authenticator
+			for _, c := range doctor.Diagnose(ctx, config) {
+				if c.Name == "auth" && authErr != nil {
+					c = doctor.Check{
+						Name:   "auth",
+						Status: doctor.StatusFail,
+						Detail: authErr.Error(),
+						Remedy: "Fix the [auth] section of config.toml",
+					}
+				}
+				checks = append(checks, c)
+			}
+		}
+
+		if opts.Output == "json" {
+			data, err := json.MarshalIndent(checks, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(exitFailed, nil)
+			}
+			fmt.Println(mask.String(string(data)))
+		} else {
+			if baseURL != "" {
+				fmt.Printf("Diagnosing %s\n\n", baseURL)
+			}
+			for _, c := range checks {
+				displayCheck(c)
+			}
+		}
+
+		if doctor.Failed(checks) {
+			exit(exitFailed, nil)
+		}
+		exit(exitOK, nil)
+	},
+}
+
+// checkSpec parses the spec and picks the server URL to diagnose and the
+// operation to try the credentials on. The server URL is empty if there is
+// none to diagnose.
+func checkSpec(specFile string) (doctor.Check, string, string) {
+	c := doctor.Check{Name: "spec"}
+	p, err := parser.ParseFileWithRefs(specFile, opts.refs())
+	if err != nil {
+		c.Status, c.Detail = doctor.StatusFail, err.Error()
+		c.Remedy = "Fix the spec, e.g. with the problems oas validate reports"
+		if opts.Server == "" {
+			return c, "", ""
+		}
+		return c, serverWithBasePath(opts.Server, &c), ""
+	}
+
+	serverURLs, err := p.GetServerURLs()
+	if err != nil {
+		c.Status, c.Detail = doctor.StatusFail, err.Error()
+		return c, "", ""
+	}
+	baseURL := opts.Server
+	if baseURL == "" && len(serverURLs) > 0 {
+		baseURL = serverURLs[0]
+	}
+	if baseURL == "" {
+		c.Status, c.Detail = doctor.StatusFail, specFile+" declares no servers"
+		c.Remedy = "Pass the API's URL with --server"
+		return c, "", ""
+	}
+	if baseURL = serverWithBasePath(baseURL, &c); c.Status == doctor.StatusFail {
+		return c, "", ""
+	}
+
+	operations, err := p.GetOperations(baseURL)
+	if err != nil {
+		c.Status, c.Detail = doctor.StatusFail, err.Error()
+		return c, "", ""
+	}
+	operations = filterOperations(operations, opts.Filter, opts.Tags)
+	c.Status = doctor.StatusOK
+	c.Detail = fmt.Sprintf("%s parsed, %d operations", specFile, len(operations))
+	if version := p.SpecVersion(); version != "" {
+		c.Detail = fmt.Sprintf("%s (version %s) parsed, %d operations", specFile, version, len(operations))
+	}
+	if len(operations) == 0 {
+		c.Status = doctor.StatusWarn
+		c.Remedy = "No operations to test; check the spec's paths, --filter and --tags"
+	}
+	return c, baseURL, authProbeURL(operations)
+}
+
+// serverWithBasePath applies --base-path, failing the check if it can't
+func serverWithBasePath(serverURL string, c *doctor.Check) string {
+	if opts.BasePath == "" {
+		return serverURL
+	}
+	withBase, err := parser.WithBasePath(serverURL, opts.BasePath)
+	if err != nil {
+		c.Status, c.Detail = doctor.StatusFail, err.Error()
+		return serverURL
+	}
+	return withBase
+}
+
+// authProbeURL returns the URL of the first GET operation without path
+// parameters that requires authentication
+func authProbeURL(operations []models.Operation) string {
+	for _, op := range operations {
+		if op.Method != http.MethodGet || strings.Contains(op.Path, "{") || len(op.Security) == 0 {
+			continue
+		}
+		required := true
+		for _, requirement := range op.Security {
+			if len(requirement) == 0 {
+				required = false
+			}
+		}
+		if required {
+			return op.FullPath
+		}
+	}
+	return ""
+}
+
+// displayCheck prints a check with its remedy
+func displayCheck(c doctor.Check) {
+	line := fmt.Sprintf("%-6s %s", c.Name, mask.String(c.Detail))
+	switch c.Status {
+	case doctor.StatusOK:
+		fmt.Printf("%s %s\n", green("✓"), line)
+	case doctor.StatusWarn:
+		fmt.Printf("%s %s\n", yellow("!"), line)
+	case doctor.StatusFail:
+		fmt.Printf("%s %s\n", red("✗"), line)
+	default:
+		fmt.Printf("- %s\n", line)
+	}
+	if c.Remedy != "" {
+		fmt.Printf("         %s\n", c.Remedy)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().DurationVarP(&doctorTimeout, "timeout", "t", 10*time.Second, "Timeout of each check")
+}
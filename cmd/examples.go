@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/generator"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/scaffold"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	examplesDir  string
+	examplesSeed int64
+)
+
+// examplesCmd represents the examples command
+var examplesCmd = &cobra.Command{
+	Use:   "examples [openapi-spec-file]",
+	Short: "Write a generated example request per operation",
+	Long: `Write a JSON file per operation with a generated example request: its path,
+query, header and cookie parameter values and its request body. The files
+document what requests look like and are editable fixtures for data-driven
+tests.
+
+Parameters use the values of the [params] config section before generated
+ones, and identifiers are correlated across operations like in a test run.
+Files are named after the operation id, or method and path when there is none.`,
+	Example: `  oas examples api-spec.json -o examples/
+  oas examples api-spec.json -o examples/ --seed 42 --tags pets`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := parseSpecs([]string{args[0]})[0]
+		operations, err := p.GetOperations("")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		operations = filterOperations(operations, opts.Filter, opts.Tags)
+		supported := operations[:0]
+		for _, op := range operations {
+			if op.Unsupported == "" {
+				supported = append(supported, op)
+			} else if opts.Verbose {
+				fmt.Printf("Skipped %s %s: %s\n", op.Method, op.Path, op.Unsupported)
+			}
+		}
+
+		g := generator.NewGenerator()
+		seed := examplesSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		g.Seed(seed)
+		g.SetParamValues(viper.GetStringMap("params"))
+
+		examples, err := scaffold.Examples(p, supported, g)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		if err := os.MkdirAll(examplesDir, 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %s\n", examplesDir, mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		for _, example := range examples {
+			data, err := json.MarshalIndent(example, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding %s %s: %s\n", example.Method, example.Path, err)
+				exit(exitFailed, nil)
+			}
+			file := filepath.Join(examplesDir, scaffold.ExampleFileName(example))
+			if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing %s: %s\n", file, mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
+			if opts.Verbose {
+				fmt.Printf("%s %s -> %s\n", example.Method, example.Path, file)
+			}
+		}
+		fmt.Printf("Wrote %d examples to %s\n", len(examples), examplesDir)
+		if skipped := len(operations) - len(supported); skipped > 0 {
+			fmt.Printf("Skipped %d operations no valid request can be built for\n", skipped)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(examplesCmd)
+
+	examplesCmd.Flags().StringVarP(&examplesDir, "output", "o", "examples", "Directory to write the examples to")
+	examplesCmd.Flags().Int64Var(&examplesSeed, "seed", 0, "Seed for generated values, to regenerate the same examples (default: random)")
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateOutput   string
+	generatePackage  string
+	generateSpecPath string
+	generateMock     bool
+	generateSchema   string
+	generateCount    int
+	generateFormat   string
+	generateSeed     int64
+)
+
+// generateCmd groups the commands that generate files from a spec
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate test code and data from an OpenAPI spec",
+}
+
+var generateGoTestsCmd = &cobra.Command{
+	Use:   "go-tests [openapi-spec-file]",
+	Short: "Generate a table-driven Go test per operation",
+	Long: `Generate a _test.go file with a table-driven test over the operations of the
+spec. Each row sends the operation through the oas tester and checks its
+documented success status, as a starting point to customize with assertions
+on the responses your clients rely on.
+
+The tests run against the server in OAS_BASE_URL or the spec's first server
+URL, or against the spec's mock server with --mock. They import the oas
+packages, so they build inside this module.
+
+The package defaults to the output directory's name with a _test suffix, and
+the spec path to the spec file relative to the output directory.`,
+	Example: `  oas generate go-tests api-spec.json -o api/api_test.go
+  oas generate go-tests api-spec.json --mock --tags pets -o pets/pets_test.go`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		specFile := args[0]
+		p := parseSpecs([]string{specFile})[0]
+		operations, err := p.GetOperations("")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		operations = filterOperations(operations, opts.Filter, opts.Tags)
+		supported := operations[:0]
+		for _, op := range operations {
+			if op.Unsupported == "" {
+				supported = append(supported, op)
+			}
+		}
+
+		goOpts := scaffold.GoTestsOptions{
+			Package:  generatePackage,
+			SpecPath: generateSpecPath,
+			Mock:     generateMock,
+		}
+		if goOpts.Package == "" {
+			goOpts.Package = testPackageName(generateOutput)
+		}
+		if goOpts.SpecPath == "" {
+			goOpts.SpecPath = specPathFrom(generateOutput, specFile)
+		}
+
+		src, err := scaffold.GoTests(p, supported, goOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		if generateOutput == "" {
+			os.Stdout.Write(src)
+			return
+		}
+		if err := os.WriteFile(generateOutput, src, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing tests: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		fmt.Printf("Generated tests for %d operations in %s\n", len(supported), generateOutput)
+	},
+}
+
+var generateDataCmd = &cobra.Command{
+	Use:   "data [openapi-spec-file]",
+	Short: "Generate instances of a component schema as JSON or NDJSON",
+	Long: `Generate instances of a schema declared under components/schemas, e.g. to
+seed a database or feed external test tools. Values honor the schema's
+examples, defaults, formats and constraints like generated request bodies do.
+
+Each instance is generated independently, so identifier fields aren't
+correlated across instances like they are in a test run, and the same --seed
+generates the same data. The format defaults
+to NDJSON for .ndjson and .jsonl output files and to a JSON array otherwise.`,
+	Example: `  oas generate data api-spec.json --schema Pet --count 100 -o pets.json
+  oas generate data api-spec.json --schema Pet --count 1000 --format ndjson | mongoimport -c pets`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := parseSpecs([]string{args[0]})[0]
+		schema, err := scaffold.LookupSchema(p, generateSchema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
+		if generateCount < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --count must not be negative\n")
+			exit(exitFailed, nil)
+		}
+
+		format := generateFormat
+		if format == "" {
+			format = scaffold.DataJSON
+			switch strings.ToLower(filepath.Ext(generateOutput)) {
+			case ".ndjson", ".jsonl":
+				format = scaffold.DataNDJSON
+			}
+		}
+		seed := generateSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		values, err := scaffold.Data(schema, generateCount, seed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
+
+		out := os.Stdout
+		if generateOutput != "" {
+			out, err = os.Create(generateOutput)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
+		}
+		err = scaffold.WriteData(out, values, format)
+		if generateOutput != "" {
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing data: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		if generateOutput != "" {
+			fmt.Printf("Generated %d %s instances in %s\n", len(values), generateSchema, generateOutput)
+		}
+	},
+}
+
+// testPackageName derives the external test package of a generated file from
+// its directory
+func testPackageName(output string) string {
+	dir := "."
+	if output != "" {
+		dir = filepath.Dir(output)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "api_test"
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(abs)) {
+		switch {
+		case r >= 'a' && r <= 'z', r == '_', b.Len() > 0 && r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "api_test"
+	}
+	return b.String() + "_test"
+}
+
+// specPathFrom is the spec file relative to the directory of the generated
+// file, as go test runs it from there
+func specPathFrom(output, specFile string) string {
+	dir := "."
+	if output != "" {
+		dir = filepath.Dir(output)
+	}
+	absDir, err1 := filepath.Abs(dir)
+	absSpec, err2 := filepath.Abs(specFile)
+	if err1 != nil || err2 != nil {
+		return filepath.ToSlash(specFile)
+	}
+	rel, err := filepath.Rel(absDir, absSpec)
+	if err != nil {
+		return filepath.ToSlash(absSpec)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.AddCommand(generateGoTestsCmd)
+	generateCmd.AddCommand(generateDataCmd)
+
+	generateGoTestsCmd.Flags().StringVarP(&generateOutput, "output", "o", "", "File to write the tests to (default: standard output)")
+	generateGoTestsCmd.Flags().StringVar(&generatePackage, "package", "", "Package of the generated file (default: output directory name + _test)")
+	generateGoTestsCmd.Flags().StringVar(&generateSpecPath, "spec-path", "", "Spec path the tests open (default: relative to the output directory)")
+	generateGoTestsCmd.Flags().BoolVar(&generateMock, "mock", false, "Test against the spec's mock server instead of a deployed one")
+
+	generateDataCmd.Flags().StringVar(&generateSchema, "schema", "", "Name of the component schema to generate")
+	generateDataCmd.Flags().IntVar(&generateCount, "count", 1, "Number of instances to generate")
+	generateDataCmd.Flags().StringVar(&generateFormat, "format", "", "Output format: json or ndjson (default: from the output file extension, else json)")
+	generateDataCmd.Flags().StringVarP(&generateOutput, "output", "o", "", "File to write the data to (default: standard output)")
+	generateDataCmd.Flags().Int64Var(&generateSeed, "seed", 0, "Seed for generated values, to regenerate the same data (default: random)")
+	generateDataCmd.MarkFlagRequired("schema")
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// Groupings of live test output accepted by --group-by
+const (
+	groupByTag  = "tag"
+	groupByPath = "path"
+)
+
+// validateGroupBy rejects unknown --group-by values
+func validateGroupBy(s string) error {
+	switch s {
+	case "", groupByTag, groupByPath:
+		return nil
+	default:
+		return fmt.Errorf("invalid group-by '%s': must be 'tag' or 'path'", s)
+	}
+}
+
+// groupKey returns the group of an operation: its first tag, or the first
+// segment of its path
+func groupKey(op models.Operation, by string) string {
+	switch by {
+	case groupByTag:
+		if len(op.Tags) > 0 {
+			return op.Tags[0]
+		}
+		return "untagged"
+	case groupByPath:
+		segment, _, _ := strings.Cut(strings.TrimPrefix(op.Path, "/"), "/")
+		return "/" + segment
+	}
+	return ""
+}
+
+// groupOperations orders operations so each group is contiguous. Groups keep
+// the order in which they first appear in the spec, and operations keep spec
+// order within their group.
+func groupOperations(ops []models.Operation, by string) []models.Operation {
+	if by == "" {
+		return ops
+	}
+	var keys []string
+	groups := make(map[string][]models.Operation)
+	for _, op := range ops {
+		key := groupKey(op, by)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], op)
+	}
+
+	grouped := make([]models.Operation, 0, len(ops))
+	for _, key := range keys {
+		grouped = append(grouped, groups[key]...)
+	}
+	return grouped
+}
+
+// outputGroup tracks the group of live output being printed
+type outputGroup struct {
+	key    string
+	tests  int
+	failed int
+}
+
+// start prints the header of the group op belongs to, if it begins a new
+// group, after closing the previous one
+func (g *outputGroup) start(op models.Operation, by, label string, collapse bool) {
+	key := groupKey(op, by)
+	if g.tests > 0 && key == g.key {
+		return
+	}
+	g.finish(label, collapse)
+	*g = outputGroup{key: key}
+	fmt.Printf("%s%s %s\n", label, cyan("▾"), g.key)
+}
+
+// record counts a completed test of the group
+func (g *outputGroup) record(result *models.TestResult) {
+	g.tests++
+	if !result.Passed {
+		g.failed++
+	}
+}
+
+// finish closes the group. With collapse, a group without failures is folded
+// into its header, erasing the one line each of its tests printed; groups
+// with failures stay expanded.
+func (g *outputGroup) finish(label string, collapse bool) {
+	if g.tests == 0 {
+		return
+	}
+	if collapse && g.failed == 0 {
+		// Move up over the header and test lines and clear them
+		fmt.Printf("\033[%dA\033[J", g.tests+1)
+		fmt.Printf("%s%s %s %s\n", label, cyan("▸"), g.key, green(fmt.Sprintf("%d passed", g.tests)))
+	}
+	g.tests = 0
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/grpcgw"
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// reportGRPCMethods prints how many GET operations of a spec map to a gRPC
+// method, warning when the spec doesn't look generated for grpc-gateway
+func reportGRPCMethods(run *specRun, client *grpcgw.Client) {
+	if !run.parser.IsGRPCGateway() {
+		fmt.Printf("%s %s doesn't look generated by grpc-gateway; operations are matched to methods by operation ID (Service_Method)\n", yellow("!"), run.file)
+	}
+
+	gets, mapped := 0, 0
+	for _, op := range run.filteredOps {
+		if op.Method != http.MethodGet {
+			continue
+		}
+		gets++
+		if _, err := client.Method(op.OperationID); err == nil {
+			mapped++
+		}
+	}
+	fmt.Printf("gRPC: %d of %d GET operations map to methods\n", mapped, gets)
+}
+
+// displayGRPCComparison prints the latency of the HTTP transcoding layer
+// against the gRPC methods behind it
+func displayGRPCComparison(results []models.TestResult) {
+	var compared int
+	var httpTotal, grpcTotal time.Duration
+	for _, r := range results {
+		if r.GRPCStatus == "" {
+			continue
+		}
+		compared++
+		httpTotal += r.ResponseTime
+		grpcTotal += r.GRPCResponseTime
+	}
+	if compared == 0 {
+		return
+	}
+
+	httpAvg := httpTotal / time.Duration(compared)
+	grpcAvg := grpcTotal / time.Duration(compared)
+	fmt.Printf("gRPC: %d operation(s) compared, average HTTP %v vs gRPC %v (transcoding overhead %v)\n",
+		compared, httpAvg.Round(time.Microsecond), grpcAvg.Round(time.Microsecond), (httpAvg - grpcAvg).Round(time.Microsecond))
+}
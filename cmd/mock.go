@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/mock"
+	"github.com/moamenhredeen/oas/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	mockListen    string
+	mockRecord    string
+	mockChaosSeed int64
+)
+
+// mockCmd represents the mock command
+var mockCmd = &cobra.Command{
+	Use:   "mock [openapi-spec-file]",
+	Short: "Serve generated responses for the operations of a spec",
+	Long: `Serve a mock of the API described by the spec, e.g. to develop a frontend
+before the backend exists. Each operation answers with its lowest documented
+2xx status and the documented example, or a value generated from the response
+schema. Paths are matched with or without the base path of the spec's server
+URL.
+
+With --record, the operations clients called are written on exit in the
+format of "oas test" results: one result per called operation with the number
+of calls as its runs, and the operations never called listed as uncalled under
+skipped_operations. The coverage shows which parts of the contract a client
+really exercises.
+
+Faults are injected from the [[mock.chaos]] rules of config.toml, e.g. to test
+a client's retries and timeouts. The first rule whose route (operation id or
+"METHOD /path", omitted for every route) matches a request applies:
+
+  [[mock.chaos]]
+  route = "GET /pets"
+  latency = "200ms"               # delay, or the mean of normal and exponential ones
+  latency_distribution = "normal" # fixed (default), uniform, normal or exponential
+  latency_stddev = "50ms"
+  latency_max = "1s"              # also latency_min, the bounds of uniform delays
+  error_rate = 0.1                # share of requests answered with error_status
+  error_status = 503              # default 500
+  reset_rate = 0.01               # share of connections reset without a response
+
+--chaos-seed makes the injected faults reproducible.
+
+The server runs until interrupted or until --deadline passes.`,
+	Example: `  oas mock api-spec.json --listen :4010
+  oas mock api-spec.json --record coverage.json & npm run e2e; kill %1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rejectFlags(cmd, requestFlags...)
+		var dest output.Destination
+		if mockRecord != "" {
+			var err error
+			dest, err = output.ParseDestination(mockRecord)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				exit(exitUsage, nil)
+			}
+		}
+
+		p := parseSpecs([]string{args[0]})[0]
+		rules, err := chaosRules()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitUsage, nil)
+		}
+		server, err := mock.NewServerWithConfig(p, mock.Config{Chaos: rules, Seed: mockChaosSeed})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+
+		listener, err := net.Listen("tcp", mockListen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
+
+		var handler http.Handler = server
+		if opts.Verbose {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Printf("%s %s\n", r.Method, r.URL.RequestURI())
+				server.ServeHTTP(w, r)
+			})
+		}
+		httpServer := &http.Server{Handler: handler}
+
+		ctx, cancel := runContext()
+		defer cancel()
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Printf("Mock server listening on http://%s (Ctrl+C to stop)\n", listener.Addr())
+		errc := make(chan error, 1)
+		go func() { errc <- httpServer.Serve(listener) }()
+		select {
+		case err := <-errc:
+			if !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				exit(exitFailed, nil)
+			}
+		case <-ctx.Done():
+		}
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelShutdown()
+		httpServer.Shutdown(shutdownCtx)
+
+		summary := server.Coverage()
+		summary.Spec = args[0]
+		summary.Build = buildInfo()
+		fmt.Printf("\nCalled %d of %d operations (%.1f%% coverage)\n",
+			summary.TotalTests, summary.TotalTests+len(summary.SkippedOperations), summary.Coverage)
+		if opts.Verbose {
+			for _, r := range summary.Results {
+				fmt.Printf("  %-7s %s (%d calls)\n", r.Method, r.Path, r.Runs)
+			}
+		}
+		displaySkippedOperations(summary.SkippedOperations, summary.TotalTests)
+
+		if mockRecord != "" {
+			if err := output.ExportTestSummary(summary, dest.Format, dest.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting coverage: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
+			fmt.Printf("Coverage recorded to: %s\n", dest.Path)
+		}
+		exit(exitOK, summary)
+	},
+}
+
+// chaosConfig is a [[mock.chaos]] rule of config.toml
+type chaosConfig struct {
+	Route               string        `mapstructure:"route"`
+	Latency             time.Duration `mapstructure:"latency"`
+	LatencyDistribution string        `mapstructure:"latency_distribution"`
+	LatencyStdDev       time.Duration `mapstructure:"latency_stddev"`
+	LatencyMin          time.Duration `mapstructure:"latency_min"`
+	LatencyMax          time.Duration `mapstructure:"latency_max"`
+	ErrorRate           float64       `mapstructure:"error_rate"`
+	ErrorStatus         int           `mapstructure:"error_status"`
+	ResetRate           float64       `mapstructure:"reset_rate"`
+}
+
+// chaosRules reads the fault injection rules of the mock server from the
+// [[mock.chaos]] tables of config.toml, rejecting unknown keys
+func chaosRules() ([]mock.ChaosRule, error) {
+	var configs []chaosConfig
+	err := viper.UnmarshalKey("mock.chaos", &configs, func(c *mapstructure.DecoderConfig) {
+		c.ErrorUnused = true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config [[mock.chaos]]: %w", err)
+	}
+
+	rules := make([]mock.ChaosRule, len(configs))
+	for i, c := range configs {
+		rules[i] = mock.ChaosRule{
+			Route: c.Route,
+			Latency: mock.Latency{
+				Distribution: c.LatencyDistribution,
+				Mean:         c.Latency,
+				StdDev:       c.LatencyStdDev,
+				Min:          c.LatencyMin,
+				Max:          c.LatencyMax,
+			},
+			ErrorRate:   c.ErrorRate,
+			ErrorStatus: c.ErrorStatus,
+			ResetRate:   c.ResetRate,
+		}
+		if err := rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("config [[mock.chaos]] %d: %w", i+1, err)
+		}
+	}
+	return rules, nil
+}
+
+func init() {
+	rootCmd.AddCommand(mockCmd)
+
+	mockCmd.Flags().StringVar(&mockListen, "listen", "127.0.0.1:4010", "Address to listen on")
+	mockCmd.Flags().StringVar(&mockRecord, "record", "", "Write the operations clients called to a .json/.csv file (or format:path) on exit")
+	mockCmd.Flags().Int64Var(&mockChaosSeed, "chaos-seed", 0, "Seed of the faults injected by the [[mock.chaos]] rules of config.toml (default: random)")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/mock"
+	"github.com/spf13/viper"
+)
+
+// readConfig loads a config.toml for the duration of a test
+func readConfig(t *testing.T, config string) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("toml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChaosRules(t *testing.T) {
+	readConfig(t, `
+[mock]
+listen = ":4010"
+
+[[mock.chaos]]
+route = "GET /pets"
+latency = "200ms"
+latency_distribution = "normal"
+latency_stddev = "50ms"
+latency_max = "1s"
+error_rate = 0.1
+error_status = 503
+
+[[mock.chaos]]
+reset_rate = 0.01
+`)
+
+	rules, err := chaosRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []mock.ChaosRule{
+		{
+			Route:       "GET /pets",
+			Latency:     mock.Latency{Distribution: mock.LatencyNormal, Mean: 200 * time.Millisecond, StdDev: 50 * time.Millisecond, Max: time.Second},
+			ErrorRate:   0.1,
+			ErrorStatus: 503,
+		},
+		{ResetRate: 0.01},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules, want %d", len(rules), len(want))
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i+1, rules[i], want[i])
+		}
+	}
+}
+
+func TestChaosRulesInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{name: "unknown key", config: "[[mock.chaos]]\nerror_rat = 0.1\n", want: "error_rat"},
+		{name: "invalid rate", config: "[[mock.chaos]]\nerror_rate = 2.0\n", want: "between 0 and 1"},
+		{name: "invalid duration", config: "[[mock.chaos]]\nlatency = \"soon\"\n", want: "latency"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readConfig(t, tt.config)
+			_, err := chaosRules()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("chaosRules() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChaosRulesNone(t *testing.T) {
+	readConfig(t, "[mock]\nlisten = \":4010\"\n")
+	rules, err := chaosRules()
+	if err != nil || len(rules) != 0 {
+		t.Errorf("chaosRules() = %v, %v, want no rules", rules, err)
+	}
+}
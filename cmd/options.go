@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// options are the flags shared by the commands. They are persistent flags of
+// the root command, so every command accepts them with the same meaning.
+// Commands where a flag means something else (e.g. -o naming the file
+// generate writes) shadow it with a local flag of the same name.
+type options struct {
+	Server     string   // overrides the spec's server URL
+	BasePath   string   // replaces the server URL's path prefix
+	Filter     string   // path pattern or operation ID
+	Tags       []string // OpenAPI tags
+	Verbose    bool
+	Timeout    int      // request timeout in seconds
+	Output     string   // result format, e.g. json
+	OutputFile string   // file the results are written to (default: stdout)
+	Tee        []string // additional format:path destinations
+	RefBase    string   // directory or URL external $refs are resolved against
+	RemoteRefs bool     // allow $refs to http(s) URLs
+}
+
+var opts options
+
+// register adds the shared flags to a flag set
+func (o *options) register(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Server, "server", "", "Override server URL from OpenAPI spec")
+	flags.StringVar(&o.BasePath, "base-path", "", "Replace the server URL's path prefix, e.g. /api/v2 behind a gateway")
+	flags.StringVar(&o.Filter, "filter", "", "Filter endpoints by path pattern or operation ID")
+	flags.StringSliceVar(&o.Tags, "tags", []string{}, "Filter by OpenAPI tags (can be specified multiple times)")
+	flags.BoolVarP(&o.Verbose, "verbose", "v", false, "Show detailed output")
+	flags.IntVarP(&o.Timeout, "timeout", "t", 30, "Request timeout in seconds")
+	flags.StringVarP(&o.Output, "output", "o", "", "Output format of test and benchmark results: json, csv, csv-detail, csv-histogram, allure, sonar, markdown, html")
+	flags.StringVar(&o.OutputFile, "output-file", "", "Write output to file (default: stdout)")
+	flags.StringVar(&o.RefBase, "ref-base", "", "Resolve relative $refs to other files against this directory or http(s) URL (default: the spec's directory)")
+	flags.BoolVar(&o.RemoteRefs, "remote-refs", false, "Resolve $refs to http(s) URLs")
+	flags.StringArrayVar(&o.Tee, "tee", nil, "Also write results to format:path or a .json/.csv/.md/.html file (repeatable)")
+}
+
+// refs returns how specs resolve $refs to other documents
+func (o *options) refs() parser.RefConfig {
+	return parser.RefConfig{Base: o.RefBase, Remote: o.RemoteRefs}
+}
+
+// requestFlags are the shared flags that only mean something to commands
+// sending requests to an API and reporting results
+var requestFlags = []string{"server", "base-path", "filter", "tags", "timeout", "output", "output-file", "tee"}
+
+// rejectFlags fails with a usage error if any of the named shared flags was
+// given to a command that ignores it
+func rejectFlags(cmd *cobra.Command, names ...string) {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			fmt.Fprintf(os.Stderr, "Error: %s doesn't support --%s\n", cmd.CommandPath(), name)
+			exit(exitUsage, nil)
+		}
+	}
+}
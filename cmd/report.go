@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var reportListen string
+
+// reportCmd groups the commands that work on stored results
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Browse stored test and benchmark results",
+}
+
+var reportServeCmd = &cobra.Command{
+	Use:   "serve [results-dir]",
+	Short: "Serve stored results as a local web app with run comparison and trends",
+	Long: `Serve the JSON results under a directory (exported with -o json or --tee,
+optionally gzip-compressed) as a small local web app. It lists the runs,
+compares any two runs of the same kind endpoint by endpoint (broken, fixed,
+added and removed endpoints, and the change in response time), and charts the
+trend of every endpoint across the runs.
+
+Runs are ordered by the modification time of their files, and the directory is
+read again on every page load, so results stored while the report is open show
+up on reload. Other JSON files in the directory are ignored.`,
+	Example: `  oas test api.json --tee results/$(date +%F-%H%M).json
+  oas report serve results/ --listen :8090`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := args[0]
+		runs, ignored, err := report.LoadDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
+		fmt.Printf("Found %d runs in %s", len(runs), dir)
+		if len(ignored) > 0 {
+			fmt.Printf(" (%d other JSON files ignored)", len(ignored))
+		}
+		fmt.Println()
+		if opts.Verbose {
+			for _, name := range ignored {
+				fmt.Printf("  ignored %s\n", name)
+			}
+		}
+
+		listener, err := net.Listen("tcp", reportListen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
+		httpServer := &http.Server{Handler: report.NewHandler(dir)}
+
+		ctx, cancel := runContext()
+		defer cancel()
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Printf("Report served on http://%s (Ctrl+C to stop)\n", listener.Addr())
+		errc := make(chan error, 1)
+		go func() { errc <- httpServer.Serve(listener) }()
+		select {
+		case err := <-errc:
+			if !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				exit(exitFailed, nil)
+			}
+		case <-ctx.Done():
+		}
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelShutdown()
+		httpServer.Shutdown(shutdownCtx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportServeCmd)
+
+	reportServeCmd.Flags().StringVar(&reportListen, "listen", "127.0.0.1:8090", "Address to listen on")
+}
@@ -4,12 +4,36 @@ Copyright © 2026 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
+	"strings"
+	"time"
 
+	"github.com/moamenhredeen/oas/internal/dotenv"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/output"
+	"github.com/moamenhredeen/oas/internal/tester"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var (
+	envFile       string
+	userAgentFlag string
+	hostHeader    string
+	acceptHeader  string
+	forceIPv4     bool
+	forceIPv6     bool
+
+	// runID identifies this invocation in server logs
+	runID = newRunID()
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "oas",
@@ -18,21 +42,133 @@ var rootCmd = &cobra.Command{
 It allows you to test your APIs using a simple and intuitive interface.
 
 You can use oas to test your APIs by providing the OpenAPI Specification file and the endpoints to test.`,
+	Version: build.Version,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := applyConfigDefaults(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitUsage, nil)
+		}
+		setupCI(cmd.CommandPath())
+	},
 }
 
 func Execute() {
 	cobra.OnInitialize(func() {
+		loadEnvFile()
+
 		viper.SetConfigName("config")
 		viper.SetConfigType("toml")
 		viper.AddConfigPath(".")
 		viper.ReadInConfig()
+		expandConfigEnv()
+		registerSecrets()
 	})
 	err := rootCmd.Execute()
 	if err != nil {
+		// Flag parsing may have stopped before reaching --ci
+		ciMode = ciMode || ciFlagSet(os.Args[1:])
+		exit(exitUsage, nil)
+	}
+}
+
+// loadEnvFile loads variables from the dotenv file into the environment and
+// registers the values of those named like credentials as secrets. Other
+// variables, such as PORT or HOST, are still masked if a credential setting
+// references them (see registerSecrets). A missing default .env is ignored;
+// a missing file given explicitly with --env-file is an error.
+func loadEnvFile() {
+	values, err := dotenv.Load(envFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !rootCmd.PersistentFlags().Changed("env-file") {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error loading env file: %v\n", err)
 		os.Exit(1)
 	}
+	mask.Register(dotenv.Secrets(values)...)
+}
+
+// expandConfigEnv substitutes ${VAR} references in config values with
+// environment variables, including those loaded from the env file
+func expandConfigEnv() {
+	for _, key := range viper.AllKeys() {
+		switch v := viper.Get(key).(type) {
+		case string:
+			viper.Set(key, os.ExpandEnv(v))
+		case []interface{}:
+			expanded := make([]interface{}, len(v))
+			for i, item := range v {
+				if s, ok := item.(string); ok {
+					expanded[i] = os.ExpandEnv(s)
+				} else {
+					expanded[i] = item
+				}
+			}
+			viper.Set(key, expanded)
+		}
+	}
+}
+
+// newRunID returns a short random identifier for this run
+func newRunID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// userAgent builds the User-Agent sent with every request. The tool version
+// and run ID are always included so operators can identify and whitelist test
+// traffic; --user-agent adds a product token in front of them.
+func userAgent() string {
+	ua := fmt.Sprintf("oas/%s (run %s)", build.Version, runID)
+	if userAgentFlag != "" {
+		ua = userAgentFlag + " " + ua
+	}
+	return ua
+}
+
+// addressFamily returns the address family selected with --ipv4 or --ipv6
+func addressFamily() string {
+	switch {
+	case forceIPv4:
+		return tester.AddressFamilyIPv4
+	case forceIPv6:
+		return tester.AddressFamilyIPv6
+	}
+	return tester.AddressFamilyAny
+}
+
+// outputPathVars returns the placeholder values for output file names.
+// The git SHA is only looked up when a file name asks for it.
+func outputPathVars(profile, specVersion string, paths ...string) map[string]string {
+	var sha string
+	if strings.Contains(strings.Join(paths, " "), "{git_sha}") {
+		sha = gitSHA()
+	}
+	return output.PathVars(time.Now(), sha, profile, specVersion, runID)
+}
+
+// gitSHA returns the short commit hash of the working directory's repository
+func gitSHA() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
 }
 
 func init() {
-	// Removed placeholder toggle flag
+	opts.register(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().StringVar(&envFile, "env-file", ".env", "Load environment variables from a dotenv file")
+	rootCmd.PersistentFlags().StringVar(&hostHeader, "host-header", "", "Host header to present, e.g. to target an IP or load balancer before DNS cutover")
+	rootCmd.PersistentFlags().StringVar(&acceptHeader, "accept", "", "Accept header for every request (default: the media types each operation documents for its responses)")
+	rootCmd.PersistentFlags().BoolVar(&forceIPv4, "ipv4", false, "Connect over IPv4 only")
+	rootCmd.PersistentFlags().BoolVar(&forceIPv6, "ipv6", false, "Connect over IPv6 only")
+	rootCmd.MarkFlagsMutuallyExclusive("ipv4", "ipv6")
+	rootCmd.PersistentFlags().BoolVar(&ciMode, "ci", false, "Machine mode: no color or spinners, NDJSON events on stdout, human output on stderr, distinct exit codes")
+	rootCmd.PersistentFlags().StringVar(&controlSocket, "control-socket", "", "Serve run events and cancellation over JSON-RPC on this Unix socket path")
+	rootCmd.PersistentFlags().DurationVar(&deadline, "deadline", 0, "Stop the whole command after this long, e.g. 10m, reporting partial results (0 = no deadline)")
+	rootCmd.PersistentFlags().StringVar(&userAgentFlag, "user-agent", "", "Product token prepended to the User-Agent (the tool version and run ID are always included)")
 }
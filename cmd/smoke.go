@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/tester"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	smokeTimeout   time.Duration
+	smokeGets      int
+	smokeEndpoints []string
+)
+
+// smokeCmd represents the smoke command
+var smokeCmd = &cobra.Command{
+	Use:   "smoke [openapi-spec-file]",
+	Short: "Quickly check health endpoints and a few GETs",
+	Long: `Check that an API is up by calling its health, liveness and readiness
+endpoints and a handful of GET operations, validating status codes only.
+It returns within seconds, so it suits deploy gates and container
+healthchecks.
+
+Health endpoints are GET operations whose last path segment is e.g. health,
+healthz, livez, readyz, ping or status, or whose operation ID or tag mentions
+health. More operations are added with --endpoint or the endpoints list
+under [smoke] in config.toml.`,
+	Example: `  oas smoke api-spec.json --server http://localhost:8080
+  oas smoke api-spec.json --endpoint getVersion --gets 0 --timeout 2s`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		specFile := args[0]
+		run, err := prepareSpec(specFile, parseSpecs([]string{specFile})[0], false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+
+		include := append(viper.GetStringSlice("smoke.endpoints"), smokeEndpoints...)
+		ops, unmatched := tester.SmokeOperations(run.filteredOps, include, smokeGets)
+		for _, name := range unmatched {
+			fmt.Fprintf(os.Stderr, "Warning: smoke endpoint %s is not in the spec\n", name)
+		}
+		if len(ops) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: no health endpoints or GET operations to check")
+			exit(exitFailed, nil)
+		}
+		run.filteredOps = ops
+
+		ctx, cancel := runContext()
+		defer cancel()
+
+		config := tester.Config{
+			Context:       ctx,
+			Timeout:       smokeTimeout,
+			UserAgent:     userAgent(),
+			HostHeader:    hostHeader,
+			Accept:        acceptHeader,
+			AddressFamily: addressFamily(),
+		}
+		profile, _ := tester.ParseProfile("smoke")
+		profile.Apply(&config)
+
+		authenticator, err := buildAuthenticator("smoke")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error configuring authentication: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		config.Authenticator = authenticator
+		config.DigestAuth = digestCredentials()
+
+		start := time.Now()
+		summary, err := testSpec(run, config, func(event tester.TestEvent) {
+			if event.Type == tester.EventCompleted {
+				displaySmokeResult(*event.Result)
+			}
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+
+		status := green("passed")
+		if summary.Failed > 0 {
+			status = red("failed")
+		}
+		fmt.Printf("Smoke %s: %d/%d in %v\n", status, summary.Passed, summary.TotalTests, time.Since(start).Round(time.Millisecond))
+		exit(testExitCode(summary), summary)
+	},
+}
+
+// displaySmokeResult prints a single line per checked operation
+func displaySmokeResult(result models.TestResult) {
+	line := fmt.Sprintf("%s %s", result.Method, result.Path)
+	if result.StatusCode != 0 {
+		line += fmt.Sprintf(" %d", result.StatusCode)
+	}
+	line += fmt.Sprintf(" %v", result.ResponseTime.Round(time.Millisecond))
+
+	if result.Passed {
+		fmt.Printf("%s %s\n", green("✓"), line)
+		return
+	}
+	fmt.Printf("%s %s\n", red("✗"), line)
+	if opts.Verbose && result.Error != "" {
+		fmt.Printf("    %s\n", red(result.Error))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(smokeCmd)
+
+	smokeCmd.Flags().DurationVarP(&smokeTimeout, "timeout", "t", 5*time.Second, "Request timeout")
+	smokeCmd.Flags().IntVar(&smokeGets, "gets", 3, "Also check the first N GET operations without path parameters")
+	smokeCmd.Flags().StringArrayVar(&smokeEndpoints, "endpoint", nil, "Also check this operation ID or path (repeatable)")
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+// specRun is a parsed spec with the operations a test run exercises
+type specRun struct {
+	file        string
+	parser      *parser.Parser
+	baseURL     string
+	operations  []models.Operation // every operation in the spec
+	filteredOps []models.Operation // operations to test
+	skipped     []models.SkippedOperation
+}
+
+// expandSpecFiles resolves glob patterns among the spec arguments. Plain paths
+// are kept as given so that a missing file is reported by the parser.
+func expandSpecFiles(args []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			globbed, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid spec pattern %q: %w", arg, err)
+			}
+			if len(globbed) == 0 {
+				return nil, fmt.Errorf("no spec files match %q", arg)
+			}
+			matches = globbed
+		}
+		for _, file := range matches {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
+
+// parseSpecs parses the spec files concurrently and reports every spec that
+// fails, exiting if any did
+func parseSpecs(specFiles []string) []*parser.Parser {
+	parsers := make([]*parser.Parser, 0, len(specFiles))
+	failed := 0
+	for _, result := range parser.ParseFiles(specFiles, runtime.GOMAXPROCS(0), opts.refs()) {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing OpenAPI file %s: %s\n", result.File, mask.String(result.Err.Error()))
+			failed++
+			continue
+		}
+		parsers = append(parsers, result.Parser)
+	}
+	if failed > 0 {
+		if len(specFiles) > 1 {
+			fmt.Fprintf(os.Stderr, "%d of %d spec files failed to parse\n", failed, len(specFiles))
+		}
+		exit(exitFailed, nil)
+	}
+	return parsers
+}
+
+// prepareSpec selects the operations of a parsed spec to test, recording what
+// is left out so the summary shows how much of the spec was exercised. In
+// multi-spec runs messages are prefixed with the spec file.
+func prepareSpec(specFile string, p *parser.Parser, multiSpec bool) (*specRun, error) {
+	// Use provided server URL or first from spec
+	serverURLs, err := p.GetServerURLs()
+	if err != nil {
+		return nil, fmt.Errorf("getting server URLs of %s: %w", specFile, err)
+	}
+	baseURL := opts.Server
+	if baseURL == "" && len(serverURLs) > 0 {
+		baseURL = serverURLs[0]
+	}
+	if baseURL == "" {
+		baseURL = "http://localhost"
+	}
+	if opts.BasePath != "" {
+		baseURL, err = parser.WithBasePath(baseURL, opts.BasePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	operations, err := p.GetOperations(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("getting operations of %s: %w", specFile, err)
+	}
+
+	filteredOps := filterOperations(operations, opts.Filter, opts.Tags)
+	skipped := models.SkipOperations(models.Exclude(operations, filteredOps), models.SkipFiltered)
+	filteredOps, skipped = skipOperations(filteredOps, skipped, models.SkipUnsupported, func(op models.Operation) bool {
+		return op.Unsupported == ""
+	})
+	if skipDeprecated {
+		filteredOps, skipped = skipOperations(filteredOps, skipped, models.SkipDeprecated, func(op models.Operation) bool {
+			return !op.Deprecated
+		})
+	}
+
+	var label string
+	if multiSpec {
+		label = specFile + ": "
+		if len(filteredOps) == 0 {
+			fmt.Printf("%sNo operations found matching the criteria\n", label)
+		}
+	}
+
+	// Sample a subset of operations for quick smoke runs
+	if sample != "" && len(filteredOps) > 0 {
+		sampled, err := sampleOperations(filteredOps, sample)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("%sSampled %d of %d operations\n", label, len(sampled), len(filteredOps))
+		skipped = append(skipped, models.SkipOperations(models.Exclude(filteredOps, sampled), models.SkipSampled)...)
+		filteredOps = sampled
+	}
+
+	// Keep each group of --group-by output together
+	filteredOps = groupOperations(filteredOps, groupBy)
+
+	return &specRun{
+		file:        specFile,
+		parser:      p,
+		baseURL:     baseURL,
+		operations:  operations,
+		filteredOps: filteredOps,
+		skipped:     skipped,
+	}, nil
+}
+
+// testSpec logs in and tests the selected operations of one spec. It returns
+// an error if the spec could not be tested at all: the login, the callback
+// listener or the seed fixtures failed.
+func testSpec(run *specRun, config tester.Config, onEvent tester.OnTestEvent) (models.TestSummary, error) {
+	jar, err := loginSession(run.parser, run.baseURL, config.Timeout)
+	if err != nil {
+		return models.TestSummary{}, fmt.Errorf("logging in: %w", err)
+	}
+	config.CookieJar = jar
+
+	t := tester.NewTesterWithConfig(config)
+	if callbacks {
+		url, err := t.StartCallbacks(run.parser)
+		if err != nil {
+			return models.TestSummary{}, err
+		}
+		defer t.StopCallbacks()
+		fmt.Printf("Callback listener: %s\n", url)
+	}
+
+	var seeded tester.SeedResult
+	if len(config.Fixtures) > 0 {
+		seeded, err = t.SeedFixtures(run.operations, run.parser)
+		for _, key := range seeded.Unmatched {
+			fmt.Fprintf(os.Stderr, "Warning: no operation of %s matches seed fixture %s\n", run.file, key)
+		}
+		if err != nil {
+			t.CleanupFixtures()
+			return models.TestSummary{}, err
+		}
+		if seeded.Created > 0 {
+			fmt.Printf("Seeded %d fixtures\n", seeded.Created)
+		}
+	}
+
+	summary := t.TestOperations(run.filteredOps, run.parser, ciTestEvents(run.file, onEvent))
+	if len(config.Fixtures) > 0 {
+		deleted, failed := t.CleanupFixtures()
+		summary.Seeded, summary.SeedCleanupFailed = seeded.Created, failed
+		if failed > 0 {
+			fmt.Fprintf(os.Stderr, "%s\n", red(fmt.Sprintf("Failed to delete %d of %d seeded resources", failed, deleted+failed)))
+		}
+	}
+	if len(run.operations) > 0 {
+		summary.Coverage = float64(summary.TotalTests) / float64(len(run.operations)) * 100
+	}
+	summary.SkippedOperations = append(run.skipped, summary.SkippedOperations...)
+	summary.Unsupported = models.CountSkipped(summary.SkippedOperations, models.SkipUnsupported)
+	summary.Spec = run.file
+	return summary, nil
+}
+
+// testSpecs tests several specs, up to workers at a time, and combines their
+// summaries in argument order. Failing requests of each spec are saved to a
+// subdirectory named after the spec file. A spec that could not be tested
+// doesn't stop the others: its error is recorded in the spec's summary and
+// returned along with the combined summary.
+func testSpecs(runs []*specRun, config tester.Config, workers int) (models.TestSummary, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	parallel := workers > 1
+
+	summaries := make([]models.TestSummary, len(runs))
+	errs := make([]error, len(runs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, workers)
+	for i, run := range runs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			c := config
+			name := tester.SafeFileName(strings.TrimSuffix(run.file, filepath.Ext(run.file)))
+			if c.Snapshot.Dir != "" {
+				c.Snapshot.Dir = filepath.Join(c.Snapshot.Dir, name)
+			}
+			if c.SaveFailuresDir != "" {
+				c.SaveFailuresDir = filepath.Join(c.SaveFailuresDir, name)
+				if err := os.MkdirAll(c.SaveFailuresDir, 0o755); err != nil {
+					errs[i] = err
+					return
+				}
+			}
+			summaries[i], errs[i] = testSpec(run, c, testEventHandler(run.file, parallel, &mu))
+		}()
+	}
+	wg.Wait()
+
+	summary := models.TestSummary{Results: make([]models.TestResult, 0)}
+	failed := 0
+	for i, run := range runs {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "Error testing %s: %s\n", run.file, mask.String(errs[i].Error()))
+			summaries[i].Error = errs[i].Error()
+			failed++
+		}
+		summary.AddSpec(run.file, len(run.operations), summaries[i])
+	}
+	if config.Cases > 1 {
+		summary.Seed = config.Seed
+	}
+	if failed > 0 {
+		return summary, fmt.Errorf("%d of %d specs could not be tested", failed, len(runs))
+	}
+	return summary, nil
+}
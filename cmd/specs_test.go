@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+func TestExpandSpecFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"orders.yaml", "pets.yaml", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pets := filepath.Join(dir, "pets.yaml")
+	missing := filepath.Join(dir, "missing.yaml")
+
+	files, err := expandSpecFiles([]string{pets, filepath.Join(dir, "*.yaml"), missing})
+	if err != nil {
+		t.Fatalf("expandSpecFiles: %v", err)
+	}
+	// Duplicates are dropped and plain paths kept even if they don't exist
+	want := []string{pets, filepath.Join(dir, "orders.yaml"), missing}
+	if !slices.Equal(files, want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+
+	if _, err := expandSpecFiles([]string{filepath.Join(dir, "*.json")}); err == nil {
+		t.Error("Expected an error for a pattern matching nothing")
+	}
+	if _, err := expandSpecFiles([]string{filepath.Join(dir, "[")}); err == nil {
+		t.Error("Expected an error for a malformed pattern")
+	}
+}
+
+func TestTestSpecsReturnsSpecErrors(t *testing.T) {
+	p, err := parser.ParseFile("../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	runs := []*specRun{{file: "pets.json", parser: p}, {file: "orders.json", parser: p}}
+
+	// A file in the way of its failures directory stops the second spec only
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "orders"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := testSpecs(runs, tester.Config{SaveFailuresDir: dir}, 1)
+	if err == nil {
+		t.Fatal("Expected an error for the spec that could not be tested")
+	}
+	if len(summary.Specs) != 2 || summary.Specs[0].Error != "" || summary.Specs[1].Error == "" {
+		t.Errorf("Expected both specs in the summary with the error on the second, got %+v", summary.Specs)
+	}
+}
@@ -4,29 +4,69 @@ Copyright © 2026 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/mattn/go-isatty"
+	"github.com/moamenhredeen/oas/internal/grpcgw"
+	"github.com/moamenhredeen/oas/internal/inventory"
+	"github.com/moamenhredeen/oas/internal/mask"
 	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/normalize"
 	"github.com/moamenhredeen/oas/internal/output"
-	"github.com/moamenhredeen/oas/internal/parser"
 	"github.com/moamenhredeen/oas/internal/tester"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
-	serverURL    string
-	filter       string
-	tags         []string
-	verbose      bool
-	outputFormat string
-	outputFile   string
-	timeout      int
+	failFast        bool
+	maxFailures     int
+	repeat          int
+	retries         int
+	retryOn         []string
+	retryBackoff    time.Duration
+	methodDefaults  bool
+	sample          string
+	skipDeprecated  bool
+	failUnsupported bool
+	maxArrayItems   int
+	saveFailures    string
+	inventoryFile   string
+	snapshotDir     string
+	snapshotUpdate  bool
+	enumCases       bool
+	pairwise        bool
+	maxEnumValues   int
+	testCases       int
+	testSeed        int64
+	optionalParams  float64
+	profileName     string
+	prefetchIDs     bool
+	parallelSpecs   int
+	unicodeStrings  bool
+	callbacks       bool
+	grpcTarget      string
+	grpcPlaintext   bool
+	graphQL         bool
+	groupBy         string
+
+	callbackListen  string
+	callbackURL     string
+	callbackTimeout time.Duration
+
+	correlationHeader string
 
 	// Color helpers for output
 	green = color.New(color.FgGreen, color.Bold).SprintFunc()
@@ -38,139 +78,458 @@ var (
 
 // testCmd represents the test command
 var testCmd = &cobra.Command{
-	Use:   "test [openapi-spec-file]",
+	Use:   "test [openapi-spec-file...]",
 	Short: "Test the APIs",
-	Long:  `Test the APIs by providing the OpenAPI Specification file and the endpoints to test.`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		specFile := args[0]
+	Long: `Test the APIs by providing the OpenAPI Specification file and the endpoints to test.
 
-		// Parse OpenAPI spec
-		p, err := parser.ParseFile(specFile)
+Several spec files (or glob patterns such as "services/*/openapi.yaml") can be
+given to test a fleet of services in one run, with a combined report grouped
+by spec.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		specFiles, err := expandSpecFiles(args)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing OpenAPI file: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitUsage, nil)
+		}
+		if err := validateGroupBy(groupBy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitUsage, nil)
+		}
+		multiSpec := len(specFiles) > 1
+
+		// Parse every spec before any request is sent
+		parsers := parseSpecs(specFiles)
+		runs := make([]*specRun, 0, len(specFiles))
+		for i, specFile := range specFiles {
+			run, err := prepareSpec(specFile, parsers[i], multiSpec)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
+			runs = append(runs, run)
+		}
+
+		if !multiSpec && len(runs[0].filteredOps) == 0 {
+			fmt.Println("No operations found matching the criteria")
+			exit(exitOK, nil)
+		}
+
+		if saveFailures != "" {
+			if err := os.MkdirAll(saveFailures, 0o755); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
 		}
 
-		// Get server URLs
-		serverURLs, err := p.GetServerURLs()
+		profile, err := tester.ParseProfile(profileName)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting server URLs: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitUsage, nil)
+		}
+		// Coverage is measured against every operation of the spec, so a
+		// selection of them could never reach the required coverage
+		if profile.MinCoverage > 0 && (opts.Filter != "" || len(opts.Tags) > 0 || sample != "") {
+			fmt.Fprintf(os.Stderr, "Error: --profile %s requires %.0f%% coverage of the spec's operations and can't be combined with --filter, --tags or --sample\n", profile.Name, profile.MinCoverage)
+			exit(exitUsage, nil)
 		}
 
-		// Use provided server URL or first from spec
-		baseURL := serverURL
-		if baseURL == "" && len(serverURLs) > 0 {
-			baseURL = serverURLs[0]
+		if optionalParams < 0 || optionalParams > 1 {
+			fmt.Fprintf(os.Stderr, "Error: --include-optional-params must be between 0 and 1, got %g\n", optionalParams)
+			exit(exitUsage, nil)
 		}
-		if baseURL == "" {
-			baseURL = "http://localhost"
+		if snapshotUpdate && snapshotDir == "" {
+			fmt.Fprintln(os.Stderr, "Error: --snapshot-update requires --snapshot")
+			exit(exitUsage, nil)
+		}
+		// Each spec starts its own callback listener, so specs tested at the
+		// same time can't share an address or URL
+		if callbacks && multiSpec && parallelSpecs > 1 && (callbackListen != "" || callbackURL != "") {
+			fmt.Fprintln(os.Stderr, "Error: --callback-listen and --callback-url can't be used with --parallel-specs")
+			exit(exitUsage, nil)
+		}
+		normalizer, err := buildNormalizer()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitUsage, nil)
+		}
+		snapshotIgnore := viper.GetStringSlice("snapshot.ignore")
+		if _, err := normalizer.WithMask(snapshotIgnore...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: [snapshot] ignore: %s\n", err)
+			exit(exitUsage, nil)
 		}
 
-		// Get all operations
-		operations, err := p.GetOperations(baseURL)
+		fixtures, err := seedFixtures()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting operations: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitUsage, nil)
 		}
 
-		// Filter operations
-		filteredOps := filterOperations(operations, filter, tags)
+		seed := testSeed
+		if testCases > 1 && seed == 0 {
+			seed = time.Now().UnixNano()
+		}
 
-		if len(filteredOps) == 0 {
-			fmt.Println("No operations found matching the criteria")
-			os.Exit(0)
+		// --fail-fast is shorthand for --max-failures 1
+		if failFast && maxFailures == 0 {
+			maxFailures = 1
+		}
+
+		ctx, cancel := runContext()
+		defer cancel()
+
+		var recorder *inventory.Recorder
+		if inventoryFile != "" {
+			recorder = inventory.NewRecorder()
 		}
 
 		// Run tests with live output
-		testRunner := tester.NewTester(time.Duration(timeout) * time.Second)
-		var s *spinner.Spinner
-
-		// Create event handler for live output
-		onEvent := func(event tester.TestEvent) {
-			switch event.Type {
-			case tester.EventStarting:
-				if isTTY {
-					// Start spinner for TTY
-					s = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-					s.Suffix = fmt.Sprintf(" [%d/%d] Running %s %s...",
-						event.Index+1, event.Total, event.Operation.Method, event.Operation.Path)
-					s.Start()
-				} else {
-					// Simple output for non-TTY
-					fmt.Printf("[%d/%d] Running %s %s...\n",
-						event.Index+1, event.Total, event.Operation.Method, event.Operation.Path)
-				}
-			case tester.EventCompleted:
-				if isTTY && s != nil {
-					s.Stop()
-				}
+		config := tester.Config{
+			Context:      ctx,
+			Timeout:      time.Duration(opts.Timeout) * time.Second,
+			MaxFailures:  maxFailures,
+			Repeat:       repeat,
+			Retry:        retryPolicy(),
+			Expectations: methodExpectations(),
+			ParamValues:  viper.GetStringMap("params"),
+			PrefetchIDs:  prefetchIDs,
+			Unicode:      unicodeStrings,
+			Assertions:   viper.GetStringMapStringSlice("assertions"),
+			Fixtures:     fixtures,
+			Inventory:    recorder,
+
+			MaxArrayItems:   maxArrayItems,
+			SaveFailuresDir: saveFailures,
+			Snapshot: tester.SnapshotConfig{
+				Dir:    snapshotDir,
+				Update: snapshotUpdate,
+				Ignore: snapshotIgnore,
+			},
+			Normalizer: normalizer,
+
+			EnumCases:     enumCases,
+			Pairwise:      pairwise,
+			MaxEnumValues: maxEnumValues,
+			Cases:         testCases,
+			Seed:          seed,
+
+			OptionalParams: optionalParams,
+
+			CorrelationHeader: correlationHeader,
+			UserAgent:         userAgent(),
+			HostHeader:        hostHeader,
+			Accept:            acceptHeader,
+			AddressFamily:     addressFamily(),
+			CallbackListen:    callbackListen,
+			CallbackURL:       callbackURL,
+			CallbackTimeout:   callbackTimeout,
+			GraphQL:           graphQL,
+		}
+		profile.Apply(&config)
 
-				result := event.Result
-				prefix := fmt.Sprintf("[%d/%d]", event.Index+1, event.Total)
+		scripts, err := tester.CompileScripts(viper.GetStringMapStringSlice("scripts"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		config.Scripts = scripts
 
-				if result.Passed {
-					fmt.Printf("%s %s %s %s\n", prefix, green("✓ PASS"), result.Method, result.Path)
-				} else {
-					fmt.Printf("%s %s %s %s\n", prefix, red("✗ FAIL"), result.Method, result.Path)
-				}
+		authenticator, err := buildAuthenticator(profileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error configuring authentication: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		config.Authenticator = authenticator
+		config.DigestAuth = digestCredentials()
 
-				// Verbose output: show details inline
-				if verbose {
-					if result.OperationID != "" {
-						fmt.Printf("    Operation ID: %s\n", result.OperationID)
-					}
-					fmt.Printf("    Status Code: %d\n", result.StatusCode)
-					fmt.Printf("    Response Time: %v\n", result.ResponseTime)
+		if err := config.Retry.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
+		if err := tester.ValidateExpectations(config.Expectations); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			exit(exitFailed, nil)
+		}
 
-					if !result.Passed {
-						if result.Error != "" {
-							fmt.Printf("    Error: %s\n", red(result.Error))
-						}
-						if len(result.ValidationErrors) > 0 {
-							fmt.Printf("    Validation Errors:\n")
-							for _, ve := range result.ValidationErrors {
-								fmt.Printf("      - %s: %s\n", ve.Field, red(ve.Message))
-							}
-						}
-					}
+		// Reject malformed assertions before any request is sent
+		for key, exprs := range config.Assertions {
+			for _, expr := range exprs {
+				if _, err := tester.ParseAssertion(expr); err != nil {
+					fmt.Fprintf(os.Stderr, "Error in assertions for %s: %s\n", key, mask.String(err.Error()))
+					exit(exitFailed, nil)
 				}
 			}
 		}
 
-		summary := testRunner.TestOperations(filteredOps, p, onEvent)
+		validators, err := tester.LoadValidators(viper.GetStringMap("validators"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		defer tester.CloseValidators(validators)
+		config.Validators = validators
 
-		// Handle output format
-		if outputFormat != "" {
-			format, err := output.ParseFormat(outputFormat)
+		if grpcTarget != "" {
+			client, err := grpcgw.Dial(grpcTarget, grpcPlaintext, config.Timeout)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
+			}
+			defer client.Close()
+			config.GRPC = client
+			for _, run := range runs {
+				reportGRPCMethods(run, client)
+			}
+		}
+
+		// Validate output destinations before running
+		var specVersion string
+		if !multiSpec {
+			specVersion = runs[0].parser.SpecVersion()
+		}
+		vars := outputPathVars(profileName, specVersion, append([]string{opts.OutputFile}, opts.Tee...)...)
+		dests, err := output.Destinations(opts.Output, opts.OutputFile, opts.Tee, vars)
+		if err == nil {
+			err = checkCIDestinations(dests)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitUsage, nil)
+		}
+		for _, dest := range dests {
+			switch dest.Format {
+			case output.FormatHistogramCSV, output.FormatMarkdown, output.FormatHTML:
+				fmt.Fprintf(os.Stderr, "Error: %s output is only available for benchmarks\n", dest.Format)
+				exit(exitUsage, nil)
 			}
+		}
 
-			if err := output.ExportTestSummary(summary, format, outputFile); err != nil {
-				fmt.Fprintf(os.Stderr, "Error exporting results: %v\n", err)
-				os.Exit(1)
+		// A spec of a multi-spec run that could not be tested fails the run
+		// once the results of the others are reported
+		var summary models.TestSummary
+		var specsErr error
+		if multiSpec {
+			summary, specsErr = testSpecs(runs, config, parallelSpecs)
+		} else {
+			summary, err = testSpec(runs[0], config, testEventHandler("", false, &sync.Mutex{}))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
 			}
+		}
+		summary.MinCoverage = profile.MinCoverage
+		summary.Build = buildInfo()
+		if saveFailures != "" && summary.Failed > 0 {
+			fmt.Fprintf(os.Stderr, "Failing requests saved to: %s\n", saveFailures)
+		}
+		if snapshotDir != "" {
+			reportSnapshots(summary)
+		}
+		if recorder != nil {
+			writeInventory(recorder.Inventory(), inventoryFile)
+		}
 
-			// If writing to file, still show summary
-			if outputFile != "" {
-				fmt.Printf("\nResults exported to: %s\n", outputFile)
-				displayResults(summary)
+		// Handle output destinations
+		for _, dest := range dests {
+			if err := output.ExportTestSummary(summary, dest.Format, dest.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting results: %s\n", mask.String(err.Error()))
+				exit(exitFailed, nil)
 			}
+		}
+		if len(dests) > 0 {
 			// If writing to stdout, skip display (already output)
-			if summary.Failed > 0 {
-				os.Exit(1)
+			if !output.WritesStdout(dests) {
+				fmt.Println()
+				for _, dest := range dests {
+					fmt.Printf("Results exported to: %s\n", dest.Path)
+				}
+				displayResults(summary)
 			}
-			return
+		} else {
+			displayResults(summary)
 		}
-
-		// Display summary
-		displayResults(summary)
+		if specsErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", specsErr)
+			exit(exitFailed, summary)
+		}
+		exit(testExitCode(summary), summary)
 	},
 }
 
+// testEventHandler prints live progress of a test run. label prefixes every
+// line in multi-spec runs. Parallel runs share the terminal, so they print
+// completed tests only, without a spinner or groups; mu serializes their
+// output. With --group-by, tests are indented under a header per group, and
+// on a terminal groups without failures collapse into their header.
+func testEventHandler(label string, parallel bool, mu *sync.Mutex) tester.OnTestEvent {
+	var s *spinner.Spinner
+	spin := isTTY && !parallel
+
+	by := groupBy
+	if parallel {
+		by = ""
+	}
+	var group outputGroup
+	collapse := spin && !opts.Verbose
+	var headerLabel string
+	if label != "" {
+		headerLabel = label + " "
+	}
+
+	return func(event tester.TestEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		prefix := fmt.Sprintf("[%d/%d]", event.Index+1, event.Total)
+		if label != "" {
+			prefix = label + " " + prefix
+		}
+		if by != "" {
+			prefix = "  " + prefix
+		}
+
+		switch event.Type {
+		case tester.EventStarting:
+			if by != "" {
+				group.start(event.Operation, by, headerLabel, collapse)
+			}
+			if spin {
+				// Start spinner for TTY
+				s = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+				s.Suffix = fmt.Sprintf(" %s Running %s %s...",
+					prefix, event.Operation.Method, event.Operation.Path)
+				s.Start()
+			} else if !parallel {
+				// Simple output for non-TTY
+				fmt.Printf("%s Running %s %s...\n",
+					prefix, event.Operation.Method, event.Operation.Path)
+			}
+		case tester.EventCompleted:
+			if spin && s != nil {
+				s.Stop()
+			}
+
+			result := event.Result
+
+			var cases string
+			if result.Cases > 0 {
+				cases = fmt.Sprintf(" (%d/%d cases passed)", result.Cases-result.FailedCases, result.Cases)
+			}
+			if result.Flaky {
+				fmt.Printf("%s %s %s %s (%d/%d runs passed)%s\n", prefix, yellow("~ FLAKY"),
+					result.Method, result.Path, result.PassCount, result.Runs, cases)
+			} else if result.Passed {
+				fmt.Printf("%s %s %s %s%s\n", prefix, green("✓ PASS"), result.Method, result.Path, cases)
+			} else {
+				fmt.Printf("%s %s %s %s%s\n", prefix, red("✗ FAIL"), result.Method, result.Path, cases)
+			}
+
+			// Verbose output: show details inline
+			if opts.Verbose {
+				if result.OperationID != "" {
+					fmt.Printf("    Operation ID: %s\n", result.OperationID)
+				}
+				fmt.Printf("    Status Code: %d\n", result.StatusCode)
+				fmt.Printf("    Response Time: %v\n", result.ResponseTime)
+				if result.Retries > 0 {
+					fmt.Printf("    Retries: %d (%v)\n", result.Retries, result.RetryTime.Round(time.Millisecond))
+				}
+				if result.RemoteAddr != "" {
+					fmt.Printf("    Remote Address: %s (%s)\n", result.RemoteAddr, result.AddressFamily)
+				}
+				if result.RequestID != "" {
+					fmt.Printf("    Request ID: %s\n", result.RequestID)
+				}
+				if result.GRPCStatus != "" {
+					fmt.Printf("    gRPC: %s in %v\n", result.GRPCStatus, result.GRPCResponseTime)
+				}
+
+				if !result.Passed {
+					if result.Error != "" {
+						fmt.Printf("    Error: %s\n", red(result.Error))
+					}
+					if len(result.ValidationErrors) > 0 {
+						fmt.Printf("    Validation Errors:\n")
+						for _, ve := range result.ValidationErrors {
+							fmt.Printf("      - %s: %s\n", ve.Field, red(ve.Message))
+						}
+					}
+				}
+			}
+
+			if by != "" {
+				group.record(result)
+				if event.Index+1 == event.Total {
+					group.finish(headerLabel, collapse)
+				}
+			}
+		}
+	}
+}
+
+// retryPolicy builds the retry policy from --retries, --retry-on and
+// --retry-backoff. The [retry.on] config section sets the retried statuses
+// per method instead, e.g. GET = [503] with POST left out to never retry it.
+func retryPolicy() tester.RetryPolicy {
+	on := tester.IdempotentRetries(retryOn)
+	if viper.IsSet("retry.on") {
+		on = viper.GetStringMapStringSlice("retry.on")
+	}
+	return tester.RetryPolicy{
+		Attempts: retries,
+		On:       on,
+		Backoff:  retryBackoff,
+	}
+}
+
+// methodExpectations returns the expectations for undocumented statuses: the
+// conventional ones with --method-defaults, overridden per method by the
+// [expectations] config section
+func methodExpectations() map[string]tester.MethodExpectation {
+	expectations := make(map[string]tester.MethodExpectation)
+	if methodDefaults {
+		expectations = tester.DefaultMethodExpectations()
+	}
+	for method := range viper.GetStringMap("expectations") {
+		key := "expectations." + method
+		// Replace the default of the same method, whose key is uppercase
+		delete(expectations, strings.ToUpper(method))
+		expectations[method] = tester.MethodExpectation{
+			Statuses:  viper.GetStringSlice(key + ".status"),
+			EmptyBody: viper.GetBool(key + ".empty_body"),
+		}
+	}
+	return expectations
+}
+
+// seedFixtures reads the [seed] config section: the JSON bodies sent to an
+// operation before the run, as strings or inline tables
+func seedFixtures() (map[string][]string, error) {
+	fixtures := make(map[string][]string)
+	for key, raw := range viper.GetStringMap("seed") {
+		list, ok := raw.([]interface{})
+		if !ok {
+			list = []interface{}{raw}
+		}
+		for i, item := range list {
+			var body []byte
+			if text, ok := item.(string); ok {
+				body = []byte(text)
+				if !json.Valid(body) {
+					return nil, fmt.Errorf("[seed] %s: fixture %d is not valid JSON", key, i+1)
+				}
+			} else {
+				var err error
+				if body, err = json.Marshal(item); err != nil {
+					return nil, fmt.Errorf("[seed] %s: fixture %d: %w", key, i+1, err)
+				}
+			}
+			fixtures[key] = append(fixtures[key], string(body))
+		}
+	}
+	return fixtures, nil
+}
+
 func filterOperations(operations []models.Operation, filterStr string, tagFilters []string) []models.Operation {
 	var filtered []models.Operation
 
@@ -207,26 +566,298 @@ func filterOperations(operations []models.Operation, filterStr string, tagFilter
 	return filtered
 }
 
+// skipOperations removes the operations rejected by keep, recording them as
+// skipped for reason
+func skipOperations(ops []models.Operation, skipped []models.SkippedOperation, reason string, keep func(models.Operation) bool) ([]models.Operation, []models.SkippedOperation) {
+	var kept, dropped []models.Operation
+	for _, op := range ops {
+		if keep(op) {
+			kept = append(kept, op)
+		} else {
+			dropped = append(dropped, op)
+		}
+	}
+	return kept, append(skipped, models.SkipOperations(dropped, reason)...)
+}
+
+// displaySkippedOperations lists the spec operations a run did not exercise,
+// grouped by reason. Filtered and sampled operations are only counted unless
+// verbose output is enabled.
+func displaySkippedOperations(skipped []models.SkippedOperation, exercised int) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	byReason := make(map[string][]models.SkippedOperation)
+	var reasons []string
+	for _, s := range skipped {
+		if _, ok := byReason[s.Reason]; !ok {
+			reasons = append(reasons, s.Reason)
+		}
+		byReason[s.Reason] = append(byReason[s.Reason], s)
+	}
+
+	fmt.Printf("\n%s\n", yellow(fmt.Sprintf("Not Covered: %d of %d spec operations", len(skipped), len(skipped)+exercised)))
+	for _, reason := range reasons {
+		ops := byReason[reason]
+		fmt.Printf("  %-12s %d\n", reason, len(ops))
+		if !opts.Verbose && (reason == models.SkipFiltered || reason == models.SkipSampled) {
+			continue
+		}
+		for _, s := range ops {
+			path := s.Path
+			if s.Spec != "" {
+				path = s.Spec + " " + path
+			}
+			if s.Detail != "" {
+				fmt.Printf("    %-7s %s (%s)\n", s.Method, path, s.Detail)
+			} else {
+				fmt.Printf("    %-7s %s\n", s.Method, path)
+			}
+		}
+	}
+}
+
+// buildNormalizer compiles the [normalize] rules of config.toml, shared by
+// snapshot and gRPC comparisons
+func buildNormalizer() (*normalize.Normalizer, error) {
+	if !viper.IsSet("normalize") {
+		return nil, nil
+	}
+	normalizer, err := normalize.New(normalize.Rules{
+		Mask:  viper.GetStringSlice("normalize.mask"),
+		Sort:  viper.GetStringSlice("normalize.sort"),
+		Round: viper.GetInt("normalize.round"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[normalize] %w", err)
+	}
+	return normalizer, nil
+}
+
+// reportSnapshots counts the snapshot outcomes of a run. Changed snapshots
+// are already listed with the failing tests.
+func reportSnapshots(summary models.TestSummary) {
+	counts := make(map[string]int)
+	for _, r := range summary.Results {
+		counts[r.Snapshot]++
+	}
+	fmt.Fprintf(os.Stderr, "Snapshots in %s: %d matched, %d changed, %d created, %d updated\n", snapshotDir,
+		counts[tester.SnapshotMatched], counts[tester.SnapshotChanged], counts[tester.SnapshotCreated], counts[tester.SnapshotUpdated])
+}
+
+// writeInventory writes the hosts, endpoints and credentials the run
+// exercised as a CycloneDX BOM
+func writeInventory(inv inventory.Inventory, path string) {
+	var buf bytes.Buffer
+	if err := inventory.WriteCycloneDX(&buf, mask.Value(inv), build.Version, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing inventory: %s\n", err)
+		exit(exitFailed, nil)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing inventory: %s\n", err)
+		exit(exitFailed, nil)
+	}
+	endpoints := 0
+	for _, h := range inv.Hosts {
+		endpoints += len(h.Endpoints)
+	}
+	fmt.Fprintf(os.Stderr, "Inventory of %d hosts and %d endpoints written to: %s\n", len(inv.Hosts), endpoints, path)
+}
+
+// sampleOperations picks a random subset of operations. The size is either a
+// percentage ("10%") or an absolute count ("25"). Operations are grouped by
+// their first tag and each group receives a proportional share, with every
+// group represented when the sample is large enough. Spec order is preserved.
+func sampleOperations(operations []models.Operation, sampleStr string) ([]models.Operation, error) {
+	var n int
+	if strings.HasSuffix(sampleStr, "%") {
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(sampleStr, "%"), 64)
+		if err != nil || pct <= 0 {
+			return nil, fmt.Errorf("invalid sample '%s': must be a positive percentage or count", sampleStr)
+		}
+		n = int(math.Ceil(float64(len(operations)) * pct / 100))
+	} else {
+		count, err := strconv.Atoi(sampleStr)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid sample '%s': must be a positive percentage or count", sampleStr)
+		}
+		n = count
+	}
+	if n >= len(operations) {
+		return operations, nil
+	}
+
+	// Group operation indices by first tag, keeping groups in spec order
+	var groupKeys []string
+	groups := make(map[string][]int)
+	for i, op := range operations {
+		key := ""
+		if len(op.Tags) > 0 {
+			key = op.Tags[0]
+		}
+		if _, ok := groups[key]; !ok {
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	// Allocate proportional quotas using the largest remainder method
+	quotas := make(map[string]int, len(groupKeys))
+	remainders := make(map[string]float64, len(groupKeys))
+	allocated := 0
+	for _, key := range groupKeys {
+		exact := float64(n) * float64(len(groups[key])) / float64(len(operations))
+		quotas[key] = int(exact)
+		remainders[key] = exact - float64(quotas[key])
+		allocated += quotas[key]
+	}
+	byRemainder := append([]string(nil), groupKeys...)
+	sort.SliceStable(byRemainder, func(i, j int) bool {
+		return remainders[byRemainder[i]] > remainders[byRemainder[j]]
+	})
+	for i := 0; allocated < n; i++ {
+		quotas[byRemainder[i%len(byRemainder)]]++
+		allocated++
+	}
+
+	// Make sure every group is represented when the sample allows it
+	if n >= len(groupKeys) {
+		for _, key := range groupKeys {
+			if quotas[key] > 0 {
+				continue
+			}
+			largest := groupKeys[0]
+			for _, k := range groupKeys {
+				if quotas[k] > quotas[largest] {
+					largest = k
+				}
+			}
+			quotas[largest]--
+			quotas[key]++
+		}
+	}
+
+	// Draw randomly within each group
+	var picked []int
+	for _, key := range groupKeys {
+		members := append([]int(nil), groups[key]...)
+		rand.Shuffle(len(members), func(i, j int) {
+			members[i], members[j] = members[j], members[i]
+		})
+		picked = append(picked, members[:quotas[key]]...)
+	}
+	sort.Ints(picked)
+
+	sampled := make([]models.Operation, 0, len(picked))
+	for _, i := range picked {
+		sampled = append(sampled, operations[i])
+	}
+	return sampled, nil
+}
+
+// formatErrorClasses lists failure counts by class, e.g. "5xx: 3, timeout: 1"
+func formatErrorClasses(counts models.ErrorCounts) string {
+	var parts []string
+	for _, class := range models.ErrorClasses {
+		if n := counts[class]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", class, n))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 func displayResults(summary models.TestSummary) {
 	fmt.Println("\n=== Test Summary ===")
 	fmt.Printf("Total Tests: %d\n", summary.TotalTests)
 	fmt.Printf("Passed: %s\n", green(summary.Passed))
 	fmt.Printf("Failed: %s\n", red(summary.Failed))
+	if len(summary.Errors) > 0 {
+		fmt.Printf("Failures by Class: %s\n", formatErrorClasses(summary.Errors))
+	}
+	if summary.Flaky > 0 {
+		fmt.Printf("Flaky: %s\n", yellow(summary.Flaky))
+		for _, r := range summary.Results {
+			if r.Flaky {
+				fmt.Printf("  %s %s - %.1f%% of %d runs failed\n", r.Method, r.Path, r.Flakiness, r.Runs)
+			}
+		}
+	}
+	if summary.Seed != 0 {
+		fmt.Printf("Seed: %d (pass --seed to reproduce the generated cases)\n", summary.Seed)
+	}
+	if summary.DeadlineExceeded {
+		fmt.Printf("%s\n", red(fmt.Sprintf("Deadline of %v exceeded, %d operation(s) not run", deadline, summary.Skipped)))
+	} else if summary.Stopped && control != nil && control.cancelRequested() {
+		fmt.Printf("Cancelled, %d operation(s) not run\n", summary.Skipped)
+	} else if summary.Stopped {
+		fmt.Printf("Stopped after %d failure(s), %d operation(s) skipped\n", summary.Failed, summary.Skipped)
+	}
 
-	// Exit with error code if any tests failed
-	if summary.Failed > 0 {
-		os.Exit(1)
+	if summary.Unsupported > 0 {
+		line := fmt.Sprintf("Unsupported: %d operation(s) skipped, see Not Covered", summary.Unsupported)
+		if failUnsupported {
+			line = red(line)
+		} else {
+			line = yellow(line)
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("Coverage: %.1f%% of spec operations\n", summary.Coverage)
+	if len(summary.Specs) > 0 {
+		fmt.Println("By spec:")
+		for _, sp := range summary.Specs {
+			failed := fmt.Sprint(sp.Failed)
+			if sp.Failed > 0 {
+				failed = red(sp.Failed)
+			}
+			fmt.Printf("  %s: %d passed, %s failed, %.1f%% coverage\n", sp.Spec, sp.Passed, failed, sp.Coverage)
+		}
+	}
+	if !summary.CoverageMet() {
+		fmt.Printf("%s coverage %.1f%% is below the required %.1f%%\n",
+			red("✗"), summary.Coverage, summary.MinCoverage)
 	}
+	displayGRPCComparison(summary.Results)
+	displaySkippedOperations(summary.SkippedOperations, summary.TotalTests)
 }
 
 func init() {
 	rootCmd.AddCommand(testCmd)
 
-	testCmd.Flags().StringVar(&serverURL, "server", "", "Override server URL from OpenAPI spec")
-	testCmd.Flags().StringVar(&filter, "filter", "", "Filter endpoints by path pattern or operation ID")
-	testCmd.Flags().StringSliceVar(&tags, "tags", []string{}, "Filter by OpenAPI tags (can be specified multiple times)")
-	testCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed output")
-	testCmd.Flags().IntVarP(&timeout, "timeout", "t", 30, "Request timeout in seconds")
-	testCmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format: json, csv")
-	testCmd.Flags().StringVar(&outputFile, "output-file", "", "Write output to file (default: stdout)")
+	testCmd.Flags().IntVar(&parallelSpecs, "parallel-specs", 1, "Test up to N spec files concurrently when several are given")
+	testCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop at the first failing operation")
+	testCmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Stop after N failing operations (0 = unlimited)")
+	testCmd.Flags().IntVar(&repeat, "repeat", 1, "Run each operation N times and report flaky operations")
+	testCmd.Flags().IntVar(&retries, "retries", 0, "Send a request up to N more times while it gets a retried status")
+	testCmd.Flags().StringSliceVar(&retryOn, "retry-on", tester.DefaultRetryStatuses, "Statuses retried for idempotent methods, e.g. 503 or 5XX (see [retry.on] in config.toml for per-method statuses)")
+	testCmd.Flags().DurationVar(&retryBackoff, "retry-backoff", tester.DefaultRetryBackoff, "Delay before the first retry, doubled for each further one")
+	testCmd.Flags().BoolVar(&methodDefaults, "method-defaults", false, "Judge undocumented statuses by method conventions: POST 2XX, DELETE 2XX or 404, HEAD 2XX without a body")
+	testCmd.Flags().StringVar(&profileName, "profile", "standard", "Test profile: smoke, standard, strict")
+	testCmd.Flags().BoolVar(&prefetchIDs, "prefetch-ids", false, "Harvest real ids from collection endpoints for item path parameters")
+	testCmd.Flags().StringVar(&correlationHeader, "correlation-header", "", "Send a unique id per request in this header (e.g. X-Request-Id)")
+	testCmd.Flags().IntVar(&maxArrayItems, "max-array-items", tester.DefaultMaxArrayItems, "Response array items validated per array, sampled evenly (0 = all)")
+	testCmd.Flags().StringVar(&saveFailures, "save-failures", "", "Write the request and response of each failing test to this directory")
+	testCmd.Flags().StringVar(&inventoryFile, "inventory", "", "Write the hosts, endpoints and credentials the run exercised to this CycloneDX JSON file")
+	testCmd.Flags().StringVar(&snapshotDir, "snapshot", "", "Store responses in this directory on the first run and diff later runs against them")
+	testCmd.Flags().BoolVar(&snapshotUpdate, "snapshot-update", false, "Rewrite the --snapshot files with the current responses")
+	testCmd.Flags().BoolVar(&enumCases, "enum-cases", false, "Test each value of enum-valued path and query parameters and request body fields")
+	testCmd.Flags().IntVar(&maxEnumValues, "enum-cases-max", tester.DefaultMaxEnumValues, "Only test enums with at most this many values value by value (0 = all)")
+	testCmd.Flags().BoolVar(&unicodeStrings, "unicode", false, "Fill free-form string fields with multi-byte, emoji, RTL, zero-width and maximum-length text")
+	testCmd.Flags().IntVar(&testCases, "cases", 1, "Test each operation with N differently seeded generated requests")
+	testCmd.Flags().Int64Var(&testSeed, "seed", 0, "Base seed for --cases (default: random, printed for reproduction)")
+	testCmd.Flags().BoolVar(&pairwise, "pairwise", false, "Test pairwise combinations of enum-valued parameters (implies --enum-cases)")
+	testCmd.Flags().Float64Var(&optionalParams, "include-optional-params", 0, "Also test with optional query parameters, each sent with this probability (0-1)")
+	testCmd.Flags().Lookup("include-optional-params").NoOptDefVal = "1"
+	testCmd.Flags().BoolVar(&skipDeprecated, "skip-deprecated", false, "Skip operations marked deprecated in the spec")
+	testCmd.Flags().BoolVar(&failUnsupported, "fail-on-unsupported", false, "Fail the run if any operation is skipped as unsupported")
+	testCmd.Flags().BoolVar(&callbacks, "callbacks", false, "Receive and validate the callbacks and webhooks the API sends")
+	testCmd.Flags().StringVar(&callbackListen, "callback-listen", "", "Address of the callback listener (default: 127.0.0.1 on a random port)")
+	testCmd.Flags().StringVar(&callbackURL, "callback-url", "", "URL the API reaches the callback listener at (default: the listen address)")
+	testCmd.Flags().DurationVar(&callbackTimeout, "callback-timeout", tester.DefaultCallbackTimeout, "How long to wait for the callbacks of an operation")
+	testCmd.Flags().StringVar(&grpcTarget, "grpc", "", "Compare GET operations of a grpc-gateway with the gRPC methods behind them at this address (needs server reflection)")
+	testCmd.Flags().BoolVar(&grpcPlaintext, "grpc-plaintext", false, "Connect to the --grpc server without TLS")
+	testCmd.Flags().BoolVar(&graphQL, "graphql", false, "Introspect POST /graphql operations and test a generated query per field")
+	testCmd.Flags().StringVar(&sample, "sample", "", "Test a random subset of operations, stratified by tag (e.g. 10% or 25)")
+	testCmd.Flags().StringVar(&groupBy, "group-by", "", "Group live output by tag or path prefix: tag, path")
 }
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/moamenhredeen/oas/internal/lint"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rulesetFile  string
+	failSeverity string
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [openapi-spec-file...]",
+	Short: "Lint OpenAPI specs against a ruleset",
+	Long: `Check that OpenAPI specs parse and follow a style guide. Without --ruleset the
+built-in rules apply, e.g. every operation has an operationId, a description
+and tags.
+
+Rulesets are YAML files in a subset of the Spectral format: each rule selects
+nodes with a JSONPath ("given") and checks them with a function ("then"), so
+existing Spectral style guides carry over. Rules using functions or paths not
+supported here are skipped and listed.`,
+	Example: `  oas validate api-spec.yaml
+  oas validate 'services/*/openapi.yaml' --ruleset .spectral.yaml --fail-severity warn`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rejectFlags(cmd, requestFlags...)
+		specFiles, err := expandSpecFiles(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		threshold, err := lint.ParseSeverity(failSeverity)
+		if err != nil || threshold == lint.SeverityOff {
+			fmt.Fprintf(os.Stderr, "Error: invalid fail-severity '%s': must be error, warn, info or hint\n", failSeverity)
+			exit(exitFailed, nil)
+		}
+
+		ruleset := lint.DefaultRuleset()
+		if rulesetFile != "" {
+			ruleset, err = lint.LoadRuleset(rulesetFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				exit(exitFailed, nil)
+			}
+		}
+		for _, skipped := range ruleset.Skipped {
+			fmt.Fprintf(os.Stderr, "Warning: skipped rule %s\n", skipped)
+		}
+
+		// Specs must parse before their style is worth checking
+		parseSpecs(specFiles)
+
+		counts := make(map[lint.Severity]int)
+		failed := false
+		for _, specFile := range specFiles {
+			data, err := os.ReadFile(specFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				exit(exitFailed, nil)
+			}
+			problems, err := ruleset.Lint(data)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error linting %s: %s\n", specFile, err)
+				exit(exitFailed, nil)
+			}
+			if len(problems) == 0 {
+				continue
+			}
+
+			fmt.Println(specFile)
+			for _, p := range problems {
+				counts[p.Severity]++
+				if p.Severity <= threshold {
+					failed = true
+				}
+				fmt.Printf("  %4d:%-3d %s  %-36s %s\n", p.Line, p.Column, severityLabel(p.Severity), p.Rule, p.Message)
+				if opts.Verbose {
+					fmt.Printf("           %s\n", p.Path)
+				}
+			}
+			fmt.Println()
+		}
+
+		total := counts[lint.SeverityError] + counts[lint.SeverityWarn] + counts[lint.SeverityInfo] + counts[lint.SeverityHint]
+		summary := fmt.Sprintf("%d problem(s) in %d spec(s): %d error(s), %d warning(s), %d info, %d hint(s)",
+			total, len(specFiles), counts[lint.SeverityError], counts[lint.SeverityWarn], counts[lint.SeverityInfo], counts[lint.SeverityHint])
+		if failed {
+			fmt.Printf("%s %s\n", red("✗"), summary)
+			exit(exitFailed, nil)
+		}
+		fmt.Printf("%s %s\n", green("✓"), summary)
+		exit(exitOK, nil)
+	},
+}
+
+// severityLabel colors a severity for the console, padded before coloring so
+// columns stay aligned
+func severityLabel(s lint.Severity) string {
+	label := fmt.Sprintf("%-5s", s)
+	switch s {
+	case lint.SeverityError:
+		return red(label)
+	case lint.SeverityWarn:
+		return yellow(label)
+	}
+	return cyan(label)
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVar(&rulesetFile, "ruleset", "", "YAML ruleset in the Spectral format (default: the built-in rules)")
+	validateCmd.Flags().StringVar(&failSeverity, "fail-severity", "error", "Fail if a problem is at least this severe: error, warn, info, hint")
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/tester"
+	"github.com/spf13/cobra"
+)
+
+var verifyTimeout time.Duration
+
+// verifyLiveCmd represents the verify-live command
+var verifyLiveCmd = &cobra.Command{
+	Use:   "verify-live [openapi-spec-file]",
+	Short: "Check that a deployed server serves every documented operation",
+	Long: `Probe a live server for every operation in the spec to catch drift between
+the spec and the deployment, e.g. as a gate before publishing a spec.
+
+Routes are probed with OPTIONS and HEAD, and GET and HEAD operations are sent
+with generated parameters (collection endpoints are consulted for real ids).
+Nothing that could change state is sent, so operations with other methods are
+confirmed only if the server lists its methods in an Allow header.
+
+An operation is missing if the server answers 404 for its route, and method
+not allowed if the route exists without the documented method. The command
+fails if any operation is missing or not allowed.`,
+	Example: `  oas verify-live api-spec.json --server https://staging.example.com
+  oas verify-live api-spec.json --server https://api.example.com --tags payments -v`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		specFile := args[0]
+		run, err := prepareSpec(specFile, parseSpecs([]string{specFile})[0], false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		if len(run.filteredOps) == 0 {
+			fmt.Println("No operations found matching the criteria")
+			exit(exitOK, nil)
+		}
+
+		ctx, cancel := runContext()
+		defer cancel()
+
+		config := tester.Config{
+			Context:       ctx,
+			Timeout:       verifyTimeout,
+			PrefetchIDs:   true,
+			UserAgent:     userAgent(),
+			HostHeader:    hostHeader,
+			Accept:        acceptHeader,
+			AddressFamily: addressFamily(),
+		}
+		authenticator, err := buildAuthenticator("")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error configuring authentication: %s\n", mask.String(err.Error()))
+			exit(exitFailed, nil)
+		}
+		config.Authenticator = authenticator
+		config.DigestAuth = digestCredentials()
+
+		fmt.Printf("Verifying %d operations against %s\n\n", len(run.filteredOps), run.baseURL)
+		summary := tester.NewTesterWithConfig(config).VerifyLive(run.filteredOps, run.parser, displayLiveCheck)
+
+		fmt.Println()
+		if summary.Operations < len(run.filteredOps) {
+			fmt.Printf("%s\n", red(fmt.Sprintf("Stopped, %d of %d operations verified", summary.Operations, len(run.filteredOps))))
+		}
+		fmt.Printf("Present: %s, Missing: %s, Method not allowed: %s, Unverified: %d, Errors: %d\n",
+			green(summary.Present), red(summary.Missing), red(summary.MethodNotAllowed), summary.Unverified, summary.Errors)
+		if summary.Unverified > 0 && !opts.Verbose {
+			fmt.Println("Unverified operations exist on the server, but their method isn't listed in an Allow header")
+		}
+		exit(verifyExitCode(summary), summary)
+	},
+}
+
+// displayLiveCheck prints a single line per probed operation
+func displayLiveCheck(check models.LiveCheck) {
+	line := fmt.Sprintf("%s %s", check.Method, check.Path)
+	switch check.Outcome {
+	case models.LivePresent:
+		fmt.Printf("%s %s\n", green("✓"), line)
+	case models.LiveMissing:
+		fmt.Printf("%s %s %s\n", red("✗"), line, red("missing"))
+	case models.LiveMethodNotAllowed:
+		fmt.Printf("%s %s %s\n", red("✗"), line, red("method not allowed"))
+	case models.LiveUnverified:
+		fmt.Printf("%s %s %s\n", yellow("?"), line, yellow("unverified"))
+	default:
+		fmt.Printf("%s %s %s\n", red("!"), line, red(mask.String(check.Error)))
+	}
+	if opts.Verbose && len(check.Probes) > 0 {
+		fmt.Printf("    %s\n", strings.Join(check.Probes, ", "))
+	}
+}
+
+// verifyExitCode maps a live verification to an exit code
+func verifyExitCode(summary models.LiveSummary) int {
+	if summary.Errors == summary.Operations && summary.Operations > 0 {
+		return exitUnreachable
+	}
+	if !deadlineAt.IsZero() && !time.Now().Before(deadlineAt) {
+		return exitDeadline
+	}
+	if summary.Drifted() || summary.Errors > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+func init() {
+	rootCmd.AddCommand(verifyLiveCmd)
+
+	verifyLiveCmd.Flags().DurationVarP(&verifyTimeout, "timeout", "t", 10*time.Second, "Request timeout")
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/spf13/cobra"
+)
+
+const libopenapiModule = "github.com/pb33f/libopenapi"
+
+var (
+	// version, commit and buildDate are set at build time with
+	// -ldflags "-X github.com/moamenhredeen/oas/cmd.version=1.2.3
+	// -X github.com/moamenhredeen/oas/cmd.commit=$(git rev-parse HEAD)
+	// -X github.com/moamenhredeen/oas/cmd.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+	version   = "dev"
+	commit    string
+	buildDate string
+
+	// build describes this binary; results record it so runs of different
+	// tool versions can be told apart
+	build = readBuildInfo()
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build information",
+	Long: `Print the version of oas, the commit and date it was built from, and the
+version of the OpenAPI parser it uses. The same information is recorded in
+results exported with -o json.
+
+Use -o json for a machine-readable document.`,
+	Example: `  oas version
+  oas version -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		info := build
+		info.RunID = ""
+		switch opts.Output {
+		case "":
+			fmt.Printf("oas %s\n", info.Version)
+			printBuildField("commit", info.Commit)
+			if info.Modified {
+				fmt.Println("  modified:   true")
+			}
+			printBuildField("built", info.BuildDate)
+			printBuildField("libopenapi", info.LibOpenAPI)
+			printBuildField("go", info.GoVersion+" "+info.Platform)
+		case "json":
+			data, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(exitFailed, nil)
+			}
+			fmt.Println(string(data))
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid output format '%s': must be 'json'\n", opts.Output)
+			exit(exitUsage, nil)
+		}
+	},
+}
+
+func printBuildField(name, value string) {
+	if value != "" {
+		fmt.Printf("  %-11s %s\n", name+":", value)
+	}
+}
+
+// readBuildInfo combines the -ldflags values with the module and VCS
+// information the Go toolchain embeds, which fills in what wasn't set, e.g.
+// for binaries built with go install or from a checkout
+func readBuildInfo() models.BuildInfo {
+	info := models.BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		RunID:     runID,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = strings.TrimPrefix(bi.Main.Version, "v")
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path == libopenapiModule {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			info.LibOpenAPI = dep.Version
+		}
+	}
+	return info
+}
+
+// buildInfo returns the build information recorded in results
+func buildInfo() *models.BuildInfo {
+	info := build
+	return &info
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,277 @@
+// Package asyncapi reads the channels and message schemas of AsyncAPI 2.x and
+// 3.x documents so that messages of event-driven APIs can be validated like
+// REST responses.
+package asyncapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"go.yaml.in/yaml/v4"
+)
+
+// Document is a parsed AsyncAPI document
+type Document struct {
+	Version  string
+	Title    string
+	Servers  []Server
+	Channels []Channel
+}
+
+// Server is a broker or endpoint the API is reachable at
+type Server struct {
+	Name     string
+	URL      string // as written in the document, e.g. ws://example.com/ws or kafka.example.com:9092
+	Protocol string
+}
+
+// Channel is a topic, queue or WebSocket path carrying messages
+type Channel struct {
+	Name     string // key under channels
+	Address  string // 3.x address, or the name in 2.x documents
+	Messages []Message
+}
+
+// Message is a message a channel may carry
+type Message struct {
+	Name        string
+	ContentType string
+	Payload     *base.Schema // nil when the payload has no schema
+	Unsupported string       // reason the payload can't be validated, e.g. an Avro schema
+}
+
+// ParseFile parses an AsyncAPI document from a JSON or YAML file
+func ParseFile(filePath string) (*Document, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses an AsyncAPI document
+func Parse(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("document is not an object")
+	}
+	r := &reader{
+		root: root.Content[0],
+		idx:  index.NewSpecIndexWithConfig(&root, index.CreateClosedAPIIndexConfig()),
+	}
+
+	doc := &Document{Version: r.str(r.root, "asyncapi")}
+	if doc.Version == "" {
+		return nil, fmt.Errorf("not an AsyncAPI document (missing asyncapi version)")
+	}
+	if !strings.HasPrefix(doc.Version, "2.") && !strings.HasPrefix(doc.Version, "3.") {
+		return nil, fmt.Errorf("unsupported AsyncAPI version %s (2.x and 3.x are supported)", doc.Version)
+	}
+	doc.Title = r.str(r.get(r.root, "info"), "title")
+	v3 := strings.HasPrefix(doc.Version, "3.")
+
+	servers := r.get(r.root, "servers")
+	for name, server := range r.pairs(servers) {
+		s := Server{Name: name, URL: r.str(server, "url"), Protocol: r.str(server, "protocol")}
+		if v3 {
+			s.URL = r.str(server, "host") + r.str(server, "pathname")
+			if s.Protocol != "" && !strings.Contains(s.URL, "://") {
+				s.URL = s.Protocol + "://" + s.URL
+			}
+		}
+		doc.Servers = append(doc.Servers, s)
+	}
+
+	channels := r.get(r.root, "channels")
+	for name, channel := range r.pairs(channels) {
+		c := Channel{Name: name, Address: name}
+		var err error
+		if v3 {
+			if address := r.str(channel, "address"); address != "" {
+				c.Address = address
+			}
+			for msgName, msg := range r.pairs(r.get(channel, "messages")) {
+				c.Messages, err = r.appendMessage(c.Messages, msgName, msg)
+				if err != nil {
+					return nil, fmt.Errorf("channel %s: %w", name, err)
+				}
+			}
+		} else {
+			// 2.x: messages hang off the publish and subscribe operations
+			for _, action := range []string{"subscribe", "publish"} {
+				msg := r.get(r.get(channel, action), "message")
+				if msg == nil {
+					continue
+				}
+				alternatives := []*yaml.Node{msg}
+				if oneOf := r.get(msg, "oneOf"); oneOf != nil {
+					alternatives = oneOf.Content
+				}
+				for _, alt := range alternatives {
+					c.Messages, err = r.appendMessage(c.Messages, "", alt)
+					if err != nil {
+						return nil, fmt.Errorf("channel %s: %w", name, err)
+					}
+				}
+			}
+		}
+		doc.Channels = append(doc.Channels, c)
+	}
+
+	return doc, nil
+}
+
+// Channel finds a channel by name or address
+func (d *Document) Channel(name string) (*Channel, error) {
+	for i := range d.Channels {
+		if d.Channels[i].Name == name || d.Channels[i].Address == name {
+			return &d.Channels[i], nil
+		}
+	}
+	return nil, fmt.Errorf("channel %q not found", name)
+}
+
+// ServerURL returns the URL of the first server using one of the protocols
+func (d *Document) ServerURL(protocols ...string) (string, bool) {
+	for _, s := range d.Servers {
+		for _, protocol := range protocols {
+			if strings.EqualFold(s.Protocol, protocol) {
+				return s.URL, true
+			}
+			if u, err := url.Parse(s.URL); err == nil && strings.EqualFold(u.Scheme, protocol) {
+				return s.URL, true
+			}
+		}
+	}
+	return "", false
+}
+
+// reader walks the YAML tree of a document, following local references
+type reader struct {
+	root *yaml.Node
+	idx  *index.SpecIndex
+}
+
+// appendMessage adds a message, skipping one already added (e.g. a message
+// both published and subscribed to)
+func (r *reader) appendMessage(messages []Message, name string, node *yaml.Node) ([]Message, error) {
+	if ref := r.str(node, "$ref"); ref != "" {
+		if name == "" {
+			name = ref[strings.LastIndex(ref, "/")+1:]
+		}
+		resolved, err := r.resolve(ref)
+		if err != nil {
+			return messages, err
+		}
+		node = resolved
+	}
+	if name == "" {
+		name = r.str(node, "name")
+	}
+	for _, m := range messages {
+		if m.Name == name && name != "" {
+			return messages, nil
+		}
+	}
+
+	msg := Message{Name: name, ContentType: r.str(node, "contentType")}
+	if msg.ContentType == "" {
+		msg.ContentType = r.str(r.root, "defaultContentType")
+	}
+
+	payload := r.get(node, "payload")
+	format := r.str(node, "schemaFormat")
+	if schema := r.get(payload, "schema"); schema != nil && r.get(payload, "schemaFormat") != nil {
+		// 3.x multi-format schema
+		format = r.str(payload, "schemaFormat")
+		payload = schema
+	}
+	switch {
+	case payload == nil:
+	case format != "" && !isJSONSchema(format):
+		msg.Unsupported = fmt.Sprintf("schema format %s is not supported", format)
+	default:
+		var low lowbase.Schema
+		if err := low.Build(context.Background(), payload, r.idx); err != nil {
+			return messages, fmt.Errorf("failed to build payload schema of message %s: %w", name, err)
+		}
+		msg.Payload = base.NewSchema(&low)
+	}
+	return append(messages, msg), nil
+}
+
+// isJSONSchema reports whether a schemaFormat is JSON Schema (or the AsyncAPI
+// superset of it) rather than e.g. Avro or Protobuf
+func isJSONSchema(format string) bool {
+	format = strings.ToLower(format)
+	return strings.Contains(format, "aai") || strings.Contains(format, "asyncapi") ||
+		strings.Contains(format, "schema+json") || strings.Contains(format, "schema+yaml")
+}
+
+// resolve follows a local reference such as #/components/messages/UserSignedUp
+func (r *reader) resolve(ref string) (*yaml.Node, error) {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("only local references are supported, got %s", ref)
+	}
+	node := r.root
+	for _, part := range strings.Split(pointer, "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		node = r.get(node, part)
+		if node == nil {
+			return nil, fmt.Errorf("reference %s not found", ref)
+		}
+	}
+	return node, nil
+}
+
+// get returns the value of a mapping key, or nil
+func (r *reader) get(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// str returns the string value of a mapping key, or ""
+func (r *reader) str(node *yaml.Node, key string) string {
+	if v := r.get(node, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// pairs iterates over the entries of a mapping in document order, following
+// references of the values
+func (r *reader) pairs(node *yaml.Node) func(yield func(string, *yaml.Node) bool) {
+	return func(yield func(string, *yaml.Node) bool) {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1]
+			if ref := r.str(value, "$ref"); ref != "" {
+				if resolved, err := r.resolve(ref); err == nil {
+					value = resolved
+				}
+			}
+			if !yield(node.Content[i].Value, value) {
+				return
+			}
+		}
+	}
+}
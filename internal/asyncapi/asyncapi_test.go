@@ -0,0 +1,132 @@
+package asyncapi
+
+import (
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+func TestParseFileV2(t *testing.T) {
+	doc, err := ParseFile("../../tests/events-asyncapi.yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	if doc.Version != "2.6.0" || doc.Title != "Account Events" {
+		t.Errorf("Expected Account Events 2.6.0, got %s %s", doc.Title, doc.Version)
+	}
+	if url, ok := doc.ServerURL("ws", "wss"); !ok || url != "ws://events.example.com/ws" {
+		t.Errorf("Expected the ws server URL, got %q", url)
+	}
+	if len(doc.Channels) != 3 {
+		t.Fatalf("Expected 3 channels, got %d", len(doc.Channels))
+	}
+
+	signedUp, err := doc.Channel("user/signedup")
+	if err != nil {
+		t.Fatalf("Expected user/signedup channel: %v", err)
+	}
+	if len(signedUp.Messages) != 1 || signedUp.Messages[0].Name != "UserSignedUp" || signedUp.Messages[0].Payload == nil {
+		t.Errorf("Expected the UserSignedUp message with its payload, got %+v", signedUp.Messages)
+	}
+	if ct := signedUp.Messages[0].ContentType; ct != "application/json" {
+		t.Errorf("Expected application/json content type, got %q", ct)
+	}
+
+	deleted, _ := doc.Channel("user/deleted")
+	if len(deleted.Messages) != 2 || deleted.Messages[1].Name != "UserPurged" {
+		t.Errorf("Expected both oneOf messages, got %+v", deleted.Messages)
+	}
+
+	audit, _ := doc.Channel("audit")
+	if len(audit.Messages) != 1 || audit.Messages[0].Unsupported == "" {
+		t.Errorf("Expected the Avro message to be marked unsupported, got %+v", audit.Messages)
+	}
+
+	if _, err := doc.Channel("missing"); err == nil {
+		t.Error("Expected an error for an unknown channel")
+	}
+}
+
+func TestParseFileV3(t *testing.T) {
+	doc, err := ParseFile("../../tests/orders-asyncapi.yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	if url, ok := doc.ServerURL("wss"); !ok || url != "wss://orders.example.com/stream" {
+		t.Errorf("Expected the wss server URL, got %q", url)
+	}
+	channel, err := doc.Channel("orders.created")
+	if err != nil {
+		t.Fatalf("Expected a channel by address: %v", err)
+	}
+	if channel.Name != "orderCreated" {
+		t.Errorf("Expected channel id orderCreated, got %s", channel.Name)
+	}
+	if len(channel.Messages) != 1 || channel.Messages[0].Name != "OrderCreated" || channel.Messages[0].Payload == nil {
+		t.Errorf("Expected the OrderCreated message with its multi-format payload, got %+v", channel.Messages)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not a document", data: "- a\n- b\n"},
+		{name: "openapi", data: "openapi: 3.0.0\n"},
+		{name: "old version", data: "asyncapi: 1.2.0\n"},
+		{name: "remote reference", data: "asyncapi: 2.6.0\nchannels:\n  a:\n    subscribe:\n      message:\n        $ref: 'other.yaml#/Message'\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestChannelValidate(t *testing.T) {
+	doc, err := ParseFile("../../tests/events-asyncapi.yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	v := tester.NewValidator()
+
+	tests := []struct {
+		channel   string
+		message   string
+		wantName  string
+		wantField string
+	}{
+		{channel: "user/signedup", message: `{"id": 1, "email": "ada@example.com"}`, wantName: "UserSignedUp"},
+		{channel: "user/signedup", message: `{"id": 1}`, wantField: "payload.email"},
+		{channel: "user/signedup", message: `{"id": "one", "email": "ada@example.com"}`, wantField: "payload.id"},
+		{channel: "user/signedup", message: `not json`, wantField: "payload"},
+		{channel: "user/deleted", message: `{"id": 1, "purgedAt": "2026-01-02T03:04:05Z"}`, wantName: "UserPurged"},
+		{channel: "user/deleted", message: `{"id": 1, "reason": "bored"}`, wantField: "payload.reason"},
+		{channel: "audit", message: `{}`, wantField: "payload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channel+" "+tt.message, func(t *testing.T) {
+			channel, err := doc.Channel(tt.channel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			name, errors := channel.Validate(v, []byte(tt.message))
+			if tt.wantField == "" {
+				if len(errors) != 0 || name != tt.wantName {
+					t.Errorf("Expected %s to match, got %q %+v", tt.wantName, name, errors)
+				}
+				return
+			}
+			if len(errors) == 0 || errors[0].Field != tt.wantField {
+				t.Errorf("Expected a %s error, got %+v", tt.wantField, errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+// Validate checks a JSON message against the messages the channel may carry.
+// It returns the name of the matching message, or the errors of the first
+// message when none matches.
+func (c *Channel) Validate(v *tester.Validator, data []byte) (string, []models.ValidationError) {
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", []models.ValidationError{{
+			Field:   "payload",
+			Message: fmt.Sprintf("failed to parse JSON message: %v", err),
+		}}
+	}
+
+	var first []models.ValidationError
+	validated := false
+	for _, msg := range c.Messages {
+		if msg.Unsupported != "" {
+			continue
+		}
+		errors := v.ValidateValue("payload", payload, msg.Payload)
+		if len(errors) == 0 {
+			return msg.Name, nil
+		}
+		if !validated {
+			first = errors
+			validated = true
+		}
+	}
+	if !validated {
+		return "", []models.ValidationError{{
+			Field:   "payload",
+			Message: fmt.Sprintf("channel %s declares no message with a JSON schema payload", c.Name),
+		}}
+	}
+	return "", first
+}
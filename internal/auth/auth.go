@@ -0,0 +1,39 @@
+package auth
+
+import "net/http"
+
+// Authenticator adds credentials to an outgoing request. It is applied as the
+// last step of request building, after the body and headers are final.
+type Authenticator interface {
+	Apply(req *http.Request) error
+}
+
+// Chain applies several authenticators in order
+type Chain []Authenticator
+
+// Apply runs every authenticator in the chain
+func (c Chain) Apply(req *http.Request) error {
+	for _, a := range c {
+		if err := a.Apply(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplayRequest returns a copy of req to send again. A request can only be
+// replayed if its body can be recreated; ok is false otherwise.
+func ReplayRequest(req *http.Request) (replay *http.Request, ok bool) {
+	replay = req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		replay.Body = body
+	}
+	return replay, true
+}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// UserCredentials is a username and password pair
+type UserCredentials struct {
+	Username string
+	Password string
+}
+
+// digestChallenge holds the parameters of a WWW-Authenticate: Digest challenge
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+// DigestTransport implements HTTP Digest authentication (RFC 7616). It is
+// negotiated transparently: a request answered with a Digest 401 challenge is
+// retried with credentials, and the challenge is reused to pre-authorize
+// subsequent requests until the server issues a new nonce.
+type DigestTransport struct {
+	Credentials UserCredentials
+	Base        http.RoundTripper
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+	nc        int
+}
+
+// NewDigestTransport wraps base with digest authentication
+func NewDigestTransport(credentials UserCredentials, base http.RoundTripper) *DigestTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &DigestTransport{Credentials: credentials, Base: base}
+}
+
+// RoundTrip sends the request, answering a digest challenge if one is issued
+func (t *DigestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Send a copy with its own body, so that the request can still be replayed
+	// after a challenge. The original body is then unused, but RoundTrip must
+	// close it all the same.
+	first, replayable := ReplayRequest(req)
+	if replayable {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+	} else {
+		first = req.Clone(req.Context())
+	}
+
+	// Pre-authorize with a known challenge
+	if auth := t.authorization(first); auth != "" {
+		first.Header.Set("Authorization", auth)
+	}
+
+	resp, err := t.Base.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge, ok := parseDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok {
+		return resp, nil
+	}
+
+	retry, ok := ReplayRequest(req)
+	if !ok {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	t.mu.Lock()
+	t.challenge = challenge
+	t.nc = 0
+	t.mu.Unlock()
+
+	retry.Header.Set("Authorization", t.authorization(retry))
+	return t.Base.RoundTrip(retry)
+}
+
+// authorization computes the Authorization header for the current challenge
+func (t *DigestTransport) authorization(req *http.Request) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.challenge == nil {
+		return ""
+	}
+	c := t.challenge
+	t.nc++
+	nc := fmt.Sprintf("%08x", t.nc)
+	cnonce := newCnonce()
+
+	newHash := md5.New
+	algorithm := strings.ToUpper(c.algorithm)
+	if strings.HasPrefix(algorithm, "SHA-256") {
+		newHash = sha256.New
+	}
+	h := func(s string) string { return digestHash(newHash, s) }
+
+	uri := req.URL.RequestURI()
+	ha1 := h(t.Credentials.Username + ":" + c.realm + ":" + t.Credentials.Password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := h(req.Method + ":" + uri)
+
+	var response string
+	if c.qop != "" {
+		response = h(strings.Join([]string{ha1, c.nonce, nc, cnonce, c.qop, ha2}, ":"))
+	} else {
+		response = h(ha1 + ":" + c.nonce + ":" + ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, t.Credentials.Username),
+		fmt.Sprintf(`realm="%s"`, c.realm),
+		fmt.Sprintf(`nonce="%s"`, c.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if c.algorithm != "" {
+		parts = append(parts, "algorithm="+c.algorithm)
+	}
+	if c.opaque != "" {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, c.opaque))
+	}
+	if c.qop != "" {
+		parts = append(parts, "qop="+c.qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	return "Digest " + strings.Join(parts, ", ")
+}
+
+// parseDigestChallenge finds and parses a Digest challenge. When several are
+// offered, SHA-256 is preferred over MD5.
+func parseDigestChallenge(headers []string) (*digestChallenge, bool) {
+	var best *digestChallenge
+	for _, header := range headers {
+		scheme, params, ok := strings.Cut(strings.TrimSpace(header), " ")
+		if !ok || !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+		values := parseAuthParams(params)
+		c := &digestChallenge{
+			realm:     values["realm"],
+			nonce:     values["nonce"],
+			opaque:    values["opaque"],
+			algorithm: values["algorithm"],
+		}
+		for _, qop := range strings.Split(values["qop"], ",") {
+			if strings.TrimSpace(qop) == "auth" {
+				c.qop = "auth"
+			}
+		}
+		if c.nonce == "" {
+			continue
+		}
+		if best == nil || strings.HasPrefix(strings.ToUpper(c.algorithm), "SHA-256") {
+			best = c
+		}
+	}
+	return best, best != nil
+}
+
+// parseAuthParams parses comma-separated key=value pairs with optional quoting
+func parseAuthParams(s string) map[string]string {
+	values := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, s = rest[1:], ""
+			} else {
+				value, s = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.Index(rest, ",")
+			if end < 0 {
+				value, s = rest, ""
+			} else {
+				value, s = rest[:end], rest[end+1:]
+			}
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+func digestHash(newHash func() hash.Hash, s string) string {
+	h := newHash()
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newCnonce() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
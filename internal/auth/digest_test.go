@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDigestTransportNegotiates(t *testing.T) {
+	const realm, nonce = "api", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Digest ") {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", qop="auth,auth-int", nonce="%s", algorithm=MD5`, realm, nonce))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		params := parseAuthParams(strings.TrimPrefix(header, "Digest "))
+		h := func(s string) string { return fmt.Sprintf("%x", md5.Sum([]byte(s))) }
+		ha1 := h("alice:" + realm + ":secret")
+		ha2 := h(r.Method + ":" + params["uri"])
+		expected := h(strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+		if params["response"] != expected {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewDigestTransport(UserCredentials{Username: "alice", Password: "secret"}, nil)}
+
+	resp, err := client.Get(server.URL + "/pets?limit=1")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 after negotiation, got %d", resp.StatusCode)
+	}
+
+	// The second request is pre-authorized with the cached challenge
+	resp, err = client.Get(server.URL + "/pets")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 on pre-authorized request, got %d", resp.StatusCode)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests (challenge, retry, pre-authorized), got %d", requests)
+	}
+}
+
+// closeRecorder records whether a request body was closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestDigestTransportClosesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != `{"name":"Rex"}` {
+			t.Errorf("Unexpected body %q", body)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="api", qop="auth", nonce="abc"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("POST", server.URL+"/pets", strings.NewReader(`{"name":"Rex"}`))
+	original := &closeRecorder{Reader: req.Body}
+	req.Body = original
+
+	transport := NewDigestTransport(UserCredentials{Username: "alice", Password: "secret"}, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected 201 after negotiation, got %d", resp.StatusCode)
+	}
+	if !original.closed {
+		t.Error("Expected the original request body to be closed")
+	}
+}
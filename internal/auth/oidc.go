@@ -0,0 +1,324 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"golang.org/x/sync/singleflight"
+)
+
+// OIDC flows
+const (
+	FlowPassword = "password"
+	FlowDevice   = "device"
+	FlowExchange = "exchange"
+)
+
+// refreshSkew renews tokens this long before they expire
+const refreshSkew = 30 * time.Second
+
+// OIDCConfig configures token acquisition from an OpenID Connect provider
+type OIDCConfig struct {
+	Flow          string // password, device or exchange
+	Issuer        string // used for discovery when endpoints are not set
+	TokenURL      string
+	DeviceAuthURL string
+	ClientID      string
+	ClientSecret  string
+	Scope         string
+	Audience      string
+
+	// Resource-owner password flow
+	Username string
+	Password string
+
+	// Token exchange flow (RFC 8693)
+	SubjectToken     string
+	SubjectTokenType string
+}
+
+// OIDC obtains bearer tokens from an OIDC provider and refreshes them
+// automatically so long benchmark runs keep a valid token
+type OIDC struct {
+	config OIDCConfig
+	client *http.Client
+	prompt io.Writer // device flow instructions
+
+	// renewal runs one token request at a time, shared by all callers that
+	// need a new token; mu only guards the token fields
+	renewal      singleflight.Group
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+// tokenResponse is the token endpoint response
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// deviceAuthResponse is the device authorization endpoint response (RFC 8628)
+type deviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// NewOIDC creates an OIDC authenticator, resolving endpoints through discovery
+// when only the issuer is configured
+func NewOIDC(config OIDCConfig) (*OIDC, error) {
+	switch config.Flow {
+	case FlowPassword, FlowDevice, FlowExchange:
+	default:
+		return nil, fmt.Errorf("invalid OIDC flow '%s': must be password, device or exchange", config.Flow)
+	}
+	if config.ClientID == "" {
+		return nil, fmt.Errorf("OIDC client_id is required")
+	}
+
+	o := &OIDC{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+		prompt: os.Stderr,
+	}
+
+	if config.TokenURL == "" || (config.Flow == FlowDevice && config.DeviceAuthURL == "") {
+		if config.Issuer == "" {
+			return nil, fmt.Errorf("OIDC issuer or token_url is required")
+		}
+		if err := o.discover(); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// discover reads the provider's endpoints from its discovery document
+func (o *OIDC) discover() error {
+	resp, err := o.client.Get(strings.TrimSuffix(o.config.Issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OIDC discovery failed: status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		TokenEndpoint               string `json:"token_endpoint"`
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+
+	if o.config.TokenURL == "" {
+		o.config.TokenURL = doc.TokenEndpoint
+	}
+	if o.config.DeviceAuthURL == "" {
+		o.config.DeviceAuthURL = doc.DeviceAuthorizationEndpoint
+	}
+	if o.config.TokenURL == "" {
+		return fmt.Errorf("OIDC discovery returned no token endpoint")
+	}
+	if o.config.Flow == FlowDevice && o.config.DeviceAuthURL == "" {
+		return fmt.Errorf("OIDC provider does not support the device flow")
+	}
+	return nil
+}
+
+// Apply sets a bearer token, acquiring or refreshing it as needed
+func (o *OIDC) Apply(req *http.Request) error {
+	token, err := o.Token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token returns a valid access token. Callers arriving while a token is
+// renewed, e.g. during an interactive device flow, wait for that renewal
+// instead of starting their own.
+func (o *OIDC) Token() (string, error) {
+	if token, ok := o.current(); ok {
+		return token, nil
+	}
+	token, err, _ := o.renewal.Do("token", func() (interface{}, error) {
+		// The previous renewal may have finished since current was checked
+		if token, ok := o.current(); ok {
+			return token, nil
+		}
+		return o.renew()
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.(string), nil
+}
+
+// current returns the access token and whether it is still fresh
+func (o *OIDC) current() (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.accessToken, o.accessToken != "" && (o.expiresAt.IsZero() || time.Now().Add(refreshSkew).Before(o.expiresAt))
+}
+
+// renew obtains a new access token
+func (o *OIDC) renew() (string, error) {
+	o.mu.Lock()
+	refreshToken := o.refreshToken
+	o.mu.Unlock()
+
+	// Prefer the refresh token; fall back to running the flow again
+	if refreshToken != "" {
+		form := o.baseForm("refresh_token")
+		form.Set("refresh_token", refreshToken)
+		if token, err := o.requestToken(form); err == nil {
+			return token, nil
+		}
+	}
+	return o.acquire()
+}
+
+// acquire runs the configured flow
+func (o *OIDC) acquire() (string, error) {
+	switch o.config.Flow {
+	case FlowPassword:
+		form := o.baseForm("password")
+		form.Set("username", o.config.Username)
+		form.Set("password", o.config.Password)
+		return o.requestToken(form)
+	case FlowExchange:
+		tokenType := o.config.SubjectTokenType
+		if tokenType == "" {
+			tokenType = "urn:ietf:params:oauth:token-type:access_token"
+		}
+		form := o.baseForm("urn:ietf:params:oauth:grant-type:token-exchange")
+		form.Set("subject_token", o.config.SubjectToken)
+		form.Set("subject_token_type", tokenType)
+		if o.config.Audience != "" {
+			form.Set("audience", o.config.Audience)
+		}
+		return o.requestToken(form)
+	case FlowDevice:
+		return o.deviceFlow()
+	}
+	return "", fmt.Errorf("unsupported OIDC flow: %s", o.config.Flow)
+}
+
+// deviceFlow asks the user to authorize on another device and polls for the token
+func (o *OIDC) deviceFlow() (string, error) {
+	form := url.Values{}
+	form.Set("client_id", o.config.ClientID)
+	if o.config.Scope != "" {
+		form.Set("scope", o.config.Scope)
+	}
+
+	resp, err := o.client.PostForm(o.config.DeviceAuthURL, form)
+	if err != nil {
+		return "", fmt.Errorf("OIDC device authorization failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var device deviceAuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+		return "", fmt.Errorf("OIDC device authorization failed: %w", err)
+	}
+	if device.DeviceCode == "" {
+		return "", fmt.Errorf("OIDC device authorization failed: status %d", resp.StatusCode)
+	}
+
+	if device.VerificationURIComplete != "" {
+		fmt.Fprintf(o.prompt, "To authorize, visit %s\n", device.VerificationURIComplete)
+	} else {
+		fmt.Fprintf(o.prompt, "To authorize, visit %s and enter code %s\n", device.VerificationURI, device.UserCode)
+	}
+
+	interval := time.Duration(max(device.Interval, 5)) * time.Second
+	deadline := time.Now().Add(time.Duration(max(device.ExpiresIn, 60)) * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		form := o.baseForm("urn:ietf:params:oauth:grant-type:device_code")
+		form.Set("device_code", device.DeviceCode)
+		token, err := o.requestToken(form)
+		if err == nil {
+			return token, nil
+		}
+		switch {
+		case strings.Contains(err.Error(), "authorization_pending"):
+			continue
+		case strings.Contains(err.Error(), "slow_down"):
+			interval += 5 * time.Second
+			continue
+		}
+		return "", err
+	}
+	return "", fmt.Errorf("OIDC device authorization expired")
+}
+
+// baseForm returns the common token request parameters
+func (o *OIDC) baseForm(grantType string) url.Values {
+	form := url.Values{}
+	form.Set("grant_type", grantType)
+	form.Set("client_id", o.config.ClientID)
+	if o.config.ClientSecret != "" {
+		form.Set("client_secret", o.config.ClientSecret)
+	}
+	if o.config.Scope != "" {
+		form.Set("scope", o.config.Scope)
+	}
+	return form
+}
+
+// requestToken posts to the token endpoint, stores the result and returns
+// the access token
+func (o *OIDC) requestToken(form url.Values) (string, error) {
+	resp, err := o.client.PostForm(o.config.TokenURL, form)
+	if err != nil {
+		return "", fmt.Errorf("OIDC token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("OIDC token request failed: status %d: %w", resp.StatusCode, err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("OIDC token request failed: %s %s", token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("OIDC token request failed: status %d", resp.StatusCode)
+	}
+
+	mask.Register(token.AccessToken, token.RefreshToken)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.accessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		o.refreshToken = token.RefreshToken
+	}
+	o.expiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		o.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOIDCPasswordFlowAndRefresh(t *testing.T) {
+	var grants []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/.well-known/openid-configuration" {
+			json.NewEncoder(w).Encode(map[string]string{"token_endpoint": "http://" + r.Host + "/token"})
+			return
+		}
+
+		r.ParseForm()
+		grants = append(grants, r.Form.Get("grant_type"))
+		switch r.Form.Get("grant_type") {
+		case "password":
+			if r.Form.Get("username") != "alice" || r.Form.Get("password") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "first", "refresh_token": "refresh", "expires_in": 10,
+			})
+		case "refresh_token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "second", "expires_in": 3600})
+		}
+	}))
+	defer server.Close()
+
+	o, err := NewOIDC(OIDCConfig{
+		Flow:     FlowPassword,
+		Issuer:   server.URL,
+		ClientID: "oas",
+		Username: "alice",
+		Password: "secret",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create OIDC authenticator: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "http://api.example.com/pets", nil)
+	if err := o.Apply(req); err != nil {
+		t.Fatalf("Failed to apply token: %v", err)
+	}
+
+	// expires_in of 10s is inside the refresh window, so the next call refreshes
+	if req.Header.Get("Authorization") != "Bearer first" {
+		t.Errorf("Expected first token, got %s", req.Header.Get("Authorization"))
+	}
+
+	token, err := o.Token()
+	if err != nil {
+		t.Fatalf("Failed to refresh token: %v", err)
+	}
+	if token != "second" {
+		t.Errorf("Expected refreshed token, got %s", token)
+	}
+
+	// A fresh token is reused without another request
+	if _, err := o.Token(); err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+	if len(grants) != 2 || grants[0] != "password" || grants[1] != "refresh_token" {
+		t.Errorf("Unexpected grant sequence: %v", grants)
+	}
+
+	if o.expiresAt.Before(time.Now().Add(time.Hour - time.Minute)) {
+		t.Errorf("Expected expiry about an hour out, got %v", o.expiresAt)
+	}
+}
+
+func TestNewOIDCInvalidFlow(t *testing.T) {
+	if _, err := NewOIDC(OIDCConfig{Flow: "implicit", ClientID: "oas", TokenURL: "http://x"}); err == nil {
+		t.Error("Expected error for unsupported flow")
+	}
+}
+
+func TestOIDCSharesRenewal(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	o, err := NewOIDC(OIDCConfig{Flow: FlowPassword, TokenURL: server.URL, ClientID: "oas"})
+	if err != nil {
+		t.Fatalf("Failed to create OIDC authenticator: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 5)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[i], _ = o.Token()
+		}()
+	}
+
+	// The token fields stay readable while the renewal waits on the provider
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok := o.current(); ok {
+		t.Error("Expected no token before the renewal finished")
+	}
+	close(release)
+	wg.Wait()
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected one token request, got %d", n)
+	}
+	for i, token := range tokens {
+		if token != "token" {
+			t.Errorf("Caller %d got token %q", i, token)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Credentials and expectations from the AWS Signature Version 4 test suite
+var testCredentials = AWSCredentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+func newTestSigner() *SigV4 {
+	s := NewSigV4("us-east-1", "service", testCredentials)
+	s.now = func() time.Time {
+		return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	}
+	return s
+}
+
+func TestSigV4GetVanilla(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	if err := newTestSigner().Apply(req); err != nil {
+		t.Fatalf("Failed to sign request: %v", err)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("Unexpected Authorization header:\n got: %s\nwant: %s", got, expected)
+	}
+
+	if req.Header.Get("X-Amz-Date") != "20150830T123600Z" {
+		t.Errorf("Unexpected X-Amz-Date: %s", req.Header.Get("X-Amz-Date"))
+	}
+}
+
+func TestSigV4GetVanillaQuery(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	if err := newTestSigner().Apply(req); err != nil {
+		t.Fatalf("Failed to sign request: %v", err)
+	}
+
+	expected := "Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"
+	if got := req.Header.Get("Authorization"); !strings.HasSuffix(got, expected) {
+		t.Errorf("Unexpected Authorization header: %s", got)
+	}
+}
+
+func TestDefaultAWSCredentialsFromEnv(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+	t.Setenv("AWS_SESSION_TOKEN", "TOKEN")
+
+	creds, err := DefaultAWSCredentials("")
+	if err != nil {
+		t.Fatalf("Failed to resolve credentials: %v", err)
+	}
+
+	if creds.AccessKeyID != "AKID" || creds.SecretAccessKey != "SECRET" || creds.SessionToken != "TOKEN" {
+		t.Errorf("Unexpected credentials: %+v", creds)
+	}
+}
@@ -0,0 +1,92 @@
+package badge
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Badge colors, matching the shields.io named colors
+const (
+	ColorGreen  = "brightgreen"
+	ColorYellow = "yellow"
+	ColorRed    = "red"
+	ColorBlue   = "blue"
+)
+
+// colorHex maps named colors to the hex values used in SVG output
+var colorHex = map[string]string{
+	ColorGreen:  "#4c1",
+	ColorYellow: "#dfb317",
+	ColorRed:    "#e05d44",
+	ColorBlue:   "#007ec6",
+}
+
+// Badge is a two-part status badge such as "p99 | 42ms"
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// textWidth approximates the rendered width of text in 11px Verdana, the font
+// shields.io badges use
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("ijlI.,:;|!' ", r):
+			width += 4
+		case strings.ContainsRune("mwMW%@", r):
+			width += 10
+		case r >= 'A' && r <= 'Z':
+			width += 8
+		default:
+			width += 7
+		}
+	}
+	return width
+}
+
+// SVG renders the badge in the flat shields.io style
+func (b Badge) SVG() []byte {
+	labelWidth := textWidth(b.Label) + 10
+	messageWidth := textWidth(b.Message) + 10
+	total := labelWidth + messageWidth
+	color, ok := colorHex[b.Color]
+	if !ok {
+		color = colorHex[ColorBlue]
+	}
+	label := html.EscapeString(b.Label)
+	message := html.EscapeString(b.Message)
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+  <title>%[4]s: %[5]s</title>
+  <linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+  <clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[2]d" height="20" fill="#555"/>
+    <rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text>
+    <text x="%[7]d" y="14">%[4]s</text>
+    <text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text>
+    <text x="%[8]d" y="14">%[5]s</text>
+  </g>
+</svg>
+`, total, labelWidth, messageWidth, label, message, color, labelWidth/2, labelWidth+messageWidth/2))
+}
+
+// ShieldsJSON renders the badge as a shields.io endpoint response, for
+// https://img.shields.io/endpoint?url=...
+func (b Badge) ShieldsJSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]interface{}{
+		"schemaVersion": 1,
+		"label":         b.Label,
+		"message":       b.Message,
+		"color":         b.Color,
+	}, "", "  ")
+}
@@ -0,0 +1,90 @@
+package badge
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestFromBenchmark(t *testing.T) {
+	summary := models.BenchmarkSummary{
+		OverallReqsPerSec: 1234,
+		OverallErrorRate:  0.5,
+		Results: []models.BenchmarkResult{
+			{P99Time: 40 * time.Millisecond},
+			{P99Time: 120 * time.Millisecond},
+		},
+	}
+
+	tests := []struct {
+		metric  string
+		limit   float64
+		message string
+		color   string
+	}{
+		{"p99", 0, "120ms", ColorBlue},
+		{"p99", 100, "120ms", ColorRed},
+		{"p99", 200, "120ms", ColorGreen},
+		{"rps", 0, "1234 req/s", ColorBlue},
+		{"errors", 1, "0.50%", ColorGreen},
+		{"errors", 0, "0.50%", ColorRed},
+	}
+
+	for _, tt := range tests {
+		b, err := FromBenchmark(summary, tt.metric, tt.limit)
+		if err != nil {
+			t.Fatalf("FromBenchmark(%s) failed: %v", tt.metric, err)
+		}
+		if b.Message != tt.message || b.Color != tt.color {
+			t.Errorf("FromBenchmark(%s, %v) = %q/%s, expected %q/%s", tt.metric, tt.limit, b.Message, b.Color, tt.message, tt.color)
+		}
+	}
+
+	if _, err := FromBenchmark(summary, "passed", 0); err == nil {
+		t.Error("Expected error for a test metric on benchmark results")
+	}
+}
+
+func TestFromTest(t *testing.T) {
+	summary := models.TestSummary{TotalTests: 10, Passed: 9, Failed: 1, Coverage: 80, MinCoverage: 100}
+
+	b, err := FromTest(summary, "passed", 0)
+	if err != nil {
+		t.Fatalf("FromTest failed: %v", err)
+	}
+	if b.Message != "9/10 passed" || b.Color != ColorRed {
+		t.Errorf("Unexpected badge %+v", b)
+	}
+
+	b, err = FromTest(summary, "coverage", 0)
+	if err != nil {
+		t.Fatalf("FromTest failed: %v", err)
+	}
+	if b.Message != "80%" || b.Color != ColorRed {
+		t.Errorf("Expected coverage below the profile minimum to be red, got %+v", b)
+	}
+}
+
+func TestRender(t *testing.T) {
+	b := Badge{Label: "p99", Message: "<42ms>", Color: ColorGreen}
+
+	svg := string(b.SVG())
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, "&lt;42ms&gt;") || !strings.Contains(svg, "#4c1") {
+		t.Errorf("Unexpected SVG: %s", svg)
+	}
+
+	data, err := b.ShieldsJSON()
+	if err != nil {
+		t.Fatalf("ShieldsJSON failed: %v", err)
+	}
+	var endpoint map[string]interface{}
+	if err := json.Unmarshal(data, &endpoint); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if endpoint["schemaVersion"] != float64(1) || endpoint["message"] != "<42ms>" || endpoint["color"] != ColorGreen {
+		t.Errorf("Unexpected shields.io endpoint: %s", data)
+	}
+}
@@ -0,0 +1,152 @@
+package badge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// BenchmarkMetrics lists the metrics available for benchmark summaries
+var BenchmarkMetrics = []string{"avg", "p50", "p90", "p99", "rps", "errors"}
+
+// TestMetrics lists the metrics available for test summaries
+var TestMetrics = []string{"passed", "pass-rate", "coverage"}
+
+// FromFile builds a badge from an exported JSON test or benchmark summary.
+// When limit is positive, the badge is red if the metric exceeds it (or, for
+// throughput, pass rate and coverage, falls below it) and green otherwise.
+func FromFile(path, metric string, limit float64) (Badge, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Badge{}, fmt.Errorf("failed to read results: %w", err)
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return Badge{}, fmt.Errorf("failed to read results: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return Badge{}, fmt.Errorf("failed to read results: %w", err)
+		}
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Badge{}, fmt.Errorf("results must be a JSON export (-o json): %w", err)
+	}
+
+	switch {
+	case probe["total_tests"] != nil:
+		var summary models.TestSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return Badge{}, fmt.Errorf("invalid test results: %w", err)
+		}
+		return FromTest(summary, metric, limit)
+	case probe["total_endpoints"] != nil:
+		var summary models.BenchmarkSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return Badge{}, fmt.Errorf("invalid benchmark results: %w", err)
+		}
+		return FromBenchmark(summary, metric, limit)
+	default:
+		return Badge{}, fmt.Errorf("%s is not a test or benchmark summary", path)
+	}
+}
+
+// FromBenchmark builds a badge for a benchmark metric. Percentiles are the
+// worst across endpoints.
+func FromBenchmark(summary models.BenchmarkSummary, metric string, limit float64) (Badge, error) {
+	var worst func(r models.BenchmarkResult) time.Duration
+	switch metric {
+	case "avg":
+		return latencyBadge(metric, summary.OverallAvgTime, limit), nil
+	case "p50":
+		worst = func(r models.BenchmarkResult) time.Duration { return r.P50Time }
+	case "p90":
+		worst = func(r models.BenchmarkResult) time.Duration { return r.P90Time }
+	case "p99":
+		worst = func(r models.BenchmarkResult) time.Duration { return r.P99Time }
+	case "rps":
+		b := Badge{Label: "throughput", Message: fmt.Sprintf("%.0f req/s", summary.OverallReqsPerSec), Color: ColorBlue}
+		if limit > 0 {
+			b.Color = thresholdColor(summary.OverallReqsPerSec >= limit)
+		}
+		return b, nil
+	case "errors":
+		b := Badge{Label: "errors", Message: fmt.Sprintf("%.2f%%", summary.OverallErrorRate)}
+		b.Color = thresholdColor(summary.OverallErrorRate <= limit)
+		return b, nil
+	default:
+		return Badge{}, fmt.Errorf("invalid benchmark metric '%s': must be one of %v", metric, BenchmarkMetrics)
+	}
+
+	var value time.Duration
+	for _, r := range summary.Results {
+		value = max(value, worst(r))
+	}
+	return latencyBadge(metric, value, limit), nil
+}
+
+// FromTest builds a badge for a test metric
+func FromTest(summary models.TestSummary, metric string, limit float64) (Badge, error) {
+	switch metric {
+	case "passed":
+		b := Badge{Label: "api tests", Message: fmt.Sprintf("%d/%d passed", summary.Passed, summary.TotalTests)}
+		switch {
+		case summary.Failed > 0:
+			b.Color = ColorRed
+		case summary.Flaky > 0:
+			b.Color = ColorYellow
+		default:
+			b.Color = ColorGreen
+		}
+		return b, nil
+	case "pass-rate":
+		rate := 0.0
+		if summary.TotalTests > 0 {
+			rate = float64(summary.Passed) / float64(summary.TotalTests) * 100
+		}
+		b := Badge{Label: "pass rate", Message: fmt.Sprintf("%.0f%%", rate)}
+		b.Color = thresholdColor(rate >= limit && (limit > 0 || summary.Failed == 0))
+		return b, nil
+	case "coverage":
+		if limit <= 0 {
+			limit = summary.MinCoverage
+		}
+		b := Badge{Label: "api coverage", Message: fmt.Sprintf("%.0f%%", summary.Coverage), Color: ColorBlue}
+		if limit > 0 {
+			b.Color = thresholdColor(summary.Coverage >= limit)
+		}
+		return b, nil
+	default:
+		return Badge{}, fmt.Errorf("invalid test metric '%s': must be one of %v", metric, TestMetrics)
+	}
+}
+
+// latencyBadge formats a latency metric, colored by an optional limit in ms
+func latencyBadge(label string, value time.Duration, maxMs float64) Badge {
+	ms := float64(value.Microseconds()) / 1000
+	b := Badge{Label: label, Message: fmt.Sprintf("%.0fms", ms), Color: ColorBlue}
+	if ms < 10 {
+		b.Message = fmt.Sprintf("%.1fms", ms)
+	}
+	if maxMs > 0 {
+		b.Color = thresholdColor(ms <= maxMs)
+	}
+	return b
+}
+
+// thresholdColor returns green for a passing metric and red otherwise
+func thresholdColor(ok bool) string {
+	if ok {
+		return ColorGreen
+	}
+	return ColorRed
+}
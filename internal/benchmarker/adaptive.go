@@ -0,0 +1,166 @@
+package benchmarker
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// Defaults of adaptive mode
+const (
+	DefaultMaxConcurrency    = 64
+	DefaultAdaptiveErrorRate = 1.0
+)
+
+// adaptiveMinWindow is the fewest requests adaptive mode judges a
+// concurrency by. Windows grow with the concurrency, so every worker
+// contributes a couple of requests.
+const adaptiveMinWindow = 20
+
+// concurrencyGate lets at most limit workers send requests at once. A nil
+// gate lets every worker through.
+type concurrencyGate struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	limit  int
+	active int
+}
+
+func newConcurrencyGate(limit int) *concurrencyGate {
+	g := &concurrencyGate{limit: limit}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+// acquire blocks until the worker may send a request. It returns false once
+// ctx is done; wake must be registered with context.AfterFunc for that.
+func (g *concurrencyGate) acquire(ctx context.Context) bool {
+	if g == nil {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for g.active >= g.limit {
+		if ctx.Err() != nil {
+			return false
+		}
+		g.cond.Wait()
+	}
+	g.active++
+	return true
+}
+
+// release frees the slot of a worker that finished its request
+func (g *concurrencyGate) release() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	g.active--
+	g.mu.Unlock()
+	g.cond.Broadcast()
+}
+
+// setLimit changes how many workers may send at once. Workers above a lowered
+// limit finish their request first.
+func (g *concurrencyGate) setLimit(limit int) {
+	g.mu.Lock()
+	g.limit = limit
+	g.mu.Unlock()
+	g.cond.Broadcast()
+}
+
+// wake lets waiting workers recheck their context
+func (g *concurrencyGate) wake() {
+	g.mu.Lock()
+	g.mu.Unlock()
+	g.cond.Broadcast()
+}
+
+// aimdController adjusts the concurrency of an endpoint by additive increase,
+// multiplicative decrease: after each window of requests it adds a worker if
+// the error rate and p99 stayed within their limits, and halves the workers
+// if they didn't. The concurrency converges on, and oscillates just below,
+// the highest load the endpoint sustains.
+type aimdController struct {
+	gate         *concurrencyGate
+	max          int
+	maxP99       time.Duration
+	maxErrorRate float64
+
+	mu        sync.Mutex
+	limit     int
+	durations []time.Duration // successful requests of the current window
+	failures  int             // failed requests of the current window
+	result    models.AdaptiveResult
+}
+
+// newAIMDController returns the controller of an adaptive run, or nil if
+// adaptive mode is off
+func newAIMDController(config Config) *aimdController {
+	if !config.Adaptive {
+		return nil
+	}
+	start := max(1, config.Concurrency)
+	return &aimdController{
+		gate:         newConcurrencyGate(start),
+		max:          max(start, config.MaxConcurrency),
+		maxP99:       config.AdaptiveP99,
+		maxErrorRate: config.AdaptiveErrorRate,
+		limit:        start,
+		result:       models.AdaptiveResult{Start: start},
+	}
+}
+
+// record adds a finished request to the current window and adjusts the
+// concurrency once the window is full. Transport errors, 429 and 5xx
+// responses count as failures: they are how an overloaded server sheds load.
+func (c *aimdController) record(res requestResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if res.Error != "" || res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+		c.failures++
+	} else {
+		c.durations = append(c.durations, res.Duration)
+	}
+	requests := len(c.durations) + c.failures
+	if requests < max(adaptiveMinWindow, 2*c.limit) {
+		return
+	}
+
+	step := models.AdaptiveStep{
+		Concurrency: c.limit,
+		Requests:    requests,
+		ErrorRate:   float64(c.failures) / float64(requests) * 100,
+	}
+	if len(c.durations) > 0 {
+		sort.Slice(c.durations, func(i, j int) bool { return c.durations[i] < c.durations[j] })
+		step.P99Time = percentile(c.durations, 99)
+	}
+	step.Healthy = step.ErrorRate <= c.maxErrorRate && (c.maxP99 <= 0 || step.P99Time <= c.maxP99)
+	c.result.Steps = append(c.result.Steps, step)
+
+	if step.Healthy {
+		c.result.Sustainable = max(c.result.Sustainable, c.limit)
+		c.limit = min(c.limit+1, c.max)
+	} else {
+		c.result.Backoffs++
+		c.limit = max(1, c.limit/2)
+	}
+	c.gate.setLimit(c.limit)
+	c.durations, c.failures = c.durations[:0], 0
+}
+
+// finish returns the trace of the concurrency search
+func (c *aimdController) finish() *models.AdaptiveResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := c.result
+	result.Final = c.limit
+	return &result
+}
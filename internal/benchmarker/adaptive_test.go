@@ -0,0 +1,102 @@
+package benchmarker
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestAIMDController(t *testing.T) {
+	c := newAIMDController(Config{Adaptive: true, Concurrency: 2, MaxConcurrency: 3, AdaptiveP99: 100 * time.Millisecond, AdaptiveErrorRate: 5})
+
+	window := func(failures int, latency time.Duration) {
+		for i := 0; i < adaptiveMinWindow; i++ {
+			res := requestResult{StatusCode: http.StatusOK, Duration: latency}
+			if i < failures {
+				res.StatusCode = http.StatusServiceUnavailable
+			}
+			c.record(res)
+		}
+	}
+
+	window(1, 10*time.Millisecond) // 5% errors is within the limit
+	window(0, 10*time.Millisecond) // capped at MaxConcurrency
+	window(0, 200*time.Millisecond)
+	window(2, 10*time.Millisecond)
+
+	result := c.finish()
+	var concurrency []int
+	for _, step := range result.Steps {
+		concurrency = append(concurrency, step.Concurrency)
+	}
+	if len(concurrency) != 4 || concurrency[0] != 2 || concurrency[1] != 3 || concurrency[2] != 3 || concurrency[3] != 1 {
+		t.Errorf("Expected concurrency 2, 3, 3 and 1 after halving on slow responses, got %v", concurrency)
+	}
+	if result.Start != 2 || result.Final != 1 || result.Sustainable != 3 || result.Backoffs != 2 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if step := result.Steps[3]; step.Healthy || step.ErrorRate != 10 {
+		t.Errorf("Expected 10%% errors to exceed the limit, got %+v", step)
+	}
+
+	if newAIMDController(DefaultConfig()) != nil {
+		t.Error("Expected no controller outside adaptive mode")
+	}
+}
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// The server handles 4 requests at a time and sheds the rest
+	const capacity = 4
+	var inFlight atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer inFlight.Add(-1)
+		if inFlight.Add(1) > capacity {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(2 * time.Millisecond)
+		w.Write([]byte(`[]`))
+	})
+
+	config := DefaultConfig()
+	config.Iterations = 400
+	config.WarmupRuns = 0
+	config.Handler = handler
+	config.Adaptive = true
+	config.MaxConcurrency = 16
+	b := NewBenchmarker(config)
+
+	ops, err := p.GetOperations("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := b.BenchmarkOperation(context.Background(), ops[0], p, nil, 0, 1)
+	if err != nil {
+		t.Fatalf("BenchmarkOperation: %v", err)
+	}
+
+	adaptive := result.Adaptive
+	if adaptive == nil {
+		t.Fatal("Expected the concurrency search in the result")
+	}
+	if adaptive.Sustainable != capacity || adaptive.Backoffs == 0 {
+		t.Errorf("Expected the search to settle on %d workers after backing off, got %+v", capacity, *adaptive)
+	}
+	for _, step := range adaptive.Steps {
+		if step.Concurrency <= capacity && !step.Healthy {
+			t.Errorf("Expected %d workers to stay within the server's capacity, got %+v", step.Concurrency, step)
+		}
+	}
+	if len(result.Workers.WorkerRequests) != config.MaxConcurrency {
+		t.Errorf("Expected %d workers, got %d", config.MaxConcurrency, len(result.Workers.WorkerRequests))
+	}
+}
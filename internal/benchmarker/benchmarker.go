@@ -2,13 +2,19 @@ package benchmarker
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/moamenhredeen/oas/internal/auth"
+	"github.com/moamenhredeen/oas/internal/mask"
 	"github.com/moamenhredeen/oas/internal/models"
 	"github.com/moamenhredeen/oas/internal/parser"
 	"github.com/moamenhredeen/oas/internal/tester"
@@ -31,6 +37,14 @@ const (
 	EventBenchmarkProgress
 	// EventBenchmarkCompleted indicates benchmark completed for an endpoint
 	EventBenchmarkCompleted
+	// EventGlobalWarmupStarting indicates the global warmup over all endpoints is starting
+	EventGlobalWarmupStarting
+	// EventGlobalWarmupCompleted indicates the global warmup completed
+	EventGlobalWarmupCompleted
+	// EventColdStarting indicates the cold phase over fresh connections is starting for an endpoint
+	EventColdStarting
+	// EventColdCompleted indicates the cold phase completed
+	EventColdCompleted
 )
 
 // BenchmarkEvent represents an event during benchmark execution
@@ -57,9 +71,50 @@ type Config struct {
 	Iterations       int           // Number of requests per endpoint
 	Concurrency      int           // Number of concurrent workers
 	WarmupRuns       int           // Number of warmup iterations (discarded)
+	WarmupDuration   time.Duration // Warm up for this long instead of WarmupRuns iterations
+	GlobalWarmup     bool          // Touch every endpoint once before any measurement
+	ReportWarmup     bool          // Report warmup samples separately instead of discarding them
 	RateLimit        float64       // Max requests per second (0 = unlimited)
+	Burst            int           // Requests the rate limiter lets through at once (0 = RateLimit)
+	PerWorkerRate    bool          // Give each worker an even share of RateLimit and Burst
+	Jitter           float64       // Delay rate-limited sends by up to this fraction (0-1) of the request interval
 	Timeout          time.Duration // Per-request timeout
 	DisableKeepAlive bool          // Disable HTTP connection reuse
+	MaxIdleConns     int           // Max idle connections across all hosts (0 = unlimited)
+	MaxConnsPerHost  int           // Max connections per host, including active ones (0 = unlimited)
+	IdleTimeout      time.Duration // How long idle connections are kept open
+	ColdHot          bool          // Also measure each endpoint over fresh connections before warming it up
+	Handler          http.Handler  // Serve requests in-process with this handler instead of over the network
+	TraceSlow        time.Duration // Trace measured requests slower than this to SlowTraces (0 = off)
+	SlowTraces       io.Writer     // Receives a JSON line with the timeline and headers of each slow request
+
+	// Adaptive mode starts at Concurrency and searches for the highest
+	// concurrency the endpoint sustains within AdaptiveP99 and AdaptiveErrorRate
+	Adaptive          bool
+	MaxConcurrency    int           // Upper bound of adaptive mode
+	AdaptiveP99       time.Duration // p99 a window of requests may reach (0 = no latency limit)
+	AdaptiveErrorRate float64       // Percentage of failed requests a window may reach
+
+	ParamValues map[string]interface{} // Fixed parameter values by name or JSON pointer
+	Scripts     *tester.Scripts        // Compiled request mutation scripts
+
+	// Probability (0-1) with which each optional query parameter is sent
+	OptionalParams float64
+
+	Authenticator auth.Authenticator    // Credentials applied to every request
+	DigestAuth    *auth.UserCredentials // Answer HTTP Digest challenges with these credentials
+	CookieJar     http.CookieJar        // Shared session cookies (see tester.Login)
+
+	CorrelationHeader string // Header carrying a unique id per request (e.g. X-Request-Id)
+	UserAgent         string // User-Agent sent with every request (default: tester.DefaultUserAgent)
+	HostHeader        string // Host header (and TLS server name) presented instead of the URL's host
+	Accept            string // Accept header for every request (default: the operation's response media types)
+	AddressFamily     string // Restrict connections to tester.AddressFamilyIPv4 or tester.AddressFamilyIPv6
+	DNSCache          bool   // Resolve hostnames once and reuse the addresses for every connection
+	Cleanup           bool   // Delete resources created by POST requests after each endpoint
+
+	Order string // Endpoint order: OrderSpec, OrderRandom or OrderSlowestFirst
+	Seed  int64  // Seed for OrderRandom
 }
 
 // DefaultConfig returns default benchmark configuration
@@ -71,6 +126,12 @@ func DefaultConfig() Config {
 		RateLimit:        0,
 		Timeout:          30 * time.Second,
 		DisableKeepAlive: false,
+		MaxIdleConns:     100,
+		MaxConnsPerHost:  0,
+		IdleTimeout:      90 * time.Second,
+
+		MaxConcurrency:    DefaultMaxConcurrency,
+		AdaptiveErrorRate: DefaultAdaptiveErrorRate,
 	}
 }
 
@@ -79,39 +140,84 @@ type Benchmarker struct {
 	config         Config
 	requestBuilder *tester.RequestBuilder
 	client         *http.Client
-	limiter        *rate.Limiter
+	coldClient     *http.Client    // dials a connection per request (see ColdHot)
+	limiters       []*rate.Limiter // one shared or one per worker, nil without a rate
+	dns            *dnsCache       // nil unless DNS caching is enabled
+
+	mu      sync.Mutex
+	created map[string][]string // ids of created resources by collection path (see Cleanup)
+
+	traceMu sync.Mutex // serializes writes to SlowTraces
 }
 
 // NewBenchmarker creates a new benchmarker instance
 func NewBenchmarker(config Config) *Benchmarker {
+	dialContext := tester.RestrictAddressFamily((&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext, config.AddressFamily)
+
+	var dns *dnsCache
+	if config.DNSCache {
+		dns = newDNSCache(config.AddressFamily)
+		dialContext = dns.dialContext(dialContext)
+	}
+
 	// Create HTTP transport with keepalive settings
 	transport := &http.Transport{
 		DisableKeepAlives:   config.DisableKeepAlive,
-		MaxIdleConns:        100,
+		MaxIdleConns:        config.MaxIdleConns,
 		MaxIdleConnsPerHost: config.Concurrency,
-		IdleConnTimeout:     90 * time.Second,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		MaxConnsPerHost:     config.MaxConnsPerHost,
+		IdleConnTimeout:     config.IdleTimeout,
+		DialContext:         dialContext,
+	}
+
+	if config.HostHeader != "" {
+		transport.TLSClientConfig = &tls.Config{ServerName: tester.ServerName(config.HostHeader)}
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if config.Handler != nil {
+		// There are no connections to measure cold
+		roundTripper = handlerTransport{handler: config.Handler}
+		config.ColdHot = false
+	}
+	if config.DigestAuth != nil {
+		roundTripper = auth.NewDigestTransport(*config.DigestAuth, roundTripper)
 	}
 
 	client := &http.Client{
 		Timeout:   config.Timeout,
-		Transport: transport,
+		Transport: roundTripper,
+		Jar:       config.CookieJar,
+	}
+
+	var coldClient *http.Client
+	if config.ColdHot {
+		coldClient = newColdClient(transport, config)
 	}
 
-	// Create rate limiter if configured
-	var limiter *rate.Limiter
-	if config.RateLimit > 0 {
-		limiter = rate.NewLimiter(rate.Limit(config.RateLimit), int(config.RateLimit))
+	requestBuilder := tester.NewRequestBuilder()
+	if len(config.ParamValues) > 0 {
+		requestBuilder.SetParamValues(config.ParamValues)
 	}
+	requestBuilder.SetScripts(config.Scripts)
+	requestBuilder.SetOptionalParams(config.OptionalParams)
+	requestBuilder.SetAuthenticator(config.Authenticator)
+	requestBuilder.SetCorrelationHeader(config.CorrelationHeader)
+	requestBuilder.SetUserAgent(config.UserAgent)
+	requestBuilder.SetHostHeader(config.HostHeader)
+	requestBuilder.SetAccept(config.Accept)
 
 	return &Benchmarker{
 		config:         config,
-		requestBuilder: tester.NewRequestBuilder(),
+		requestBuilder: requestBuilder,
 		client:         client,
-		limiter:        limiter,
+		coldClient:     coldClient,
+		limiters:       newLimiters(config),
+		dns:            dns,
+		created:        make(map[string][]string),
 	}
 }
 
@@ -120,6 +226,12 @@ type requestResult struct {
 	Duration   time.Duration
 	StatusCode int
 	Error      string
+	ErrorClass models.ErrorClass // cause of Error
+	RequestID  string            // correlation id, if configured
+	RemoteAddr net.Addr
+	ConnReused bool                // served over a pooled connection
+	Corrected  time.Duration       // latency from the intended send time (rate-limited runs only)
+	Slow       *models.SlowRequest // trace of a request slower than TraceSlow
 }
 
 // BenchmarkOperation benchmarks a single API operation
@@ -134,6 +246,7 @@ func (b *Benchmarker) BenchmarkOperation(
 		Path:        op.Path,
 		Method:      op.Method,
 		OperationID: op.OperationID,
+		Tags:        op.Tags,
 		Iterations:  b.config.Iterations,
 		Concurrency: b.config.Concurrency,
 		WarmupRuns:  b.config.WarmupRuns,
@@ -152,8 +265,16 @@ func (b *Benchmarker) BenchmarkOperation(
 		return result, fmt.Errorf("failed to build request: %w", err)
 	}
 
+	// Cold phase over fresh connections, before anything is warmed up
+	if b.coldClient != nil {
+		if err := b.runColdPhase(ctx, opDetails, op, onEvent, index, total, &result); err != nil {
+			return result, err
+		}
+	}
+
 	// Warmup phase
-	if b.config.WarmupRuns > 0 && onEvent != nil {
+	warmup := b.config.WarmupRuns > 0 || b.config.WarmupDuration > 0
+	if warmup && onEvent != nil {
 		onEvent(BenchmarkEvent{
 			Type:      EventWarmupStarting,
 			Operation: op,
@@ -163,15 +284,22 @@ func (b *Benchmarker) BenchmarkOperation(
 		})
 	}
 
-	// Run warmup (single-threaded, no stats collection)
-	for i := 0; i < b.config.WarmupRuns; i++ {
+	// Run warmup (single-threaded, samples kept only when reported)
+	var warmupResults []requestResult
+	warmupStart := time.Now()
+	warmupCount := 0
+	for i := 0; b.warmingUp(i, warmupStart); i++ {
 		select {
 		case <-ctx.Done():
 			return result, ctx.Err()
 		default:
 		}
 
-		b.executeRequest(ctx, opDetails, op.ServerURL)
+		r := b.executeRequest(ctx, opDetails, op.ServerURL)
+		warmupCount++
+		if b.config.ReportWarmup {
+			warmupResults = append(warmupResults, r)
+		}
 
 		if onEvent != nil && (i+1)%max(1, b.config.WarmupRuns/5) == 0 {
 			onEvent(BenchmarkEvent{
@@ -185,7 +313,12 @@ func (b *Benchmarker) BenchmarkOperation(
 		}
 	}
 
-	if b.config.WarmupRuns > 0 && onEvent != nil {
+	result.WarmupRuns = warmupCount
+	if b.config.ReportWarmup && len(warmupResults) > 0 {
+		result.Warmup = summarizeWarmup(warmupResults)
+	}
+
+	if warmup && onEvent != nil {
 		onEvent(BenchmarkEvent{
 			Type:      EventWarmupCompleted,
 			Operation: op,
@@ -206,13 +339,23 @@ func (b *Benchmarker) BenchmarkOperation(
 	}
 
 	// Execute benchmark with concurrency
+	aimd := newAIMDController(b.config)
 	startTime := time.Now()
-	results := b.runConcurrentBenchmark(ctx, opDetails, op.ServerURL, onEvent, op, index, total)
+	results, workers := b.runConcurrentBenchmark(ctx, b.client, opDetails, op.ServerURL, onEvent, op, index, total, aimd)
 	result.TotalDuration = time.Since(startTime)
+	result.Workers = workers
+	if aimd != nil {
+		result.Adaptive = aimd.finish()
+	}
 
 	// Process results
 	result = b.processResults(result, results)
 
+	// Remove resources created by this endpoint, outside the measured phase
+	if b.config.Cleanup {
+		b.cleanup(ctx, op, p, &result)
+	}
+
 	if onEvent != nil {
 		onEvent(BenchmarkEvent{
 			Type:      EventBenchmarkCompleted,
@@ -226,17 +369,21 @@ func (b *Benchmarker) BenchmarkOperation(
 	return result, nil
 }
 
-// runConcurrentBenchmark executes the benchmark with worker pool
+// runConcurrentBenchmark executes the benchmark with worker pool. With an
+// AIMD controller, up to its maximum of workers run and the controller
+// decides how many of them send at once.
 func (b *Benchmarker) runConcurrentBenchmark(
 	ctx context.Context,
+	client *http.Client,
 	opDetails *parser.OperationDetails,
 	serverURL string,
 	onEvent OnBenchmarkEvent,
 	op models.Operation,
 	index, total int,
-) []requestResult {
+	aimd *aimdController,
+) ([]requestResult, *models.WorkerStats) {
 	results := make([]requestResult, b.config.Iterations)
-	jobs := make(chan int, b.config.Iterations)
+	jobs := make(chan job, b.config.Iterations)
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -244,28 +391,63 @@ func (b *Benchmarker) runConcurrentBenchmark(
 	var totalDuration time.Duration
 	var errorCount int
 
+	workers := b.config.Concurrency
+	var gate *concurrencyGate
+	if aimd != nil {
+		workers = aimd.max
+		gate = aimd.gate
+		stop := context.AfterFunc(ctx, gate.wake)
+		defer stop()
+	}
+
+	// Saturation diagnostics
+	workerRequests := make([]int, workers)
+	var queueWait, limiterWait, busy time.Duration
+
 	// Progress reporting interval
 	progressInterval := max(1, b.config.Iterations/20) // ~5% intervals
+	phaseStart := time.Now()
 
 	// Start workers
-	for w := 0; w < b.config.Concurrency; w++ {
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
-		go func() {
+		go func(worker int) {
 			defer wg.Done()
-			for i := range jobs {
-				select {
-				case <-ctx.Done():
+			for {
+				// Wait for a free slot before taking a job (adaptive mode)
+				if !gate.acquire(ctx) {
 					return
-				default:
+				}
+				j, ok := <-jobs
+				if !ok || ctx.Err() != nil {
+					gate.release()
+					return
+				}
+				// A request taken up after it was due waited for a free
+				// worker. Without a rate every request is due at once, so
+				// there is no schedule to fall behind.
+				var waited time.Duration
+				if !j.due.IsZero() {
+					waited = max(0, time.Since(j.due))
 				}
 
 				// Apply rate limiting
-				if b.limiter != nil {
-					b.limiter.Wait(ctx)
+				limitStart := time.Now()
+				jitter := b.waitTurn(ctx, worker)
+				limited := time.Since(limitStart)
+
+				res := b.sendRequest(ctx, client, opDetails, serverURL)
+				if b.config.RateLimit > 0 {
+					// Measure from when the schedule intended to send the
+					// request, so stalls delaying later sends are not hidden.
+					// Jitter moves the intended time with it.
+					res.Corrected = max(res.Duration, time.Since(j.due.Add(jitter)))
+				}
+				results[j.index] = res
+				gate.release()
+				if aimd != nil {
+					aimd.record(res)
 				}
-
-				res := b.executeRequest(ctx, opDetails, serverURL)
-				results[i] = res
 
 				// Update progress
 				mu.Lock()
@@ -274,6 +456,10 @@ func (b *Benchmarker) runConcurrentBenchmark(
 				if res.Error != "" {
 					errorCount++
 				}
+				workerRequests[worker]++
+				queueWait += waited
+				limiterWait += limited
+				busy += limited + res.Duration
 				currentCompleted := completed
 				currentTotalDuration := totalDuration
 				currentErrorCount := errorCount
@@ -282,10 +468,10 @@ func (b *Benchmarker) runConcurrentBenchmark(
 				// Report progress periodically
 				if onEvent != nil && currentCompleted%progressInterval == 0 {
 					avgDuration := currentTotalDuration / time.Duration(currentCompleted)
-					elapsed := time.Since(time.Now().Add(-currentTotalDuration))
-					reqsPerSec := float64(currentCompleted) / elapsed.Seconds()
-					if elapsed.Seconds() == 0 {
-						reqsPerSec = 0
+					elapsed := time.Since(phaseStart)
+					var reqsPerSec float64
+					if elapsed > 0 {
+						reqsPerSec = float64(currentCompleted) / elapsed.Seconds()
 					}
 
 					onEvent(BenchmarkEvent{
@@ -301,17 +487,36 @@ func (b *Benchmarker) runConcurrentBenchmark(
 					})
 				}
 			}
-		}()
+		}(w)
 	}
 
 	// Send jobs
 	for i := 0; i < b.config.Iterations; i++ {
-		jobs <- i
+		j := job{index: i}
+		if b.config.RateLimit > 0 {
+			j.due = phaseStart.Add(time.Duration(float64(i) / b.config.RateLimit * float64(time.Second)))
+		}
+		jobs <- j
 	}
 	close(jobs)
 
 	wg.Wait()
-	return results
+
+	stats := &models.WorkerStats{WorkerRequests: workerRequests}
+	if completed > 0 {
+		stats.AvgQueueWait = queueWait / time.Duration(completed)
+		stats.AvgLimiterWait = limiterWait / time.Duration(completed)
+	}
+	if busy > 0 {
+		stats.LimiterWaitPct = float64(limiterWait) / float64(busy) * 100
+	}
+	return results, stats
+}
+
+// job is a benchmark iteration waiting for a worker
+type job struct {
+	index int
+	due   time.Time // when the rate schedules the request, zero without a rate
 }
 
 // executeRequest executes a single HTTP request and returns timing
@@ -319,31 +524,137 @@ func (b *Benchmarker) executeRequest(
 	ctx context.Context,
 	opDetails *parser.OperationDetails,
 	serverURL string,
+) requestResult {
+	return b.sendRequest(ctx, b.client, opDetails, serverURL)
+}
+
+// sendRequest executes a single HTTP request with client and returns timing
+func (b *Benchmarker) sendRequest(
+	ctx context.Context,
+	client *http.Client,
+	opDetails *parser.OperationDetails,
+	serverURL string,
 ) requestResult {
 	result := requestResult{}
 
 	req, err := b.requestBuilder.BuildRequest(opDetails, serverURL)
 	if err != nil {
-		result.Error = fmt.Sprintf("build request failed: %v", err)
+		result.Error = mask.String(fmt.Sprintf("build request failed: %v", err))
+		result.ErrorClass = models.ErrorOther
 		return result
 	}
 
+	var trace *requestTrace
+	if b.config.TraceSlow > 0 && b.config.SlowTraces != nil {
+		trace = &requestTrace{}
+		ctx = httptrace.WithClientTrace(ctx, trace.clientTrace())
+	}
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			result.RemoteAddr = info.Conn.RemoteAddr()
+			result.ConnReused = info.Reused
+		},
+	})
 	req = req.WithContext(ctx)
+	if b.config.CorrelationHeader != "" {
+		result.RequestID = req.Header.Get(b.config.CorrelationHeader)
+	}
 
 	startTime := time.Now()
-	resp, err := b.client.Do(req)
+	if trace != nil {
+		trace.start = startTime
+	}
+	resp, err := client.Do(req)
 	result.Duration = time.Since(startTime)
 
 	if err != nil {
-		result.Error = fmt.Sprintf("request failed: %v", err)
+		result.Error = mask.String(fmt.Sprintf("request failed: %v", err))
+		result.ErrorClass = tester.ClassifyError(err)
+		resp = nil
+	} else {
+		defer resp.Body.Close()
+		result.StatusCode = resp.StatusCode
+	}
+	if trace != nil && result.Duration >= b.config.TraceSlow {
+		slow := trace.slowRequest(req, resp, opDetails.Path, result)
+		result.Slow = &slow
+	}
+	if resp == nil {
 		return result
 	}
-	defer resp.Body.Close()
 
-	result.StatusCode = resp.StatusCode
+	if b.config.Cleanup && opDetails.Method == http.MethodPost && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if body, err := io.ReadAll(resp.Body); err == nil {
+			b.recordCreated(opDetails.Path, body)
+		}
+	}
+	// The connection only returns to the pool once the body is read to
+	// the end; a body beyond the limit isn't worth keeping it for
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
 	return result
 }
 
+// maxDrainBytes is the most of a response body read to reuse its connection
+const maxDrainBytes = 16 << 20
+
+// warmingUp reports whether the warmup phase should run iteration i, bounded
+// by WarmupDuration when set and by WarmupRuns otherwise
+func (b *Benchmarker) warmingUp(i int, start time.Time) bool {
+	if b.config.WarmupDuration > 0 {
+		return time.Since(start) < b.config.WarmupDuration
+	}
+	return i < b.config.WarmupRuns
+}
+
+// summarizeWarmup calculates statistics for warmup samples
+func summarizeWarmup(rawResults []requestResult) *models.WarmupResult {
+	warmup := &models.WarmupResult{Requests: len(rawResults)}
+
+	var durations []time.Duration
+	var totalDuration time.Duration
+	for _, r := range rawResults {
+		if r.Error != "" {
+			warmup.ErrorCount++
+			continue
+		}
+		durations = append(durations, r.Duration)
+		totalDuration += r.Duration
+	}
+
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		warmup.MinTime = durations[0]
+		warmup.MaxTime = durations[len(durations)-1]
+		warmup.AvgTime = totalDuration / time.Duration(len(durations))
+		warmup.P50Time = percentile(durations, 50)
+		warmup.P99Time = percentile(durations, 99)
+	}
+	return warmup
+}
+
+// globalWarmup sends one request to every endpoint so connections, caches and
+// lazily initialized server code are warm before any endpoint is measured
+func (b *Benchmarker) globalWarmup(ctx context.Context, operations []models.Operation, p *parser.Parser, onEvent OnBenchmarkEvent) {
+	if onEvent != nil {
+		onEvent(BenchmarkEvent{Type: EventGlobalWarmupStarting, Total: len(operations)})
+	}
+
+	for _, op := range operations {
+		if ctx.Err() != nil {
+			return
+		}
+		opDetails, err := p.GetOperationDetails(op.Path, op.Method)
+		if err != nil {
+			continue // reported when the operation is benchmarked
+		}
+		b.executeRequest(ctx, opDetails, op.ServerURL)
+	}
+
+	if onEvent != nil {
+		onEvent(BenchmarkEvent{Type: EventGlobalWarmupCompleted, Total: len(operations)})
+	}
+}
+
 // processResults calculates statistics from raw results
 func (b *Benchmarker) processResults(result models.BenchmarkResult, rawResults []requestResult) models.BenchmarkResult {
 	if len(rawResults) == 0 {
@@ -353,22 +664,82 @@ func (b *Benchmarker) processResults(result models.BenchmarkResult, rawResults [
 	var durations []time.Duration
 	var totalDuration time.Duration
 	errorSet := make(map[string]bool)
+	families := make(map[string]bool)
+	perIP := make(map[string][]time.Duration)
 
 	for _, r := range rawResults {
 		if r.Error != "" {
 			result.ErrorCount++
+			result.Errors.Add(r.ErrorClass, 1)
 			if len(result.SampleErrors) < 5 && !errorSet[r.Error] {
-				result.SampleErrors = append(result.SampleErrors, r.Error)
+				sample := r.Error
+				if r.RequestID != "" {
+					sample += fmt.Sprintf(" (%s: %s)", b.config.CorrelationHeader, r.RequestID)
+				}
+				result.SampleErrors = append(result.SampleErrors, sample)
 				errorSet[r.Error] = true
 			}
 		} else {
 			result.SuccessCount++
 			durations = append(durations, r.Duration)
 			totalDuration += r.Duration
+			if addr, ok := r.RemoteAddr.(*net.TCPAddr); ok {
+				perIP[addr.IP.String()] = append(perIP[addr.IP.String()], r.Duration)
+			}
+			if r.Duration > result.MaxTime {
+				result.MaxTime = r.Duration
+				result.SlowestRequestID = r.RequestID
+			}
 		}
 
 		if r.StatusCode > 0 {
 			result.StatusCodes[r.StatusCode]++
+			if class := models.StatusErrorClass(r.StatusCode); class != "" {
+				result.Errors.Add(class, 1)
+			}
+		}
+		// Only measured requests are traced, not warmup or cold ones
+		if r.Slow != nil {
+			b.writeSlowRequest(*r.Slow)
+			result.SlowRequests++
+		}
+		if r.RemoteAddr != nil {
+			families[tester.AddressFamilyOf(r.RemoteAddr)] = true
+			if r.ConnReused {
+				result.ReusedConns++
+			} else {
+				result.NewConns++
+			}
+		}
+	}
+
+	// Break latency down by address when DNS returned several records
+	if len(perIP) > 1 {
+		for ip, ipDurations := range perIP {
+			sort.Slice(ipDurations, func(i, j int) bool { return ipDurations[i] < ipDurations[j] })
+			var sum time.Duration
+			for _, d := range ipDurations {
+				sum += d
+			}
+			result.PerIP = append(result.PerIP, models.IPLatency{
+				IP:       ip,
+				Requests: len(ipDurations),
+				AvgTime:  sum / time.Duration(len(ipDurations)),
+				P50Time:  percentile(ipDurations, 50),
+				P99Time:  percentile(ipDurations, 99),
+			})
+		}
+		sort.Slice(result.PerIP, func(i, j int) bool { return result.PerIP[i].IP < result.PerIP[j].IP })
+	}
+
+	// Report the address families connections used (both on dual-stack
+	// hosts unless a family was forced)
+	for _, family := range []string{tester.AddressFamilyIPv4, tester.AddressFamilyIPv6} {
+		if families[family] {
+			if result.AddressFamily != "" {
+				result.AddressFamily += ","
+			}
+			result.AddressFamily += family
 		}
 	}
 
@@ -384,6 +755,12 @@ func (b *Benchmarker) processResults(result models.BenchmarkResult, rawResults [
 		result.P50Time = percentile(durations, 50)
 		result.P90Time = percentile(durations, 90)
 		result.P99Time = percentile(durations, 99)
+		result.Histogram = histogram(durations)
+	}
+
+	// Latency corrected for coordinated omission
+	if b.config.RateLimit > 0 {
+		result.Corrected = correctedLatency(rawResults)
 	}
 
 	// Calculate throughput
@@ -399,6 +776,31 @@ func (b *Benchmarker) processResults(result models.BenchmarkResult, rawResults [
 	return result
 }
 
+// correctedLatency calculates latency statistics measured from each request's
+// intended send time
+func correctedLatency(rawResults []requestResult) *models.CorrectedLatency {
+	var durations []time.Duration
+	var total time.Duration
+	for _, r := range rawResults {
+		if r.Error == "" && r.Corrected > 0 {
+			durations = append(durations, r.Corrected)
+			total += r.Corrected
+		}
+	}
+	if len(durations) == 0 {
+		return nil
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return &models.CorrectedLatency{
+		AvgTime: total / time.Duration(len(durations)),
+		MaxTime: durations[len(durations)-1],
+		P50Time: percentile(durations, 50),
+		P90Time: percentile(durations, 90),
+		P99Time: percentile(durations, 99),
+	}
+}
+
 // percentile calculates the p-th percentile from sorted durations
 func percentile(sorted []time.Duration, p int) time.Duration {
 	if len(sorted) == 0 {
@@ -436,26 +838,45 @@ func (b *Benchmarker) BenchmarkOperations(
 		Concurrency: b.config.Concurrency,
 		WarmupRuns:  b.config.WarmupRuns,
 		Results:     make([]models.BenchmarkResult, 0, len(operations)),
+		Order:       b.config.Order,
+	}
+	if b.config.Order == OrderRandom {
+		summary.Seed = b.config.Seed
+	}
+
+	operations = b.orderOperations(ctx, operations, p)
+
+	// Global warmup is not part of the measured run
+	if b.config.GlobalWarmup {
+		b.globalWarmup(ctx, operations, p, onEvent)
 	}
 
 	startTime := time.Now()
+	monitor := startResourceMonitor()
 
 	for i, op := range operations {
-		select {
-		case <-ctx.Done():
+		// Stop once interrupted or past the deadline
+		if err := ctx.Err(); err != nil {
+			reason := models.SkipStopped
+			if errors.Is(err, context.DeadlineExceeded) {
+				reason = models.SkipDeadline
+			}
+			summary.SkippedOperations = models.SkipOperations(operations[i:], reason)
 			break
-		default:
 		}
 
 		result, err := b.BenchmarkOperation(ctx, op, p, onEvent, i, len(operations))
 		if err != nil {
-			result.SampleErrors = append(result.SampleErrors, err.Error())
+			result.SampleErrors = append(result.SampleErrors, mask.String(err.Error()))
 			result.ErrorCount = result.Iterations
 			result.ErrorRate = 100
+			result.Errors.Add(models.ErrorOther, result.Iterations)
 		}
 		summary.AddResult(result)
 	}
 
+	summary.DeadlineExceeded = errors.Is(ctx.Err(), context.DeadlineExceeded)
+	summary.Client = monitor.finish()
 	summary.Finalize(time.Since(startTime))
 	return summary
 }
@@ -0,0 +1,99 @@
+package benchmarker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// largeBodyServer answers every request with a JSON body of about 2 MB, more
+// than net/http reads of an unread body when it is closed. It counts the
+// connections clients open.
+func largeBodyServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	body := "[" + strings.Repeat(`{"id": 1, "name": "Fluffy", "tag": "cat"},`, 50000) + `{"id": 2, "name": "Rex"}]`
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, &conns
+}
+
+// listPetsOperation returns GET /pets of the pet store spec on serverURL
+func listPetsOperation(t *testing.T, serverURL string) (models.Operation, *parser.Parser) {
+	t.Helper()
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	operations, err := p.GetOperations(serverURL)
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+	for _, op := range operations {
+		if op.Method == http.MethodGet && op.Path == "/pets" {
+			return op, p
+		}
+	}
+	t.Fatal("GET /pets not found")
+	return models.Operation{}, nil
+}
+
+func TestConnectionReuse(t *testing.T) {
+	server, _ := largeBodyServer(t)
+	listPets, p := listPetsOperation(t, server.URL)
+
+	config := DefaultConfig()
+	config.Iterations = 50
+	config.Concurrency = 2
+	config.WarmupRuns = 0
+	result, err := NewBenchmarker(config).BenchmarkOperation(context.Background(), listPets, p, nil, 0, 1)
+	if err != nil {
+		t.Fatalf("BenchmarkOperation: %v", err)
+	}
+
+	// Each worker dials once and reuses its connection afterwards
+	if result.NewConns > config.Concurrency || result.ReusedConns < config.Iterations-config.Concurrency {
+		t.Errorf("Expected pooled connections, got %d new and %d reused", result.NewConns, result.ReusedConns)
+	}
+}
+
+func TestColdHotConnections(t *testing.T) {
+	server, conns := largeBodyServer(t)
+	listPets, p := listPetsOperation(t, server.URL)
+
+	config := DefaultConfig()
+	config.Iterations = 20
+	config.Concurrency = 2
+	config.WarmupRuns = 0
+	config.ColdHot = true
+	result, err := NewBenchmarker(config).BenchmarkOperation(context.Background(), listPets, p, nil, 0, 1)
+	if err != nil {
+		t.Fatalf("BenchmarkOperation: %v", err)
+	}
+	if result.Cold == nil || result.Cold.Requests != config.Iterations || result.Cold.ErrorCount != 0 {
+		t.Fatalf("Expected %d cold requests, got %+v", config.Iterations, result.Cold)
+	}
+
+	// Cold requests dial a connection each, hot ones reuse the pool
+	if result.NewConns > config.Concurrency {
+		t.Errorf("Expected hot requests to reuse connections, got %d new and %d reused", result.NewConns, result.ReusedConns)
+	}
+	if n := int(conns.Load()); n > config.Iterations+config.Concurrency {
+		t.Errorf("Expected at most %d connections, got %d", config.Iterations+config.Concurrency, n)
+	}
+}
@@ -0,0 +1,121 @@
+package benchmarker
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// Budget is a latency limit for the endpoints of an OpenAPI tag, e.g.
+// "payments: p99<300ms"
+type Budget struct {
+	Tag       string
+	Metric    string        // avg, p50, p90, p99 or max
+	Inclusive bool          // <= rather than <
+	Limit     time.Duration // latency the metric must stay below
+	Expr      string        // the budget as written, e.g. "p99<300ms"
+}
+
+// budgetPattern matches budget expressions such as "p99<300ms" or "avg <= 1s"
+var budgetPattern = regexp.MustCompile(`^\s*(avg|p50|p90|p99|max)\s*(<=|<)\s*(\S+)\s*$`)
+
+// ParseBudget parses a budget expression for a tag
+func ParseBudget(tag, expr string) (Budget, error) {
+	m := budgetPattern.FindStringSubmatch(strings.ToLower(expr))
+	if m == nil {
+		return Budget{}, fmt.Errorf("invalid budget '%s' for tag %s: expected e.g. p99<300ms (metrics: avg, p50, p90, p99, max)", expr, tag)
+	}
+	limit, err := time.ParseDuration(m[3])
+	if err != nil || limit <= 0 {
+		return Budget{}, fmt.Errorf("invalid budget '%s' for tag %s: '%s' is not a positive duration", expr, tag, m[3])
+	}
+	return Budget{
+		Tag:       tag,
+		Metric:    m[1],
+		Inclusive: m[2] == "<=",
+		Limit:     limit,
+		Expr:      m[1] + m[2] + m[3],
+	}, nil
+}
+
+// ParseTagBudget parses a budget given as "tag:expr", e.g. "payments:p99<300ms"
+func ParseTagBudget(s string) (Budget, error) {
+	tag, expr, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(tag) == "" {
+		return Budget{}, fmt.Errorf("invalid budget '%s': expected tag:expression, e.g. payments:p99<300ms", s)
+	}
+	return ParseBudget(strings.TrimSpace(tag), expr)
+}
+
+// metric returns the latency statistic a budget limits
+func (b Budget) metric(r models.BenchmarkResult) time.Duration {
+	switch b.Metric {
+	case "avg":
+		return r.AvgTime
+	case "p50":
+		return r.P50Time
+	case "p90":
+		return r.P90Time
+	case "max":
+		return r.MaxTime
+	}
+	return r.P99Time
+}
+
+// within reports whether a latency stays within the budget
+func (b Budget) within(d time.Duration) bool {
+	if b.Inclusive {
+		return d <= b.Limit
+	}
+	return d < b.Limit
+}
+
+// EvaluateBudgets checks every endpoint carrying a budget's tag against it.
+// A tag is as fast as its slowest endpoint, so the worst endpoint is reported
+// along with how much of the budget it used. Tags match case-insensitively,
+// since configuration keys are lowercased when loaded. Endpoints without a
+// successful request have no latency and are left out.
+func EvaluateBudgets(results []models.BenchmarkResult, budgets []Budget) []models.BudgetResult {
+	evaluated := make([]models.BudgetResult, 0, len(budgets))
+	for _, budget := range budgets {
+		br := models.BudgetResult{
+			Tag:    budget.Tag,
+			Budget: budget.Expr,
+			Metric: budget.Metric,
+			Limit:  budget.Limit,
+			Passed: true,
+		}
+		for _, r := range results {
+			if r.SuccessCount == 0 || !hasTag(r.Tags, budget.Tag) {
+				continue
+			}
+			br.Endpoints++
+			if d := budget.metric(r); br.Endpoints == 1 || d > br.Actual {
+				br.Actual = d
+				br.Endpoint = r.Method + " " + r.Path
+			}
+		}
+		if br.Endpoints > 0 {
+			br.Utilization = float64(br.Actual) / float64(budget.Limit) * 100
+			br.Passed = budget.within(br.Actual)
+		}
+		evaluated = append(evaluated, br)
+	}
+
+	sort.SliceStable(evaluated, func(i, j int) bool { return strings.ToLower(evaluated[i].Tag) < strings.ToLower(evaluated[j].Tag) })
+	return evaluated
+}
+
+// hasTag reports whether tags contain tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
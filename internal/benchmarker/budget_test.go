@@ -0,0 +1,57 @@
+package benchmarker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestParseBudget(t *testing.T) {
+	budget, err := ParseTagBudget("payments: P99 <= 300ms")
+	if err != nil {
+		t.Fatalf("Expected valid budget, got %v", err)
+	}
+	if budget.Tag != "payments" || budget.Metric != "p99" || !budget.Inclusive || budget.Limit != 300*time.Millisecond {
+		t.Errorf("Unexpected budget %+v", budget)
+	}
+	if budget.Expr != "p99<=300ms" {
+		t.Errorf("Expected expression p99<=300ms, got %s", budget.Expr)
+	}
+
+	for _, s := range []string{"p99<300ms", "payments:p95<300ms", "payments:p99>300ms", "payments:p99<fast", "payments:avg<0s"} {
+		if _, err := ParseTagBudget(s); err == nil {
+			t.Errorf("Expected budget %q to be rejected", s)
+		}
+	}
+}
+
+func TestEvaluateBudgets(t *testing.T) {
+	results := []models.BenchmarkResult{
+		{Method: "GET", Path: "/payments", Tags: []string{"Payments"}, SuccessCount: 10, P99Time: 120 * time.Millisecond},
+		{Method: "POST", Path: "/payments", Tags: []string{"Payments"}, SuccessCount: 10, P99Time: 360 * time.Millisecond},
+		{Method: "GET", Path: "/refunds", Tags: []string{"payments"}, SuccessCount: 0},
+		{Method: "GET", Path: "/users", Tags: []string{"users"}, SuccessCount: 10, P99Time: 80 * time.Millisecond},
+	}
+	budgets := []Budget{
+		{Tag: "payments", Metric: "p99", Limit: 300 * time.Millisecond, Expr: "p99<300ms"},
+		{Tag: "users", Metric: "p99", Limit: 100 * time.Millisecond, Expr: "p99<100ms"},
+		{Tag: "admin", Metric: "p99", Limit: time.Second, Expr: "p99<1s"},
+	}
+
+	evaluated := EvaluateBudgets(results, budgets)
+	if len(evaluated) != 3 || evaluated[0].Tag != "admin" || evaluated[1].Tag != "payments" {
+		t.Fatalf("Expected budgets sorted by tag, got %+v", evaluated)
+	}
+
+	admin, payments, users := evaluated[0], evaluated[1], evaluated[2]
+	if !admin.Passed || admin.Endpoints != 0 {
+		t.Errorf("Expected a tag without endpoints to pass, got %+v", admin)
+	}
+	if payments.Passed || payments.Endpoints != 2 || payments.Endpoint != "POST /payments" || payments.Utilization != 120 {
+		t.Errorf("Expected payments to exceed its budget at POST /payments (120%%), got %+v", payments)
+	}
+	if !users.Passed || users.Utilization != 80 {
+		t.Errorf("Expected users to use 80%% of its budget, got %+v", users)
+	}
+}
@@ -0,0 +1,79 @@
+package benchmarker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// IsSafeMethod reports whether an HTTP method is read-only, so benchmarking it
+// does not create or destroy data on the server
+func IsSafeMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// recordCreated remembers the id of a resource created by a POST so it can be
+// deleted after the endpoint has been benchmarked
+func (b *Benchmarker) recordCreated(path string, body []byte) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return
+	}
+	id, ok := obj["id"]
+	if !ok || id == nil {
+		return
+	}
+
+	b.mu.Lock()
+	b.created[path] = append(b.created[path], fmt.Sprint(id))
+	b.mu.Unlock()
+}
+
+// cleanup deletes the resources created while benchmarking an operation
+func (b *Benchmarker) cleanup(ctx context.Context, op models.Operation, p *parser.Parser, result *models.BenchmarkResult) {
+	b.mu.Lock()
+	ids := b.created[op.Path]
+	delete(b.created, op.Path)
+	b.mu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	details, paramName, ok := p.ItemOperation(op.Path, http.MethodDelete)
+	if !ok {
+		result.CleanupFailed += len(ids)
+		return
+	}
+
+	for _, id := range ids {
+		req, err := b.requestBuilder.BuildRequestWithParams(details, op.ServerURL, map[string]string{paramName: id})
+		if err != nil {
+			result.CleanupFailed++
+			continue
+		}
+		// Clean up even when the run was interrupted
+		resp, err := b.client.Do(req.WithContext(context.WithoutCancel(ctx)))
+		if err != nil {
+			result.CleanupFailed++
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 || resp.StatusCode == http.StatusNotFound {
+			result.CleanedUp++
+		} else {
+			result.CleanupFailed++
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package benchmarker
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/auth"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// newColdClient returns a client that dials (and handshakes) a fresh
+// connection for every request, so its latency includes connection setup
+func newColdClient(transport *http.Transport, config Config) *http.Client {
+	cold := transport.Clone()
+	cold.DisableKeepAlives = true
+
+	var roundTripper http.RoundTripper = cold
+	if config.DigestAuth != nil {
+		roundTripper = auth.NewDigestTransport(*config.DigestAuth, cold)
+	}
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: roundTripper,
+		Jar:       config.CookieJar,
+	}
+}
+
+// runColdPhase sends the endpoint's iterations over fresh connections and
+// records their latency in result.Cold, to be compared with the hot
+// (pooled and warmed up) measurement that follows
+func (b *Benchmarker) runColdPhase(
+	ctx context.Context,
+	opDetails *parser.OperationDetails,
+	op models.Operation,
+	onEvent OnBenchmarkEvent,
+	index, total int,
+	result *models.BenchmarkResult,
+) error {
+	if onEvent != nil {
+		onEvent(BenchmarkEvent{
+			Type:      EventColdStarting,
+			Operation: op,
+			Index:     index,
+			Total:     total,
+			MaxIter:   b.config.Iterations,
+		})
+	}
+
+	results, _ := b.runConcurrentBenchmark(ctx, b.coldClient, opDetails, op.ServerURL, nil, op, index, total, nil)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	result.Cold = summarizeCold(results)
+
+	if onEvent != nil {
+		onEvent(BenchmarkEvent{
+			Type:      EventColdCompleted,
+			Operation: op,
+			Index:     index,
+			Total:     total,
+		})
+	}
+	return nil
+}
+
+// summarizeCold calculates statistics for requests sent over fresh connections
+func summarizeCold(rawResults []requestResult) *models.ColdResult {
+	cold := &models.ColdResult{Requests: len(rawResults)}
+
+	var durations []time.Duration
+	var totalDuration time.Duration
+	for _, r := range rawResults {
+		if r.Error != "" {
+			cold.ErrorCount++
+			continue
+		}
+		durations = append(durations, r.Duration)
+		totalDuration += r.Duration
+	}
+
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		cold.MinTime = durations[0]
+		cold.MaxTime = durations[len(durations)-1]
+		cold.AvgTime = totalDuration / time.Duration(len(durations))
+		cold.P50Time = percentile(durations, 50)
+		cold.P90Time = percentile(durations, 90)
+		cold.P99Time = percentile(durations, 99)
+	}
+	return cold
+}
@@ -0,0 +1,120 @@
+package benchmarker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+// dnsCache resolves hostnames once and serves later dials from the cache, so
+// DNS lookups don't add variance to measured latency. When a host resolves to
+// several addresses, dials rotate through them.
+type dnsCache struct {
+	network string // ip, ip4 or ip6
+
+	mu    sync.Mutex
+	addrs map[string][]string
+	next  map[string]int
+}
+
+// newDNSCache creates a cache resolving addresses of the given family
+func newDNSCache(family string) *dnsCache {
+	network := "ip"
+	switch family {
+	case tester.AddressFamilyIPv4:
+		network = "ip4"
+	case tester.AddressFamilyIPv6:
+		network = "ip6"
+	}
+	return &dnsCache{
+		network: network,
+		addrs:   make(map[string][]string),
+		next:    make(map[string]int),
+	}
+}
+
+// resolve looks up a host unless it is already cached or an IP literal
+func (c *dnsCache) resolve(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	c.mu.Lock()
+	addrs, ok := c.addrs[host]
+	c.mu.Unlock()
+	if ok {
+		return addrs, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, c.network, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+
+	c.mu.Lock()
+	c.addrs[host] = addrs
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+// pick returns the next cached address for a host
+func (c *dnsCache) pick(host string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	addrs := c.addrs[host]
+	if len(addrs) == 0 {
+		return "", false
+	}
+	addr := addrs[c.next[host]%len(addrs)]
+	c.next[host]++
+	return addr, true
+}
+
+// dialContext wraps a dial function to connect to cached addresses
+func (c *dnsCache) dialContext(dial tester.DialContextFunc) tester.DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+		if _, err := c.resolve(ctx, host); err != nil {
+			return nil, err
+		}
+		if ip, ok := c.pick(host); ok {
+			addr = net.JoinHostPort(ip, port)
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// Resolve pre-resolves the hosts of the given server URLs when DNS caching is
+// enabled, and returns the addresses found for each host
+func (b *Benchmarker) Resolve(ctx context.Context, serverURLs []string) (map[string][]string, error) {
+	resolved := make(map[string][]string)
+	if b.dns == nil {
+		return resolved, nil
+	}
+	for _, serverURL := range serverURLs {
+		u, err := url.Parse(serverURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server URL %s: %w", serverURL, err)
+		}
+		host := u.Hostname()
+		if _, ok := resolved[host]; ok || host == "" {
+			continue
+		}
+		addrs, err := b.dns.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		resolved[host] = addrs
+	}
+	return resolved, nil
+}
@@ -0,0 +1,61 @@
+package benchmarker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// ErrorLimit caps the failed requests of an error class over a run, as a
+// count or a percentage of all requests, e.g. "5xx=0" or "timeout=0.5%"
+type ErrorLimit struct {
+	Class   models.ErrorClass
+	Max     float64
+	Percent bool   // Max is a percentage of all requests
+	Expr    string // the limit as written, e.g. "0.5%"
+}
+
+// ParseErrorLimit parses a limit given as "class=max"
+func ParseErrorLimit(s string) (ErrorLimit, error) {
+	name, expr, ok := strings.Cut(s, "=")
+	if !ok {
+		return ErrorLimit{}, fmt.Errorf("invalid error limit '%s': expected class=max, e.g. 5xx=0 or timeout=0.5%%", s)
+	}
+	class, err := models.ParseErrorClass(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return ErrorLimit{}, fmt.Errorf("invalid error limit '%s': %w", s, err)
+	}
+
+	expr = strings.TrimSpace(expr)
+	number, percent := strings.CutSuffix(expr, "%")
+	max, err := strconv.ParseFloat(number, 64)
+	if err != nil || max < 0 || (!percent && max != float64(int(max))) {
+		return ErrorLimit{}, fmt.Errorf("invalid error limit '%s': '%s' is not a request count or percentage", s, expr)
+	}
+	return ErrorLimit{Class: class, Max: max, Percent: percent, Expr: expr}, nil
+}
+
+// EvaluateErrorLimits checks the failed requests of each limited error class
+// over the whole run. A class may fail as many requests as its limit allows.
+func EvaluateErrorLimits(summary models.BenchmarkSummary, limits []ErrorLimit) []models.ErrorLimitResult {
+	evaluated := make([]models.ErrorLimitResult, 0, len(limits))
+	for _, limit := range limits {
+		lr := models.ErrorLimitResult{
+			Class:  limit.Class,
+			Limit:  limit.Expr,
+			Actual: summary.Errors[limit.Class],
+		}
+		if summary.TotalRequests > 0 {
+			lr.Rate = float64(lr.Actual) / float64(summary.TotalRequests) * 100
+		}
+		if limit.Percent {
+			lr.Passed = lr.Rate <= limit.Max
+		} else {
+			lr.Passed = float64(lr.Actual) <= limit.Max
+		}
+		evaluated = append(evaluated, lr)
+	}
+	return evaluated
+}
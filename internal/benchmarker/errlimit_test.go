@@ -0,0 +1,89 @@
+package benchmarker
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestBenchmarkErrorClasses(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// Every other request is rejected by an overloaded server
+	var requests atomic.Int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1)%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+
+	config := DefaultConfig()
+	config.Iterations = 20
+	config.WarmupRuns = 0
+	config.Handler = handler
+	summary, err := NewBenchmarker(config).BenchmarkSpec(context.Background(), p, nil)
+	if err != nil {
+		t.Fatalf("BenchmarkSpec: %v", err)
+	}
+
+	for _, result := range summary.Results {
+		if result.Errors[models.ErrorServer] != 10 || len(result.Errors) != 1 {
+			t.Errorf("Expected 10 5xx responses for %s %s, got %v", result.Method, result.Path, result.Errors)
+		}
+	}
+	if want := 10 * len(summary.Results); summary.Errors[models.ErrorServer] != want {
+		t.Errorf("Expected %d 5xx responses in the summary, got %v", want, summary.Errors)
+	}
+}
+
+func TestParseErrorLimit(t *testing.T) {
+	limit, err := ParseErrorLimit("Timeout = 0.5%")
+	if err != nil {
+		t.Fatalf("Expected valid limit, got %v", err)
+	}
+	if limit.Class != models.ErrorTimeout || limit.Max != 0.5 || !limit.Percent || limit.Expr != "0.5%" {
+		t.Errorf("Unexpected limit %+v", limit)
+	}
+
+	for _, s := range []string{"5xx", "server=0", "5xx=-1", "5xx=1.5", "5xx=many"} {
+		if _, err := ParseErrorLimit(s); err == nil {
+			t.Errorf("Expected limit %q to be rejected", s)
+		}
+	}
+}
+
+func TestEvaluateErrorLimits(t *testing.T) {
+	summary := models.BenchmarkSummary{
+		TotalRequests: 200,
+		Errors:        models.ErrorCounts{models.ErrorServer: 3, models.ErrorTimeout: 1},
+	}
+	limits := []ErrorLimit{
+		{Class: models.ErrorServer, Max: 2, Expr: "2"},
+		{Class: models.ErrorTimeout, Max: 0.5, Percent: true, Expr: "0.5%"},
+		{Class: models.ErrorDNS, Max: 0, Expr: "0"},
+	}
+
+	evaluated := EvaluateErrorLimits(summary, limits)
+	if len(evaluated) != 3 {
+		t.Fatalf("Expected 3 limits, got %+v", evaluated)
+	}
+	server, timeout, dns := evaluated[0], evaluated[1], evaluated[2]
+	if server.Passed || server.Actual != 3 || server.Rate != 1.5 {
+		t.Errorf("Expected 3 5xx responses (1.5%%) to exceed 2, got %+v", server)
+	}
+	if !timeout.Passed || timeout.Rate != 0.5 {
+		t.Errorf("Expected 0.5%% timeouts to stay within 0.5%%, got %+v", timeout)
+	}
+	if !dns.Passed || dns.Actual != 0 {
+		t.Errorf("Expected no DNS errors to pass, got %+v", dns)
+	}
+}
@@ -0,0 +1,99 @@
+package benchmarker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// handlerHost is the server URL of in-process benchmarks whose spec declares
+// no absolute server URL
+const handlerHost = "http://localhost"
+
+// handlerTransport serves requests with an http.Handler in-process instead
+// of sending them over a connection
+type handlerTransport struct {
+	handler http.Handler
+}
+
+// RoundTrip turns the client request into the server request the handler
+// would get from net/http and records its response
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	serverReq := req.Clone(req.Context())
+	serverReq.RequestURI = req.URL.RequestURI()
+	serverReq.RemoteAddr = "127.0.0.1:0"
+	serverReq.Host = req.Host
+	if serverReq.Host == "" {
+		serverReq.Host = req.URL.Host
+	}
+	if serverReq.Body == nil {
+		serverReq.Body = http.NoBody
+	}
+
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, serverReq)
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// BenchmarkHandler benchmarks every operation of a spec against an
+// http.Handler in-process, without a network socket, so a Go service can
+// run spec-driven load tests inside go test -bench:
+//
+//	func BenchmarkAPI(b *testing.B) {
+//		p, _ := parser.ParseFile("openapi.yaml")
+//		summary, err := benchmarker.BenchmarkHandler(context.Background(), p, api.NewRouter())
+//		...
+//	}
+//
+// Requests keep the path of the spec's first server URL. The default
+// configuration is used; for another, set Config.Handler and call
+// BenchmarkSpec.
+func BenchmarkHandler(ctx context.Context, p *parser.Parser, handler http.Handler) (models.BenchmarkSummary, error) {
+	config := DefaultConfig()
+	config.Handler = handler
+	return NewBenchmarker(config).BenchmarkSpec(ctx, p, nil)
+}
+
+// BenchmarkSpec benchmarks every operation of a spec against its first
+// server URL. Unsupported operations are listed as skipped.
+func (b *Benchmarker) BenchmarkSpec(ctx context.Context, p *parser.Parser, onEvent OnBenchmarkEvent) (models.BenchmarkSummary, error) {
+	serverURLs, err := p.GetServerURLs()
+	if err != nil {
+		return models.BenchmarkSummary{}, err
+	}
+	baseURL := handlerHost
+	if len(serverURLs) > 0 {
+		baseURL = serverURLs[0]
+		if !strings.Contains(baseURL, "://") {
+			// A relative server URL is relative to the host serving the spec
+			baseURL = handlerHost + "/" + strings.TrimPrefix(baseURL, "/")
+		}
+	}
+
+	operations, err := p.GetOperations(baseURL)
+	if err != nil {
+		return models.BenchmarkSummary{}, fmt.Errorf("failed to get operations: %w", err)
+	}
+	var supported, unsupported []models.Operation
+	for _, op := range operations {
+		if op.Unsupported == "" {
+			supported = append(supported, op)
+		} else {
+			unsupported = append(unsupported, op)
+		}
+	}
+	summary := b.BenchmarkOperations(ctx, supported, p, onEvent)
+	summary.SkippedOperations = append(models.SkipOperations(unsupported, models.SkipUnsupported), summary.SkippedOperations...)
+	return summary, nil
+}
@@ -0,0 +1,62 @@
+package benchmarker
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestBenchmarkHandler(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	var mu sync.Mutex
+	paths := make(map[string]int)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		if r.Host != "petstore.swagger.io" || r.RequestURI == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+
+	config := DefaultConfig()
+	config.Iterations = 20
+	config.Concurrency = 4
+	config.WarmupRuns = 0
+	config.Handler = handler
+	summary, err := NewBenchmarker(config).BenchmarkSpec(context.Background(), p, nil)
+	if err != nil {
+		t.Fatalf("BenchmarkSpec: %v", err)
+	}
+
+	if len(summary.Results) != 3 {
+		t.Fatalf("Expected 3 operations, got %d", len(summary.Results))
+	}
+	for _, result := range summary.Results {
+		if result.SuccessCount != 20 || result.ErrorCount != 0 {
+			t.Errorf("Expected 20 successful requests to %s %s, got %+v", result.Method, result.Path, result)
+		}
+		if result.StatusCodes[http.StatusBadRequest] > 0 {
+			t.Errorf("Expected the handler to get server requests, got 400s for %s %s", result.Method, result.Path)
+		}
+	}
+	for key, n := range paths {
+		if !strings.HasPrefix(strings.SplitN(key, " ", 2)[1], "/v1/pets") || n != 20 {
+			t.Errorf("Unexpected requests: %d to %s", n, key)
+		}
+	}
+}
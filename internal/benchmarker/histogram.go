@@ -0,0 +1,47 @@
+package benchmarker
+
+import (
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// latencyBounds are the histogram bucket upper bounds, a 1-2-5 series so that
+// histograms of different endpoints and runs line up
+var latencyBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for decade := 100 * time.Microsecond; decade <= 10*time.Second; decade *= 10 {
+		bounds = append(bounds, decade, 2*decade, 5*decade)
+	}
+	return append(bounds, 100*time.Second)
+}()
+
+// histogram buckets sorted latencies. Empty buckets before the fastest and
+// after the slowest request are left out; latencies above the largest bound
+// end up in a final bucket bounded by the slowest request.
+func histogram(sorted []time.Duration) []models.HistogramBucket {
+	if len(sorted) == 0 {
+		return nil
+	}
+
+	var buckets []models.HistogramBucket
+	i := 0
+	for _, bound := range latencyBounds {
+		if i == len(sorted) {
+			break
+		}
+		count := 0
+		for i < len(sorted) && sorted[i] <= bound {
+			count++
+			i++
+		}
+		if count == 0 && len(buckets) == 0 {
+			continue
+		}
+		buckets = append(buckets, models.HistogramBucket{UpperBound: bound, Count: count})
+	}
+	if i < len(sorted) {
+		buckets = append(buckets, models.HistogramBucket{UpperBound: sorted[len(sorted)-1], Count: len(sorted) - i})
+	}
+	return buckets
+}
@@ -0,0 +1,63 @@
+package benchmarker
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// Endpoint orderings accepted by Config.Order
+const (
+	OrderSpec         = "spec"
+	OrderRandom       = "random"
+	OrderSlowestFirst = "slowest-first"
+)
+
+// ParseOrder validates an endpoint ordering
+func ParseOrder(s string) (string, error) {
+	switch s {
+	case "", OrderSpec:
+		return OrderSpec, nil
+	case OrderRandom, OrderSlowestFirst:
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid order '%s': must be 'spec', 'random' or 'slowest-first'", s)
+	}
+}
+
+// orderOperations returns the operations in the configured order. Random order
+// is driven by Config.Seed so a run can be reproduced; slowest-first probes
+// each endpoint once and benchmarks the slowest ones first.
+func (b *Benchmarker) orderOperations(ctx context.Context, operations []models.Operation, p *parser.Parser) []models.Operation {
+	ordered := make([]models.Operation, len(operations))
+	copy(ordered, operations)
+
+	switch b.config.Order {
+	case OrderRandom:
+		rng := rand.New(rand.NewSource(b.config.Seed))
+		rng.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+
+	case OrderSlowestFirst:
+		probes := make(map[string]time.Duration, len(ordered))
+		for _, op := range ordered {
+			if ctx.Err() != nil {
+				break
+			}
+			opDetails, err := p.GetOperationDetails(op.Path, op.Method)
+			if err != nil {
+				continue
+			}
+			probes[op.Method+" "+op.Path] = b.executeRequest(ctx, opDetails, op.ServerURL).Duration
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return probes[ordered[i].Method+" "+ordered[i].Path] > probes[ordered[j].Method+" "+ordered[j].Path]
+		})
+	}
+
+	return ordered
+}
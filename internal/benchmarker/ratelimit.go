@@ -0,0 +1,61 @@
+package benchmarker
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newLimiters returns the rate limiters of a config: one shared by all
+// workers, or with PerWorkerRate one per worker, each allowing its share of
+// the rate and burst. It returns nil without a rate.
+func newLimiters(config Config) []*rate.Limiter {
+	if config.RateLimit <= 0 {
+		return nil
+	}
+	// A second's worth of requests unless a burst is set
+	burst := config.Burst
+	if burst <= 0 {
+		burst = int(config.RateLimit)
+	}
+
+	workers := 1
+	if config.PerWorkerRate {
+		workers = max(1, config.Concurrency)
+	}
+	limiters := make([]*rate.Limiter, workers)
+	for i := range limiters {
+		// Every limiter must allow at least one request at a time
+		share := max(1, int(math.Ceil(float64(burst)/float64(workers))))
+		limiters[i] = rate.NewLimiter(rate.Limit(config.RateLimit/float64(workers)), share)
+	}
+	return limiters
+}
+
+// waitTurn blocks until a worker may send its next request, delayed by a
+// random part of the request interval with Jitter, so workers released
+// together don't hit the server in synchronized waves. It returns the
+// jitter delay.
+func (b *Benchmarker) waitTurn(ctx context.Context, worker int) time.Duration {
+	if len(b.limiters) == 0 {
+		return 0
+	}
+	limiter := b.limiters[worker%len(b.limiters)]
+	limiter.Wait(ctx)
+
+	if b.config.Jitter <= 0 {
+		return 0
+	}
+	interval := time.Duration(float64(time.Second) / float64(limiter.Limit()))
+	delay := time.Duration(rand.Float64() * b.config.Jitter * float64(interval))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return delay
+}
@@ -0,0 +1,98 @@
+package benchmarker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+	"golang.org/x/time/rate"
+)
+
+func TestNewLimiters(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		limiter int
+		limit   rate.Limit
+		burst   int
+	}{
+		{"a second's worth by default", Config{RateLimit: 100, Concurrency: 4}, 1, 100, 100},
+		{"burst", Config{RateLimit: 100, Burst: 5, Concurrency: 4}, 1, 100, 5},
+		{"below one request per second", Config{RateLimit: 0.5}, 1, 0.5, 1},
+		{"per worker", Config{RateLimit: 100, Burst: 10, Concurrency: 4, PerWorkerRate: true}, 4, 25, 3},
+		{"per worker without burst", Config{RateLimit: 2, Concurrency: 4, PerWorkerRate: true}, 4, 0.5, 1},
+	}
+	for _, tt := range tests {
+		limiters := newLimiters(tt.config)
+		if len(limiters) != tt.limiter {
+			t.Errorf("%s: expected %d limiters, got %d", tt.name, tt.limiter, len(limiters))
+			continue
+		}
+		for _, l := range limiters {
+			if l.Limit() != tt.limit || l.Burst() != tt.burst {
+				t.Errorf("%s: expected %v req/s with burst %d, got %v with burst %d", tt.name, tt.limit, tt.burst, l.Limit(), l.Burst())
+			}
+		}
+	}
+
+	if limiters := newLimiters(Config{Concurrency: 4, Burst: 10}); limiters != nil {
+		t.Errorf("Expected no limiters without a rate, got %d", len(limiters))
+	}
+}
+
+func TestWaitTurnJitter(t *testing.T) {
+	// 100 req/s is an interval of 10ms, jittered by up to half of it
+	b := NewBenchmarker(Config{RateLimit: 100, Burst: 1, Concurrency: 1, Jitter: 0.5})
+
+	jittered := false
+	for i := 0; i < 20; i++ {
+		delay := b.waitTurn(context.Background(), 0)
+		if delay < 0 || delay >= 5*time.Millisecond {
+			t.Fatalf("Expected a jitter below 5ms, got %v", delay)
+		}
+		jittered = jittered || delay > 0
+	}
+	if !jittered {
+		t.Error("Expected sends to be jittered")
+	}
+
+	if delay := NewBenchmarker(Config{RateLimit: 100, Concurrency: 1}).waitTurn(context.Background(), 0); delay != 0 {
+		t.Errorf("Expected no jitter by default, got %v", delay)
+	}
+}
+
+func TestQueueWait(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	run := func(delay time.Duration, rateLimit float64) time.Duration {
+		config := DefaultConfig()
+		config.Iterations = 10
+		config.WarmupRuns = 0
+		config.RateLimit = rateLimit
+		config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			w.Write([]byte(`[]`))
+		})
+		summary, err := NewBenchmarker(config).BenchmarkSpec(context.Background(), p, nil)
+		if err != nil {
+			t.Fatalf("BenchmarkSpec: %v", err)
+		}
+		return summary.Results[0].Workers.AvgQueueWait
+	}
+
+	// A single worker answering every 20ms falls behind a 5ms schedule
+	if wait := run(20*time.Millisecond, 200); wait < 10*time.Millisecond {
+		t.Errorf("Expected requests to wait for the busy worker, got %v", wait)
+	}
+	if wait := run(0, 200); wait > time.Millisecond {
+		t.Errorf("Expected no queue wait while the worker keeps up, got %v", wait)
+	}
+	if wait := run(0, 0); wait != 0 {
+		t.Errorf("Expected no queue wait without a rate, got %v", wait)
+	}
+}
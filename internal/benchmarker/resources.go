@@ -0,0 +1,91 @@
+package benchmarker
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// resourceSampleInterval is how often the load generator's own usage is sampled
+const resourceSampleInterval = 250 * time.Millisecond
+
+// resourceMonitor tracks the load generator's own resource usage during a run,
+// so a saturated client can be told apart from a slow API
+type resourceMonitor struct {
+	start    time.Time
+	startCPU time.Duration
+
+	mu             sync.Mutex
+	peakHeap       uint64
+	peakGoroutines int
+	peakFDs        int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startResourceMonitor begins sampling resource usage in the background
+func startResourceMonitor() *resourceMonitor {
+	m := &resourceMonitor{
+		start:    time.Now(),
+		startCPU: processCPUTime(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	m.sample()
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(resourceSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.sample()
+			}
+		}
+	}()
+	return m
+}
+
+// sample records current usage, keeping the peaks
+func (m *resourceMonitor) sample() {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	goroutines := runtime.NumGoroutine()
+	fds := openFileDescriptors()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.peakHeap = max(m.peakHeap, mem.HeapAlloc)
+	m.peakGoroutines = max(m.peakGoroutines, goroutines)
+	m.peakFDs = max(m.peakFDs, fds)
+}
+
+// finish stops sampling and returns the usage over the run
+func (m *resourceMonitor) finish() *models.ClientResources {
+	close(m.stop)
+	<-m.done
+	m.sample()
+
+	wall := time.Since(m.start)
+	cpu := processCPUTime() - m.startCPU
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	resources := &models.ClientResources{
+		NumCPU:         runtime.NumCPU(),
+		CPUTime:        cpu,
+		PeakHeapBytes:  m.peakHeap,
+		PeakGoroutines: m.peakGoroutines,
+		PeakOpenFDs:    m.peakFDs,
+	}
+	if wall > 0 {
+		resources.CPUPercent = float64(cpu) / float64(wall) / float64(runtime.NumCPU()) * 100
+	}
+	return resources
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package benchmarker
+
+import "time"
+
+// processCPUTime is not available on this platform
+func processCPUTime() time.Duration {
+	return 0
+}
+
+// openFileDescriptors is not available on this platform
+func openFileDescriptors() int {
+	return 0
+}
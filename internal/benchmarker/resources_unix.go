@@ -0,0 +1,33 @@
+//go:build unix
+
+package benchmarker
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and system CPU time consumed by this process
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// openFileDescriptors returns the number of file descriptors held by this
+// process, or 0 when it cannot be determined
+func openFileDescriptors() int {
+	dir := "/dev/fd"
+	if runtime.GOOS == "linux" {
+		dir = "/proc/self/fd"
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
@@ -0,0 +1,139 @@
+package benchmarker
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// credentialHeaders are left out of slow request traces, since the trace file
+// is meant to be shared
+var credentialHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// requestTrace records when each phase of a request happened. The hooks of
+// httptrace may run on other goroutines, e.g. DNS in the dialer.
+type requestTrace struct {
+	mu    sync.Mutex
+	start time.Time
+
+	getConn, gotConn          time.Time
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	remoteAddr                string
+	reused                    bool
+}
+
+// clientTrace returns the hooks recording the phases of the request
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	mark := func(at *time.Time) {
+		t.mu.Lock()
+		if at.IsZero() {
+			*at = time.Now()
+		}
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		GetConn: func(string) { mark(&t.getConn) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			mark(&t.gotConn)
+			t.mu.Lock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+			t.mu.Unlock()
+		},
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { mark(&t.dnsDone) },
+		ConnectStart:         func(string, string) { mark(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { mark(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { mark(&t.firstByte) },
+	}
+}
+
+// slowRequest returns the trace of a finished request; resp is nil if it
+// failed
+func (t *requestTrace) slowRequest(req *http.Request, resp *http.Response, path string, result requestResult) models.SlowRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	slow := models.SlowRequest{
+		Time:       t.start,
+		Method:     req.Method,
+		Path:       path,
+		URL:        mask.String(req.URL.String()),
+		RequestID:  result.RequestID,
+		StatusCode: result.StatusCode,
+		Error:      result.Error,
+		ErrorClass: result.ErrorClass,
+		Duration:   result.Duration,
+		RemoteAddr: t.remoteAddr,
+		ConnReused: t.reused,
+
+		GetConn:   between(t.getConn, t.gotConn),
+		DNS:       between(t.dnsStart, t.dnsDone),
+		Connect:   between(t.connectStart, t.connectDone),
+		TLS:       between(t.tlsStart, t.tlsDone),
+		Send:      between(t.gotConn, t.wroteRequest),
+		FirstByte: between(t.wroteRequest, t.firstByte),
+
+		RequestHeaders: traceHeaders(req.Header),
+	}
+	if resp != nil {
+		slow.ResponseHeaders = traceHeaders(resp.Header)
+	}
+	return slow
+}
+
+// between returns the time from start to end, or zero if either didn't happen
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// traceHeaders copies headers for a trace, without credentials and with
+// registered secrets masked
+func traceHeaders(header http.Header) map[string][]string {
+	headers := make(map[string][]string, len(header))
+	for name, values := range header {
+		if credentialHeaders[http.CanonicalHeaderKey(name)] {
+			headers[name] = []string{"[redacted]"}
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, v := range values {
+			masked[i] = mask.String(v)
+		}
+		headers[name] = masked
+	}
+	return headers
+}
+
+// writeSlowRequest appends a slow request to the trace file as a JSON line
+func (b *Benchmarker) writeSlowRequest(slow models.SlowRequest) {
+	line, err := json.Marshal(slow)
+	if err != nil {
+		return
+	}
+	b.traceMu.Lock()
+	defer b.traceMu.Unlock()
+	b.config.SlowTraces.Write(append(line, '\n'))
+}
@@ -0,0 +1,95 @@
+package benchmarker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestTraceSlowRequests(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1)%5 == 0 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.Header().Set("X-Cache", "miss")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	operations, err := p.GetOperations(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+	var listPets models.Operation
+	for _, op := range operations {
+		if op.Method == http.MethodGet && op.Path == "/pets" {
+			listPets = op
+		}
+	}
+
+	var traces bytes.Buffer
+	config := DefaultConfig()
+	config.Iterations = 20
+	config.Concurrency = 2
+	config.WarmupRuns = 0
+	config.CorrelationHeader = "X-Request-Id"
+	config.TraceSlow = 40 * time.Millisecond
+	config.SlowTraces = &traces
+	result, err := NewBenchmarker(config).BenchmarkOperation(context.Background(), listPets, p, nil, 0, 1)
+	if err != nil {
+		t.Fatalf("BenchmarkOperation: %v", err)
+	}
+
+	if result.SlowRequests != 4 {
+		t.Errorf("Expected 4 slow requests, got %d", result.SlowRequests)
+	}
+	lines := 0
+	scanner := bufio.NewScanner(&traces)
+	for scanner.Scan() {
+		lines++
+		var slow models.SlowRequest
+		if err := json.Unmarshal(scanner.Bytes(), &slow); err != nil {
+			t.Fatalf("Invalid trace line %q: %v", scanner.Text(), err)
+		}
+		if slow.Path != "/pets" || slow.Method != http.MethodGet || slow.StatusCode != http.StatusOK || slow.RequestID == "" {
+			t.Errorf("Expected the request of GET /pets, got %+v", slow)
+		}
+		if slow.Duration < config.TraceSlow || slow.FirstByte < config.TraceSlow || slow.FirstByte > slow.Duration {
+			t.Errorf("Expected the server wait in the first byte phase, got %+v", slow)
+		}
+		if slow.RemoteAddr == "" || slow.ResponseHeaders["X-Cache"][0] != "miss" || len(slow.RequestHeaders["User-Agent"]) != 1 {
+			t.Errorf("Expected the connection and headers, got %+v", slow)
+		}
+	}
+	if lines != result.SlowRequests {
+		t.Errorf("Expected a trace line per slow request, got %d lines", lines)
+	}
+}
+
+func TestTraceHeadersRedactCredentials(t *testing.T) {
+	headers := traceHeaders(http.Header{
+		"Authorization": {"Bearer secret"},
+		"Cookie":        {"session=secret"},
+		"Accept":        {"application/json"},
+	})
+	if headers["Authorization"][0] != "[redacted]" || headers["Cookie"][0] != "[redacted]" {
+		t.Errorf("Expected credentials to be redacted, got %v", headers)
+	}
+	if headers["Accept"][0] != "application/json" {
+		t.Errorf("Expected other headers to be kept, got %v", headers)
+	}
+}
@@ -0,0 +1,183 @@
+package dashboard
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/benchmarker"
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Point is a progress sample plotted on the dashboard's time series
+type Point struct {
+	Time      time.Time `json:"time"`
+	Endpoint  string    `json:"endpoint"`
+	Progress  int       `json:"progress"`
+	Total     int       `json:"total"`
+	ReqPerSec float64   `json:"req_per_sec"`
+	AvgMs     float64   `json:"avg_ms"`
+	Errors    int       `json:"errors"`
+}
+
+// message is sent to browsers over the event stream
+type message struct {
+	Type   string                  `json:"type"` // "point" or "result"
+	Point  *Point                  `json:"point,omitempty"`
+	Result *models.BenchmarkResult `json:"result,omitempty"`
+}
+
+// Server serves a live benchmark dashboard fed by benchmark events
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+
+	mu      sync.Mutex
+	history []message
+	clients map[chan []byte]struct{}
+}
+
+// New creates a dashboard server listening on addr (e.g. ":8089")
+func New(addr string) *Server {
+	s := &Server{clients: make(map[chan []byte]struct{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/events", s.handleEvents)
+	s.server = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// Start begins serving in the background and returns the dashboard URL
+func (s *Server) Start() (string, error) {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to start dashboard: %w", err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Dashboard stopped: %v\n", err)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	host := "localhost"
+	if !addr.IP.IsUnspecified() {
+		host = addr.IP.String()
+	}
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, fmt.Sprint(addr.Port))), nil
+}
+
+// Close stops the server and disconnects browsers
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// Handle records a benchmark event and forwards it to connected browsers
+func (s *Server) Handle(event benchmarker.BenchmarkEvent) {
+	endpoint := event.Operation.Method + " " + event.Operation.Path
+
+	switch event.Type {
+	case benchmarker.EventBenchmarkProgress:
+		s.publish(message{Type: "point", Point: &Point{
+			Time:      time.Now(),
+			Endpoint:  endpoint,
+			Progress:  event.Progress,
+			Total:     event.MaxIter,
+			ReqPerSec: event.RunningReqSec,
+			AvgMs:     float64(event.RunningAvg.Microseconds()) / 1000,
+			Errors:    event.ErrorCount,
+		}})
+	case benchmarker.EventBenchmarkCompleted:
+		if event.Result != nil {
+			result := *event.Result
+			result.SampleErrors = nil // may be long; the terminal shows them
+			s.publish(message{Type: "result", Result: &result})
+		}
+	}
+}
+
+// publish stores a message and sends it to every connected browser
+func (s *Server) publish(msg message) {
+	msg = mask.Value(msg)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = append(s.history, msg)
+	for client := range s.clients {
+		select {
+		case client <- data:
+		default: // slow browser; it catches up from history on reconnect
+		}
+	}
+}
+
+// handleIndex serves the dashboard page
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+// handleEvents streams events to a browser as server-sent events, starting
+// with the history so far
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	client := make(chan []byte, 64)
+	s.mu.Lock()
+	var backlog [][]byte
+	for _, msg := range s.history {
+		if data, err := json.Marshal(msg); err == nil {
+			backlog = append(backlog, data)
+		}
+	}
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	for _, data := range backlog {
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-client:
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
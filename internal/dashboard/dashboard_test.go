@@ -0,0 +1,61 @@
+package dashboard
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/benchmarker"
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestEventsReplayHistory(t *testing.T) {
+	s := New("127.0.0.1:0")
+	op := models.Operation{Method: "GET", Path: "/pets"}
+	s.Handle(benchmarker.BenchmarkEvent{
+		Type:          benchmarker.EventBenchmarkProgress,
+		Operation:     op,
+		Progress:      50,
+		MaxIter:       100,
+		RunningReqSec: 120,
+		RunningAvg:    8 * time.Millisecond,
+	})
+	s.Handle(benchmarker.BenchmarkEvent{
+		Type:      benchmarker.EventBenchmarkCompleted,
+		Operation: op,
+		Result:    &models.BenchmarkResult{Method: "GET", Path: "/pets", SampleErrors: []string{"boom"}},
+	})
+
+	url, err := s.Start()
+	if err != nil {
+		t.Fatalf("Failed to start dashboard: %v", err)
+	}
+	defer s.Close()
+
+	resp, err := http.Get(url + "/events")
+	if err != nil {
+		t.Fatalf("Failed to connect to event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event stream: %v", err)
+		}
+		if strings.HasPrefix(line, "data: ") {
+			lines = append(lines, line)
+		}
+	}
+
+	if !strings.Contains(lines[0], `"endpoint":"GET /pets"`) || !strings.Contains(lines[0], `"avg_ms":8`) {
+		t.Errorf("Unexpected progress event: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"type":"result"`) || strings.Contains(lines[1], "boom") {
+		t.Errorf("Unexpected result event: %s", lines[1])
+	}
+}
@@ -0,0 +1,436 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/auth"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+// Statuses of a check
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn" // works, but is likely to cause trouble
+	StatusFail = "fail" // a run would fail
+	StatusSkip = "skip" // not applicable, or an earlier check failed
+)
+
+// Clock skew limits: tokens and signatures are commonly accepted with a few
+// seconds of leeway, SigV4 requests are rejected beyond five minutes
+const (
+	WarnClockSkew = 30 * time.Second
+	FailClockSkew = 5 * time.Minute
+)
+
+// certExpiryWarning is how early an expiring certificate is reported
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// Check is the outcome of one diagnosis, with what to do about a problem
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Remedy string `json:"remedy,omitempty"`
+}
+
+// Failed reports whether any of the checks failed
+func Failed(checks []Check) bool {
+	for _, c := range checks {
+		if c.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is the environment to diagnose
+type Config struct {
+	BaseURL       string
+	HostHeader    string // Host header and TLS server name to present instead of the URL's host
+	AddressFamily string // one of the tester.AddressFamily constants
+	Timeout       time.Duration
+	UserAgent     string
+
+	// Authenticator adds credentials to requests; nil if none are configured
+	Authenticator auth.Authenticator
+
+	// ProbeURL is requested with credentials to check that the API accepts
+	// them, e.g. a GET operation that requires authentication. Empty skips
+	// that part of the auth check.
+	ProbeURL string
+
+	Resolver *net.Resolver // default: net.DefaultResolver
+}
+
+// Diagnose checks that the API in config can be reached and authenticated
+// against: DNS resolution, TCP and TLS connectivity, the clock skew to the
+// server and the credentials. Checks depending on a failed one are skipped.
+func Diagnose(ctx context.Context, config Config) []Check {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Resolver == nil {
+		config.Resolver = net.DefaultResolver
+	}
+
+	target, err := url.Parse(config.BaseURL)
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return []Check{{
+			Name:   "server",
+			Status: StatusFail,
+			Detail: fmt.Sprintf("invalid server URL %q", config.BaseURL),
+			Remedy: "Pass an absolute http or https URL with --server, or fix the servers of the spec",
+		}}
+	}
+
+	var checks []Check
+	add := func(c Check) bool {
+		checks = append(checks, c)
+		return c.Status != StatusFail
+	}
+	skipRest := func(names ...string) []Check {
+		for _, name := range names {
+			checks = append(checks, Check{Name: name, Status: StatusSkip, Detail: "skipped after an earlier failure"})
+		}
+		return checks
+	}
+
+	if !add(checkDNS(ctx, config, target)) {
+		return skipRest("tcp", "tls", "http", "clock", "auth")
+	}
+	if !add(checkTCP(ctx, config, target)) {
+		return skipRest("tls", "http", "clock", "auth")
+	}
+	if !add(checkTLS(ctx, config, target)) {
+		return skipRest("http", "clock", "auth")
+	}
+	httpCheck, skew, hasDate := checkHTTP(ctx, config, target)
+	if !add(httpCheck) {
+		return skipRest("clock", "auth")
+	}
+	add(checkClock(skew, hasDate))
+	add(checkAuth(ctx, config))
+	return checks
+}
+
+func checkDNS(ctx context.Context, config Config, target *url.URL) Check {
+	c := Check{Name: "dns"}
+	host := target.Hostname()
+	if net.ParseIP(host) != nil {
+		c.Status, c.Detail = StatusSkip, host+" is an IP address"
+		return c
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+	network := "ip"
+	switch config.AddressFamily {
+	case tester.AddressFamilyIPv4:
+		network = "ip4"
+	case tester.AddressFamilyIPv6:
+		network = "ip6"
+	}
+	ips, err := config.Resolver.LookupIP(ctx, network, host)
+	if err != nil || len(ips) == 0 {
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("cannot resolve %s", host)
+		if err != nil {
+			c.Detail += ": " + err.Error()
+		}
+		c.Remedy = "Check the host name in --server or the spec's servers; for internal hosts, connect to the VPN or add the host to /etc/hosts"
+		if network != "ip" {
+			c.Remedy = fmt.Sprintf("Check that %s has %s addresses, or drop --%s", host, config.AddressFamily, config.AddressFamily)
+		}
+		return c
+	}
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = ip.String()
+	}
+	c.Status, c.Detail = StatusOK, fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", "))
+	return c
+}
+
+func checkTCP(ctx context.Context, config Config, target *url.URL) Check {
+	c := Check{Name: "tcp"}
+	addr := hostPort(target)
+	dialer := &net.Dialer{Timeout: config.Timeout, Resolver: config.Resolver}
+	dial := tester.RestrictAddressFamily(dialer.DialContext, config.AddressFamily)
+
+	start := time.Now()
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("cannot connect to %s: %v", addr, err)
+		c.Remedy = fmt.Sprintf("Check that the API is running and listening on port %s, and that no firewall or proxy blocks the connection", target.Port())
+		if target.Port() == "" {
+			c.Remedy = fmt.Sprintf("Check that the API is running and listening on the default %s port, and that no firewall or proxy blocks the connection", target.Scheme)
+		}
+		return c
+	}
+	defer conn.Close()
+	c.Status = StatusOK
+	c.Detail = fmt.Sprintf("connected to %s in %v", conn.RemoteAddr(), time.Since(start).Round(time.Millisecond))
+	return c
+}
+
+func checkTLS(ctx context.Context, config Config, target *url.URL) Check {
+	c := Check{Name: "tls"}
+	if target.Scheme != "https" {
+		c.Status, c.Detail = StatusSkip, "plain HTTP"
+		return c
+	}
+
+	serverName := target.Hostname()
+	if config.HostHeader != "" {
+		serverName = tester.ServerName(config.HostHeader)
+	}
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: config.Timeout, Resolver: config.Resolver},
+		Config:    &tls.Config{ServerName: serverName},
+	}
+	conn, err := dialer.DialContext(ctx, tcpNetwork(config.AddressFamily), hostPort(target))
+	if err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("TLS handshake with %s failed: %v", serverName, err)
+		c.Remedy = tlsRemedy(err, serverName)
+		return c
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	leaf := state.PeerCertificates[0]
+	c.Status = StatusOK
+	c.Detail = fmt.Sprintf("%s, certificate for %s valid until %s", tls.VersionName(state.Version), serverName, leaf.NotAfter.Format("2006-01-02"))
+	if until := time.Until(leaf.NotAfter); until < certExpiryWarning {
+		c.Status = StatusWarn
+		c.Detail = fmt.Sprintf("certificate for %s expires in %d days (%s)", serverName, int(until.Hours()/24), leaf.NotAfter.Format("2006-01-02"))
+		c.Remedy = "Renew the server certificate before it expires"
+	}
+	return c
+}
+
+// tlsRemedy suggests a fix for a failed TLS handshake
+func tlsRemedy(err error, serverName string) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var recordHeader tls.RecordHeaderError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "Add the CA that issued the server certificate to the system trust store, or point SSL_CERT_FILE at it"
+	case errors.As(err, &hostname):
+		return fmt.Sprintf("The certificate is not issued for %s; use a host name it covers in --server, or present one with --host-header", serverName)
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return "The server certificate has expired (or the local clock is wrong); renew the certificate or sync the clock"
+	case errors.As(err, &recordHeader):
+		return "The server does not speak TLS on this port; use an http:// server URL"
+	}
+	return "Check that the server accepts TLS connections for this host name"
+}
+
+// checkHTTP sends a request to the base URL. It returns how far the server's
+// clock is ahead of the local one, if the server sent a Date header.
+func checkHTTP(ctx context.Context, config Config, target *url.URL) (Check, time.Duration, bool) {
+	c := Check{Name: "http"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		c.Status, c.Detail = StatusFail, err.Error()
+		return c, 0, false
+	}
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	}
+	if config.HostHeader != "" {
+		req.Host = config.HostHeader
+	}
+
+	start := time.Now()
+	resp, err := newClient(config).Do(req)
+	if err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("GET %s failed: %v", target, err)
+		c.Remedy = "Check that a proxy (HTTPS_PROXY, NO_PROXY) or load balancer in between forwards requests to the API"
+		return c, 0, false
+	}
+	rtt := time.Since(start)
+	resp.Body.Close()
+
+	c.Status = StatusOK
+	c.Detail = fmt.Sprintf("GET %s answered %d in %v", target, resp.StatusCode, rtt.Round(time.Millisecond))
+	if resp.StatusCode >= 500 {
+		c.Status = StatusWarn
+		c.Remedy = "The server answers with server errors; check its logs and health before a long run"
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return c, 0, false
+	}
+	// The Date header was set about halfway through the round trip
+	return c, date.Sub(start.Add(rtt / 2)), true
+}
+
+// checkClock judges how far the server's clock is ahead of the local one.
+// Date headers have a resolution of a second, so smaller skews are ignored.
+func checkClock(skew time.Duration, known bool) Check {
+	c := Check{Name: "clock"}
+	if !known {
+		c.Status, c.Detail = StatusSkip, "the server sent no Date header"
+		return c
+	}
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	direction := "ahead of"
+	if skew < 0 {
+		direction = "behind"
+	}
+
+	c.Status, c.Detail = StatusOK, "server clock matches the local clock"
+	if abs >= 2*time.Second {
+		c.Detail = fmt.Sprintf("server clock is %v %s the local clock", abs.Round(time.Second), direction)
+	}
+	if abs >= WarnClockSkew {
+		c.Status = StatusWarn
+		c.Remedy = "Sync the local clock with NTP (e.g. timedatectl set-ntp true); skewed clocks make tokens appear expired or not yet valid"
+		if abs >= FailClockSkew {
+			c.Status = StatusFail
+			c.Remedy += ", and signed requests (SigV4) are rejected"
+		}
+	}
+	return c
+}
+
+// checkAuth acquires credentials and, given a probe URL, checks that the API
+// accepts them
+func checkAuth(ctx context.Context, config Config) Check {
+	c := Check{Name: "auth"}
+	if config.Authenticator == nil {
+		c.Status, c.Detail = StatusSkip, "no authentication configured"
+		return c
+	}
+
+	probe := config.ProbeURL
+	if probe == "" {
+		probe = config.BaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe, nil)
+	if err != nil {
+		c.Status, c.Detail = StatusFail, err.Error()
+		return c
+	}
+	if err := config.Authenticator.Apply(req); err != nil {
+		c.Status, c.Detail = StatusFail, fmt.Sprintf("cannot acquire credentials: %v", err)
+		c.Remedy = "Check the credentials and token endpoint under [auth] in config.toml, and the environment variables they reference"
+		return c
+	}
+
+	c.Status, c.Detail = StatusOK, "credentials acquired"
+	if exp, ok := bearerExpiry(req.Header.Get("Authorization")); ok {
+		if time.Until(exp) <= 0 {
+			c.Status = StatusFail
+			c.Detail = fmt.Sprintf("token expired at %s", exp.Format(time.RFC3339))
+			c.Remedy = "Configure a flow that fetches fresh tokens, or replace the expired token"
+			return c
+		}
+		c.Detail += fmt.Sprintf(", token valid until %s", exp.Format(time.RFC3339))
+	}
+	if config.ProbeURL == "" {
+		return c
+	}
+
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	}
+	if config.HostHeader != "" {
+		req.Host = config.HostHeader
+	}
+	resp, err := newClient(config).Do(req)
+	if err != nil {
+		c.Status, c.Detail = StatusWarn, fmt.Sprintf("GET %s failed: %v", config.ProbeURL, err)
+		return c
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("GET %s answered 401 with the configured credentials", config.ProbeURL)
+		c.Remedy = "The API rejects the credentials; check the token's issuer and audience, and that the account exists in this environment"
+	case http.StatusForbidden:
+		c.Status = StatusWarn
+		c.Detail = fmt.Sprintf("GET %s answered 403 with the configured credentials", config.ProbeURL)
+		c.Remedy = "The credentials are accepted but lack permissions; check the scopes and roles of the account"
+	default:
+		c.Detail += fmt.Sprintf(", GET %s answered %d", config.ProbeURL, resp.StatusCode)
+	}
+	return c
+}
+
+// bearerExpiry returns the exp claim of a JWT bearer token
+func bearerExpiry(authorization string) (time.Time, bool) {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return time.Time{}, false
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(claims.Exp), 0), true
+}
+
+func newClient(config Config) *http.Client {
+	dialer := &net.Dialer{Timeout: config.Timeout, Resolver: config.Resolver}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = tester.RestrictAddressFamily(dialer.DialContext, config.AddressFamily)
+	if config.HostHeader != "" {
+		transport.TLSClientConfig = &tls.Config{ServerName: tester.ServerName(config.HostHeader)}
+	}
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func hostPort(target *url.URL) string {
+	port := target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(target.Hostname(), port)
+}
+
+func tcpNetwork(family string) string {
+	switch family {
+	case tester.AddressFamilyIPv4:
+		return "tcp4"
+	case tester.AddressFamilyIPv6:
+		return "tcp6"
+	}
+	return "tcp"
+}
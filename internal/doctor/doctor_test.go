@@ -0,0 +1,163 @@
+package doctor
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type authFunc func(req *http.Request) error
+
+func (f authFunc) Apply(req *http.Request) error { return f(req) }
+
+// jwt returns an unsigned token expiring at exp
+func jwt(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+func statuses(checks []Check) map[string]string {
+	byName := make(map[string]string)
+	for _, c := range checks {
+		byName[c.Name] = c.Status
+	}
+	return byName
+}
+
+func TestDiagnoseHealthyServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/me" && r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	checks := Diagnose(context.Background(), Config{
+		BaseURL: server.URL,
+		Authenticator: authFunc(func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+jwt(time.Now().Add(time.Hour)))
+			return nil
+		}),
+		ProbeURL: server.URL + "/me",
+	})
+	want := map[string]string{
+		"dns":   StatusSkip, // 127.0.0.1
+		"tcp":   StatusOK,
+		"tls":   StatusSkip,
+		"http":  StatusOK,
+		"clock": StatusOK,
+		"auth":  StatusOK,
+	}
+	got := statuses(checks)
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("Expected %s check to be %s, got %+v", name, status, checks)
+		}
+	}
+	if Failed(checks) {
+		t.Errorf("Expected no failed checks, got %+v", checks)
+	}
+}
+
+func TestDiagnoseUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	checks := Diagnose(context.Background(), Config{BaseURL: url, Timeout: time.Second})
+	got := statuses(checks)
+	if got["tcp"] != StatusFail || got["http"] != StatusSkip || got["auth"] != StatusSkip {
+		t.Fatalf("Expected tcp to fail and later checks to be skipped, got %+v", checks)
+	}
+	if !Failed(checks) || checks[1].Remedy == "" {
+		t.Errorf("Expected a failure with a remedy, got %+v", checks[1])
+	}
+}
+
+func TestDiagnoseUntrustedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	checks := Diagnose(context.Background(), Config{BaseURL: server.URL})
+	for _, c := range checks {
+		if c.Name == "tls" {
+			if c.Status != StatusFail || !strings.Contains(c.Remedy, "trust store") {
+				t.Errorf("Expected the untrusted test certificate to fail with a trust store remedy, got %+v", c)
+			}
+			return
+		}
+	}
+	t.Fatalf("No tls check in %+v", checks)
+}
+
+func TestDiagnoseInvalidURL(t *testing.T) {
+	checks := Diagnose(context.Background(), Config{BaseURL: "localhost:8080"})
+	if len(checks) != 1 || checks[0].Status != StatusFail {
+		t.Errorf("Expected a single failed server check, got %+v", checks)
+	}
+}
+
+func TestCheckClock(t *testing.T) {
+	for _, tc := range []struct {
+		skew   time.Duration
+		known  bool
+		status string
+	}{
+		{0, false, StatusSkip},
+		{500 * time.Millisecond, true, StatusOK},
+		{-45 * time.Second, true, StatusWarn},
+		{10 * time.Minute, true, StatusFail},
+	} {
+		if c := checkClock(tc.skew, tc.known); c.Status != tc.status {
+			t.Errorf("checkClock(%v, %v) = %+v, want %s", tc.skew, tc.known, c, tc.status)
+		}
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	bearer := func(token string) authFunc {
+		return func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		}
+	}
+	for name, tc := range map[string]struct {
+		authenticator authFunc
+		status        string
+	}{
+		"accepted": {bearer("good"), StatusOK},
+		"rejected": {bearer("bad"), StatusFail},
+		"expired":  {bearer(jwt(time.Now().Add(-time.Minute))), StatusFail},
+		"no token": {func(*http.Request) error { return errors.New("invalid_client") }, StatusFail},
+	} {
+		c := checkAuth(context.Background(), Config{
+			BaseURL:       server.URL,
+			Timeout:       time.Second,
+			Authenticator: tc.authenticator,
+			ProbeURL:      server.URL + "/me",
+		})
+		if c.Status != tc.status {
+			t.Errorf("%s: expected %s, got %+v", name, tc.status, c)
+		}
+		if c.Status == StatusFail && c.Remedy == "" {
+			t.Errorf("%s: expected a remedy, got %+v", name, c)
+		}
+	}
+
+	if c := checkAuth(context.Background(), Config{BaseURL: server.URL}); c.Status != StatusSkip {
+		t.Errorf("Expected the check to be skipped without authentication, got %+v", c)
+	}
+}
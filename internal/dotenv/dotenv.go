@@ -0,0 +1,115 @@
+package dotenv
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Parse reads KEY=VALUE pairs from a dotenv file. Blank lines and # comments
+// are skipped, an optional "export " prefix is accepted, and values may be
+// wrapped in single or double quotes. Double-quoted values support \n escapes.
+func Parse(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("%s:%d: empty key", path, lineNo)
+		}
+
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = strings.ReplaceAll(value[1:len(value)-1], `\n`, "\n")
+			value = strings.ReplaceAll(value, `\"`, `"`)
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			// Strip trailing comments from unquoted values
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return values, nil
+}
+
+// Load parses a dotenv file and sets its variables in the process environment.
+// Variables already set in the environment take precedence. The loaded
+// key/value pairs are returned.
+func Load(path string) (map[string]string, error) {
+	values, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		if _, exists := os.LookupEnv(key); exists {
+			values[key] = os.Getenv(key)
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	return values, nil
+}
+
+// secretSuffixes and secretWords mark variable names that hold credentials
+var (
+	secretSuffixes = []string{"_TOKEN", "_KEY", "_SECRET"}
+	secretWords    = []string{"PASSWORD", "PASSWD"}
+)
+
+// IsSecret reports whether a variable name looks like it holds a credential:
+// it ends in _TOKEN, _KEY or _SECRET, is one of those words alone, or
+// contains PASSWORD. Names are compared case-insensitively.
+func IsSecret(name string) bool {
+	name = strings.ToUpper(name)
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(name, suffix) || name == suffix[1:] {
+			return true
+		}
+	}
+	for _, word := range secretWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Secrets returns the values of the variables whose name looks like a
+// credential (see IsSecret)
+func Secrets(values map[string]string) []string {
+	var secrets []string
+	for key, value := range values {
+		if IsSecret(key) {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,121 @@
+package dotenv
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/output"
+)
+
+func TestParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# credentials
+API_KEY=abc123
+export TOKEN="line1\nline2"
+SINGLE='keep $raw'
+EMPTY=
+WITH_COMMENT=value # trailing
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	values, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Failed to parse env file: %v", err)
+	}
+
+	expected := map[string]string{
+		"API_KEY":      "abc123",
+		"TOKEN":        "line1\nline2",
+		"SINGLE":       "keep $raw",
+		"EMPTY":        "",
+		"WITH_COMMENT": "value",
+	}
+	for key, want := range expected {
+		if got := values[key]; got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestLoadKeepsExistingEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("OAS_TEST_EXISTING=file\nOAS_TEST_NEW=file\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	t.Setenv("OAS_TEST_EXISTING", "env")
+	t.Setenv("OAS_TEST_NEW", "")
+	os.Unsetenv("OAS_TEST_NEW")
+
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Failed to load env file: %v", err)
+	}
+
+	if got := os.Getenv("OAS_TEST_EXISTING"); got != "env" {
+		t.Errorf("Expected existing variable to win, got %q", got)
+	}
+	if got := os.Getenv("OAS_TEST_NEW"); got != "file" {
+		t.Errorf("Expected new variable from file, got %q", got)
+	}
+}
+
+func TestIsSecret(t *testing.T) {
+	tests := map[string]bool{
+		"API_TOKEN":     true,
+		"stripe_key":    true,
+		"CLIENT_SECRET": true,
+		"DB_PASSWORD":   true,
+		"TOKEN":         true,
+		"PORT":          false,
+		"DEBUG":         false,
+		"HOST":          false,
+		"NODE_ENV":      false,
+		"KEYBOARD":      false,
+	}
+	for name, want := range tests {
+		if got := IsSecret(name); got != want {
+			t.Errorf("IsSecret(%q) = %v, expected %v", name, got, want)
+		}
+	}
+}
+
+func TestSecretsLeaveSettingsInReports(t *testing.T) {
+	values := map[string]string{
+		"PORT":      "8080",
+		"HOST":      "localhost",
+		"API_TOKEN": "tok-51f2e9",
+	}
+	secrets := Secrets(values)
+	if !slices.Equal(secrets, []string{"tok-51f2e9"}) {
+		t.Fatalf("Expected only the token to be secret, got %v", secrets)
+	}
+	mask.Register(secrets...)
+
+	var summary models.TestSummary
+	summary.AddResult(models.TestResult{
+		Method: "GET", Path: "/health", StatusCode: 503,
+		Error: "http://localhost:8080/health rejected tok-51f2e9",
+	})
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := output.ExportTestSummary(summary, output.FormatJSON, path); err != nil {
+		t.Fatalf("ExportTestSummary: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got models.TestSummary
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if msg := got.Results[0].Error; !strings.Contains(msg, "localhost:8080") || strings.Contains(msg, "tok-51f2e9") {
+		t.Errorf("Expected the port to stay and the token to be masked, got %q", msg)
+	}
+}
@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+// resolveSchema returns the schema a value is generated from: the allOf
+// parts of a composed schema merged into one, or the first non-null
+// alternative of a oneOf/anyOf schema without a type of its own. $refs are
+// resolved by the schema proxies.
+func resolveSchema(schema *base.Schema) *base.Schema {
+	if len(schema.AllOf) > 0 {
+		return mergeAllOf(schema)
+	}
+	if len(schema.Type) > 0 || schema.Properties != nil {
+		return schema
+	}
+	for _, proxy := range append(append([]*base.SchemaProxy{}, schema.OneOf...), schema.AnyOf...) {
+		if proxy == nil {
+			continue
+		}
+		if alt := proxy.Schema(); alt != nil && schemaType(alt) != "null" {
+			return resolveSchema(alt)
+		}
+	}
+	return schema
+}
+
+// mergeAllOf merges a schema and its allOf parts into a single schema
+// satisfying all of them: the tightest bounds win, required fields and
+// properties are combined, enums are intersected, and other keywords are
+// taken from the first schema declaring them
+func mergeAllOf(schema *base.Schema) *base.Schema {
+	merged := *schema
+	merged.AllOf = nil
+	merged.Required = append([]string(nil), schema.Required...)
+	for _, proxy := range schema.AllOf {
+		if proxy == nil {
+			continue
+		}
+		part := proxy.Schema()
+		if part == nil {
+			continue
+		}
+		mergeInto(&merged, resolveSchema(part))
+	}
+	return &merged
+}
+
+// mergeInto narrows dst by the keywords of part
+func mergeInto(dst, part *base.Schema) {
+	dst.Type = intersectTypes(dst.Type, part.Type)
+	if dst.Format == "" {
+		dst.Format = part.Format
+	}
+	if dst.Pattern == "" {
+		dst.Pattern = part.Pattern
+	}
+	if dst.Const == nil {
+		dst.Const = part.Const
+	}
+	if dst.Example == nil {
+		dst.Example = part.Example
+	}
+	if len(dst.Examples) == 0 {
+		dst.Examples = part.Examples
+	}
+	if dst.Default == nil {
+		dst.Default = part.Default
+	}
+	if dst.Nullable == nil {
+		dst.Nullable = part.Nullable
+	}
+	dst.Enum = intersectEnum(dst.Enum, part.Enum)
+
+	dst.Minimum = tighter(dst.Minimum, part.Minimum, func(a, b float64) bool { return a > b })
+	dst.Maximum = tighter(dst.Maximum, part.Maximum, func(a, b float64) bool { return a < b })
+	if dst.ExclusiveMinimum == nil {
+		dst.ExclusiveMinimum = part.ExclusiveMinimum
+	}
+	if dst.ExclusiveMaximum == nil {
+		dst.ExclusiveMaximum = part.ExclusiveMaximum
+	}
+	if dst.MultipleOf == nil {
+		dst.MultipleOf = part.MultipleOf
+	}
+	dst.MinLength = tighter(dst.MinLength, part.MinLength, func(a, b int64) bool { return a > b })
+	dst.MaxLength = tighter(dst.MaxLength, part.MaxLength, func(a, b int64) bool { return a < b })
+	dst.MinItems = tighter(dst.MinItems, part.MinItems, func(a, b int64) bool { return a > b })
+	dst.MaxItems = tighter(dst.MaxItems, part.MaxItems, func(a, b int64) bool { return a < b })
+
+	if dst.Items == nil {
+		dst.Items = part.Items
+	}
+	if len(dst.PrefixItems) == 0 {
+		dst.PrefixItems = part.PrefixItems
+	}
+	if dst.DependentRequired == nil {
+		dst.DependentRequired = part.DependentRequired
+	}
+
+	for _, name := range part.Required {
+		if !containsString(dst.Required, name) {
+			dst.Required = append(dst.Required, name)
+		}
+	}
+	if part.Properties != nil {
+		properties := orderedmap.New[string, *base.SchemaProxy]()
+		if dst.Properties != nil {
+			for pair := dst.Properties.First(); pair != nil; pair = pair.Next() {
+				properties.Set(pair.Key(), pair.Value())
+			}
+		}
+		for pair := part.Properties.First(); pair != nil; pair = pair.Next() {
+			if _, ok := properties.Get(pair.Key()); !ok {
+				properties.Set(pair.Key(), pair.Value())
+			}
+		}
+		dst.Properties = properties
+	}
+}
+
+// tighter returns the stricter of two optional bounds
+func tighter[T int64 | float64](a, b *T, stricter func(a, b T) bool) *T {
+	if a == nil {
+		return b
+	}
+	if b != nil && stricter(*b, *a) {
+		return b
+	}
+	return a
+}
+
+// intersectTypes returns the types allowed by both type lists; an empty list
+// allows any type
+func intersectTypes(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	var types []string
+	for _, t := range a {
+		if containsString(b, t) || (t == "integer" && containsString(b, "number")) {
+			types = append(types, t)
+		} else if t == "number" && containsString(b, "integer") {
+			types = append(types, "integer")
+		}
+	}
+	if len(types) == 0 {
+		return a
+	}
+	return types
+}
+
+// intersectEnum returns the enum values allowed by both enums; an empty enum
+// allows any value
+func intersectEnum(a, b []*yaml.Node) []*yaml.Node {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	var values []*yaml.Node
+	for _, node := range a {
+		for _, other := range b {
+			if node != nil && other != nil && node.Value == other.Value {
+				values = append(values, node)
+				break
+			}
+		}
+	}
+	if len(values) == 0 {
+		return a
+	}
+	return values
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestGenerateComposedParameters(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/sensors/{sensorId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	g := NewGenerator()
+	for _, param := range opDetails.Parameters {
+		for i := 0; i < 20; i++ {
+			g.Seed(int64(i + 1))
+			g.entities = make(map[string]interface{})
+			val, err := g.GeneratePathParameter(param)
+			if err != nil {
+				t.Fatalf("Failed to generate %s: %v", param.Name, err)
+			}
+			switch param.Name {
+			case "sensorId":
+				if n, err := strconv.Atoi(val); err != nil || n < 1000 || n > 1999 {
+					t.Errorf("Expected sensorId within the $ref'd minimum and allOf maximum, got %q", val)
+				}
+			case "window":
+				if n, err := strconv.Atoi(val); err != nil || n < 5 || n > 60 {
+					t.Errorf("Expected window within the merged bounds, got %q", val)
+				}
+			case "mode":
+				if val != "slow" && val != "off" {
+					t.Errorf("Expected a value of the intersected enums, got %q", val)
+				}
+			}
+		}
+	}
+}
+
+func TestMergeAllOfKeepsSource(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/sensors/{sensorId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	schema := opDetails.Parameters[0].Schema.Schema()
+	merged := resolveSchema(schema)
+	if len(merged.AllOf) != 0 || len(merged.Type) != 1 || merged.Type[0] != "integer" {
+		t.Errorf("Expected a merged integer schema, got type %v and %d allOf parts", merged.Type, len(merged.AllOf))
+	}
+	if len(schema.AllOf) != 2 || schema.Minimum != nil {
+		t.Errorf("Expected the spec's schema to be left unchanged")
+	}
+}
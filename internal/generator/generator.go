@@ -1,25 +1,172 @@
 package generator
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
 )
 
 // Generator generates test data from OpenAPI schemas
 type Generator struct {
-	rng *rand.Rand
+	rng     *rand.Rand
+	unicode bool // generate edge-case unicode for free-form strings (see SetUnicode)
+
+	mu          sync.Mutex
+	paramValues map[string]interface{} // configured values by name or JSON pointer
+	chosen      map[string]string      // value picked for each key, reused for the run
+	entities    map[string]interface{} // correlated entity identifiers, reused for the run
 }
 
 // NewGenerator creates a new generator instance
 func NewGenerator() *Generator {
 	return &Generator{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		entities: make(map[string]interface{}),
+	}
+}
+
+// entityKey normalizes a field name so that petId, pet_id and PET-ID correlate
+func entityKey(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// isEntityField reports whether a field name identifies an entity: it ends in
+// an "id" word, as in petId, pet_id, PET-ID or petID, but not paid, valid or
+// uuid. A bare "id" is ambiguous across entities and is only correlated when
+// scoped to a resource path (see pathEntityKey and GenerateRequestBodyForPath).
+func isEntityField(name string) bool {
+	if len(name) <= 2 {
+		return false
+	}
+	stem, suffix := name[:len(name)-2], name[len(name)-2:]
+	last := rune(stem[len(stem)-1])
+	switch {
+	case last == '_' || last == '-':
+		return len(stem) > 1 && strings.EqualFold(suffix, "id")
+	case suffix == "Id":
+		return true
+	case suffix == "ID":
+		// petID, but not UUID
+		return !unicode.IsUpper(last)
+	}
+	return false
+}
+
+// pathEntityKey returns the entity key of a parameter of an operation on path,
+// and whether the parameter identifies an entity. A bare "id" is scoped to the
+// resource, so {id} in /pets/{id} correlates with petId and pet_id.
+func pathEntityKey(path, name string) (string, bool) {
+	key := entityKey(name)
+	if key == "id" {
+		if entity := resourceEntity(path); entity != "" {
+			return entityKey(entity + "id"), true
+		}
+	}
+	return key, isEntityField(name)
+}
+
+// entityValue returns the value correlated with key, generating and storing
+// one on first use so every operation in the run sees the same identifier.
+func (g *Generator) entityValue(key string, generate func() interface{}) interface{} {
+	g.mu.Lock()
+	val, ok := g.entities[key]
+	g.mu.Unlock()
+	if ok {
+		return val
+	}
+
+	val = generate()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if existing, ok := g.entities[key]; ok {
+		return existing
+	}
+	g.entities[key] = val
+	return val
+}
+
+// Seed restarts random generation from the given seed, so the same schema
+// yields the same values again
+func (g *Generator) Seed(seed int64) {
+	g.rng = rand.New(rand.NewSource(seed))
+}
+
+// Chance reports true with the given probability, drawing from the same
+// source as generated values so a seed reproduces the outcome
+func (g *Generator) Chance(probability float64) bool {
+	if probability >= 1 {
+		return true
+	}
+	return probability > 0 && g.rng.Float64() < probability
+}
+
+// SetEntityValue fixes the correlated value for the entity identifier
+// parameter name of path, e.g. a real id harvested from the server, replacing
+// any generated value
+func (g *Generator) SetEntityValue(path, name string, value interface{}) {
+	key, _ := pathEntityKey(path, name)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.entities[key] = value
+}
+
+// resourceEntity derives the entity name from a resource path, e.g.
+// /pets and /pets/{petId} both yield "pet"
+func resourceEntity(path string) string {
+	var last string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			last = segment
+		}
+	}
+	switch {
+	case strings.HasSuffix(last, "ies"):
+		return strings.TrimSuffix(last, "ies") + "y"
+	case strings.HasSuffix(last, "s"):
+		return strings.TrimSuffix(last, "s")
 	}
+	return last
+}
+
+// maxExactInt is the largest integer a float64 holds exactly (2^53)
+const maxExactInt = 1 << 53
+
+// nodeValue decodes a YAML node (example or default) into a plain Go value.
+// Integers too large for a float64 are kept as a json.Number, so their
+// digits survive a round trip through JSON.
+func nodeValue(node *yaml.Node) interface{} {
+	var val interface{}
+	if err := node.Decode(&val); err != nil {
+		return node.Value
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!int" && json.Valid([]byte(node.Value)) {
+		switch n := val.(type) {
+		case int:
+			if n > maxExactInt || n < -maxExactInt {
+				return json.Number(node.Value)
+			}
+		case uint64, float64:
+			return json.Number(node.Value)
+		}
+	}
+	return val
 }
 
 // GenerateValue generates a test value based on a schema
@@ -27,21 +174,43 @@ func (g *Generator) GenerateValue(schema *base.Schema) (interface{}, error) {
 	if schema == nil {
 		return nil, fmt.Errorf("schema is nil")
 	}
+	schema = resolveSchema(schema)
+
+	if g.unicode && isFreeString(schema) {
+		return g.generateUnicodeString(schema), nil
+	}
 
-	// Check for example value first
+	// A const (3.1) is the only valid value
+	if schema.Const != nil {
+		return nodeValue(schema.Const), nil
+	}
+
+	// Check for example value first, the 3.0 example or the first of the 3.1
+	// examples
 	if schema.Example != nil {
-		return schema.Example, nil
+		return nodeValue(schema.Example), nil
+	}
+	if len(schema.Examples) > 0 && schema.Examples[0] != nil {
+		return nodeValue(schema.Examples[0]), nil
 	}
 
 	// Check for default value
 	if schema.Default != nil {
-		return schema.Default, nil
+		return nodeValue(schema.Default), nil
+	}
+
+	// Pick an enum value at random, so a run covers more than the first
+	if len(schema.Enum) > 0 {
+		if node := schema.Enum[g.rng.Intn(len(schema.Enum))]; node != nil {
+			return nodeValue(node), nil
+		}
 	}
 
 	// Handle different schema types
-	if schema.Type != nil && len(schema.Type) > 0 {
-		schemaType := schema.Type[0]
-		switch schemaType {
+	if len(schema.Type) > 0 {
+		switch schemaType(schema) {
+		case "null":
+			return nil, nil
 		case "string":
 			return g.generateString(schema), nil
 		case "integer", "number":
@@ -64,6 +233,21 @@ func (g *Generator) GenerateValue(schema *base.Schema) (interface{}, error) {
 	return "", nil
 }
 
+// schemaType returns the type to generate a value of. A 3.1 type list such
+// as ["null", "string"] yields its first non-null type, so a value is
+// generated rather than null.
+func schemaType(schema *base.Schema) string {
+	for _, t := range schema.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	if len(schema.Type) > 0 {
+		return "null"
+	}
+	return ""
+}
+
 // generateString generates a string value based on schema constraints
 func (g *Generator) generateString(schema *base.Schema) string {
 	// Check format
@@ -74,15 +258,6 @@ func (g *Generator) generateString(schema *base.Schema) string {
 		}
 	}
 
-	// Check enum
-	if schema.Enum != nil && len(schema.Enum) > 0 {
-		// Enum values are yaml.Node, extract value
-		enumNode := schema.Enum[0]
-		if enumNode != nil {
-			return enumNode.Value
-		}
-	}
-
 	// Check pattern (simplified - just return a basic string)
 	if schema.Pattern != "" {
 		// For now, return a simple string. Full pattern matching would require regex engine
@@ -112,33 +287,160 @@ func (g *Generator) generateString(schema *base.Schema) string {
 
 // generateNumber generates a number value based on schema constraints
 func (g *Generator) generateNumber(schema *base.Schema) interface{} {
-	var min, max float64
-	var isInt bool
-
-	if schema.Type != nil && len(schema.Type) > 0 && schema.Type[0] == "integer" {
-		isInt = true
-		min = 0
-		max = 100
-	} else {
-		min = 0.0
-		max = 100.0
+	if schemaType(schema) == "integer" {
+		return g.generateInteger(schema)
 	}
 
+	min, max := 0.0, 100.0
 	if schema.Minimum != nil {
 		min = *schema.Minimum
 	}
 	if schema.Maximum != nil {
 		max = *schema.Maximum
 	}
+	if schema.Minimum != nil && schema.Maximum == nil {
+		max = min + 100
+	}
+	if schema.Maximum != nil && schema.Minimum == nil {
+		min = max - 100
+	}
 
-	value := min + g.rng.Float64()*(max-min)
+	// 3.1 exclusive bounds are numbers of their own, 3.0 ones flag minimum
+	// and maximum as exclusive
+	exclusiveMin := schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsA() && schema.ExclusiveMinimum.A
+	exclusiveMax := schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsA() && schema.ExclusiveMaximum.A
+	if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsB() {
+		min, exclusiveMin = schema.ExclusiveMinimum.B, true
+	}
+	if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsB() {
+		max, exclusiveMax = schema.ExclusiveMaximum.B, true
+	}
+	if schema.Format == "float" {
+		min = math.Max(min, -math.MaxFloat32)
+		max = math.Min(max, math.MaxFloat32)
+	}
 
-	if isInt {
-		return int(value)
+	value := min + g.rng.Float64()*(max-min)
+	if (exclusiveMin && value == min) || (exclusiveMax && value == max) {
+		value = min + (max-min)/2
 	}
 	return value
 }
 
+// generateInteger generates an integer within the schema's bounds and the
+// range of its format (int32 or int64). Bounds are read as integers from the
+// spec where possible, since int64 bounds lose precision as float64.
+// Without bounds values stay in 0-100.
+func (g *Generator) generateInteger(schema *base.Schema) int64 {
+	formatMin, formatMax := int64(math.MinInt64), int64(math.MaxInt64)
+	if schema.Format == "int32" {
+		formatMin, formatMax = math.MinInt32, math.MaxInt32
+	}
+
+	lo, hasLo := integerBound(schema, true)
+	hi, hasHi := integerBound(schema, false)
+	switch {
+	case !hasLo && !hasHi:
+		lo, hi = 0, 100
+	case !hasHi:
+		hi = saturatingAdd(lo, 100)
+	case !hasLo:
+		lo = saturatingAdd(hi, -100)
+	}
+	lo, hi = max(lo, formatMin), min(hi, formatMax)
+	if hi <= lo {
+		return lo
+	}
+
+	span := uint64(hi) - uint64(lo)
+	if span == math.MaxUint64 {
+		return int64(g.rng.Uint64())
+	}
+	return lo + int64(g.rng.Uint64()%(span+1))
+}
+
+// integerBound returns the smallest (lower) or largest (upper) integer the
+// schema's inclusive and exclusive bounds allow, and whether there is one
+func integerBound(schema *base.Schema, lower bool) (int64, bool) {
+	var bound *float64
+	var exclusive *base.DynamicValue[bool, float64]
+	if lower {
+		bound, exclusive = schema.Minimum, schema.ExclusiveMinimum
+	} else {
+		bound, exclusive = schema.Maximum, schema.ExclusiveMaximum
+	}
+
+	var value int64
+	var ok, isExclusive bool
+	if bound != nil {
+		value, ok = exactBound(schema, *bound, lower), true
+		isExclusive = exclusive != nil && exclusive.IsA() && exclusive.A
+	}
+	if exclusive != nil && exclusive.IsB() {
+		value, ok, isExclusive = floatToInt(exclusive.B, lower), true, true
+		if float64(value) != exclusive.B {
+			isExclusive = false // rounding already moved inside the bound
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	if isExclusive {
+		if lower {
+			value = saturatingAdd(value, 1)
+		} else {
+			value = saturatingAdd(value, -1)
+		}
+	}
+	return value, true
+}
+
+// exactBound returns a minimum or maximum as an integer, parsed from the
+// spec's text when it holds the same number, so int64 bounds beyond 2^53 stay
+// exact
+func exactBound(schema *base.Schema, bound float64, lower bool) int64 {
+	if low := schema.GoLow(); low != nil {
+		ref := low.Maximum
+		if lower {
+			ref = low.Minimum
+		}
+		if ref.ValueNode != nil && ref.Value == bound {
+			if n, err := strconv.ParseInt(ref.ValueNode.Value, 10, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return floatToInt(bound, lower)
+}
+
+// floatToInt rounds a bound inwards to an integer, clamped to the int64 range
+func floatToInt(f float64, lower bool) int64 {
+	if lower {
+		f = math.Ceil(f)
+	} else {
+		f = math.Floor(f)
+	}
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// saturatingAdd adds without overflowing the int64 range
+func saturatingAdd(a, b int64) int64 {
+	sum := a + b
+	if b > 0 && sum < a {
+		return math.MaxInt64
+	}
+	if b < 0 && sum > a {
+		return math.MinInt64
+	}
+	return sum
+}
+
 // generateArray generates an array value
 func (g *Generator) generateArray(schema *base.Schema) []interface{} {
 	minItems := 0
@@ -158,7 +460,25 @@ func (g *Generator) generateArray(schema *base.Schema) []interface{} {
 		count = 1
 	}
 
+	// 3.1 prefixItems fix the schema of the leading items
+	if count < len(schema.PrefixItems) {
+		count = len(schema.PrefixItems)
+	}
 	result := make([]interface{}, count)
+	for i, proxy := range schema.PrefixItems {
+		if proxy != nil && proxy.Schema() != nil {
+			result[i], _ = g.GenerateValue(proxy.Schema())
+		}
+	}
+	if len(schema.PrefixItems) > 0 {
+		if schema.Items == nil || !schema.Items.IsA() || schema.Items.A == nil || schema.Items.A.Schema() == nil {
+			return result[:len(schema.PrefixItems)]
+		}
+		for i := len(schema.PrefixItems); i < count; i++ {
+			result[i], _ = g.GenerateValue(schema.Items.A.Schema())
+		}
+		return result
+	}
 	if schema.Items != nil {
 		// Items is a DynamicValue, need to check if it's a SchemaProxy
 		if schema.Items.IsA() {
@@ -222,6 +542,13 @@ func (g *Generator) generateObject(schema *base.Schema) map[string]interface{} {
 			if isRequired || g.rng.Float64() > 0.5 {
 				propSchema := propSchemaProxy.Schema()
 				if propSchema != nil {
+					if key := entityKey(propName); isEntityField(propName) {
+						result[propName] = g.entityValue(key, func() interface{} {
+							val, _ := g.GenerateValue(propSchema)
+							return val
+						})
+						continue
+					}
 					val, _ := g.GenerateValue(propSchema)
 					result[propName] = val
 				}
@@ -229,6 +556,22 @@ func (g *Generator) generateObject(schema *base.Schema) map[string]interface{} {
 		}
 	}
 
+	// 3.1 dependentRequired: a generated property requires its dependents
+	if schema.DependentRequired != nil && schema.Properties != nil {
+		for pair := schema.DependentRequired.First(); pair != nil; pair = pair.Next() {
+			if _, ok := result[pair.Key()]; !ok {
+				continue
+			}
+			for _, name := range pair.Value() {
+				proxy := schema.Properties.GetOrZero(name)
+				if _, ok := result[name]; ok || proxy == nil || proxy.Schema() == nil {
+					continue
+				}
+				result[name], _ = g.GenerateValue(proxy.Schema())
+			}
+		}
+	}
+
 	return result
 }
 
@@ -260,25 +603,109 @@ func (g *Generator) generateFromFormat(format string) interface{} {
 
 // GeneratePathParameter generates a value for a path parameter
 func (g *Generator) GeneratePathParameter(param *v3.Parameter) (string, error) {
+	return g.GeneratePathParameterForPath("", param)
+}
+
+// GeneratePathParameterForPath generates a value for a parameter of an
+// operation on the given resource path, where a bare {id} is correlated with
+// the resource's other identifiers
+func (g *Generator) GeneratePathParameterForPath(path string, param *v3.Parameter) (string, error) {
 	if param == nil {
 		return "", fmt.Errorf("parameter is nil")
 	}
 
-	if param.Schema != nil {
-		schema := param.Schema.Schema()
-		if schema != nil {
-			val, err := g.GenerateValue(schema)
-			if err != nil {
-				return "", err
-			}
+	if schema := parameterSchema(param); schema != nil {
+		if key, ok := pathEntityKey(path, param.Name); ok {
+			val := g.entityValue(key, func() interface{} {
+				val, _ := g.GenerateValue(schema)
+				return val
+			})
 			return fmt.Sprintf("%v", val), nil
 		}
+		val, err := g.GenerateValue(schema)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%v", val), nil
 	}
 
 	// Default to string
 	return "test", nil
 }
 
+// parameterSchema returns the schema of a parameter, declared directly or
+// under the parameter's content, or nil if it has none
+func parameterSchema(param *v3.Parameter) *base.Schema {
+	if param.Schema != nil {
+		return param.Schema.Schema()
+	}
+	if param.Content != nil {
+		for pair := param.Content.First(); pair != nil; pair = pair.Next() {
+			if mediaType := pair.Value(); mediaType != nil && mediaType.Schema != nil {
+				return mediaType.Schema.Schema()
+			}
+		}
+	}
+	return nil
+}
+
+// SetParamValues configures fixed parameter values. Keys are either a
+// parameter name or a JSON pointer of the form
+// /paths/{escaped path}/{method}/parameters/{name}; pointer keys take
+// precedence. Keys match case-insensitively, since configuration keys are
+// lowercased when loaded. A list value offers several candidates, one of which
+// is picked on first use and reused for every operation in the run.
+func (g *Generator) SetParamValues(values map[string]interface{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paramValues = make(map[string]interface{}, len(values))
+	for key, val := range values {
+		g.paramValues[strings.ToLower(key)] = val
+	}
+	g.chosen = make(map[string]string)
+}
+
+// LookupParameter returns the configured value for a parameter, if any
+func (g *Generator) LookupParameter(path, method string, param *v3.Parameter) (string, bool) {
+	if param == nil {
+		return "", false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.paramValues) == 0 {
+		return "", false
+	}
+
+	for _, key := range []string{ParameterPointer(path, method, param.Name), param.Name} {
+		key = strings.ToLower(key)
+		if val, ok := g.chosen[key]; ok {
+			return val, true
+		}
+		raw, ok := g.paramValues[key]
+		if !ok {
+			continue
+		}
+		if list, ok := raw.([]interface{}); ok {
+			if len(list) == 0 {
+				continue
+			}
+			raw = list[g.rng.Intn(len(list))]
+		}
+		val := fmt.Sprintf("%v", raw)
+		g.chosen[key] = val
+		return val, true
+	}
+
+	return "", false
+}
+
+// ParameterPointer returns the JSON pointer identifying a parameter of an operation
+func ParameterPointer(path, method, name string) string {
+	escape := strings.NewReplacer("~", "~0", "/", "~1")
+	return "/paths/" + escape.Replace(path) + "/" + strings.ToLower(method) + "/parameters/" + escape.Replace(name)
+}
+
 // GenerateQueryParameter generates a value for a query parameter
 func (g *Generator) GenerateQueryParameter(param *v3.Parameter) (string, error) {
 	return g.GeneratePathParameter(param)
@@ -286,6 +713,13 @@ func (g *Generator) GenerateQueryParameter(param *v3.Parameter) (string, error)
 
 // GenerateRequestBody generates a request body from a schema
 func (g *Generator) GenerateRequestBody(requestBody *v3.RequestBody) ([]byte, string, error) {
+	return g.GenerateRequestBodyForPath("", requestBody)
+}
+
+// GenerateRequestBodyForPath generates a request body for an operation on the
+// given resource path. A top-level "id" property is correlated with the
+// resource's identifier parameter, so POST /pets and GET /pets/{petId} agree.
+func (g *Generator) GenerateRequestBodyForPath(path string, requestBody *v3.RequestBody) ([]byte, string, error) {
 	if requestBody == nil {
 		return nil, "", fmt.Errorf("request body is nil")
 	}
@@ -332,8 +766,19 @@ func (g *Generator) GenerateRequestBody(requestBody *v3.RequestBody) ([]byte, st
 		return nil, "", err
 	}
 
+	if obj, ok := val.(map[string]interface{}); ok && path != "" {
+		if id, ok := obj["id"]; ok {
+			if entity := resourceEntity(path); entity != "" {
+				obj["id"] = g.entityValue(entityKey(entity+"id"), func() interface{} { return id })
+			}
+		}
+	}
+
 	// Convert to JSON
-	jsonBytes := []byte(fmt.Sprintf("%v", val))
+	jsonBytes, err := json.Marshal(val)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode request body: %w", err)
+	}
 	if contentType == "" {
 		contentType = "application/json"
 	}
@@ -1,9 +1,13 @@
 package generator
 
 import (
+	"encoding/json"
+	"math"
 	"testing"
 
+	"github.com/moamenhredeen/oas/internal/parser"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -31,6 +35,21 @@ func TestGenerateString(t *testing.T) {
 	}
 }
 
+func TestSeed(t *testing.T) {
+	g := NewGenerator()
+	schema := &base.Schema{
+		Type: []string{"string"},
+	}
+
+	g.Seed(42)
+	first, _ := g.GenerateValue(schema)
+	g.Seed(42)
+	second, _ := g.GenerateValue(schema)
+	if first != second {
+		t.Errorf("Expected the same value for the same seed, got %v and %v", first, second)
+	}
+}
+
 func TestGenerateInteger(t *testing.T) {
 	g := NewGenerator()
 
@@ -43,7 +62,7 @@ func TestGenerateInteger(t *testing.T) {
 		t.Fatalf("Failed to generate value: %v", err)
 	}
 
-	if _, ok := val.(int); !ok {
+	if _, ok := val.(int64); !ok {
 		t.Errorf("Expected integer, got %T", val)
 	}
 }
@@ -73,9 +92,8 @@ func TestGenerateArray(t *testing.T) {
 	}
 
 	schema := &base.Schema{
-		Type: []string{"array"},
-		Items: &base.DynamicValue[*base.SchemaProxy, bool]{
-		},
+		Type:  []string{"array"},
+		Items: &base.DynamicValue[*base.SchemaProxy, bool]{},
 	}
 
 	// Note: This is a simplified test. Full array generation requires proper Items setup
@@ -113,7 +131,7 @@ func TestGenerateFromFormat(t *testing.T) {
 
 	tests := []struct {
 		format string
-		check func(interface{}) bool
+		check  func(interface{}) bool
 	}{
 		{"email", func(v interface{}) bool {
 			str, ok := v.(string)
@@ -157,3 +175,214 @@ func TestGenerateValueWithExample(t *testing.T) {
 	}
 }
 
+func TestLookupParameter(t *testing.T) {
+	g := NewGenerator()
+	g.SetParamValues(map[string]interface{}{
+		"petId":  []interface{}{1, 2, 3},
+		"status": "available",
+		ParameterPointer("/pets/{petId}", "GET", "status"): "sold",
+	})
+
+	petId := &v3.Parameter{Name: "petId", In: "path"}
+	first, ok := g.LookupParameter("/pets/{petId}", "GET", petId)
+	if !ok {
+		t.Fatal("Expected configured value for petId")
+	}
+
+	// The same value is reused across operations
+	second, _ := g.LookupParameter("/owners/{ownerId}/pets/{petId}", "DELETE", petId)
+	if first != second {
+		t.Errorf("Expected consistent value, got %s and %s", first, second)
+	}
+
+	status := &v3.Parameter{Name: "status", In: "query"}
+	if val, _ := g.LookupParameter("/pets", "GET", status); val != "available" {
+		t.Errorf("Expected name match 'available', got %s", val)
+	}
+	if val, _ := g.LookupParameter("/pets/{petId}", "GET", status); val != "sold" {
+		t.Errorf("Expected pointer match 'sold', got %s", val)
+	}
+
+	if _, ok := g.LookupParameter("/pets", "GET", &v3.Parameter{Name: "limit", In: "query"}); ok {
+		t.Error("Expected no configured value for limit")
+	}
+}
+
+func TestEntityCorrelation(t *testing.T) {
+	g := NewGenerator()
+
+	intSchema := base.CreateSchemaProxy(&base.Schema{Type: []string{"integer"}})
+	petId := &v3.Parameter{Name: "petId", In: "path", Schema: intSchema}
+
+	first, err := g.GeneratePathParameter(petId)
+	if err != nil {
+		t.Fatalf("Failed to generate parameter: %v", err)
+	}
+
+	// The same logical field gets the same value, regardless of spelling
+	petIdSnake := &v3.Parameter{Name: "pet_id", In: "query", Schema: intSchema}
+	second, err := g.GeneratePathParameter(petIdSnake)
+	if err != nil {
+		t.Fatalf("Failed to generate parameter: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("Expected correlated values, got %s and %s", first, second)
+	}
+
+	// A bare {id} is scoped to its resource
+	bareID := &v3.Parameter{Name: "id", In: "path", Schema: intSchema}
+	third, err := g.GeneratePathParameterForPath("/pets/{id}", bareID)
+	if err != nil {
+		t.Fatalf("Failed to generate parameter: %v", err)
+	}
+	if third != first {
+		t.Errorf("Expected /pets/{id} to correlate with petId, got %s and %s", third, first)
+	}
+	g.SetEntityValue("/owners/{id}", "id", 7)
+	if val, _ := g.GeneratePathParameterForPath("/owners/{id}", bareID); val != "7" {
+		t.Errorf("Expected seeded owner id 7, got %s", val)
+	}
+
+	for _, name := range []string{"petId", "pet_id", "PET-ID", "petID", "owner_Id"} {
+		if !isEntityField(name) {
+			t.Errorf("Expected %s to identify an entity", name)
+		}
+	}
+	for _, name := range []string{"id", "paid", "valid", "uuid", "UUID", "void", "_id"} {
+		if isEntityField(name) {
+			t.Errorf("Expected %s not to identify an entity", name)
+		}
+	}
+
+	if entity := resourceEntity("/pets/{petId}"); entity != "pet" {
+		t.Errorf("Expected entity 'pet', got %s", entity)
+	}
+
+	if entity := resourceEntity("/categories"); entity != "category" {
+		t.Errorf("Expected entity 'category', got %s", entity)
+	}
+}
+
+func TestGenerateOpenAPI31Keywords(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/readings", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	schema := opDetails.Responses.Codes.GetOrZero("200").Content.GetOrZero("application/json").Schema.Schema()
+
+	g := NewGenerator()
+	for i := 0; i < 20; i++ {
+		val, err := g.GenerateValue(schema)
+		if err != nil {
+			t.Fatalf("Failed to generate value: %v", err)
+		}
+		reading := val.(map[string]interface{})
+		if reading["kind"] != "temperature" {
+			t.Errorf("Expected the const kind, got %v", reading["kind"])
+		}
+		if reading["sensor"] != "s-17" {
+			t.Errorf("Expected the first example for sensor, got %v", reading["sensor"])
+		}
+		if value := reading["value"].(float64); value <= -273.15 || value >= 1000 {
+			t.Errorf("Expected value within the exclusive bounds, got %v", value)
+		}
+		if location := reading["location"].([]interface{}); len(location) != 2 || location[0] == nil || location[1] == nil {
+			t.Errorf("Expected a latitude and longitude, got %v", location)
+		}
+		if battery, ok := reading["battery"]; ok && battery.(int64) < 1 {
+			t.Errorf("Expected battery above its exclusive minimum 0, got %v", battery)
+		}
+		if _, ok := reading["unit"]; ok {
+			if _, ok := reading["precision"]; !ok {
+				t.Errorf("Expected precision alongside unit, got %v", reading)
+			}
+		}
+	}
+
+	if val, _ := g.GenerateValue(&base.Schema{Type: []string{"null"}}); val != nil {
+		t.Errorf("Expected null for a null type, got %v", val)
+	}
+}
+
+func TestGenerateEnumPicksSeeded(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/readings", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	schema := opDetails.RequestBody.Content.GetOrZero("application/json").Schema.Schema()
+	quality := schema.Properties.GetOrZero("quality").Schema()
+
+	g := NewGenerator()
+	g.Seed(7)
+	seen := make(map[interface{}]bool)
+	var first []interface{}
+	for i := 0; i < 20; i++ {
+		val, _ := g.GenerateValue(quality)
+		seen[val] = true
+		first = append(first, val)
+	}
+	if !seen["raw"] || !seen["calibrated"] || len(seen) != 2 {
+		t.Errorf("Expected both enum values to be picked, got %v", seen)
+	}
+
+	g.Seed(7)
+	for i := 0; i < 20; i++ {
+		if val, _ := g.GenerateValue(quality); val != first[i] {
+			t.Fatalf("Expected the same picks for the same seed")
+		}
+	}
+}
+
+func TestGenerateIntegerFormats(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	schemas, err := p.GetSchemas()
+	if err != nil {
+		t.Fatalf("Failed to get schemas: %v", err)
+	}
+	var counter *base.Schema
+	for _, s := range schemas {
+		if s.Name == "Counter" {
+			counter = s.Schema
+		}
+	}
+	if counter == nil {
+		t.Fatal("Counter schema not found")
+	}
+
+	g := NewGenerator()
+	for i := 0; i < 20; i++ {
+		val, err := g.GenerateValue(counter)
+		if err != nil {
+			t.Fatalf("Failed to generate value: %v", err)
+		}
+		obj := val.(map[string]interface{})
+		// bounds beyond 2^53 are read exactly, not rounded through float64
+		if total := obj["total"].(int64); total < 9007199254740993 || total > 9007199254740995 {
+			t.Errorf("Expected total within its int64 bounds, got %d", total)
+		}
+		// a minimum below the int32 range is clamped to it
+		if delta := obj["delta"].(int64); delta < math.MinInt32 || delta > math.MinInt32+100 {
+			t.Errorf("Expected delta near the int32 minimum, got %d", delta)
+		}
+		if offset := obj["offset"]; offset != json.Number("9223372036854775807") {
+			t.Errorf("Expected the exact example offset, got %v (%T)", offset, offset)
+		}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{"offset": nodeValue(counter.Properties.GetOrZero("offset").Schema().Example)})
+	if string(data) != `{"offset":9223372036854775807}` {
+		t.Errorf("Expected the large example to marshal exactly, got %s", data)
+	}
+}
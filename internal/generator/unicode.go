@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// unicodeSamples are the text fragments of the unicode strategy, each aimed
+// at a class of encoding bug: multi-byte characters miscounted as bytes,
+// astral-plane emoji split into surrogates, right-to-left text, and invisible
+// characters that break comparisons
+var unicodeSamples = []string{
+	"Zoë Ünïcödé façade ñ ß",
+	"日本語のテキスト 한국어 中文",
+	"😀🚀👍🏽👨\u200d👩\u200d👧",
+	"مرحبا بالعالم שלום עולם",
+	"zero\u200bwidth\u200cjoin\u200dmark\ufeff\u2060",
+	"e\u0301 a\u0308 combining n\u0303",
+}
+
+// unicodeLongLength is the length of long strings when the schema sets no
+// maxLength
+const unicodeLongLength = 4096
+
+// SetUnicode switches string generation to the unicode strategy: free-form
+// string fields get multi-byte, emoji, right-to-left and zero-width text, or
+// a string as long as the schema allows, instead of plain ASCII. Examples and
+// defaults of such fields are ignored so the strategy reaches every field.
+func (g *Generator) SetUnicode(enabled bool) {
+	g.unicode = enabled
+}
+
+// isFreeString reports whether a schema is a string without a format, enum or
+// pattern, i.e. one any text is valid for
+func isFreeString(schema *base.Schema) bool {
+	return schemaType(schema) == "string" &&
+		schema.Format == "" && len(schema.Enum) == 0 && schema.Pattern == ""
+}
+
+// generateUnicodeString picks a unicode sample or a long string, repeated or
+// cut to fit the schema's length limits. Lengths count characters (code
+// points), as JSON Schema does, so the value stays valid while its byte
+// length exceeds the character count.
+func (g *Generator) generateUnicodeString(schema *base.Schema) string {
+	minLength, maxLength := 0, -1
+	if schema.MinLength != nil {
+		minLength = int(*schema.MinLength)
+	}
+	if schema.MaxLength != nil {
+		maxLength = int(*schema.MaxLength)
+	}
+
+	// One pick in len+1 is a long string mixing every sample
+	pick := g.rng.Intn(len(unicodeSamples) + 1)
+	var text string
+	var length int
+	if pick == len(unicodeSamples) {
+		text = strings.Join(unicodeSamples, " ")
+		length = unicodeLongLength
+		if maxLength >= 0 {
+			length = maxLength
+		}
+	} else {
+		text = unicodeSamples[pick]
+		length = utf8.RuneCountInString(text)
+		if maxLength >= 0 && length > maxLength {
+			length = maxLength
+		}
+	}
+	if length < minLength {
+		length = minLength
+	}
+
+	return fitRunes(text, length)
+}
+
+// fitRunes repeats or truncates text to exactly n characters
+func fitRunes(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(text)
+	out := make([]rune, 0, n)
+	for len(out) < n {
+		out = append(out, runes[:min(len(runes), n-len(out))]...)
+	}
+	return string(out)
+}
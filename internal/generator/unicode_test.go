@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestUnicodeStringsWithinLimits(t *testing.T) {
+	g := NewGenerator()
+	g.SetUnicode(true)
+
+	minLength, maxLength := int64(3), int64(12)
+	schema := &base.Schema{
+		Type:      []string{"string"},
+		MinLength: &minLength,
+		MaxLength: &maxLength,
+		Example:   &yaml.Node{Kind: yaml.ScalarNode, Value: "plain"},
+	}
+
+	multiByte := false
+	for i := 0; i < 50; i++ {
+		val, err := g.GenerateValue(schema)
+		if err != nil {
+			t.Fatalf("Failed to generate value: %v", err)
+		}
+		str := val.(string)
+		if str == "plain" {
+			t.Fatalf("Expected the example to be bypassed")
+		}
+		if !utf8.ValidString(str) {
+			t.Errorf("Generated invalid UTF-8 %q", str)
+		}
+		if n := utf8.RuneCountInString(str); n < 3 || n > 12 {
+			t.Errorf("Expected 3-12 characters, got %d in %q", n, str)
+		}
+		if len(str) > utf8.RuneCountInString(str) {
+			multiByte = true
+		}
+	}
+	if !multiByte {
+		t.Error("Expected multi-byte characters in generated strings")
+	}
+}
+
+func TestUnicodeLeavesConstrainedStrings(t *testing.T) {
+	g := NewGenerator()
+	g.SetUnicode(true)
+
+	val, err := g.GenerateValue(&base.Schema{Type: []string{"string"}, Format: "email"})
+	if err != nil {
+		t.Fatalf("Failed to generate value: %v", err)
+	}
+	if val != "test@example.com" {
+		t.Errorf("Expected formatted strings to be unaffected, got %v", val)
+	}
+}
+
+func TestFitRunes(t *testing.T) {
+	if got := fitRunes("日本", 5); got != "日本日本日" {
+		t.Errorf("Expected repeated text, got %q", got)
+	}
+	if got := fitRunes("😀🚀👍", 2); got != "😀🚀" {
+		t.Errorf("Expected truncated text, got %q", got)
+	}
+	if got := fitRunes("abc", 0); got != "" {
+		t.Errorf("Expected empty text, got %q", got)
+	}
+}
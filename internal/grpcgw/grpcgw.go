@@ -0,0 +1,356 @@
+// Package grpcgw calls the gRPC services behind a grpc-gateway, so that
+// operations of a spec generated from protobuf definitions can be compared
+// with the method they are transcoded to. Services are discovered through
+// server reflection.
+package grpcgw
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Client calls the methods of a gRPC server
+type Client struct {
+	conn     *grpc.ClientConn
+	services []protoreflect.ServiceDescriptor
+}
+
+// Response is the outcome of a gRPC call
+type Response struct {
+	Code         codes.Code
+	Message      proto.Message // nil unless the call succeeded
+	ResponseTime time.Duration
+}
+
+// Dial connects to a gRPC server and loads its services through server
+// reflection. Without plaintext the connection uses TLS.
+func Dial(target string, plaintext bool, timeout time.Duration) (*Client, error) {
+	creds := credentials.NewTLS(&tls.Config{})
+	if plaintext {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gRPC server %s: %w", target, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	services, err := loadServices(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to list services of %s: %w", target, err)
+	}
+	return &Client{conn: conn, services: services}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Method finds the method an operation is transcoded to. grpc-gateway names
+// operations <Service>_<Method>, with the service's short name.
+func (c *Client) Method(operationID string) (protoreflect.MethodDescriptor, error) {
+	service, method, ok := strings.Cut(operationID, "_")
+	if !ok {
+		return nil, fmt.Errorf("operation ID %q is not of the form Service_Method", operationID)
+	}
+
+	var found protoreflect.MethodDescriptor
+	for _, s := range c.services {
+		if string(s.Name()) != service {
+			continue
+		}
+		if m := s.Methods().ByName(protoreflect.Name(method)); m != nil {
+			if found != nil {
+				return nil, fmt.Errorf("method %s is ambiguous (%s and %s)", operationID, found.FullName(), m.FullName())
+			}
+			found = m
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no gRPC method for operation %s", operationID)
+	}
+	return found, nil
+}
+
+// Invoke calls a unary method with a request built from params, keyed by
+// field path as in path templates and query strings (e.g. message_id or
+// filter.name). Repeated fields take every value.
+func (c *Client) Invoke(ctx context.Context, method protoreflect.MethodDescriptor, params map[string][]string) (*Response, error) {
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("streaming method %s is not supported", method.FullName())
+	}
+
+	req := dynamicpb.NewMessage(method.Input())
+	for name, values := range params {
+		for _, value := range values {
+			if err := setField(req, name, value); err != nil {
+				return nil, fmt.Errorf("failed to set %s: %w", name, err)
+			}
+		}
+	}
+
+	resp := dynamicpb.NewMessage(method.Output())
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	start := time.Now()
+	err := c.conn.Invoke(ctx, fullMethod, req, resp)
+	result := &Response{Code: status.Code(err), ResponseTime: time.Since(start)}
+	if err == nil {
+		result.Message = resp
+	}
+	return result, nil
+}
+
+// DecodeResponse decodes the JSON body the gateway returned as the method's
+// response message
+func DecodeResponse(method protoreflect.MethodDescriptor, body []byte) (proto.Message, error) {
+	msg := dynamicpb.NewMessage(method.Output())
+	if err := protojson.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// HTTPStatus returns the HTTP status grpc-gateway responds with for a gRPC
+// status code
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return 200
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return 400
+	case codes.DeadlineExceeded:
+		return 504
+	case codes.NotFound:
+		return 404
+	case codes.AlreadyExists, codes.Aborted:
+		return 409
+	case codes.PermissionDenied:
+		return 403
+	case codes.Unauthenticated:
+		return 401
+	case codes.ResourceExhausted:
+		return 429
+	case codes.FailedPrecondition:
+		return 400
+	case codes.Unimplemented:
+		return 501
+	case codes.Unavailable:
+		return 503
+	default:
+		return 500
+	}
+}
+
+// setField sets a scalar field, converting the string value to the field's
+// kind. Dotted names address fields of nested messages.
+func setField(msg protoreflect.Message, name, value string) error {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		fields := msg.Descriptor().Fields()
+		fd := fields.ByName(protoreflect.Name(part))
+		if fd == nil {
+			fd = fields.ByJSONName(part)
+		}
+		if fd == nil {
+			return fmt.Errorf("%s has no field %s", msg.Descriptor().FullName(), part)
+		}
+
+		if i < len(parts)-1 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("field %s is not a message", part)
+			}
+			msg = msg.Mutable(fd).Message()
+			continue
+		}
+
+		v, err := scalarValue(fd, value)
+		if err != nil {
+			return err
+		}
+		if fd.IsList() {
+			msg.Mutable(fd).List().Append(v)
+		} else {
+			msg.Set(fd, v)
+		}
+	}
+	return nil
+}
+
+// scalarValue parses a path or query parameter value for a field
+func scalarValue(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(value)), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown %s value %q", fd.Enum().FullName(), value)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("field %s of kind %s can't be set from a parameter", fd.Name(), fd.Kind())
+	}
+}
+
+// loadServices lists the services of a server and builds their descriptors
+// from the files the reflection service returns
+func loadServices(ctx context.Context, conn *grpc.ClientConn) ([]protoreflect.ServiceDescriptor, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	ask := func(req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("reflection stream closed")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e := resp.GetErrorResponse(); e != nil {
+			return nil, fmt.Errorf("reflection error: %s", e.GetErrorMessage())
+		}
+		return resp, nil
+	}
+
+	resp, err := ask(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		if !strings.HasPrefix(s.GetName(), "grpc.reflection.") {
+			names = append(names, s.GetName())
+		}
+	}
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	var order []string
+	addFiles := func(resp *reflectionpb.ServerReflectionResponse) error {
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return fmt.Errorf("invalid file descriptor: %w", err)
+			}
+			if _, ok := files[fd.GetName()]; !ok {
+				files[fd.GetName()] = fd
+				order = append(order, fd.GetName())
+			}
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		resp, err := ask(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: name},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", name, err)
+		}
+		if err := addFiles(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch dependencies the server didn't send along, preferring the
+	// well-known types compiled into this binary
+	for i := 0; i < len(order); i++ {
+		for _, dep := range files[order[i]].GetDependency() {
+			if _, ok := files[dep]; ok {
+				continue
+			}
+			if known, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+				files[dep] = protodesc.ToFileDescriptorProto(known)
+				order = append(order, dep)
+				continue
+			}
+			resp, err := ask(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("file %s: %w", dep, err)
+			}
+			if err := addFiles(resp); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, name := range order {
+		set.File = append(set.File, files[name])
+	}
+	registry, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptors: %w", err)
+	}
+
+	var services []protoreflect.ServiceDescriptor
+	for _, name := range names {
+		d, err := registry.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", name, err)
+		}
+		if s, ok := d.(protoreflect.ServiceDescriptor); ok {
+			services = append(services, s)
+		}
+	}
+	return services, nil
+}
@@ -0,0 +1,190 @@
+package grpcgw
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// messagingFile describes a service as grpc-gateway examples do:
+//
+//	service Messaging { rpc GetMessage(GetMessageRequest) returns (Message); }
+func messagingFile(t *testing.T) *protoregistry.Files {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("demo/messaging.proto"),
+		Package:    proto.String("demo"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("GetMessageRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("message_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("revision", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+				},
+			},
+			{
+				Name: proto.String("Message"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("message_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("text", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("created_at", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Messaging"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("GetMessage"),
+				InputType:  proto.String(".demo.GetMessageRequest"),
+				OutputType: proto.String(".demo.Message"),
+			}},
+		}},
+	}
+
+	files := new(protoregistry.Files)
+	if err := files.RegisterFile(timestamppb.File_google_protobuf_timestamp_proto); err != nil {
+		t.Fatal(err)
+	}
+	file, err := protodesc.NewFile(fd, files)
+	if err != nil {
+		t.Fatalf("Invalid test descriptor: %v", err)
+	}
+	if err := files.RegisterFile(file); err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// startServer serves the Messaging service with reflection. GetMessage
+// echoes the id and revision, and returns NOT_FOUND for the id "missing".
+func startServer(t *testing.T) string {
+	t.Helper()
+	files := messagingFile(t)
+	d, _ := files.FindDescriptorByName("demo.Messaging")
+	method := d.(protoreflect.ServiceDescriptor).Methods().Get(0)
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "demo.Messaging",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "GetMessage",
+			Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := dynamicpb.NewMessage(method.Input())
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				id := req.Get(method.Input().Fields().ByName("message_id")).String()
+				if id == "missing" {
+					return nil, status.Error(codes.NotFound, "no such message")
+				}
+				revision := req.Get(method.Input().Fields().ByName("revision")).Int()
+
+				resp := dynamicpb.NewMessage(method.Output())
+				fields := method.Output().Fields()
+				resp.Set(fields.ByName("message_id"), protoreflect.ValueOfString(id))
+				resp.Set(fields.ByName("text"), protoreflect.ValueOfString("revision "+strconv.FormatInt(revision, 10)))
+				ts := timestamppb.New(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+				resp.Set(fields.ByName("created_at"), protoreflect.ValueOfMessage(ts.ProtoReflect()))
+				return resp, nil
+			},
+		}},
+	}, nil)
+	reflectionpb.RegisterServerReflectionServer(server, reflection.NewServerV1(reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: files,
+	}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(ln)
+	t.Cleanup(server.Stop)
+	return ln.Addr().String()
+}
+
+func TestClient(t *testing.T) {
+	client, err := Dial(startServer(t), true, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	method, err := client.Method("Messaging_GetMessage")
+	if err != nil {
+		t.Fatalf("Expected Messaging_GetMessage to map to a method: %v", err)
+	}
+	if method.FullName() != "demo.Messaging.GetMessage" {
+		t.Errorf("Expected demo.Messaging.GetMessage, got %s", method.FullName())
+	}
+	for _, id := range []string{"Messaging_DeleteMessage", "Chat_GetMessage", "getMessage"} {
+		if _, err := client.Method(id); err == nil {
+			t.Errorf("Expected no method for %s", id)
+		}
+	}
+
+	ctx := context.Background()
+	resp, err := client.Invoke(ctx, method, map[string][]string{"message_id": {"m1"}, "revision": {"2"}})
+	if err != nil {
+		t.Fatalf("Failed to invoke: %v", err)
+	}
+	if resp.Code != codes.OK || resp.Message == nil {
+		t.Fatalf("Expected a message, got %+v", resp)
+	}
+
+	// The gateway's JSON decodes to the same message, whichever field names
+	// and defaults it was rendered with
+	body := `{"messageId": "m1", "text": "revision 2", "createdAt": "2026-01-02T03:04:05Z"}`
+	decoded, err := DecodeResponse(method, []byte(body))
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if !proto.Equal(decoded, resp.Message) {
+		t.Errorf("Expected the gateway body to equal the gRPC response")
+	}
+
+	resp, err = client.Invoke(ctx, method, map[string][]string{"message_id": {"missing"}})
+	if err != nil {
+		t.Fatalf("Failed to invoke: %v", err)
+	}
+	if resp.Code != codes.NotFound || HTTPStatus(resp.Code) != 404 {
+		t.Errorf("Expected NOT_FOUND (404), got %v", resp.Code)
+	}
+
+	if _, err := client.Invoke(ctx, method, map[string][]string{"revision": {"two"}}); err == nil {
+		t.Error("Expected an error for a non-numeric int32 parameter")
+	}
+	if _, err := client.Invoke(ctx, method, map[string][]string{"unknown": {"x"}}); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// cycloneDX is a CycloneDX 1.5 BOM listing the exercised hosts as services
+type cycloneDX struct {
+	BOMFormat   string             `json:"bomFormat"`
+	SpecVersion string             `json:"specVersion"`
+	Version     int                `json:"version"`
+	Metadata    cycloneDXMetadata  `json:"metadata"`
+	Services    []cycloneDXService `json:"services"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cycloneDXComponent `json:"components"`
+	} `json:"tools"`
+}
+
+type cycloneDXComponent struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type cycloneDXService struct {
+	BOMRef        string              `json:"bom-ref"`
+	Name          string              `json:"name"`
+	Endpoints     []string            `json:"endpoints"`
+	Authenticated bool                `json:"authenticated"`
+	Properties    []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// WriteCycloneDX writes the inventory as a CycloneDX 1.5 JSON BOM with a
+// service per host. The service endpoints are the URL templates called, and
+// oas:* properties list each request ("GET /v1/pets/{petId}") with the
+// credentials it carried, the declared security schemes and the remote
+// addresses connected to.
+func WriteCycloneDX(w io.Writer, inv Inventory, toolVersion string, now time.Time) error {
+	bom := cycloneDX{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Services:    []cycloneDXService{},
+	}
+	bom.Metadata.Timestamp = now.UTC().Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []cycloneDXComponent{{Type: "application", Name: "oas", Version: toolVersion}}
+
+	for _, h := range inv.Hosts {
+		svc := cycloneDXService{
+			BOMRef:        h.URL(),
+			Name:          h.Host,
+			Authenticated: h.Authenticated(),
+		}
+		seen := make(map[string]bool)
+		for _, e := range h.Endpoints {
+			if url := h.URL() + e.Path; !seen[url] {
+				seen[url] = true
+				svc.Endpoints = append(svc.Endpoints, url)
+			}
+			request := e.Method + " " + e.Path
+			credentials := "none"
+			if len(e.Credentials) > 0 {
+				credentials = strings.Join(e.Credentials, ",")
+			}
+			svc.Properties = append(svc.Properties,
+				cycloneDXProperty{Name: "oas:request", Value: request},
+				cycloneDXProperty{Name: "oas:credentials", Value: request + " " + credentials},
+			)
+			for _, requirement := range e.Security {
+				value := "none"
+				if len(requirement) > 0 {
+					value = strings.Join(requirement, "+")
+				}
+				svc.Properties = append(svc.Properties, cycloneDXProperty{Name: "oas:security", Value: request + " " + value})
+			}
+		}
+		for _, addr := range h.Addresses {
+			svc.Properties = append(svc.Properties, cycloneDXProperty{Name: "oas:address", Value: addr})
+		}
+		bom.Services = append(bom.Services, svc)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bom)
+}
@@ -0,0 +1,227 @@
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// Credential kinds recognized on outgoing requests, besides the lowercased
+// scheme of an Authorization header (e.g. bearer, basic, digest)
+const (
+	CredentialCookie = "cookie"
+	CredentialSigV4  = "sigv4"
+)
+
+// Inventory lists what a run exercised: the hosts it connected to, the
+// endpoints it called on each and the credentials it sent
+type Inventory struct {
+	Hosts []Host `json:"hosts"`
+}
+
+// Host is a scheme and host:port requests were sent to
+type Host struct {
+	Scheme    string     `json:"scheme"`
+	Host      string     `json:"host"`
+	Addresses []string   `json:"addresses,omitempty"` // remote addresses connected to
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is a method and path called on a host. Paths of spec operations
+// are their templates, other requests (e.g. logins) keep their actual path.
+type Endpoint struct {
+	Method      string     `json:"method"`
+	Path        string     `json:"path"`
+	OperationID string     `json:"operation_id,omitempty"`
+	Requests    int        `json:"requests"`
+	Credentials []string   `json:"credentials,omitempty"` // credential kinds sent, empty for anonymous requests
+	Security    [][]string `json:"security,omitempty"`    // security requirements the spec declares
+}
+
+// Authenticated reports whether any request to the host carried credentials
+func (h Host) Authenticated() bool {
+	for _, e := range h.Endpoints {
+		if len(e.Credentials) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// URL returns the scheme and host, e.g. https://api.example.com
+func (h Host) URL() string {
+	return h.Scheme + "://" + h.Host
+}
+
+type operationKey struct{}
+
+// WithOperation marks a request context with the spec operation it exercises
+func WithOperation(ctx context.Context, op models.Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// Recorder records the requests sent through its transports. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	hosts map[string]*hostEntry
+}
+
+type hostEntry struct {
+	scheme, host string
+	addresses    map[string]bool
+	endpoints    map[string]*endpointEntry
+}
+
+type endpointEntry struct {
+	Endpoint
+	credentials map[string]bool
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{hosts: make(map[string]*hostEntry)}
+}
+
+// Transport wraps a transport so every request sent through it is recorded.
+// Wrapped innermost, it sees the credentials added by other transports and
+// the cookies added by the client.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return recordingTransport{recorder: r, next: next}
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := t.recorder.record(req)
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.recorder.mu.Lock()
+			entry.addresses[info.Conn.RemoteAddr().String()] = true
+			t.recorder.mu.Unlock()
+		},
+	}
+	return t.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// record counts a request and returns the entry of its host
+func (r *Recorder) record(req *http.Request) *hostEntry {
+	method, path := req.Method, req.URL.Path
+	var operationID string
+	var security [][]string
+	if op, ok := req.Context().Value(operationKey{}).(models.Operation); ok {
+		method, operationID, security = op.Method, op.OperationID, op.Security
+		path = templatePath(req.URL.Path, op.Path)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hostKey := req.URL.Scheme + "://" + req.URL.Host
+	h, ok := r.hosts[hostKey]
+	if !ok {
+		h = &hostEntry{
+			scheme:    req.URL.Scheme,
+			host:      req.URL.Host,
+			addresses: make(map[string]bool),
+			endpoints: make(map[string]*endpointEntry),
+		}
+		r.hosts[hostKey] = h
+	}
+	e, ok := h.endpoints[method+" "+path]
+	if !ok {
+		e = &endpointEntry{
+			Endpoint:    Endpoint{Method: method, Path: path, OperationID: operationID, Security: security},
+			credentials: make(map[string]bool),
+		}
+		h.endpoints[method+" "+path] = e
+	}
+	if e.OperationID == "" && e.Security == nil {
+		// e.g. first sent to seed a fixture
+		e.OperationID, e.Security = operationID, security
+	}
+	e.Requests++
+	for _, c := range credentials(req) {
+		e.credentials[c] = true
+	}
+	return h
+}
+
+// Inventory returns what was recorded, sorted by host and endpoint
+func (r *Recorder) Inventory() Inventory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inv := Inventory{Hosts: []Host{}}
+	for _, h := range r.hosts {
+		host := Host{Scheme: h.scheme, Host: h.host, Addresses: sortedKeys(h.addresses)}
+		for _, e := range h.endpoints {
+			endpoint := e.Endpoint
+			endpoint.Credentials = sortedKeys(e.credentials)
+			host.Endpoints = append(host.Endpoints, endpoint)
+		}
+		sort.Slice(host.Endpoints, func(i, j int) bool {
+			if host.Endpoints[i].Path != host.Endpoints[j].Path {
+				return host.Endpoints[i].Path < host.Endpoints[j].Path
+			}
+			return host.Endpoints[i].Method < host.Endpoints[j].Method
+		})
+		inv.Hosts = append(inv.Hosts, host)
+	}
+	sort.Slice(inv.Hosts, func(i, j int) bool { return inv.Hosts[i].URL() < inv.Hosts[j].URL() })
+	return inv
+}
+
+// credentials lists the kinds of credentials a request carries
+func credentials(req *http.Request) []string {
+	var kinds []string
+	if authz := req.Header.Get("Authorization"); authz != "" {
+		scheme, _, _ := strings.Cut(authz, " ")
+		scheme = strings.ToLower(scheme)
+		if scheme == "aws4-hmac-sha256" {
+			scheme = CredentialSigV4
+		}
+		kinds = append(kinds, scheme)
+	}
+	if req.Header.Get("Cookie") != "" {
+		kinds = append(kinds, CredentialCookie)
+	}
+	return kinds
+}
+
+// templatePath keeps the part of the actual path before the operation's
+// path, i.e. the server's base path, and appends the operation's template
+func templatePath(actual, template string) string {
+	if strings.Trim(template, "/") == "" {
+		return actual
+	}
+	actualSegments := strings.Split(strings.Trim(actual, "/"), "/")
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	if len(actualSegments) < len(templateSegments) {
+		return template
+	}
+	base := actualSegments[:len(actualSegments)-len(templateSegments)]
+	if len(base) == 0 {
+		return template
+	}
+	return "/" + strings.Join(base, "/") + template
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return nil
+	}
+	return keys
+}
@@ -0,0 +1,106 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	recorder := NewRecorder()
+	client := &http.Client{Transport: recorder.Transport(http.DefaultTransport)}
+	op := models.Operation{Method: "GET", Path: "/pets/{petId}", OperationID: "showPetById", Security: [][]string{{"bearerAuth"}}}
+
+	for _, id := range []string{"1", "2"} {
+		req, _ := http.NewRequest("GET", server.URL+"/v1/pets/"+id, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := client.Do(req.WithContext(WithOperation(req.Context(), op)))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	req, _ := http.NewRequest("POST", server.URL+"/login", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "x"})
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	inv := recorder.Inventory()
+	if len(inv.Hosts) != 1 {
+		t.Fatalf("Expected one host, got %+v", inv.Hosts)
+	}
+	host := inv.Hosts[0]
+	if host.URL() != server.URL || len(host.Addresses) != 1 || !host.Authenticated() {
+		t.Errorf("Unexpected host: %+v", host)
+	}
+	if len(host.Endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %+v", host.Endpoints)
+	}
+
+	login, pet := host.Endpoints[0], host.Endpoints[1]
+	if login.Method != "POST" || login.Path != "/login" || len(login.Credentials) != 1 || login.Credentials[0] != CredentialCookie {
+		t.Errorf("Unexpected login endpoint: %+v", login)
+	}
+	if pet.Path != "/v1/pets/{petId}" || pet.OperationID != "showPetById" || pet.Requests != 2 ||
+		len(pet.Credentials) != 1 || pet.Credentials[0] != "bearer" || len(pet.Security) != 1 {
+		t.Errorf("Unexpected pet endpoint: %+v", pet)
+	}
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	inv := Inventory{Hosts: []Host{{
+		Scheme:    "https",
+		Host:      "api.example.com",
+		Addresses: []string{"203.0.113.7:443"},
+		Endpoints: []Endpoint{
+			{Method: "GET", Path: "/pets", Requests: 1, Security: [][]string{{}}},
+			{Method: "POST", Path: "/pets", Requests: 1, Credentials: []string{"bearer"}, Security: [][]string{{"bearerAuth"}}},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, inv, "1.2.3", time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteCycloneDX: %v", err)
+	}
+	var bom cycloneDX
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if bom.BOMFormat != "CycloneDX" || bom.Metadata.Timestamp != "2026-01-15T12:00:00Z" || bom.Metadata.Tools.Components[0].Version != "1.2.3" {
+		t.Errorf("Unexpected BOM metadata: %+v", bom)
+	}
+	if len(bom.Services) != 1 {
+		t.Fatalf("Expected one service, got %+v", bom.Services)
+	}
+	svc := bom.Services[0]
+	if svc.Name != "api.example.com" || !svc.Authenticated || len(svc.Endpoints) != 1 || svc.Endpoints[0] != "https://api.example.com/pets" {
+		t.Errorf("Unexpected service: %+v", svc)
+	}
+	props := make(map[string]bool)
+	for _, p := range svc.Properties {
+		props[p.Name+"="+p.Value] = true
+	}
+	for _, want := range []string{
+		"oas:credentials=GET /pets none",
+		"oas:credentials=POST /pets bearer",
+		"oas:security=GET /pets none",
+		"oas:security=POST /pets bearerAuth",
+		"oas:address=203.0.113.7:443",
+	} {
+		if !props[want] {
+			t.Errorf("Missing property %s in %+v", want, svc.Properties)
+		}
+	}
+}
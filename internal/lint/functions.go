@@ -0,0 +1,239 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// check applies a rule function to a value, nil when the field is missing,
+// and returns why it fails or "" if it passes
+type check func(value *yaml.Node) string
+
+// functions are the supported rule functions, named as in Spectral
+var functions = map[string]func(options map[string]interface{}) (check, error){
+	"truthy":      func(map[string]interface{}) (check, error) { return truthy, nil },
+	"falsy":       func(map[string]interface{}) (check, error) { return falsy, nil },
+	"defined":     func(map[string]interface{}) (check, error) { return defined, nil },
+	"undefined":   func(map[string]interface{}) (check, error) { return undefined, nil },
+	"pattern":     newPattern,
+	"enumeration": newEnumeration,
+	"length":      newLength,
+	"casing":      newCasing,
+}
+
+// isTruthy reports whether a value is truthy in the JavaScript sense Spectral
+// rulesets are written for: present, and not false, null, "" or 0
+func isTruthy(value *yaml.Node) bool {
+	if value == nil {
+		return false
+	}
+	if value.Kind != yaml.ScalarNode {
+		return true
+	}
+	switch value.ShortTag() {
+	case "!!null":
+		return false
+	case "!!bool":
+		return value.Value == "true"
+	case "!!int", "!!float":
+		f, err := strconv.ParseFloat(value.Value, 64)
+		return err != nil || f != 0
+	}
+	return value.Value != ""
+}
+
+func truthy(value *yaml.Node) string {
+	if !isTruthy(value) {
+		return "must be truthy"
+	}
+	return ""
+}
+
+func falsy(value *yaml.Node) string {
+	if isTruthy(value) {
+		return "must be falsy"
+	}
+	return ""
+}
+
+func defined(value *yaml.Node) string {
+	if value == nil {
+		return "must be defined"
+	}
+	return ""
+}
+
+func undefined(value *yaml.Node) string {
+	if value != nil {
+		return "must be undefined"
+	}
+	return ""
+}
+
+// newPattern checks strings against the match and notMatch regular
+// expressions. Patterns may be written as /regex/ like in Spectral.
+func newPattern(options map[string]interface{}) (check, error) {
+	compile := func(name string) (*regexp.Regexp, error) {
+		raw, ok := options[name]
+		if !ok {
+			return nil, nil
+		}
+		s := fmt.Sprint(raw)
+		if len(s) >= 2 && strings.HasPrefix(s, "/") {
+			if end := strings.LastIndex(s, "/"); end > 0 {
+				flags := s[end+1:]
+				s = s[1:end]
+				if strings.Contains(flags, "i") {
+					s = "(?i)" + s
+				}
+			}
+		}
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern: %w", name, err)
+		}
+		return re, nil
+	}
+	match, err := compile("match")
+	if err != nil {
+		return nil, err
+	}
+	notMatch, err := compile("notMatch")
+	if err != nil {
+		return nil, err
+	}
+	if match == nil && notMatch == nil {
+		return nil, fmt.Errorf("pattern needs match or notMatch")
+	}
+
+	return func(value *yaml.Node) string {
+		if value == nil || value.Kind != yaml.ScalarNode {
+			return ""
+		}
+		if match != nil && !match.MatchString(value.Value) {
+			return fmt.Sprintf("must match the pattern '%s'", match)
+		}
+		if notMatch != nil && notMatch.MatchString(value.Value) {
+			return fmt.Sprintf("must not match the pattern '%s'", notMatch)
+		}
+		return ""
+	}, nil
+}
+
+// newEnumeration checks that a value is one of the listed values
+func newEnumeration(options map[string]interface{}) (check, error) {
+	raw, ok := options["values"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("enumeration needs a list of values")
+	}
+	values := make([]string, len(raw))
+	for i, v := range raw {
+		values[i] = fmt.Sprint(v)
+	}
+
+	return func(value *yaml.Node) string {
+		if value == nil || value.Kind != yaml.ScalarNode {
+			return ""
+		}
+		for _, v := range values {
+			if value.Value == v {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(values, ", "))
+	}, nil
+}
+
+// newLength checks the length of strings, arrays and objects (number of
+// properties) and the value of numbers against min and max
+func newLength(options map[string]interface{}) (check, error) {
+	bound := func(name string) (*float64, error) {
+		raw, ok := options[name]
+		if !ok {
+			return nil, nil
+		}
+		f, err := strconv.ParseFloat(fmt.Sprint(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("length %s must be a number", name)
+		}
+		return &f, nil
+	}
+	min, err := bound("min")
+	if err != nil {
+		return nil, err
+	}
+	max, err := bound("max")
+	if err != nil {
+		return nil, err
+	}
+	if min == nil && max == nil {
+		return nil, fmt.Errorf("length needs min or max")
+	}
+
+	return func(value *yaml.Node) string {
+		if value == nil {
+			return ""
+		}
+		var n float64
+		switch value.Kind {
+		case yaml.SequenceNode:
+			n = float64(len(value.Content))
+		case yaml.MappingNode:
+			n = float64(len(value.Content) / 2)
+		case yaml.ScalarNode:
+			if tag := value.ShortTag(); tag == "!!int" || tag == "!!float" {
+				n, _ = strconv.ParseFloat(value.Value, 64)
+			} else {
+				n = float64(len([]rune(value.Value)))
+			}
+		default:
+			return ""
+		}
+		if min != nil && n < *min {
+			return fmt.Sprintf("must not be shorter than %v", *min)
+		}
+		if max != nil && n > *max {
+			return fmt.Sprintf("must not be longer than %v", *max)
+		}
+		return ""
+	}, nil
+}
+
+// casings are the patterns of the casing function's types
+var casings = map[string]string{
+	"flat":   `^[a-z][a-z{d}]*$`,
+	"camel":  `^[a-z][a-z{d}]*(?:[A-Z{d}](?:[a-z{d}]+|$))*$`,
+	"pascal": `^[A-Z][a-z{d}]*(?:[A-Z{d}](?:[a-z{d}]+|$))*$`,
+	"kebab":  `^[a-z][a-z{d}]*(?:-[a-z{d}]+)*$`,
+	"cobol":  `^[A-Z][A-Z{d}]*(?:-[A-Z{d}]+)*$`,
+	"snake":  `^[a-z][a-z{d}]*(?:_[a-z{d}]+)*$`,
+	"macro":  `^[A-Z][A-Z{d}]*(?:_[A-Z{d}]+)*$`,
+}
+
+// newCasing checks that strings follow a casing type, e.g. camel or kebab
+func newCasing(options map[string]interface{}) (check, error) {
+	casing := fmt.Sprint(options["type"])
+	pattern, ok := casings[casing]
+	if !ok {
+		return nil, fmt.Errorf("unknown casing type '%s'", casing)
+	}
+	digits := "0-9"
+	if disallow, _ := options["disallowDigits"].(bool); disallow {
+		digits = ""
+	}
+	re := regexp.MustCompile(strings.ReplaceAll(pattern, "{d}", digits))
+
+	return func(value *yaml.Node) string {
+		if value == nil || value.Kind != yaml.ScalarNode || value.Value == "" {
+			return ""
+		}
+		if !re.MatchString(value.Value) {
+			return fmt.Sprintf("must be %s case", casing)
+		}
+		return ""
+	}, nil
+}
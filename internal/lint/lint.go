@@ -0,0 +1,467 @@
+// Package lint checks OpenAPI documents against a ruleset of style rules. The
+// ruleset format is a subset of Spectral's, so existing style guides can be
+// imported: each rule selects nodes with a JSONPath ("given") and checks them,
+// or one of their fields, with a function ("then").
+package lint
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// BuiltinRuleset is the name of the built-in ruleset in extends
+const BuiltinRuleset = "oas"
+
+//go:embed ruleset.yaml
+var builtinRuleset []byte
+
+// Severity is how serious a rule violation is
+type Severity int
+
+// Severities, numbered as in Spectral
+const (
+	SeverityOff   Severity = -1
+	SeverityError Severity = 0
+	SeverityWarn  Severity = 1
+	SeverityInfo  Severity = 2
+	SeverityHint  Severity = 3
+)
+
+var severityNames = map[Severity]string{
+	SeverityOff:   "off",
+	SeverityError: "error",
+	SeverityWarn:  "warn",
+	SeverityInfo:  "info",
+	SeverityHint:  "hint",
+}
+
+// ParseSeverity parses a severity name or Spectral severity number
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(s); err == nil && n >= int(SeverityOff) && n <= int(SeverityHint) {
+		return Severity(n), nil
+	}
+	for sev, name := range severityNames {
+		if s == name {
+			return sev, nil
+		}
+	}
+	switch s {
+	case "warning":
+		return SeverityWarn, nil
+	case "information":
+		return SeverityInfo, nil
+	case "false":
+		return SeverityOff, nil
+	}
+	return 0, fmt.Errorf("invalid severity '%s': must be error, warn, info, hint or off", s)
+}
+
+// String returns the severity's name
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// Rule is a compiled lint rule
+type Rule struct {
+	Name        string
+	Description string
+	Message     string // template with {{error}}, {{description}}, {{path}}, {{property}} and {{value}}
+	Severity    Severity
+	Given       []*Path
+	Then        []Then
+
+	declared Severity // severity in the rule's definition
+}
+
+// Then is a check a rule applies to the nodes it selects
+type Then struct {
+	Field    string // dotted field below the node, "@key" for each of its keys, or "" for the node itself
+	Function string
+	check    check
+}
+
+// Ruleset is a set of rules by name
+type Ruleset struct {
+	Rules []*Rule
+
+	// Skipped lists the rules that were left out because they use features
+	// not supported here, e.g. custom Spectral functions
+	Skipped []string
+}
+
+// Problem is a rule violation
+type Problem struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"-"`
+	Level    string   `json:"severity"`
+	Message  string   `json:"message"`
+	Path     string   `json:"path"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+}
+
+// ruleFile is a ruleset document
+type ruleFile struct {
+	Extends interface{}          `yaml:"extends"`
+	Rules   map[string]yaml.Node `yaml:"rules"`
+}
+
+// ruleDef is a rule as written in a ruleset document
+type ruleDef struct {
+	Description string      `yaml:"description"`
+	Message     string      `yaml:"message"`
+	Severity    interface{} `yaml:"severity"`
+	Given       interface{} `yaml:"given"`
+	Then        yaml.Node   `yaml:"then"`
+}
+
+// thenDef is a check as written in a ruleset document
+type thenDef struct {
+	Field           string                 `yaml:"field"`
+	Function        string                 `yaml:"function"`
+	FunctionOptions map[string]interface{} `yaml:"functionOptions"`
+}
+
+// DefaultRuleset returns the built-in ruleset
+func DefaultRuleset() *Ruleset {
+	rs, err := parseRuleset(builtinRuleset, "")
+	if err != nil {
+		panic(fmt.Sprintf("built-in ruleset: %v", err))
+	}
+	return rs
+}
+
+// LoadRuleset reads a ruleset file. Rulesets it extends are loaded relative
+// to it; "oas" (or "spectral:oas") extends the built-in ruleset.
+func LoadRuleset(path string) (*Ruleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ruleset: %w", err)
+	}
+	return parseRuleset(data, filepath.Dir(path))
+}
+
+// parseRuleset parses a ruleset document, resolving its extends from dir
+func parseRuleset(data []byte, dir string) (*Ruleset, error) {
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset: %w", err)
+	}
+
+	rs := &Ruleset{}
+	for _, ext := range extendsList(file.Extends) {
+		base, err := extendedRuleset(ext.name, dir)
+		if err != nil {
+			return nil, err
+		}
+		if ext.off {
+			for _, r := range base.Rules {
+				r.Severity = SeverityOff
+			}
+		}
+		rs.merge(base)
+	}
+
+	names := make([]string, 0, len(file.Rules))
+	for name := range file.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		node := file.Rules[name]
+
+		// A scalar only changes the severity of an inherited rule; true
+		// restores the severity it was defined with
+		if node.Kind == yaml.ScalarNode {
+			r := rs.rule(name)
+			if node.Value == "true" {
+				if r != nil {
+					r.Severity = r.declared
+				}
+				continue
+			}
+			sev, err := ParseSeverity(node.Value)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", name, err)
+			}
+			if r != nil {
+				r.Severity = sev
+			}
+			continue
+		}
+
+		var def ruleDef
+		if err := node.Decode(&def); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		rule, skip, err := compileRule(name, def)
+		if err != nil {
+			return nil, err
+		}
+		if skip != "" {
+			rs.Skipped = append(rs.Skipped, fmt.Sprintf("%s: %s", name, skip))
+			continue
+		}
+		rs.set(rule)
+	}
+	return rs, nil
+}
+
+// extension is an entry of extends
+type extension struct {
+	name string
+	off  bool // inherited rules are disabled unless re-enabled
+}
+
+// extendsList normalizes extends: a name, a list of names, or [name, mode]
+// pairs as in Spectral
+func extendsList(raw interface{}) []extension {
+	switch v := raw.(type) {
+	case string:
+		return []extension{{name: v}}
+	case []interface{}:
+		var list []extension
+		for _, item := range v {
+			switch e := item.(type) {
+			case string:
+				list = append(list, extension{name: e})
+			case []interface{}:
+				if len(e) > 0 {
+					ext := extension{name: fmt.Sprint(e[0])}
+					if len(e) > 1 && fmt.Sprint(e[1]) == "off" {
+						ext.off = true
+					}
+					list = append(list, ext)
+				}
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// extendedRuleset loads a ruleset named in extends
+func extendedRuleset(name, dir string) (*Ruleset, error) {
+	if name == BuiltinRuleset || name == "spectral:oas" {
+		return DefaultRuleset(), nil
+	}
+	if strings.Contains(name, ":") || strings.HasPrefix(name, "http") {
+		return nil, fmt.Errorf("cannot extend '%s': only the built-in '%s' ruleset and local files are supported", name, BuiltinRuleset)
+	}
+	if !filepath.IsAbs(name) && dir != "" {
+		name = filepath.Join(dir, name)
+	}
+	return LoadRuleset(name)
+}
+
+// compileRule compiles a rule definition. skip is set, rather than an error
+// returned, for rules using features not supported here.
+func compileRule(name string, def ruleDef) (*Rule, string, error) {
+	rule := &Rule{Name: name, Description: def.Description, Message: def.Message, Severity: SeverityWarn}
+	if def.Severity != nil {
+		sev, err := ParseSeverity(fmt.Sprint(def.Severity))
+		if err != nil {
+			return nil, "", fmt.Errorf("rule %s: %w", name, err)
+		}
+		rule.Severity = sev
+	}
+	rule.declared = rule.Severity
+
+	var given []string
+	switch g := def.Given.(type) {
+	case string:
+		given = []string{g}
+	case []interface{}:
+		for _, item := range g {
+			given = append(given, fmt.Sprint(item))
+		}
+	}
+	if len(given) == 0 {
+		return nil, "", fmt.Errorf("rule %s: missing given", name)
+	}
+	for _, g := range given {
+		if strings.HasPrefix(g, "#") {
+			return nil, fmt.Sprintf("path alias %s is not supported", g), nil
+		}
+		path, err := ParsePath(g)
+		if err != nil {
+			return nil, err.Error(), nil
+		}
+		rule.Given = append(rule.Given, path)
+	}
+
+	var thens []thenDef
+	switch def.Then.Kind {
+	case yaml.MappingNode:
+		var t thenDef
+		if err := def.Then.Decode(&t); err != nil {
+			return nil, "", fmt.Errorf("rule %s: %w", name, err)
+		}
+		thens = []thenDef{t}
+	case yaml.SequenceNode:
+		if err := def.Then.Decode(&thens); err != nil {
+			return nil, "", fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	if len(thens) == 0 {
+		return nil, "", fmt.Errorf("rule %s: missing then", name)
+	}
+	for _, t := range thens {
+		factory, ok := functions[t.Function]
+		if !ok {
+			return nil, fmt.Sprintf("function '%s' is not supported", t.Function), nil
+		}
+		if strings.HasPrefix(t.Field, "$") {
+			return nil, fmt.Sprintf("JSONPath field %s is not supported", t.Field), nil
+		}
+		c, err := factory(t.FunctionOptions)
+		if err != nil {
+			return nil, "", fmt.Errorf("rule %s: %w", name, err)
+		}
+		rule.Then = append(rule.Then, Then{Field: t.Field, Function: t.Function, check: c})
+	}
+	return rule, "", nil
+}
+
+// rule returns the rule with a name, or nil
+func (rs *Ruleset) rule(name string) *Rule {
+	for _, r := range rs.Rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// set adds a rule, replacing an inherited rule of the same name
+func (rs *Ruleset) set(rule *Rule) {
+	for i, r := range rs.Rules {
+		if r.Name == rule.Name {
+			rs.Rules[i] = rule
+			return
+		}
+	}
+	rs.Rules = append(rs.Rules, rule)
+}
+
+// merge adds the rules of an extended ruleset
+func (rs *Ruleset) merge(other *Ruleset) {
+	for _, r := range other.Rules {
+		rs.set(r)
+	}
+	rs.Skipped = append(rs.Skipped, other.Skipped...)
+}
+
+// Lint checks a JSON or YAML document against the ruleset. Problems are
+// ordered by their position in the document.
+func (rs *Ruleset) Lint(data []byte) ([]Problem, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	var problems []Problem
+	for _, rule := range rs.Rules {
+		if rule.Severity == SeverityOff {
+			continue
+		}
+		for _, given := range rule.Given {
+			for _, m := range given.find(&root) {
+				for _, then := range rule.Then {
+					problems = append(problems, rule.apply(m, then)...)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems, nil
+}
+
+// apply runs a check on a selected node and returns its violations
+func (rule *Rule) apply(m match, then Then) []Problem {
+	type target struct {
+		value    *yaml.Node // nil if missing
+		at       *yaml.Node // where a violation is reported
+		path     []string
+		property string
+	}
+
+	var targets []target
+	switch {
+	case then.Field == "@key":
+		if m.node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(m.node.Content); i += 2 {
+				key := m.node.Content[i]
+				targets = append(targets, target{value: key, at: key, path: appendPath(m.path, key.Value), property: key.Value})
+			}
+		}
+	case then.Field != "":
+		value, ok := lookup(m.node, then.Field)
+		t := target{at: m.node, path: append(append([]string{}, m.path...), strings.Split(then.Field, ".")...), property: then.Field}
+		if ok {
+			t.value, t.at = value, value
+		}
+		targets = append(targets, t)
+	default:
+		targets = append(targets, target{value: m.node, at: m.node, path: m.path, property: m.key})
+	}
+
+	var problems []Problem
+	for _, t := range targets {
+		failure := then.check(t.value)
+		if failure == "" {
+			continue
+		}
+		path := strings.Join(t.path, ".")
+		problems = append(problems, Problem{
+			Rule:     rule.Name,
+			Severity: rule.Severity,
+			Level:    rule.Severity.String(),
+			Message:  rule.message(failure, path, t.property, t.value),
+			Path:     path,
+			Line:     t.at.Line,
+			Column:   t.at.Column,
+		})
+	}
+	return problems
+}
+
+// message renders the rule's message for a violation
+func (rule *Rule) message(failure, path, property string, value *yaml.Node) string {
+	text := rule.Message
+	if text == "" {
+		text = rule.Description
+	}
+	if text == "" {
+		text = "{{property}} {{error}}"
+		if property == "" {
+			text = "{{error}}"
+		}
+	}
+	var v string
+	if value != nil && value.Kind == yaml.ScalarNode {
+		v = value.Value
+	}
+	return strings.NewReplacer(
+		"{{error}}", failure,
+		"{{description}}", rule.Description,
+		"{{path}}", path,
+		"{{property}}", property,
+		"{{value}}", v,
+	).Replace(text)
+}
@@ -0,0 +1,188 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const spec = `openapi: 3.0.3
+info:
+  title: Pets
+  version: "1"
+paths:
+  /pets/:
+    get:
+      operationId: list_pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+      responses:
+        "200":
+          description: ok
+  /pets/{petId}:
+    delete:
+      operationId: deletePet
+      description: Deletes a pet
+      responses: {}
+`
+
+func lintWith(t *testing.T, ruleset string) []Problem {
+	t.Helper()
+	rs, err := parseRuleset([]byte(ruleset), t.TempDir())
+	if err != nil {
+		t.Fatalf("parseRuleset: %v", err)
+	}
+	problems, err := rs.Lint([]byte(spec))
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	return problems
+}
+
+func rulesOf(problems []Problem) []string {
+	var rules []string
+	for _, p := range problems {
+		rules = append(rules, p.Rule)
+	}
+	return rules
+}
+
+func TestParsePath(t *testing.T) {
+	for _, p := range []string{"$", "$.info", "$.paths[*][get,post]", "$..parameters[?(@.in == 'query')]", "$.tags[0]", "$['paths']"} {
+		if _, err := ParsePath(p); err != nil {
+			t.Errorf("ParsePath(%q): %v", p, err)
+		}
+	}
+	for _, p := range []string{"info", "$.paths[", "$.[?(x)]"} {
+		if _, err := ParsePath(p); err == nil {
+			t.Errorf("Expected ParsePath(%q) to fail", p)
+		}
+	}
+}
+
+func TestDefaultRuleset(t *testing.T) {
+	problems, err := DefaultRuleset().Lint([]byte(spec))
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	got := strings.Join(rulesOf(problems), " ")
+	for _, want := range []string{"info-contact", "operation-description", "path-keys-no-trailing-slash", "operation-tags", "operation-responses", "parameter-description"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected a %s problem, got %s", want, got)
+		}
+	}
+
+	// Problems are reported where they are, in document order
+	for i := 1; i < len(problems); i++ {
+		if problems[i].Line < problems[i-1].Line {
+			t.Fatalf("Problems are not in document order: %v", problems)
+		}
+	}
+}
+
+func TestSpectralRule(t *testing.T) {
+	problems := lintWith(t, `
+rules:
+  operation-id-camel:
+    description: "operationId {{value}} must be camelCase"
+    severity: error
+    given: $.paths[*][*]
+    then:
+      field: operationId
+      function: casing
+      functionOptions:
+        type: camel
+`)
+	if len(problems) != 1 {
+		t.Fatalf("Expected one problem, got %v", problems)
+	}
+	p := problems[0]
+	if p.Severity != SeverityError || p.Line != 8 || p.Path != "paths./pets/.get.operationId" {
+		t.Errorf("Unexpected problem %+v", p)
+	}
+	if p.Message != "operationId list_pets must be camelCase" {
+		t.Errorf("Unexpected message %q", p.Message)
+	}
+}
+
+func TestFilterAndKeyRules(t *testing.T) {
+	problems := lintWith(t, `
+rules:
+  query-params-short:
+    given: $..parameters[?(@.in == 'query')]
+    then:
+      field: name
+      function: length
+      functionOptions:
+        max: 3
+  no-trailing-slash:
+    given: $.paths
+    then:
+      field: "@key"
+      function: pattern
+      functionOptions:
+        notMatch: /\/$/
+`)
+	got := strings.Join(rulesOf(problems), " ")
+	if got != "no-trailing-slash query-params-short" {
+		t.Errorf("Unexpected problems %s", got)
+	}
+}
+
+func TestExtendsAndOverrides(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	os.WriteFile(base, []byte(`
+extends: spectral:oas
+rules:
+  info-contact: off
+  operation-description: error
+  custom-rule:
+    given: $.paths[*][*]
+    then:
+      function: oasOpSuccessResponse
+`), 0o644)
+
+	rs, err := LoadRuleset(base)
+	if err != nil {
+		t.Fatalf("LoadRuleset: %v", err)
+	}
+	if r := rs.rule("info-contact"); r == nil || r.Severity != SeverityOff {
+		t.Errorf("Expected info-contact to be turned off")
+	}
+	if r := rs.rule("operation-description"); r == nil || r.Severity != SeverityError {
+		t.Errorf("Expected operation-description to be an error")
+	}
+	if len(rs.Skipped) != 1 || !strings.HasPrefix(rs.Skipped[0], "custom-rule") {
+		t.Errorf("Expected the rule with a custom function to be skipped, got %v", rs.Skipped)
+	}
+
+	// A ruleset extending another relative to its own directory
+	child := filepath.Join(dir, "child.yaml")
+	os.WriteFile(child, []byte("extends: [[base.yaml, off]]\nrules:\n  operation-tags: true\n"), 0o644)
+	rs, err = LoadRuleset(child)
+	if err != nil {
+		t.Fatalf("LoadRuleset: %v", err)
+	}
+	for _, r := range rs.Rules {
+		enabled := r.Severity != SeverityOff
+		if enabled != (r.Name == "operation-tags") {
+			t.Errorf("Rule %s has severity %s", r.Name, r.Severity)
+		}
+	}
+}
+
+func TestInvalidRuleset(t *testing.T) {
+	for _, ruleset := range []string{
+		"rules:\n  r:\n    severity: fatal\n    given: $\n    then: {function: truthy}\n",
+		"rules:\n  r:\n    given: $\n    then: {function: pattern}\n",
+		"extends: https://example.com/ruleset.yaml\n",
+	} {
+		if _, err := parseRuleset([]byte(ruleset), ""); err == nil {
+			t.Errorf("Expected ruleset to be rejected:\n%s", ruleset)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package lint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// match is a node selected by a path, with the location it was found at
+type match struct {
+	node *yaml.Node
+	key  string   // property name or index the node is stored under
+	path []string // keys from the document root
+}
+
+// selector is a step of a compiled path
+type selector struct {
+	names     []string // child names or indexes; nil with wildcard
+	wildcard  bool     // every child
+	recursive bool     // descendants at any depth, not just children
+	filter    *filter  // children matching a filter expression
+}
+
+// filter is a [?(@.field == 'value')] expression. Without an operator it
+// checks that the field exists.
+type filter struct {
+	field string
+	op    string // "==", "!=" or "" for existence
+	value string
+}
+
+// Path is a compiled JSONPath subset: $, .name, ['name'], [0], [a,b], * and
+// [*], recursive descent (..name, ..*) and simple filters such as
+// [?(@.in == 'query')] or [?(@.deprecated)]
+type Path struct {
+	source    string
+	selectors []selector
+}
+
+// ParsePath compiles a JSONPath expression
+func ParsePath(source string) (*Path, error) {
+	s := strings.TrimSpace(source)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("invalid path '%s': must start with $", source)
+	}
+	p := &Path{source: source}
+	rest := s[1:]
+	for rest != "" {
+		var sel selector
+		var err error
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			sel, rest, err = parseDotSelector(rest[2:])
+			sel.recursive = true
+		case rest[0] == '.':
+			sel, rest, err = parseDotSelector(rest[1:])
+		case rest[0] == '[':
+			sel, rest, err = parseBracketSelector(rest)
+		default:
+			err = fmt.Errorf("unexpected '%s'", rest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid path '%s': %w", source, err)
+		}
+		p.selectors = append(p.selectors, sel)
+	}
+	return p, nil
+}
+
+// parseDotSelector parses the name or * after a dot
+func parseDotSelector(s string) (selector, string, error) {
+	if strings.HasPrefix(s, "[") {
+		return parseBracketSelector(s)
+	}
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		end = len(s)
+	}
+	name := s[:end]
+	if name == "" {
+		return selector{}, "", fmt.Errorf("missing name after '.'")
+	}
+	if name == "*" {
+		return selector{wildcard: true}, s[end:], nil
+	}
+	return selector{names: []string{name}}, s[end:], nil
+}
+
+// parseBracketSelector parses a [...] selector
+func parseBracketSelector(s string) (selector, string, error) {
+	end := closingBracket(s)
+	if end < 0 {
+		return selector{}, "", fmt.Errorf("unclosed '['")
+	}
+	inner := strings.TrimSpace(s[1:end])
+	rest := s[end+1:]
+
+	if inner == "*" {
+		return selector{wildcard: true}, rest, nil
+	}
+	if strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")") {
+		f, err := parseFilter(inner[2 : len(inner)-1])
+		if err != nil {
+			return selector{}, "", err
+		}
+		return selector{filter: f}, rest, nil
+	}
+
+	var names []string
+	for _, part := range strings.Split(inner, ",") {
+		name := unquote(strings.TrimSpace(part))
+		if name == "" {
+			return selector{}, "", fmt.Errorf("empty name in '[%s]'", inner)
+		}
+		names = append(names, name)
+	}
+	return selector{names: names}, rest, nil
+}
+
+// closingBracket returns the index of the ] closing the [ at the start of s,
+// skipping brackets inside quotes
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseFilter parses the expression inside [?(...)]
+func parseFilter(expr string) (*filter, error) {
+	expr = strings.TrimSpace(expr)
+	for _, op := range []string{"==", "!="} {
+		if left, right, ok := strings.Cut(expr, op); ok {
+			field, ok := strings.CutPrefix(strings.TrimSpace(left), "@.")
+			if !ok {
+				return nil, fmt.Errorf("filter '%s' must compare a field of @", expr)
+			}
+			return &filter{field: field, op: op, value: unquote(strings.TrimSpace(right))}, nil
+		}
+	}
+	field, ok := strings.CutPrefix(expr, "@.")
+	if !ok {
+		return nil, fmt.Errorf("unsupported filter '%s'", expr)
+	}
+	return &filter{field: field}, nil
+}
+
+// unquote strips the quotes around a name
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// String returns the path as written
+func (p *Path) String() string {
+	return p.source
+}
+
+// find returns the nodes the path selects in a document
+func (p *Path) find(root *yaml.Node) []match {
+	matches := []match{{node: resolve(root)}}
+	for _, sel := range p.selectors {
+		var next []match
+		for _, m := range matches {
+			next = append(next, sel.apply(m)...)
+		}
+		matches = next
+	}
+	return matches
+}
+
+// apply returns the nodes a selector selects from a node
+func (sel selector) apply(m match) []match {
+	var found []match
+	for _, child := range children(m) {
+		if sel.selects(child) {
+			found = append(found, child)
+		}
+		if sel.recursive {
+			found = append(found, sel.apply(child)...)
+		}
+	}
+	return found
+}
+
+// selects reports whether a child is selected
+func (sel selector) selects(child match) bool {
+	switch {
+	case sel.wildcard:
+		return true
+	case sel.filter != nil:
+		return sel.filter.matches(child.node)
+	}
+	for _, name := range sel.names {
+		if name == child.key {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether a node passes the filter
+func (f *filter) matches(node *yaml.Node) bool {
+	value, ok := lookup(node, f.field)
+	switch f.op {
+	case "==":
+		return ok && value.Kind == yaml.ScalarNode && value.Value == f.value
+	case "!=":
+		return !ok || value.Kind != yaml.ScalarNode || value.Value != f.value
+	}
+	return ok
+}
+
+// children returns the properties of a mapping or the items of a sequence
+func children(m match) []match {
+	node := m.node
+	var found []match
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			found = append(found, match{node: resolve(node.Content[i+1]), key: key, path: appendPath(m.path, key)})
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := strconv.Itoa(i)
+			found = append(found, match{node: resolve(item), key: key, path: appendPath(m.path, key)})
+		}
+	}
+	return found
+}
+
+// lookup returns the node at a dotted field path below node
+func lookup(node *yaml.Node, field string) (*yaml.Node, bool) {
+	m := match{node: resolve(node)}
+	for _, name := range strings.Split(field, ".") {
+		var next *match
+		for _, child := range children(m) {
+			if child.key == name {
+				next = &child
+				break
+			}
+		}
+		if next == nil {
+			return nil, false
+		}
+		m = *next
+	}
+	return m.node, true
+}
+
+// resolve follows document nodes and aliases to the value they stand for
+func resolve(node *yaml.Node) *yaml.Node {
+	for node != nil && (node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else if len(node.Content) > 0 {
+			node = node.Content[0]
+		} else {
+			return node
+		}
+	}
+	return node
+}
+
+// appendPath returns a copy of path with key appended
+func appendPath(path []string, key string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), key)
+}
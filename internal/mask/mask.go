@@ -0,0 +1,136 @@
+package mask
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces secret values in output
+const Placeholder = "****"
+
+// minSecretLength avoids masking trivially short values such as "1" or "on",
+// which would mangle unrelated output
+const minSecretLength = 4
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// Register marks values as secret so they are masked in output
+func Register(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range values {
+		if len(v) < minSecretLength {
+			continue
+		}
+		secrets = append(secrets, v)
+	}
+	// Mask longer secrets first so overlapping values are fully hidden
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// String replaces every registered secret in s with the placeholder
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	return s
+}
+
+// Bytes replaces every registered secret in b with the placeholder
+func Bytes(b []byte) []byte {
+	mu.RLock()
+	empty := len(secrets) == 0
+	mu.RUnlock()
+	if empty {
+		return b
+	}
+	return []byte(String(string(b)))
+}
+
+// Value returns a copy of v with registered secrets masked in every string it
+// holds, following struct fields, slices, maps and pointers. Masking values
+// before they are encoded, rather than the encoded output, leaves numbers and
+// other data that happen to equal a secret intact and catches secrets the
+// encoding escapes.
+func Value[T any](v T) T {
+	mu.RLock()
+	empty := len(secrets) == 0
+	mu.RUnlock()
+	if empty {
+		return v
+	}
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	maskValue(dst, src)
+	return dst.Interface().(T)
+}
+
+var numberType = reflect.TypeFor[json.Number]()
+
+// maskValue stores a masked copy of src in dst
+func maskValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.String:
+		if src.Type() == numberType {
+			dst.Set(src)
+			return
+		}
+		dst.SetString(String(src.String()))
+	case reflect.Struct:
+		// Copy first, so unexported fields such as those of time.Time survive
+		dst.Set(src)
+		for i := range src.NumField() {
+			if dst.Field(i).CanSet() {
+				maskValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := range src.Len() {
+			maskValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := range src.Len() {
+			maskValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			key := reflect.New(src.Type().Key()).Elem()
+			maskValue(key, iter.Key())
+			elem := reflect.New(src.Type().Elem()).Elem()
+			maskValue(elem, iter.Value())
+			dst.SetMapIndex(key, elem)
+		}
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		maskValue(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		maskValue(elem, src.Elem())
+		dst.Set(elem)
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,62 @@
+package mask
+
+import "testing"
+
+func TestString(t *testing.T) {
+	Register("s3cr3t-token", "s3cr3t", "on")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Authorization: Bearer s3cr3t-token", "Authorization: Bearer ****"},
+		{"password=s3cr3t", "password=****"},
+		{"feature is on", "feature is on"},
+		{"nothing to hide", "nothing to hide"},
+	}
+
+	for _, tt := range tests {
+		if got := String(tt.input); got != tt.expected {
+			t.Errorf("String(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	if got := string(Bytes([]byte(`{"token":"s3cr3t-token"}`))); got != `{"token":"****"}` {
+		t.Errorf("Bytes did not mask secret, got %s", got)
+	}
+}
+
+func TestValue(t *testing.T) {
+	Register("hunter22")
+
+	type result struct {
+		Error   string
+		Port    int
+		Headers map[string][]string
+		Cause   *string
+		Extra   any
+	}
+	cause := "wrong password hunter22"
+	in := result{
+		Error:   "login hunter22 failed",
+		Port:    8080,
+		Headers: map[string][]string{"Authorization": {"Basic hunter22"}},
+		Cause:   &cause,
+		Extra:   []string{"hunter22"},
+	}
+	got := Value(in)
+
+	if got.Error != "login **** failed" || got.Port != 8080 {
+		t.Errorf("Unexpected fields: %+v", got)
+	}
+	if got.Headers["Authorization"][0] != "Basic ****" {
+		t.Errorf("Map values not masked: %v", got.Headers)
+	}
+	if *got.Cause != "wrong password ****" || got.Extra.([]string)[0] != Placeholder {
+		t.Errorf("Pointer or interface not masked: %q %v", *got.Cause, got.Extra)
+	}
+	// The input is left untouched
+	if in.Error != "login hunter22 failed" || cause != "wrong password hunter22" || in.Headers["Authorization"][0] != "Basic hunter22" {
+		t.Errorf("Value modified its input: %+v", in)
+	}
+}
@@ -0,0 +1,155 @@
+package mock
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// Latency distributions of a ChaosRule
+const (
+	LatencyFixed       = "fixed"       // always Mean
+	LatencyUniform     = "uniform"     // evenly between Min and Max
+	LatencyNormal      = "normal"      // around Mean with StdDev
+	LatencyExponential = "exponential" // mostly short with a long tail, averaging Mean
+)
+
+// Latency delays the responses of a route
+type Latency struct {
+	Distribution string        // one of the Latency* constants (default LatencyFixed)
+	Mean         time.Duration // fixed delay, or the mean of normal and exponential delays
+	StdDev       time.Duration // standard deviation of normal delays
+	Min, Max     time.Duration // bounds of uniform delays, and clamps for the others (Max 0 = unbounded)
+}
+
+// ChaosRule injects faults into the responses of the mock server, so clients
+// can be tested for resilience against the same spec-driven mock
+type ChaosRule struct {
+	Route string // operation id or "METHOD /path" as in the spec; empty matches every route
+
+	Latency     Latency // delay before answering
+	ErrorRate   float64 // share of requests answered with ErrorStatus (0 to 1)
+	ErrorStatus int     // status of injected errors (default 500)
+	ResetRate   float64 // share of requests whose connection is reset without a response (0 to 1)
+}
+
+// Validate checks that the rates and the latency distribution are valid
+func (r ChaosRule) Validate() error {
+	switch r.Latency.Distribution {
+	case "", LatencyFixed, LatencyUniform, LatencyNormal, LatencyExponential:
+	default:
+		return fmt.Errorf("chaos %q: unknown latency distribution %q", r.Route, r.Latency.Distribution)
+	}
+	if r.Latency.Max > 0 && r.Latency.Max < r.Latency.Min {
+		return fmt.Errorf("chaos %q: latency max %s is below min %s", r.Route, r.Latency.Max, r.Latency.Min)
+	}
+	if r.ErrorRate < 0 || r.ErrorRate > 1 {
+		return fmt.Errorf("chaos %q: error rate %v must be between 0 and 1", r.Route, r.ErrorRate)
+	}
+	if r.ResetRate < 0 || r.ResetRate > 1 {
+		return fmt.Errorf("chaos %q: reset rate %v must be between 0 and 1", r.Route, r.ResetRate)
+	}
+	if r.ErrorStatus != 0 && (r.ErrorStatus < 100 || r.ErrorStatus > 599) {
+		return fmt.Errorf("chaos %q: invalid error status %d", r.Route, r.ErrorStatus)
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to an operation
+func (r ChaosRule) matches(details *parser.OperationDetails) bool {
+	if r.Route == "" {
+		return true
+	}
+	if details.Operation != nil && details.Operation.OperationId != "" && strings.EqualFold(r.Route, details.Operation.OperationId) {
+		return true
+	}
+	return strings.EqualFold(r.Route, details.Method+" "+details.Path)
+}
+
+// SetChaos replaces the fault injection rules. The first rule matching a
+// route applies to it. Rules can be changed while the server is running,
+// e.g. to let a test recover from an outage.
+func (s *Server) SetChaos(rules []ChaosRule) error {
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chaos = append([]ChaosRule(nil), rules...)
+	return nil
+}
+
+// fault is what a chaos rule decided for one request
+type fault struct {
+	delay  time.Duration
+	reset  bool
+	status int // injected error status, 0 for none
+}
+
+// decideFault rolls the dice of the first chaos rule matching an operation
+func (s *Server) decideFault(details *parser.OperationDetails) fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.chaos {
+		if !r.matches(details) {
+			continue
+		}
+		f := fault{delay: r.Latency.sample(s.rng)}
+		switch roll := s.rng.Float64(); {
+		case roll < r.ResetRate:
+			f.reset = true
+		case roll < r.ResetRate+r.ErrorRate:
+			f.status = r.ErrorStatus
+			if f.status == 0 {
+				f.status = http.StatusInternalServerError
+			}
+		}
+		return f
+	}
+	return fault{}
+}
+
+// sample draws a delay from the distribution
+func (l Latency) sample(rng *rand.Rand) time.Duration {
+	var d float64
+	switch l.Distribution {
+	case LatencyUniform:
+		d = float64(l.Min) + rng.Float64()*float64(l.Max-l.Min)
+	case LatencyNormal:
+		d = float64(l.Mean) + rng.NormFloat64()*float64(l.StdDev)
+	case LatencyExponential:
+		d = rng.ExpFloat64() * float64(l.Mean)
+	default:
+		d = float64(l.Mean)
+	}
+	d = math.Max(d, float64(l.Min))
+	if l.Max > 0 {
+		d = math.Min(d, float64(l.Max))
+	}
+	return time.Duration(d)
+}
+
+// resetConnection closes the client connection without a response. Over TCP
+// the close sends a reset, as a crashed server or a dropping proxy would.
+func resetConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
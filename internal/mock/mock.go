@@ -0,0 +1,327 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/generator"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// Server answers requests for the operations of a spec with responses
+// generated from the spec: the documented example if there is one, and a
+// value generated from the response schema otherwise
+type Server struct {
+	routes     []route
+	operations []models.Operation // in spec order, for coverage
+
+	mu        sync.Mutex // guards the generator, the fault injection and the recorded calls
+	generator *generator.Generator
+	chaos     []ChaosRule
+	rng       *rand.Rand
+	calls     map[string]*callStats // by "METHOD /path"
+}
+
+// Config configures a mock server
+type Config struct {
+	Chaos []ChaosRule // fault injection rules (see SetChaos)
+	Seed  int64       // seed of the fault injection dice (default: random)
+}
+
+// route is an operation the server answers
+type route struct {
+	method   string
+	segments []string // path template split at "/"
+	literals int      // segments without parameters, preferred when several routes match
+	details  *parser.OperationDetails
+}
+
+// NewServer builds a mock server for every operation of a spec
+func NewServer(p *parser.Parser) (*Server, error) {
+	return NewServerWithConfig(p, Config{})
+}
+
+// NewServerWithConfig builds a mock server for every operation of a spec,
+// injecting the configured faults
+func NewServerWithConfig(p *parser.Parser, config Config) (*Server, error) {
+	operations, err := p.GetOperations("")
+	if err != nil {
+		return nil, err
+	}
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := &Server{
+		operations: operations,
+		generator:  generator.NewGenerator(),
+		rng:        rand.New(rand.NewSource(seed)),
+		calls:      make(map[string]*callStats),
+	}
+	if err := s.SetChaos(config.Chaos); err != nil {
+		return nil, err
+	}
+	for _, op := range operations {
+		details, err := p.GetOperationDetails(op.Path, op.Method)
+		if err != nil {
+			return nil, err
+		}
+		r := route{method: op.Method, segments: splitPath(op.Path), details: details}
+		for _, seg := range r.segments {
+			if !strings.HasPrefix(seg, "{") {
+				r.literals++
+			}
+		}
+		s.routes = append(s.routes, r)
+	}
+
+	// Prefer literal segments, so /pets/mine wins over /pets/{petId}
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		if s.routes[i].literals != s.routes[j].literals {
+			return s.routes[i].literals > s.routes[j].literals
+		}
+		return len(s.routes[i].segments) > len(s.routes[j].segments)
+	})
+	return s, nil
+}
+
+// ServeHTTP answers a request with the generated response of its operation.
+// Paths are matched against the end of the request path, so the server
+// works behind the base path of the spec's server URL.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	details, allowed := s.match(r.Method, splitPath(r.URL.Path))
+	if details == nil {
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	if f := s.decideFault(details); f != (fault{}) {
+		if f.delay > 0 {
+			timer := time.NewTimer(f.delay)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				s.record(details, 0)
+				return
+			}
+		}
+		if f.reset {
+			s.record(details, 0)
+			resetConnection(w)
+			return
+		}
+		if f.status != 0 {
+			s.record(details, s.respond(w, r, f.status, documentedResponse(details.Responses, f.status)))
+			return
+		}
+	}
+
+	status, response := successResponse(details.Responses)
+	s.record(details, s.respond(w, r, status, response))
+}
+
+// respond writes a response with its documented headers and a generated body,
+// and returns the status sent
+func (s *Server) respond(w http.ResponseWriter, r *http.Request, status int, response *v3.Response) int {
+	if response != nil && response.Headers != nil {
+		for pair := response.Headers.First(); pair != nil; pair = pair.Next() {
+			if value, ok := s.headerValue(pair.Value()); ok {
+				w.Header().Set(pair.Key(), value)
+			}
+		}
+	}
+
+	body, contentType, err := s.body(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return http.StatusInternalServerError
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+	return status
+}
+
+// match finds the operation of a request, or the methods the path allows
+func (s *Server) match(method string, segments []string) (*parser.OperationDetails, []string) {
+	var allowed []string
+	for _, r := range s.routes {
+		if !matchSegments(r.segments, segments) {
+			continue
+		}
+		if r.method == method {
+			return r.details, nil
+		}
+		allowed = append(allowed, r.method)
+	}
+	return nil, allowed
+}
+
+// matchSegments reports whether a path template matches the end of a path
+func matchSegments(template, path []string) bool {
+	if len(path) < len(template) {
+		return false
+	}
+	path = path[len(path)-len(template):]
+	for i, seg := range template {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if path[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// successResponse picks the response a mock answers with: the lowest
+// documented 2xx status, then a 2XX range, then default
+func successResponse(responses *v3.Responses) (int, *v3.Response) {
+	if responses == nil {
+		return http.StatusOK, nil
+	}
+
+	best, bestCode := (*v3.Response)(nil), 0
+	if responses.Codes != nil {
+		for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+			code, err := strconv.Atoi(pair.Key())
+			if err == nil && code >= 200 && code < 300 && (best == nil || code < bestCode) {
+				best, bestCode = pair.Value(), code
+			}
+		}
+		if best != nil {
+			return bestCode, best
+		}
+		for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+			if strings.EqualFold(pair.Key(), "2XX") {
+				return http.StatusOK, pair.Value()
+			}
+		}
+	}
+	return http.StatusOK, responses.Default
+}
+
+// documentedResponse returns the response documented for a status: its own,
+// its range (e.g. 5XX), or default. Injected errors answer with it, so
+// clients get the error body the spec promises.
+func documentedResponse(responses *v3.Responses, status int) *v3.Response {
+	if responses == nil {
+		return nil
+	}
+	if responses.Codes != nil {
+		if response, ok := responses.Codes.Get(strconv.Itoa(status)); ok {
+			return response
+		}
+		if response, ok := responses.Codes.Get(fmt.Sprintf("%dXX", status/100)); ok {
+			return response
+		}
+	}
+	return responses.Default
+}
+
+// headerValue generates the value of a documented response header
+func (s *Server) headerValue(header *v3.Header) (string, bool) {
+	if header == nil {
+		return "", false
+	}
+	if header.Example != nil {
+		return nodeString(header.Example), true
+	}
+	if header.Schema == nil || header.Schema.Schema() == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, err := s.generator.GenerateValue(header.Schema.Schema())
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// body generates the body of a response, preferring a JSON media type
+func (s *Server) body(response *v3.Response) ([]byte, string, error) {
+	if response == nil || response.Content == nil || response.Content.Len() == 0 {
+		return nil, "", nil
+	}
+
+	contentType, media := "", (*v3.MediaType)(nil)
+	for pair := response.Content.First(); pair != nil; pair = pair.Next() {
+		if media == nil || (strings.Contains(pair.Key(), "json") && !strings.Contains(contentType, "json")) {
+			contentType, media = pair.Key(), pair.Value()
+		}
+	}
+	if !strings.Contains(contentType, "json") {
+		// Only JSON bodies are generated
+		return nil, contentType, nil
+	}
+
+	var value interface{}
+	switch {
+	case media.Example != nil:
+		value = nodeValue(media.Example)
+	case media.Examples != nil && media.Examples.Len() > 0:
+		if example := media.Examples.First().Value(); example != nil && example.Value != nil {
+			value = nodeValue(example.Value)
+		}
+	case media.Schema != nil && media.Schema.Schema() != nil:
+		s.mu.Lock()
+		generated, err := s.generator.GenerateValue(media.Schema.Schema())
+		s.mu.Unlock()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to generate response: %w", err)
+		}
+		value = generated
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode response: %w", err)
+	}
+	return body, contentType, nil
+}
+
+// nodeValue decodes an example into a plain Go value
+func nodeValue(node *yaml.Node) interface{} {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	return value
+}
+
+// nodeString formats an example as a header value
+func nodeString(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	return fmt.Sprint(nodeValue(node))
+}
@@ -0,0 +1,193 @@
+package mock
+
+import (
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+func TestServerConformsToSpec(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	ts, err := NewTestServer(p, tester.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewTestServer: %v", err)
+	}
+	defer ts.Close()
+
+	if ts.BaseURL != ts.URL+"/v1" {
+		t.Errorf("Expected the spec's base path on the server URL, got %s", ts.BaseURL)
+	}
+	summary := ts.Tester.TestOperations(ts.Operations, p, nil)
+	if summary.TotalTests != 3 || summary.Failed != 0 {
+		for _, r := range summary.Results {
+			t.Logf("%s %s: %s", r.Method, r.Path, r.Error)
+		}
+		t.Fatalf("Expected the mock to pass its own spec, got %d of %d failed", summary.Failed, summary.TotalTests)
+	}
+}
+
+func TestServerRouting(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	ts, err := NewTestServer(p, tester.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewTestServer: %v", err)
+	}
+	defer ts.Close()
+
+	tests := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantAllow string
+	}{
+		{"GET", "/v1/pets", http.StatusOK, ""},
+		{"GET", "/pets/42", http.StatusOK, ""},
+		{"POST", "/v1/pets", http.StatusCreated, ""},
+		{"DELETE", "/v1/pets", http.StatusMethodNotAllowed, "GET, POST"},
+		{"GET", "/v1/owners", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantCode || resp.Header.Get("Allow") != tt.wantAllow {
+			t.Errorf("%s %s: expected %d (Allow %q), got %d (Allow %q)", tt.method, tt.path, tt.wantCode, tt.wantAllow, resp.StatusCode, resp.Header.Get("Allow"))
+		}
+	}
+}
+
+func TestServerChaos(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	ts, err := NewTestServer(p, tester.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewTestServer: %v", err)
+	}
+	defer ts.Close()
+
+	err = ts.Mock.SetChaos([]ChaosRule{
+		{Route: "listPets", ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+		{Route: "GET /pets/{petId}", ResetRate: 1},
+		{Latency: Latency{Mean: 50 * time.Millisecond}},
+	})
+	if err != nil {
+		t.Fatalf("SetChaos: %v", err)
+	}
+
+	resp, err := http.Get(ts.BaseURL + "/pets")
+	if err != nil {
+		t.Fatalf("GET /pets: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected injected 503, got %d", resp.StatusCode)
+	}
+
+	if resp, err := http.Get(ts.BaseURL + "/pets/1"); err == nil {
+		resp.Body.Close()
+		t.Errorf("Expected a reset connection, got %d", resp.StatusCode)
+	}
+
+	start := time.Now()
+	resp, err = http.Post(ts.BaseURL+"/pets", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /pets: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected a delayed 201, got %d after %s", resp.StatusCode, elapsed)
+	}
+
+	// Clearing the rules restores normal responses
+	ts.Mock.SetChaos(nil)
+	resp, err = http.Get(ts.BaseURL + "/pets")
+	if err != nil {
+		t.Fatalf("GET /pets: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 without chaos, got %d", resp.StatusCode)
+	}
+}
+
+func TestChaosRuleValidate(t *testing.T) {
+	invalid := []ChaosRule{
+		{ErrorRate: 1.5},
+		{ResetRate: -0.1},
+		{ErrorStatus: 42},
+		{Latency: Latency{Distribution: "pareto"}},
+		{Latency: Latency{Min: time.Second, Max: time.Millisecond}},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", r)
+		}
+	}
+	if err := (ChaosRule{Latency: Latency{Distribution: LatencyNormal, Mean: time.Millisecond}, ErrorRate: 0.1}).Validate(); err != nil {
+		t.Errorf("Expected a valid rule, got %v", err)
+	}
+}
+
+func TestLatencySample(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	l := Latency{Distribution: LatencyUniform, Min: 10 * time.Millisecond, Max: 20 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		if d := l.sample(rng); d < l.Min || d > l.Max {
+			t.Fatalf("Expected a delay between %s and %s, got %s", l.Min, l.Max, d)
+		}
+	}
+	l = Latency{Distribution: LatencyNormal, Mean: 10 * time.Millisecond, StdDev: 50 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		if d := l.sample(rng); d < 0 {
+			t.Fatalf("Expected no negative delay, got %s", d)
+		}
+	}
+}
+
+func TestServerCoverage(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	ts, err := NewTestServer(p, tester.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewTestServer: %v", err)
+	}
+	defer ts.Close()
+
+	for _, path := range []string{"/v1/pets", "/pets", "/v1/owners"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	summary := ts.Mock.Coverage()
+	if summary.TotalTests != 1 || summary.Results[0].OperationID != "listPets" || summary.Results[0].Runs != 2 {
+		t.Fatalf("Expected listPets called twice, got %+v", summary.Results)
+	}
+	if len(summary.SkippedOperations) != 2 || summary.SkippedOperations[0].Reason != models.SkipUncalled {
+		t.Errorf("Expected 2 uncalled operations, got %+v", summary.SkippedOperations)
+	}
+	if summary.Coverage < 33 || summary.Coverage > 34 {
+		t.Errorf("Expected 33.3%% coverage, got %.1f", summary.Coverage)
+	}
+}
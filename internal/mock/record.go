@@ -0,0 +1,59 @@
+package mock
+
+import (
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// callStats counts the calls clients made to an operation
+type callStats struct {
+	count      int
+	lastStatus int // status of the last response, 0 if the connection was reset
+}
+
+// record counts a call to an operation
+func (s *Server) record(details *parser.OperationDetails, status int) {
+	key := details.Method + " " + details.Path
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.calls[key]
+	if !ok {
+		stats = &callStats{}
+		s.calls[key] = stats
+	}
+	stats.count++
+	stats.lastStatus = status
+}
+
+// Coverage reports which operations clients called since the server started,
+// in the summary format of a test run: a result per called operation, with
+// the number of calls as its runs, and the operations never called as not
+// covered. It shows which parts of the contract a client really exercises.
+func (s *Server) Coverage() models.TestSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := models.TestSummary{Results: []models.TestResult{}}
+	var uncalled []models.Operation
+	for _, op := range s.operations {
+		stats, ok := s.calls[op.Method+" "+op.Path]
+		if !ok {
+			uncalled = append(uncalled, op)
+			continue
+		}
+		summary.AddResult(models.TestResult{
+			Path:        op.Path,
+			Method:      op.Method,
+			OperationID: op.OperationID,
+			Passed:      true,
+			StatusCode:  stats.lastStatus,
+			Runs:        stats.count,
+			PassCount:   stats.count,
+		})
+	}
+	summary.SkippedOperations = models.SkipOperations(uncalled, models.SkipUncalled)
+	if len(s.operations) > 0 {
+		summary.Coverage = float64(summary.TotalTests) / float64(len(s.operations)) * 100
+	}
+	return summary
+}
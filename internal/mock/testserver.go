@@ -0,0 +1,63 @@
+package mock
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+// TestServer is the mock server of a spec on a local port, with a tester
+// pointed at it. API clients can run consumer-driven contract tests against
+// it without a real backend:
+//
+//	ts, err := mock.NewTestServer(p, tester.DefaultConfig())
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer ts.Close()
+//	client := petstore.NewClient(ts.BaseURL)
+//
+// Requests are answered under the path of the spec's first server URL, and
+// under the spec's paths alone.
+type TestServer struct {
+	*httptest.Server
+	Mock       *Server            // the mock handler, e.g. to inject faults with SetChaos
+	BaseURL    string             // server URL with the path of the spec's first server URL
+	Tester     *tester.Tester     // configured tester against the server
+	Operations []models.Operation // the spec's operations, against BaseURL
+}
+
+// NewTestServer starts the mock server of a spec as an httptest.Server and
+// configures a tester against it. Close the server when done.
+func NewTestServer(p *parser.Parser, config tester.Config) (*TestServer, error) {
+	handler, err := NewServer(p)
+	if err != nil {
+		return nil, err
+	}
+	server := httptest.NewServer(handler)
+
+	baseURL := server.URL
+	if serverURLs, err := p.GetServerURLs(); err == nil && len(serverURLs) > 0 {
+		if u, err := url.Parse(serverURLs[0]); err == nil && strings.Trim(u.Path, "/") != "" {
+			baseURL = parser.JoinURL(server.URL, strings.TrimRight(u.Path, "/"))
+		}
+	}
+
+	operations, err := p.GetOperations(baseURL)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	return &TestServer{
+		Server:     server,
+		Mock:       handler,
+		BaseURL:    baseURL,
+		Tester:     tester.NewTesterWithConfig(config),
+		Operations: operations,
+	}, nil
+}
@@ -5,9 +5,10 @@ import "time"
 // BenchmarkResult represents the benchmark results for a single API endpoint
 type BenchmarkResult struct {
 	// Operation details
-	Path        string `json:"path"`
-	Method      string `json:"method"`
-	OperationID string `json:"operation_id,omitempty"`
+	Path        string   `json:"path"`
+	Method      string   `json:"method"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
 
 	// Benchmark configuration
 	Iterations  int `json:"iterations"`
@@ -22,6 +23,16 @@ type BenchmarkResult struct {
 	P90Time time.Duration `json:"p90_time_ns"`
 	P99Time time.Duration `json:"p99_time_ns"`
 
+	// Latency distribution of successful requests
+	Histogram []HistogramBucket `json:"histogram,omitempty"`
+
+	// Latency measured from the intended send time when a rate is configured,
+	// compensating for coordinated omission (the fields above are uncorrected)
+	Corrected *CorrectedLatency `json:"corrected,omitempty"`
+
+	// Correlation id of the slowest successful request (set with a correlation header)
+	SlowestRequestID string `json:"slowest_request_id,omitempty"`
+
 	// Throughput
 	RequestsPerSec float64       `json:"requests_per_sec"`
 	TotalDuration  time.Duration `json:"total_duration_ns"`
@@ -31,11 +42,151 @@ type BenchmarkResult struct {
 	ErrorCount   int     `json:"error_count"`
 	ErrorRate    float64 `json:"error_rate"`
 
+	// Failed requests by error class: the transport errors of ErrorCount and
+	// the responses with an error status
+	Errors ErrorCounts `json:"error_classes,omitempty"`
+
+	// Connection pool usage: requests on freshly dialed vs pooled connections
+	NewConns    int `json:"new_conns"`
+	ReusedConns int `json:"reused_conns"`
+
+	// Address families used by connections (ipv4, ipv6 or both)
+	AddressFamily string `json:"address_family,omitempty"`
+
+	// Latency per server address (only set when requests reached several addresses)
+	PerIP []IPLatency `json:"per_ip,omitempty"`
+
 	// Status code distribution
 	StatusCodes map[int]int `json:"status_codes"`
 
+	// Cleanup of resources created by POST requests (only with cleanup enabled)
+	CleanedUp     int `json:"cleaned_up,omitempty"`
+	CleanupFailed int `json:"cleanup_failed,omitempty"`
+
+	// Worker saturation diagnostics
+	Workers *WorkerStats `json:"workers,omitempty"`
+
+	// How adaptive mode adjusted the concurrency (only set in adaptive mode)
+	Adaptive *AdaptiveResult `json:"adaptive,omitempty"`
+
+	// Warmup statistics (only set when warmup samples are reported)
+	Warmup *WarmupResult `json:"warmup,omitempty"`
+
+	// Latency over fresh connections, measured before warmup (only set in
+	// cold/hot comparison mode; the fields above are the hot measurement)
+	Cold *ColdResult `json:"cold,omitempty"`
+
 	// Sample errors (first few unique errors)
 	SampleErrors []string `json:"sample_errors,omitempty"`
+
+	// Requests slower than the trace threshold, written to the trace file
+	SlowRequests int `json:"slow_requests,omitempty"`
+}
+
+// HistogramBucket counts requests with a latency up to UpperBound (and above
+// the previous bucket's bound)
+type HistogramBucket struct {
+	UpperBound time.Duration `json:"le_ns"`
+	Count      int           `json:"count"`
+}
+
+// CorrectedLatency holds latency statistics corrected for coordinated omission
+type CorrectedLatency struct {
+	AvgTime time.Duration `json:"avg_time_ns"`
+	MaxTime time.Duration `json:"max_time_ns"`
+	P50Time time.Duration `json:"p50_time_ns"`
+	P90Time time.Duration `json:"p90_time_ns"`
+	P99Time time.Duration `json:"p99_time_ns"`
+}
+
+// WorkerStats shows whether the client's concurrency or rate limit, rather than
+// the server, constrained throughput
+type WorkerStats struct {
+	WorkerRequests []int         `json:"worker_requests"`     // requests completed by each worker
+	AvgQueueWait   time.Duration `json:"avg_queue_wait_ns"`   // time a rate-limited request waited for a free worker after it was due
+	AvgLimiterWait time.Duration `json:"avg_limiter_wait_ns"` // time a request waited on the rate limiter
+	LimiterWaitPct float64       `json:"limiter_wait_pct"`    // share of worker time spent in the rate limiter
+}
+
+// AdaptiveResult traces the concurrency search of adaptive mode: the
+// concurrency is raised by one while a window of requests stays within the
+// error rate and p99 limits, and halved when it doesn't
+type AdaptiveResult struct {
+	Start       int            `json:"start"`
+	Final       int            `json:"final"`
+	Sustainable int            `json:"sustainable"` // highest concurrency that stayed within the limits (0 if none did)
+	Backoffs    int            `json:"backoffs"`
+	Steps       []AdaptiveStep `json:"steps"`
+}
+
+// AdaptiveStep is one measured window of adaptive mode
+type AdaptiveStep struct {
+	Concurrency int           `json:"concurrency"`
+	Requests    int           `json:"requests"`
+	P99Time     time.Duration `json:"p99_time_ns"`
+	ErrorRate   float64       `json:"error_rate"` // transport errors, 429 and 5xx responses
+	Healthy     bool          `json:"healthy"`    // within the limits
+}
+
+// WarmupResult holds statistics for the warmup requests of an endpoint
+type WarmupResult struct {
+	Requests   int           `json:"requests"`
+	ErrorCount int           `json:"error_count"`
+	MinTime    time.Duration `json:"min_time_ns"`
+	MaxTime    time.Duration `json:"max_time_ns"`
+	AvgTime    time.Duration `json:"avg_time_ns"`
+	P50Time    time.Duration `json:"p50_time_ns"`
+	P99Time    time.Duration `json:"p99_time_ns"`
+}
+
+// ColdResult holds statistics for the requests of an endpoint sent over fresh
+// connections, so they include DNS lookup, TCP and TLS handshakes
+type ColdResult struct {
+	Requests   int           `json:"requests"`
+	ErrorCount int           `json:"error_count"`
+	MinTime    time.Duration `json:"min_time_ns"`
+	MaxTime    time.Duration `json:"max_time_ns"`
+	AvgTime    time.Duration `json:"avg_time_ns"`
+	P50Time    time.Duration `json:"p50_time_ns"`
+	P90Time    time.Duration `json:"p90_time_ns"`
+	P99Time    time.Duration `json:"p99_time_ns"`
+}
+
+// SlowRequest is the timeline of a request slower than the trace threshold.
+// Phases are measured from the start of the request; a phase the request
+// skipped, such as DNS on a pooled connection, is zero.
+type SlowRequest struct {
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	Path       string        `json:"path"` // spec path
+	URL        string        `json:"url"`
+	RequestID  string        `json:"request_id,omitempty"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	ErrorClass ErrorClass    `json:"error_class,omitempty"`
+	Duration   time.Duration `json:"duration_ns"`
+
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	ConnReused bool   `json:"conn_reused"`
+
+	GetConn   time.Duration `json:"get_conn_ns"`   // waiting for a pooled or new connection, including DNS, connect and TLS
+	DNS       time.Duration `json:"dns_ns"`        // DNS lookup
+	Connect   time.Duration `json:"connect_ns"`    // TCP connect
+	TLS       time.Duration `json:"tls_ns"`        // TLS handshake
+	Send      time.Duration `json:"send_ns"`       // writing the request
+	FirstByte time.Duration `json:"first_byte_ns"` // from the request written to the first response byte
+
+	RequestHeaders  map[string][]string `json:"request_headers"`
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+}
+
+// IPLatency holds latency statistics for requests served by one address
+type IPLatency struct {
+	IP       string        `json:"ip"`
+	Requests int           `json:"requests"`
+	AvgTime  time.Duration `json:"avg_time_ns"`
+	P50Time  time.Duration `json:"p50_time_ns"`
+	P99Time  time.Duration `json:"p99_time_ns"`
 }
 
 // BenchmarkSummary represents the overall benchmark results
@@ -46,6 +197,16 @@ type BenchmarkSummary struct {
 	Concurrency    int `json:"concurrency"`
 	WarmupRuns     int `json:"warmup_runs"`
 
+	// Partial is true for interim results written while the run is in progress
+	Partial bool `json:"partial,omitempty"`
+
+	// DeadlineExceeded is true if the run stopped at its deadline
+	DeadlineExceeded bool `json:"deadline_exceeded,omitempty"`
+
+	// Endpoint order, with the seed needed to reproduce a random order
+	Order string `json:"order,omitempty"`
+	Seed  int64  `json:"seed,omitempty"`
+
 	// Aggregate timing
 	OverallMinTime time.Duration `json:"overall_min_time_ns"`
 	OverallMaxTime time.Duration `json:"overall_max_time_ns"`
@@ -56,13 +217,67 @@ type BenchmarkSummary struct {
 	TotalSuccesses    int           `json:"total_successes"`
 	TotalErrors       int           `json:"total_errors"`
 	OverallErrorRate  float64       `json:"overall_error_rate"`
+	Errors            ErrorCounts   `json:"error_classes,omitempty"`
 	TotalDuration     time.Duration `json:"total_duration_ns"`
 	OverallReqsPerSec float64       `json:"overall_requests_per_sec"`
 
+	// Resource usage of the load generator itself
+	Client *ClientResources `json:"client_resources,omitempty"`
+
+	// Spec operations this run did not benchmark, and why
+	SkippedOperations []SkippedOperation `json:"skipped_operations,omitempty"`
+
+	// Latency budgets per tag, and how much of them the endpoints used
+	Budgets []BudgetResult `json:"budgets,omitempty"`
+
+	// Limits on failed requests per error class, and whether they were kept
+	ErrorLimits []ErrorLimitResult `json:"error_limits,omitempty"`
+
+	// oas build that produced the results
+	Build *BuildInfo `json:"build,omitempty"`
+
+	// Earlier run the Markdown and HTML reports compare against (--baseline)
+	Baseline *BenchmarkSummary `json:"-"`
+
 	// Per-endpoint results
 	Results []BenchmarkResult `json:"results"`
 }
 
+// BudgetResult is the outcome of a tag's latency budget. The tag is measured
+// by its slowest endpoint.
+type BudgetResult struct {
+	Tag         string        `json:"tag"`
+	Budget      string        `json:"budget"` // e.g. p99<300ms
+	Metric      string        `json:"metric"`
+	Limit       time.Duration `json:"limit_ns"`
+	Actual      time.Duration `json:"actual_ns"`          // metric of the slowest endpoint
+	Endpoint    string        `json:"endpoint,omitempty"` // slowest endpoint, e.g. "GET /payments"
+	Endpoints   int           `json:"endpoints"`          // benchmarked endpoints with the tag
+	Utilization float64       `json:"utilization_pct"`    // actual as a percentage of the limit
+	Passed      bool          `json:"passed"`
+}
+
+// ErrorLimitResult is the outcome of a limit on the failed requests of an
+// error class over the whole run
+type ErrorLimitResult struct {
+	Class  ErrorClass `json:"class"`
+	Limit  string     `json:"limit"`    // a count or a percentage of all requests, e.g. 10 or 0.5%
+	Actual int        `json:"actual"`   // failed requests of the class
+	Rate   float64    `json:"rate_pct"` // actual as a percentage of all requests
+	Passed bool       `json:"passed"`
+}
+
+// ClientResources describes the load generator's own resource usage during a
+// run. High CPU usage means the client, not the API, may have been the bottleneck.
+type ClientResources struct {
+	NumCPU         int           `json:"num_cpu"`
+	CPUTime        time.Duration `json:"cpu_time_ns"`
+	CPUPercent     float64       `json:"cpu_pct"` // of all cores over the run
+	PeakHeapBytes  uint64        `json:"peak_heap_bytes"`
+	PeakGoroutines int           `json:"peak_goroutines"`
+	PeakOpenFDs    int           `json:"peak_open_fds,omitempty"`
+}
+
 // AddResult adds a benchmark result to the summary and updates aggregates
 func (s *BenchmarkSummary) AddResult(result BenchmarkResult) {
 	s.Results = append(s.Results, result)
@@ -70,6 +285,7 @@ func (s *BenchmarkSummary) AddResult(result BenchmarkResult) {
 	s.TotalRequests += result.Iterations
 	s.TotalSuccesses += result.SuccessCount
 	s.TotalErrors += result.ErrorCount
+	s.Errors.Merge(result.Errors)
 
 	// Update min/max
 	if s.OverallMinTime == 0 || result.MinTime < s.OverallMinTime {
@@ -96,6 +312,26 @@ func (s *BenchmarkSummary) AddResult(result BenchmarkResult) {
 	}
 }
 
+// BudgetsMet reports whether every latency budget was kept
+func (s *BenchmarkSummary) BudgetsMet() bool {
+	for _, b := range s.Budgets {
+		if !b.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrorLimitsMet reports whether every error class stayed within its limit
+func (s *BenchmarkSummary) ErrorLimitsMet() bool {
+	for _, l := range s.ErrorLimits {
+		if !l.Passed {
+			return false
+		}
+	}
+	return true
+}
+
 // Finalize calculates final aggregate metrics
 func (s *BenchmarkSummary) Finalize(totalDuration time.Duration) {
 	s.TotalDuration = totalDuration
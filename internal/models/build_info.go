@@ -0,0 +1,14 @@
+package models
+
+// BuildInfo identifies the oas build that produced a result, so results of
+// different tool versions can be told apart when they are compared
+type BuildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	Modified   bool   `json:"modified,omitempty"` // built from a working tree with uncommitted changes
+	BuildDate  string `json:"build_date,omitempty"`
+	LibOpenAPI string `json:"libopenapi,omitempty"` // version of the OpenAPI parser
+	GoVersion  string `json:"go_version,omitempty"`
+	Platform   string `json:"platform,omitempty"` // e.g. linux/amd64
+	RunID      string `json:"run_id,omitempty"`   // identifies the run in server logs
+}
@@ -0,0 +1,68 @@
+package models
+
+import "fmt"
+
+// ErrorClass buckets a failed request by its cause, so failures can be
+// aggregated and limited per kind rather than by message
+type ErrorClass string
+
+// Error classes, in reporting order (see ErrorClasses)
+const (
+	ErrorConnection ErrorClass = "connection" // refused, reset or dropped connections
+	ErrorDNS        ErrorClass = "dns"        // the host name could not be resolved
+	ErrorTLS        ErrorClass = "tls"        // handshake or certificate failures
+	ErrorTimeout    ErrorClass = "timeout"    // no response within the client timeout
+	ErrorClient     ErrorClass = "4xx"        // client error status
+	ErrorServer     ErrorClass = "5xx"        // server error status
+	ErrorValidation ErrorClass = "validation" // the response doesn't match the spec
+	ErrorOther      ErrorClass = "other"      // e.g. no request could be built
+)
+
+// ErrorClasses lists every error class in reporting order
+var ErrorClasses = []ErrorClass{
+	ErrorConnection, ErrorDNS, ErrorTLS, ErrorTimeout,
+	ErrorClient, ErrorServer, ErrorValidation, ErrorOther,
+}
+
+// ParseErrorClass parses an error class name
+func ParseErrorClass(s string) (ErrorClass, error) {
+	for _, class := range ErrorClasses {
+		if string(class) == s {
+			return class, nil
+		}
+	}
+	return "", fmt.Errorf("unknown error class '%s' (must be connection, dns, tls, timeout, 4xx, 5xx, validation or other)", s)
+}
+
+// StatusErrorClass returns the class of an error status, or "" for a
+// status below 400
+func StatusErrorClass(code int) ErrorClass {
+	switch {
+	case code >= 500:
+		return ErrorServer
+	case code >= 400:
+		return ErrorClient
+	}
+	return ""
+}
+
+// ErrorCounts counts failed requests per error class
+type ErrorCounts map[ErrorClass]int
+
+// Add counts n failures of a class
+func (c *ErrorCounts) Add(class ErrorClass, n int) {
+	if n == 0 {
+		return
+	}
+	if *c == nil {
+		*c = make(ErrorCounts)
+	}
+	(*c)[class] += n
+}
+
+// Merge adds the counts of other
+func (c *ErrorCounts) Merge(other ErrorCounts) {
+	for class, n := range other {
+		c.Add(class, n)
+	}
+}
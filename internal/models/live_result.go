@@ -0,0 +1,54 @@
+package models
+
+// Outcomes of probing a documented operation on a live server
+const (
+	LivePresent          = "present"            // the server serves the operation
+	LiveMissing          = "missing"            // the server doesn't know the route (404)
+	LiveMethodNotAllowed = "method_not_allowed" // the route exists, but not with the documented method
+	LiveUnverified       = "unverified"         // the route exists; the method can't be confirmed without sending a mutating request
+	LiveError            = "error"              // the server could not be probed, e.g. connection refused
+)
+
+// LiveCheck is the outcome of probing one documented operation
+type LiveCheck struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Outcome     string   `json:"outcome"`
+	Probes      []string `json:"probes,omitempty"` // requests sent and their answers, e.g. "OPTIONS 204 (Allow: GET, POST)"
+	Error       string   `json:"error,omitempty"`
+}
+
+// LiveSummary is the outcome of probing every documented operation
+type LiveSummary struct {
+	Operations       int         `json:"operations"`
+	Present          int         `json:"present"`
+	Missing          int         `json:"missing"`
+	MethodNotAllowed int         `json:"method_not_allowed"`
+	Unverified       int         `json:"unverified"`
+	Errors           int         `json:"errors"`
+	Checks           []LiveCheck `json:"checks"`
+}
+
+// AddCheck adds the outcome of probing an operation to the summary
+func (s *LiveSummary) AddCheck(check LiveCheck) {
+	s.Operations++
+	s.Checks = append(s.Checks, check)
+	switch check.Outcome {
+	case LivePresent:
+		s.Present++
+	case LiveMissing:
+		s.Missing++
+	case LiveMethodNotAllowed:
+		s.MethodNotAllowed++
+	case LiveUnverified:
+		s.Unverified++
+	default:
+		s.Errors++
+	}
+}
+
+// Drifted reports whether the deployment lacks documented operations
+func (s LiveSummary) Drifted() bool {
+	return s.Missing > 0 || s.MethodNotAllowed > 0
+}
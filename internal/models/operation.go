@@ -8,4 +8,72 @@ type Operation struct {
 	Tags        []string
 	ServerURL   string
 	FullPath    string // ServerURL + Path with parameters resolved
+	Deprecated  bool
+	Unsupported string // why no valid request can be built for the operation, empty if one can
+
+	Summary     string     // operation summary, or the path item's if the operation has none
+	Description string     // operation description, or the path item's if the operation has none
+	Parameters  []string   // names of the operation's parameters, path-level ones included
+	Security    [][]string // alternative security requirements, each listing the schemes it needs; an empty one means none
+}
+
+// Reasons a spec operation was not exercised by a run
+const (
+	SkipFiltered    = "filtered"    // excluded by --filter or --tag
+	SkipSampled     = "sampled"     // left out by --sample
+	SkipDeprecated  = "deprecated"  // deprecated and --skip-deprecated set
+	SkipUnsupported = "unsupported" // no valid request can be built
+	SkipMutating    = "mutating"    // write method not benchmarked without --include-mutations
+	SkipStopped     = "stopped"     // not reached because the run stopped early
+	SkipDeadline    = "deadline"    // not reached before the run's deadline
+	SkipUncalled    = "uncalled"    // no client called it on the mock server
+)
+
+// SkippedOperation is a spec operation that a run did not exercise
+type SkippedOperation struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operation_id,omitempty"`
+	Reason      string `json:"reason"`
+	Detail      string `json:"detail,omitempty"`
+	Spec        string `json:"spec,omitempty"` // spec file, only set in multi-spec runs
+}
+
+// SkipOperations records ops as skipped for the given reason
+func SkipOperations(ops []Operation, reason string) []SkippedOperation {
+	skipped := make([]SkippedOperation, 0, len(ops))
+	for _, op := range ops {
+		s := SkippedOperation{Method: op.Method, Path: op.Path, OperationID: op.OperationID, Reason: reason}
+		if reason == SkipUnsupported {
+			s.Detail = op.Unsupported
+		}
+		skipped = append(skipped, s)
+	}
+	return skipped
+}
+
+// CountSkipped counts the skipped operations with the given reason
+func CountSkipped(skipped []SkippedOperation, reason string) int {
+	n := 0
+	for _, s := range skipped {
+		if s.Reason == reason {
+			n++
+		}
+	}
+	return n
+}
+
+// Exclude returns the operations in all that are missing from kept
+func Exclude(all, kept []Operation) []Operation {
+	seen := make(map[string]bool, len(kept))
+	for _, op := range kept {
+		seen[op.Method+" "+op.Path] = true
+	}
+	var excluded []Operation
+	for _, op := range all {
+		if !seen[op.Method+" "+op.Path] {
+			excluded = append(excluded, op)
+		}
+	}
+	return excluded
 }
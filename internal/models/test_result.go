@@ -8,17 +8,49 @@ type TestResult struct {
 	Path        string `json:"path"`
 	Method      string `json:"method"`
 	OperationID string `json:"operation_id,omitempty"`
+	Spec        string `json:"spec,omitempty"` // spec file, only set in multi-spec runs
 
 	// Test status
-	Passed bool   `json:"passed"`
-	Error  string `json:"error,omitempty"`
+	Passed     bool       `json:"passed"`
+	Error      string     `json:"error,omitempty"`
+	ErrorClass ErrorClass `json:"error_class,omitempty"` // cause of a failure
 
 	// Response details
-	StatusCode   int           `json:"status_code"`
-	ResponseTime time.Duration `json:"response_time_ns"`
+	StatusCode    int           `json:"status_code"`
+	ResponseTime  time.Duration `json:"response_time_ns"`
+	RequestID     string        `json:"request_id,omitempty"` // correlation id sent with the request
+	RemoteAddr    string        `json:"remote_addr,omitempty"`
+	AddressFamily string        `json:"address_family,omitempty"` // ipv4 or ipv6
+
+	// Retries sent before the final response, and the time they took including
+	// backoff (ResponseTime covers the final attempt only)
+	Retries   int           `json:"retries,omitempty"`
+	RetryTime time.Duration `json:"retry_time_ns,omitempty"`
 
 	// Validation details
 	ValidationErrors []ValidationError `json:"validation_errors,omitempty"`
+
+	// Repetition details (only set when the operation was run more than once)
+	Runs      int     `json:"runs,omitempty"`
+	PassCount int     `json:"pass_count,omitempty"`
+	Flaky     bool    `json:"flaky,omitempty"`
+	Flakiness float64 `json:"flakiness_pct,omitempty"` // percentage of runs that failed
+
+	// Parameter combinations (only set when the operation was tested with several cases)
+	Cases       int    `json:"cases,omitempty"`
+	FailedCases int    `json:"failed_cases,omitempty"`
+	FailedCase  string `json:"failed_case,omitempty"` // label of the first failing case
+
+	// Callbacks received and validated after the response (see --callbacks)
+	Callbacks int `json:"callbacks,omitempty"`
+
+	// Outcome of comparing the response with its snapshot (see --snapshot):
+	// created, matched, changed or updated
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// Outcome of the gRPC method behind a grpc-gateway operation (see --grpc)
+	GRPCStatus       string        `json:"grpc_status,omitempty"`
+	GRPCResponseTime time.Duration `json:"grpc_response_time_ns,omitempty"`
 }
 
 // ValidationError represents a specific validation failure
@@ -29,19 +61,122 @@ type ValidationError struct {
 
 // TestSummary represents the overall test results
 type TestSummary struct {
-	TotalTests int          `json:"total_tests"`
-	Passed     int          `json:"passed"`
-	Failed     int          `json:"failed"`
-	Results    []TestResult `json:"results"`
+	TotalTests int  `json:"total_tests"`
+	Passed     int  `json:"passed"`
+	Failed     int  `json:"failed"`
+	Flaky      int  `json:"flaky,omitempty"`
+	Skipped    int  `json:"skipped,omitempty"` // operations not run because the run stopped early
+	Stopped    bool `json:"stopped,omitempty"` // true if the run hit the failure limit or its deadline
+
+	DeadlineExceeded bool `json:"deadline_exceeded,omitempty"` // true if the run stopped at its deadline
+
+	// Failed tests by error class
+	Errors ErrorCounts `json:"error_classes,omitempty"`
+
+	// Coverage of the spec's operations (percentage exercised by this run)
+	Coverage    float64 `json:"coverage_pct"`
+	MinCoverage float64 `json:"min_coverage_pct,omitempty"`
+
+	// Spec operations this run did not exercise, and why
+	SkippedOperations []SkippedOperation `json:"skipped_operations,omitempty"`
+
+	// Operations no valid request can be built for, counted apart from
+	// failures (also listed in SkippedOperations)
+	Unsupported int `json:"unsupported,omitempty"`
+
+	// Base seed of differently seeded cases (--cases), for reproduction
+	Seed int64 `json:"seed,omitempty"`
+
+	// Resources created by [seed] fixtures before the run, and those that
+	// could not be deleted after it
+	Seeded            int `json:"seeded,omitempty"`
+	SeedCleanupFailed int `json:"seed_cleanup_failed,omitempty"`
+
+	// Spec file of a single-spec run; multi-spec runs list theirs in Specs
+	Spec string `json:"spec,omitempty"`
+
+	// Why the spec could not be tested, e.g. a failed login
+	Error string `json:"error,omitempty"`
+
+	// Per-spec totals, only set in multi-spec runs
+	Specs []SpecSummary `json:"specs,omitempty"`
+
+	// oas build that produced the results
+	Build *BuildInfo `json:"build,omitempty"`
+
+	Results []TestResult `json:"results"`
 }
 
-// AddResult adds a test result to the summary
+// AddResult adds a test result to the summary. Failures without a class
+// count as ErrorOther.
 func (s *TestSummary) AddResult(result TestResult) {
+	if !result.Passed && result.ErrorClass == "" {
+		result.ErrorClass = ErrorOther
+	}
 	s.TotalTests++
 	s.Results = append(s.Results, result)
 	if result.Passed {
 		s.Passed++
 	} else {
 		s.Failed++
+		s.Errors.Add(result.ErrorClass, 1)
+	}
+	if result.Flaky {
+		s.Flaky++
+	}
+}
+
+// SpecSummary totals the results of one spec in a multi-spec run
+type SpecSummary struct {
+	Spec       string  `json:"spec"`
+	Operations int     `json:"operations"` // operations the spec declares
+	TotalTests int     `json:"total_tests"`
+	Passed     int     `json:"passed"`
+	Failed     int     `json:"failed"`
+	Coverage   float64 `json:"coverage_pct"`
+	Error      string  `json:"error,omitempty"` // why the spec could not be tested
+}
+
+// AddSpec merges the summary of one spec into a multi-spec run, tagging its
+// results and skipped operations with the spec. Coverage becomes the share of
+// all specs' operations exercised.
+func (s *TestSummary) AddSpec(spec string, operations int, other TestSummary) {
+	for _, result := range other.Results {
+		result.Spec = spec
+		s.AddResult(result)
+	}
+	for _, skipped := range other.SkippedOperations {
+		skipped.Spec = spec
+		s.SkippedOperations = append(s.SkippedOperations, skipped)
+	}
+	s.Skipped += other.Skipped
+	s.Unsupported += other.Unsupported
+	s.Seeded += other.Seeded
+	s.SeedCleanupFailed += other.SeedCleanupFailed
+	s.Stopped = s.Stopped || other.Stopped
+	s.DeadlineExceeded = s.DeadlineExceeded || other.DeadlineExceeded
+
+	s.Specs = append(s.Specs, SpecSummary{
+		Spec:       spec,
+		Operations: operations,
+		TotalTests: other.TotalTests,
+		Passed:     other.Passed,
+		Failed:     other.Failed,
+		Coverage:   other.Coverage,
+		Error:      other.Error,
+	})
+
+	exercised, total := 0, 0
+	for _, sp := range s.Specs {
+		exercised += sp.TotalTests
+		total += sp.Operations
+	}
+	if total > 0 {
+		s.Coverage = float64(exercised) / float64(total) * 100
 	}
 }
+
+// CoverageMet reports whether the run exercised enough of the spec's operations
+func (s *TestSummary) CoverageMet() bool {
+	return s.MinCoverage <= 0 || s.Coverage >= s.MinCoverage
+}
@@ -0,0 +1,40 @@
+package models
+
+import "testing"
+
+func TestAddSpec(t *testing.T) {
+	var pets TestSummary
+	pets.AddResult(TestResult{Method: "GET", Path: "/pets", Passed: true})
+	pets.AddResult(TestResult{Method: "POST", Path: "/pets"})
+	pets.SkippedOperations = []SkippedOperation{{Method: "DELETE", Path: "/pets/{petId}", Reason: SkipFiltered}}
+	pets.Coverage = 50
+
+	summary := TestSummary{Results: make([]TestResult, 0)}
+	summary.AddSpec("pets.yaml", 4, pets)
+	summary.AddSpec("orders.yaml", 4, TestSummary{Error: "logging in: 401 Unauthorized"})
+
+	if summary.TotalTests != 2 || summary.Passed != 1 || summary.Failed != 1 {
+		t.Errorf("Expected 2 tests, 1 passed and 1 failed, got %d, %d and %d", summary.TotalTests, summary.Passed, summary.Failed)
+	}
+	for _, r := range summary.Results {
+		if r.Spec != "pets.yaml" {
+			t.Errorf("Expected result tagged with pets.yaml, got %q", r.Spec)
+		}
+	}
+	if len(summary.SkippedOperations) != 1 || summary.SkippedOperations[0].Spec != "pets.yaml" {
+		t.Errorf("Expected skipped operation tagged with pets.yaml, got %+v", summary.SkippedOperations)
+	}
+	// Coverage is the share of both specs' 8 operations
+	if summary.Coverage != 25 {
+		t.Errorf("Expected 25%% coverage, got %.2f", summary.Coverage)
+	}
+	if len(summary.Specs) != 2 {
+		t.Fatalf("Expected 2 spec summaries, got %d", len(summary.Specs))
+	}
+	if s := summary.Specs[0]; s.Spec != "pets.yaml" || s.TotalTests != 2 || s.Passed != 1 || s.Coverage != 50 {
+		t.Errorf("Unexpected pets.yaml summary: %+v", s)
+	}
+	if s := summary.Specs[1]; s.Error != "logging in: 401 Unauthorized" || s.TotalTests != 0 {
+		t.Errorf("Expected orders.yaml to record its error, got %+v", s)
+	}
+}
@@ -0,0 +1,365 @@
+package normalize
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Masked replaces the values of masked fields
+const Masked = "<ignored>"
+
+// Rules configure how JSON bodies are normalized before they are compared
+type Rules struct {
+	Mask  []string // Fields whose values are replaced with Masked, e.g. "$..createdAt" or "$.items[*].id"
+	Sort  []string // Arrays sorted by an item field, "<path>:<field>", or by value without a field
+	Round int      // Decimal places numbers are rounded to (0 = keep them as they are)
+}
+
+// Normalizer applies compiled rules to decoded JSON values. A nil Normalizer
+// leaves values unchanged.
+type Normalizer struct {
+	masks [][]step
+	sorts []sortRule
+	round int
+}
+
+// sortRule sorts the arrays a path selects
+type sortRule struct {
+	path  []step
+	field string // item field compared, empty to compare whole items
+}
+
+// step is a step of a path
+type step struct {
+	name      string // property name, or "*" for any property or item
+	index     int    // item index, -1 if name is set
+	recursive bool   // at any depth below the previous step
+}
+
+// New compiles normalization rules
+func New(rules Rules) (*Normalizer, error) {
+	if rules.Round < 0 {
+		return nil, fmt.Errorf("invalid round %d: must be 0 or more decimal places", rules.Round)
+	}
+	n := &Normalizer{round: rules.Round}
+	if err := n.addMasks(rules.Mask); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules.Sort {
+		path, field := rule, ""
+		if i := strings.LastIndexByte(rule, ':'); i >= 0 {
+			path, field = rule[:i], strings.TrimSpace(rule[i+1:])
+		}
+		steps, err := parsePath(path, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sort rule '%s': %w", rule, err)
+		}
+		n.sorts = append(n.sorts, sortRule{path: steps, field: field})
+	}
+	return n, nil
+}
+
+// WithMask returns a copy of the normalizer that also masks the given fields
+func (n *Normalizer) WithMask(paths ...string) (*Normalizer, error) {
+	c := &Normalizer{}
+	if n != nil {
+		*c = *n
+		c.masks = append([][]step(nil), n.masks...)
+	}
+	if err := c.addMasks(paths); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (n *Normalizer) addMasks(paths []string) error {
+	for _, path := range paths {
+		steps, err := parsePath(path, false)
+		if err != nil {
+			return fmt.Errorf("invalid mask '%s': %w", path, err)
+		}
+		n.masks = append(n.masks, steps)
+	}
+	return nil
+}
+
+// Apply normalizes a decoded JSON value in place and returns it. Numbers are
+// rounded first, then arrays sorted and fields masked, so arrays can be
+// sorted by fields that are masked afterwards.
+func (n *Normalizer) Apply(v interface{}) interface{} {
+	if n == nil {
+		return v
+	}
+	if n.round > 0 {
+		v = roundNumbers(v, math.Pow10(n.round))
+	}
+	for _, rule := range n.sorts {
+		field := rule.field
+		v = apply(v, rule.path, func(x interface{}) interface{} {
+			if items, ok := x.([]interface{}); ok {
+				sort.SliceStable(items, func(i, j int) bool {
+					return less(sortKey(items[i], field), sortKey(items[j], field))
+				})
+			}
+			return x
+		})
+	}
+	for _, steps := range n.masks {
+		v = apply(v, steps, func(interface{}) interface{} { return Masked })
+	}
+	return v
+}
+
+// parsePath parses a path: $ followed by .name, ..name, .*, [*] or [n]
+// steps. A path without $ is a field name at any depth. Only sort rules may
+// select the whole value with $.
+func parsePath(path string, allowRoot bool) ([]step, error) {
+	s := strings.TrimSpace(path)
+	if !strings.HasPrefix(s, "$") {
+		s = "$.." + s
+	}
+	s = s[1:]
+
+	steps := []step{}
+	for s != "" {
+		st := step{index: -1}
+		if strings.HasPrefix(s, "..") {
+			st.recursive = true
+			s = s[2:]
+		} else if s[0] == '.' {
+			s = s[1:]
+		} else if s[0] != '[' {
+			return nil, fmt.Errorf("unexpected '%c'", s[0])
+		}
+
+		if strings.HasPrefix(s, "[") {
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '['")
+			}
+			inner := strings.Trim(s[1:end], `'"`)
+			s = s[end+1:]
+			if i, err := strconv.Atoi(inner); err == nil {
+				st.index = i
+			} else {
+				st.name = inner
+			}
+		} else {
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			st.name = s[:end]
+			s = s[end:]
+		}
+		if st.name == "" && st.index < 0 {
+			return nil, fmt.Errorf("missing name")
+		}
+		steps = append(steps, st)
+	}
+	if len(steps) == 0 && !allowRoot {
+		return nil, fmt.Errorf("it would select the whole body")
+	}
+	return steps, nil
+}
+
+// apply replaces the values the steps select with fn's result
+func apply(v interface{}, steps []step, fn func(interface{}) interface{}) interface{} {
+	if len(steps) == 0 {
+		return fn(v)
+	}
+	st := steps[0]
+	visit := func(child interface{}, name string, index int) interface{} {
+		if st.recursive {
+			child = apply(child, steps, fn)
+		}
+		if st.name == "*" || (st.index >= 0 && index == st.index) || (st.index < 0 && name != "" && name == st.name) {
+			child = apply(child, steps[1:], fn)
+		}
+		return child
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for name, child := range val {
+			val[name] = visit(child, name, -1)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = visit(child, "", i)
+		}
+	}
+	return v
+}
+
+// roundNumbers rounds every number in a value to a multiple of 1/scale.
+// Integers decoded as json.Number are kept exact.
+func roundNumbers(v interface{}, scale float64) interface{} {
+	switch val := v.(type) {
+	case float64:
+		return math.Round(val*scale) / scale
+	case json.Number:
+		if !strings.ContainsAny(string(val), ".eE") {
+			return val
+		}
+		if f, err := val.Float64(); err == nil {
+			return json.Number(strconv.FormatFloat(math.Round(f*scale)/scale, 'f', -1, 64))
+		}
+	case map[string]interface{}:
+		for name, child := range val {
+			val[name] = roundNumbers(child, scale)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = roundNumbers(child, scale)
+		}
+	}
+	return v
+}
+
+// sortKey returns the value an array item is sorted by
+func sortKey(item interface{}, field string) interface{} {
+	if field == "" {
+		return item
+	}
+	if obj, ok := item.(map[string]interface{}); ok {
+		return obj[field]
+	}
+	return nil
+}
+
+// less orders numbers numerically and everything else by its JSON encoding,
+// with missing values first
+func less(a, b interface{}) bool {
+	x, xok := number(a)
+	y, yok := number(b)
+	if xok && yok {
+		return x < y
+	}
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	if s, ok := a.(string); ok {
+		if t, ok := b.(string); ok {
+			return s < t
+		}
+	}
+	ea, _ := json.Marshal(a)
+	eb, _ := json.Marshal(b)
+	return string(ea) < string(eb)
+}
+
+// number returns a decoded JSON number as a float64, whether it was decoded
+// as one or as a json.Number
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// sameNumber reports whether two json.Numbers have the same exact value, so
+// 1e2 equals 100 while integers beyond float64 precision stay apart
+func sameNumber(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	x, xok := new(big.Rat).SetString(string(a))
+	y, yok := new(big.Rat).SetString(string(b))
+	return xok && yok && x.Cmp(y) == 0
+}
+
+// Diff describes how actual differs from expected, field by field, e.g.
+// "body.items[0].price: expected 10, got 12". Fields are named from path.
+func Diff(path string, expected, actual interface{}) []string {
+	var diffs []string
+	diff(path, expected, actual, &diffs)
+	return diffs
+}
+
+func diff(path string, expected, actual interface{}, diffs *[]string) {
+	field := path
+	if field == "" {
+		field = "value"
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected an object, got %s", field, describe(actual)))
+			return
+		}
+		names := make([]string, 0, len(exp)+len(act))
+		for name := range exp {
+			names = append(names, name)
+		}
+		for name := range act {
+			if _, ok := exp[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := name
+			if path != "" {
+				child = path + "." + name
+			}
+			e, inExp := exp[name]
+			a, inAct := act[name]
+			switch {
+			case !inAct:
+				*diffs = append(*diffs, fmt.Sprintf("%s: missing, expected %s", child, describe(e)))
+			case !inExp:
+				*diffs = append(*diffs, fmt.Sprintf("%s: unexpected field with %s", child, describe(a)))
+			default:
+				diff(child, e, a, diffs)
+			}
+		}
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected an array, got %s", field, describe(actual)))
+			return
+		}
+		if len(exp) != len(act) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %d items, got %d", field, len(exp), len(act)))
+		}
+		for i := 0; i < len(exp) && i < len(act); i++ {
+			diff(fmt.Sprintf("%s[%d]", path, i), exp[i], act[i], diffs)
+		}
+	case json.Number:
+		if act, ok := actual.(json.Number); !ok || !sameNumber(exp, act) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %s, got %s", field, describe(expected), describe(actual)))
+		}
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %s, got %s", field, describe(expected), describe(actual)))
+		}
+	}
+}
+
+// describe formats a value for a difference message
+func describe(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	}
+	data, _ := json.Marshal(v)
+	if len(data) > 80 {
+		return string(data[:77]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,150 @@
+package normalize
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+// decodeNumbers decodes JSON with numbers as json.Number
+func decodeNumbers(t *testing.T, s string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestInvalidRules(t *testing.T) {
+	for _, rules := range []Rules{
+		{Mask: []string{"$"}},
+		{Mask: []string{"$.items["}},
+		{Mask: []string{"$."}},
+		{Mask: []string{"items.."}},
+		{Mask: []string{"$items"}},
+		{Sort: []string{"$.items[:id"}},
+		{Round: -1},
+	} {
+		if _, err := New(rules); err == nil {
+			t.Errorf("Expected %+v to be rejected", rules)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		input string
+		want  string
+	}{
+		{
+			name:  "mask",
+			rules: Rules{Mask: []string{"$.items[*].id", "$..tags.*", "createdAt"}},
+			input: `{"id": 1, "items": [{"id": 2, "tags": {"a": 1}}], "meta": {"id": 3, "createdAt": "today"}}`,
+			want:  `{"id": 1, "items": [{"id": "<ignored>", "tags": {"a": "<ignored>"}}], "meta": {"id": 3, "createdAt": "<ignored>"}}`,
+		},
+		{
+			name:  "sort by field",
+			rules: Rules{Sort: []string{"$.items:name"}},
+			input: `{"items": [{"name": "b"}, {"name": "a"}, {}]}`,
+			want:  `{"items": [{}, {"name": "a"}, {"name": "b"}]}`,
+		},
+		{
+			name:  "sort by value",
+			rules: Rules{Sort: []string{"$..tags", "$"}},
+			input: `[{"tags": [3, 1, 2]}, {"tags": ["b", "a"]}]`,
+			want:  `[{"tags": ["a", "b"]}, {"tags": [1, 2, 3]}]`,
+		},
+		{
+			name:  "sort before masking the sort field",
+			rules: Rules{Sort: []string{"$.items:id"}, Mask: []string{"$.items[*].id"}},
+			input: `{"items": [{"id": 2, "v": "x"}, {"id": 1, "v": "y"}]}`,
+			want:  `{"items": [{"id": "<ignored>", "v": "y"}, {"id": "<ignored>", "v": "x"}]}`,
+		},
+		{
+			name:  "round",
+			rules: Rules{Round: 2},
+			input: `{"price": 10.004, "rates": [0.3333333, 2]}`,
+			want:  `{"price": 10, "rates": [0.33, 2]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(tt.rules)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			got := n.Apply(decode(t, tt.input))
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestWithMask(t *testing.T) {
+	var n *Normalizer
+	if v := n.Apply(decode(t, `{"id": 1}`)); !reflect.DeepEqual(v, decode(t, `{"id": 1}`)) {
+		t.Errorf("Expected a nil normalizer to keep values, got %v", v)
+	}
+
+	base, _ := New(Rules{Mask: []string{"id"}})
+	extended, err := base.WithMask("name")
+	if err != nil {
+		t.Fatalf("WithMask: %v", err)
+	}
+	if v := extended.Apply(decode(t, `{"id": 1, "name": "a"}`)); !reflect.DeepEqual(v, decode(t, `{"id": "<ignored>", "name": "<ignored>"}`)) {
+		t.Errorf("Expected both fields masked, got %v", v)
+	}
+	if v := base.Apply(decode(t, `{"id": 1, "name": "a"}`)); !reflect.DeepEqual(v, decode(t, `{"id": "<ignored>", "name": "a"}`)) {
+		t.Errorf("Expected the base normalizer to be unchanged, got %v", v)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	expected := decode(t, `{"items": [{"price": 10}], "name": "a", "old": true}`)
+	actual := decode(t, `{"items": [{"price": 12}, {}], "name": "a", "new": null}`)
+	want := []string{
+		"body.items: expected 1 items, got 2",
+		"body.items[0].price: expected 10, got 12",
+		"body.new: unexpected field with null",
+		"body.old: missing, expected true",
+	}
+	if got := Diff("body", expected, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	n, err := New(Rules{Round: 2, Sort: []string{"$.rates"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	got := n.Apply(decodeNumbers(t, `{"id": 9007199254740993, "rates": [2, 0.3333333, 10.004]}`))
+	want := decodeNumbers(t, `{"id": 9007199254740993, "rates": [0.33, 2, 10]}`)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Integers beyond float64 precision differ, equal values in other notations don't
+	expected := decodeNumbers(t, `{"id": 9007199254740993, "price": 100}`)
+	actual := decodeNumbers(t, `{"id": 9007199254740992, "price": 1e2}`)
+	if got, want := Diff("body", expected, actual), []string{"body.id: expected 9007199254740993, got 9007199254740992"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
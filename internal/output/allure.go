@@ -0,0 +1,235 @@
+package output
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// Allure statuses: failed is a contract violation, broken an error before
+// the response could be judged
+const (
+	allurePassed  = "passed"
+	allureFailed  = "failed"
+	allureBroken  = "broken"
+	allureSkipped = "skipped"
+)
+
+// allureResult is a test result file of the Allure 2 results format
+type allureResult struct {
+	UUID          string              `json:"uuid"`
+	HistoryID     string              `json:"historyId"`
+	TestCaseID    string              `json:"testCaseId"`
+	Name          string              `json:"name"`
+	FullName      string              `json:"fullName"`
+	Status        string              `json:"status"`
+	StatusDetails *allureStatusDetail `json:"statusDetails,omitempty"`
+	Stage         string              `json:"stage"`
+	Start         int64               `json:"start"`
+	Stop          int64               `json:"stop"`
+	Labels        []allureLabel       `json:"labels"`
+	Parameters    []allureLabel       `json:"parameters,omitempty"`
+	Steps         []allureStep        `json:"steps,omitempty"`
+}
+
+type allureStatusDetail struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+	Flaky   bool   `json:"flaky,omitempty"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureStep struct {
+	Name          string              `json:"name"`
+	Status        string              `json:"status"`
+	StatusDetails *allureStatusDetail `json:"statusDetails,omitempty"`
+	Stage         string              `json:"stage"`
+	Start         int64               `json:"start"`
+	Stop          int64               `json:"stop"`
+	Parameters    []allureLabel       `json:"parameters,omitempty"`
+	Steps         []allureStep        `json:"steps,omitempty"`
+}
+
+// Error prefixes of the tester by the phase that failed
+var (
+	allureBuildErrors = []string{"failed to get operation details", "failed to register callbacks", "failed to build request"}
+	allureSendErrors  = []string{"request failed", "failed to read response body"}
+)
+
+// exportTestAllure writes one Allure result file per operation into dir, with
+// steps for building the request, sending it and validating the response.
+// Skipped operations are written as skipped results. Results carry no start
+// times, so they are laid out back to back, ending at the time of the export.
+func exportTestAllure(summary models.TestSummary, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create allure results directory: %w", err)
+	}
+
+	var total time.Duration
+	for _, r := range summary.Results {
+		total += r.RetryTime + r.ResponseTime
+	}
+	clock := time.Now().Add(-total)
+
+	var results []allureResult
+	for _, r := range summary.Results {
+		results = append(results, allureTestResult(r, clock))
+		clock = clock.Add(r.RetryTime + r.ResponseTime)
+	}
+	for _, op := range summary.SkippedOperations {
+		res := newAllureResult(op.Spec, op.Method, op.Path, op.OperationID, clock, clock)
+		res.Status = allureSkipped
+		res.StatusDetails = &allureStatusDetail{Message: "not covered: " + op.Reason, Trace: op.Detail}
+		results = append(results, res)
+	}
+
+	for _, res := range results {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, res.UUID+"-result.json")
+		if err := writeFileAtomic(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allureTestResult converts a test result that started at start
+func allureTestResult(r models.TestResult, start time.Time) allureResult {
+	sent := start.Add(r.RetryTime)
+	stop := sent.Add(r.ResponseTime)
+	res := newAllureResult(r.Spec, r.Method, r.Path, r.OperationID, start, stop)
+
+	build := allureStep{Name: "Build request", Status: allurePassed, Stage: "finished", Start: start.UnixMilli(), Stop: start.UnixMilli()}
+	send := allureStep{Name: fmt.Sprintf("Send %s %s", r.Method, r.Path), Status: allurePassed, Stage: "finished", Start: start.UnixMilli(), Stop: stop.UnixMilli()}
+	if r.StatusCode != 0 {
+		send.Parameters = append(send.Parameters, allureLabel{Name: "status", Value: strconv.Itoa(r.StatusCode)})
+	}
+	if r.Retries > 0 {
+		send.Parameters = append(send.Parameters, allureLabel{Name: "retries", Value: strconv.Itoa(r.Retries)})
+	}
+	validate := allureStep{Name: "Validate response", Status: allurePassed, Stage: "finished", Start: stop.UnixMilli(), Stop: stop.UnixMilli()}
+	for _, ve := range r.ValidationErrors {
+		validate.Steps = append(validate.Steps, allureStep{
+			Name:          ve.Field,
+			Status:        allureFailed,
+			StatusDetails: &allureStatusDetail{Message: ve.Message},
+			Stage:         "finished",
+			Start:         stop.UnixMilli(),
+			Stop:          stop.UnixMilli(),
+		})
+	}
+
+	res.Status = allurePassed
+	if !r.Passed {
+		failure := &allureStatusDetail{Message: r.Error}
+		switch {
+		case hasAnyPrefix(r.Error, allureBuildErrors):
+			res.Status = allureBroken
+			build.Status, build.StatusDetails = allureBroken, failure
+			res.Steps = []allureStep{build}
+		case hasAnyPrefix(r.Error, allureSendErrors):
+			res.Status = allureBroken
+			send.Status, send.StatusDetails = allureBroken, failure
+			res.Steps = []allureStep{build, send}
+		case len(r.ValidationErrors) == 0:
+			// e.g. a validator or negative test error
+			res.Status = allureBroken
+			validate.Status, validate.StatusDetails = allureBroken, failure
+		default:
+			res.Status = allureFailed
+			validate.Status, validate.StatusDetails = allureFailed, failure
+		}
+		var trace []string
+		for _, ve := range r.ValidationErrors {
+			trace = append(trace, ve.Field+": "+ve.Message)
+		}
+		res.StatusDetails = &allureStatusDetail{Message: r.Error, Trace: strings.Join(trace, "\n")}
+	}
+	if res.Steps == nil {
+		res.Steps = []allureStep{build, send, validate}
+	}
+	if r.Flaky {
+		if res.StatusDetails == nil {
+			res.StatusDetails = &allureStatusDetail{}
+		}
+		res.StatusDetails.Flaky = true
+	}
+	if r.FailedCase != "" {
+		res.Parameters = append(res.Parameters, allureLabel{Name: "failed case", Value: r.FailedCase})
+	}
+	if r.Runs > 1 {
+		res.Parameters = append(res.Parameters, allureLabel{Name: "runs", Value: fmt.Sprintf("%d (%d passed)", r.Runs, r.PassCount)})
+	}
+	return res
+}
+
+// newAllureResult creates a result for an operation. Its history id is stable
+// across runs, so Allure tracks the operation's history and retries.
+func newAllureResult(spec, method, path, operationID string, start, stop time.Time) allureResult {
+	name := method + " " + path
+	fullName := name
+	if spec != "" {
+		fullName = spec + ": " + name
+	}
+	sum := md5.Sum([]byte(fullName))
+	id := hex.EncodeToString(sum[:])
+
+	suite := "oas"
+	if spec != "" {
+		suite = filepath.Base(spec)
+	}
+	labels := []allureLabel{
+		{Name: "framework", Value: "oas"},
+		{Name: "suite", Value: suite},
+		{Name: "subSuite", Value: path},
+	}
+	if operationID != "" {
+		labels = append(labels, allureLabel{Name: "story", Value: operationID})
+	}
+	return allureResult{
+		UUID:       newUUID(),
+		HistoryID:  id,
+		TestCaseID: id,
+		Name:       name,
+		FullName:   fullName,
+		Stage:      "finished",
+		Start:      start.UnixMilli(),
+		Stop:       stop.UnixMilli(),
+		Labels:     labels,
+	}
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
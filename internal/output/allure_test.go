@@ -0,0 +1,93 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestExportTestAllure(t *testing.T) {
+	summary := models.TestSummary{
+		SkippedOperations: []models.SkippedOperation{{Method: "DELETE", Path: "/pets/{id}", Reason: models.SkipMutating}},
+	}
+	summary.AddResult(models.TestResult{Method: "GET", Path: "/pets", OperationID: "listPets", Passed: true, StatusCode: 200, ResponseTime: 20 * time.Millisecond})
+	summary.AddResult(models.TestResult{
+		Method: "POST", Path: "/pets", Passed: false, StatusCode: 200,
+		Error:            "validation failed: body.id: expected integer",
+		ValidationErrors: []models.ValidationError{{Field: "body.id", Message: "expected integer"}},
+	})
+	summary.AddResult(models.TestResult{Method: "GET", Path: "/owners", Passed: false, Error: "request failed: connection refused"})
+
+	dir := filepath.Join(t.TempDir(), "allure-results")
+	if err := ExportTestSummary(summary, FormatAllure, dir); err != nil {
+		t.Fatalf("ExportTestSummary: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	if err != nil || len(files) != 4 {
+		t.Fatalf("Expected 4 result files, got %v (%v)", files, err)
+	}
+	byName := make(map[string]allureResult)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res allureResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		if filepath.Base(f) != res.UUID+"-result.json" {
+			t.Errorf("File %s doesn't match uuid %s", f, res.UUID)
+		}
+		byName[res.Name] = res
+	}
+
+	tests := []struct {
+		name, status string
+		steps        int
+		failedStep   string
+	}{
+		{"GET /pets", allurePassed, 3, ""},
+		{"POST /pets", allureFailed, 3, "Validate response"},
+		{"GET /owners", allureBroken, 2, "Send GET /owners"},
+		{"DELETE /pets/{id}", allureSkipped, 0, ""},
+	}
+	for _, tt := range tests {
+		res, ok := byName[tt.name]
+		if !ok {
+			t.Errorf("Missing result for %s", tt.name)
+			continue
+		}
+		if res.Status != tt.status || len(res.Steps) != tt.steps {
+			t.Errorf("%s: expected %s with %d steps, got %s with %d", tt.name, tt.status, tt.steps, res.Status, len(res.Steps))
+			continue
+		}
+		if tt.failedStep != "" {
+			last := res.Steps[len(res.Steps)-1]
+			if last.Name != tt.failedStep || last.Status != tt.status {
+				t.Errorf("%s: expected step %q to be %s, got %q %s", tt.name, tt.failedStep, tt.status, last.Name, last.Status)
+			}
+		}
+	}
+
+	if got := byName["POST /pets"].Steps[2].Steps; len(got) != 1 || got[0].Name != "body.id" {
+		t.Errorf("Expected a failed sub-step per validation error, got %+v", got)
+	}
+	if byName["GET /pets"].HistoryID == byName["POST /pets"].HistoryID {
+		t.Error("Expected distinct history ids per operation")
+	}
+}
+
+func TestAllureNeedsDirectory(t *testing.T) {
+	if _, err := Destinations("allure", "", nil, nil); err == nil {
+		t.Error("Expected an error for allure output to stdout")
+	}
+	if _, err := Destinations("", "", []string{"allure:allure-results"}, nil); err != nil {
+		t.Errorf("Unexpected error for --tee allure:dir: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+//go:embed benchmark.html
+var benchmarkHTML string
+
+var benchmarkTemplate = template.Must(template.New("benchmark").Parse(benchmarkHTML))
+
+// Directions of a reportCell delta
+const (
+	deltaNone      = ""
+	deltaWorse     = "worse"
+	deltaBetter    = "better"
+	deltaUnchanged = "unchanged"
+)
+
+// reportCell is one metric of a report, with its change from the baseline
+type reportCell struct {
+	Value     string
+	Delta     string // e.g. "▲ 5.1%", empty without a baseline value
+	Direction string // one of the delta* constants
+}
+
+// reportRow is one endpoint of a report
+type reportRow struct {
+	Endpoint string
+	New      bool // not in the baseline
+	Cells    []reportCell
+}
+
+// benchmarkReport is the data shared by the Markdown and HTML reports
+type benchmarkReport struct {
+	Headline string
+	Overall  []reportCell // same columns as the rows
+	Columns  []string
+	Rows     []reportRow
+	Baseline bool
+	Removed  []string // endpoints only in the baseline
+}
+
+var reportColumns = []string{"Avg (ms)", "p50 (ms)", "p90 (ms)", "p99 (ms)", "Req/s", "Errors"}
+
+// newBenchmarkReport builds the report of a summary, annotating every metric
+// with its change from summary.Baseline when there is one
+func newBenchmarkReport(summary models.BenchmarkSummary) benchmarkReport {
+	report := benchmarkReport{
+		Headline: fmt.Sprintf("%d endpoints, %d requests in %v, %.2f req/s, %.2f%% errors",
+			summary.TotalEndpoints, summary.TotalRequests, summary.TotalDuration.Round(time.Millisecond),
+			summary.OverallReqsPerSec, summary.OverallErrorRate),
+		Columns:  reportColumns,
+		Baseline: summary.Baseline != nil,
+	}
+
+	baseline := make(map[string]models.BenchmarkResult)
+	if summary.Baseline != nil {
+		for _, r := range summary.Baseline.Results {
+			baseline[r.Method+" "+r.Path] = r
+		}
+	}
+	seen := make(map[string]bool)
+	for _, r := range summary.Results {
+		endpoint := r.Method + " " + r.Path
+		seen[endpoint] = true
+		row := reportRow{Endpoint: endpoint}
+		if base, ok := baseline[endpoint]; ok {
+			row.Cells = resultCells(r, &base)
+		} else {
+			row.Cells = resultCells(r, nil)
+			row.New = summary.Baseline != nil
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	if summary.Baseline != nil {
+		for _, r := range summary.Baseline.Results {
+			if endpoint := r.Method + " " + r.Path; !seen[endpoint] {
+				report.Removed = append(report.Removed, endpoint)
+			}
+		}
+	}
+
+	overall := models.BenchmarkResult{
+		AvgTime:        summary.OverallAvgTime,
+		RequestsPerSec: summary.OverallReqsPerSec,
+		ErrorRate:      summary.OverallErrorRate,
+	}
+	var base *models.BenchmarkResult
+	if b := summary.Baseline; b != nil {
+		base = &models.BenchmarkResult{AvgTime: b.OverallAvgTime, RequestsPerSec: b.OverallReqsPerSec, ErrorRate: b.OverallErrorRate}
+	}
+	report.Overall = resultCells(overall, base)
+	// Percentiles are per endpoint only
+	report.Overall[1], report.Overall[2], report.Overall[3] = reportCell{}, reportCell{}, reportCell{}
+	return report
+}
+
+// resultCells returns the metrics of a result in reportColumns order
+func resultCells(r models.BenchmarkResult, base *models.BenchmarkResult) []reportCell {
+	latency := func(d time.Duration, b func(models.BenchmarkResult) time.Duration) reportCell {
+		cell := reportCell{Value: fmt.Sprintf("%.2f", float64(d.Microseconds())/1000)}
+		if base != nil {
+			cell.Delta, cell.Direction = relativeDelta(float64(d), float64(b(*base)), true)
+		}
+		return cell
+	}
+	throughput := reportCell{Value: fmt.Sprintf("%.2f", r.RequestsPerSec)}
+	errors := reportCell{Value: fmt.Sprintf("%.2f%%", r.ErrorRate)}
+	if base != nil {
+		throughput.Delta, throughput.Direction = relativeDelta(r.RequestsPerSec, base.RequestsPerSec, false)
+		errors.Delta, errors.Direction = pointsDelta(r.ErrorRate, base.ErrorRate)
+	}
+	return []reportCell{
+		latency(r.AvgTime, func(b models.BenchmarkResult) time.Duration { return b.AvgTime }),
+		latency(r.P50Time, func(b models.BenchmarkResult) time.Duration { return b.P50Time }),
+		latency(r.P90Time, func(b models.BenchmarkResult) time.Duration { return b.P90Time }),
+		latency(r.P99Time, func(b models.BenchmarkResult) time.Duration { return b.P99Time }),
+		throughput,
+		errors,
+	}
+}
+
+// relativeDelta formats the change from base to value in percent. An
+// increase is worse if higherIsWorse, as for latencies.
+func relativeDelta(value, base float64, higherIsWorse bool) (string, string) {
+	if base <= 0 {
+		return "", deltaNone
+	}
+	pct := (value - base) / base * 100
+	return formatDelta(pct, "%", higherIsWorse)
+}
+
+// pointsDelta formats the change of a percentage in percentage points, since
+// a relative change from a 0% error rate is undefined
+func pointsDelta(value, base float64) (string, string) {
+	return formatDelta(value-base, "pp", true)
+}
+
+func formatDelta(change float64, unit string, higherIsWorse bool) (string, string) {
+	rounded := math.Round(change*10) / 10
+	switch {
+	case rounded == 0:
+		return "± 0.0" + unit, deltaUnchanged
+	case (rounded > 0) == higherIsWorse:
+		return deltaArrow(rounded) + fmt.Sprintf(" %.1f%s", math.Abs(rounded), unit), deltaWorse
+	default:
+		return deltaArrow(rounded) + fmt.Sprintf(" %.1f%s", math.Abs(rounded), unit), deltaBetter
+	}
+}
+
+func deltaArrow(change float64) string {
+	if change > 0 {
+		return "▲"
+	}
+	return "▼"
+}
+
+// exportBenchmarkMarkdown exports benchmark results as a Markdown report,
+// e.g. for a pull request comment
+func exportBenchmarkMarkdown(w io.Writer, summary models.BenchmarkSummary) error {
+	report := newBenchmarkReport(summary)
+	var b strings.Builder
+
+	b.WriteString("# Benchmark results\n\n")
+	b.WriteString(report.Headline + "\n\n")
+	if report.Baseline {
+		b.WriteString("Changes from the baseline are marked ▲ (increase) and ▼ (decrease); error rates change in percentage points.\n\n")
+	}
+
+	b.WriteString("| Endpoint | " + strings.Join(report.Columns, " | ") + " |\n")
+	b.WriteString("|---" + strings.Repeat("|--:", len(report.Columns)) + "|\n")
+	for _, row := range report.Rows {
+		endpoint := "`" + markdownEscape(row.Endpoint) + "`"
+		if row.New {
+			endpoint += " (new)"
+		}
+		writeMarkdownRow(&b, endpoint, row.Cells)
+	}
+	writeMarkdownRow(&b, "**Overall**", report.Overall)
+
+	if len(report.Removed) > 0 {
+		b.WriteString("\nNot in this run, only in the baseline:\n\n")
+		for _, endpoint := range report.Removed {
+			b.WriteString("- `" + markdownEscape(endpoint) + "`\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownRow(b *strings.Builder, endpoint string, cells []reportCell) {
+	b.WriteString("| " + endpoint)
+	for _, cell := range cells {
+		b.WriteString(" | " + cell.Value)
+		if cell.Delta != "" {
+			b.WriteString(" " + cell.Delta)
+		}
+	}
+	b.WriteString(" |\n")
+}
+
+// markdownEscape escapes the characters that would end a table cell
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// exportBenchmarkHTML exports benchmark results as a self-contained HTML
+// report, with regressions from the baseline colored red and improvements
+// green
+func exportBenchmarkHTML(w io.Writer, summary models.BenchmarkSummary) error {
+	return benchmarkTemplate.Execute(w, newBenchmarkReport(summary))
+}
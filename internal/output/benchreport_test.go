@@ -0,0 +1,105 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func benchmarkReportSummary() models.BenchmarkSummary {
+	baseline := &models.BenchmarkSummary{
+		OverallAvgTime:    20 * time.Millisecond,
+		OverallReqsPerSec: 100,
+		Results: []models.BenchmarkResult{
+			{Method: "GET", Path: "/pets", AvgTime: 10 * time.Millisecond, P50Time: 10 * time.Millisecond, P90Time: 20 * time.Millisecond, P99Time: 40 * time.Millisecond, RequestsPerSec: 100},
+			{Method: "GET", Path: "/owners", AvgTime: 30 * time.Millisecond, RequestsPerSec: 50},
+		},
+	}
+	return models.BenchmarkSummary{
+		TotalEndpoints:    2,
+		OverallAvgTime:    20 * time.Millisecond,
+		OverallReqsPerSec: 80,
+		OverallErrorRate:  1,
+		Baseline:          baseline,
+		Results: []models.BenchmarkResult{
+			{Method: "GET", Path: "/pets", AvgTime: 12 * time.Millisecond, P50Time: 9 * time.Millisecond, P90Time: 20 * time.Millisecond, P99Time: 50 * time.Millisecond, RequestsPerSec: 80, ErrorRate: 2.5},
+			{Method: "GET", Path: "/pets/{petId}", AvgTime: 5 * time.Millisecond, RequestsPerSec: 200},
+		},
+	}
+}
+
+func TestBenchmarkReportDeltas(t *testing.T) {
+	report := newBenchmarkReport(benchmarkReportSummary())
+
+	if len(report.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %+v", report.Rows)
+	}
+	want := []reportCell{
+		{Value: "12.00", Delta: "▲ 20.0%", Direction: deltaWorse},
+		{Value: "9.00", Delta: "▼ 10.0%", Direction: deltaBetter},
+		{Value: "20.00", Delta: "± 0.0%", Direction: deltaUnchanged},
+		{Value: "50.00", Delta: "▲ 25.0%", Direction: deltaWorse},
+		{Value: "80.00", Delta: "▼ 20.0%", Direction: deltaWorse}, // lower throughput is a regression
+		{Value: "2.50%", Delta: "▲ 2.5pp", Direction: deltaWorse},
+	}
+	for i, cell := range report.Rows[0].Cells {
+		if cell != want[i] {
+			t.Errorf("%s: expected %+v, got %+v", report.Columns[i], want[i], cell)
+		}
+	}
+
+	if row := report.Rows[1]; !row.New || row.Cells[0].Delta != "" {
+		t.Errorf("Expected a new endpoint without deltas, got %+v", row)
+	}
+	if len(report.Removed) != 1 || report.Removed[0] != "GET /owners" {
+		t.Errorf("Expected GET /owners as removed, got %v", report.Removed)
+	}
+	if report.Overall[4].Delta != "▼ 20.0%" || report.Overall[1].Value != "" {
+		t.Errorf("Expected overall throughput delta and no overall percentiles, got %+v", report.Overall)
+	}
+}
+
+func TestExportBenchmarkReports(t *testing.T) {
+	summary := benchmarkReportSummary()
+
+	var md bytes.Buffer
+	if err := exportBenchmarkMarkdown(&md, summary); err != nil {
+		t.Fatalf("exportBenchmarkMarkdown: %v", err)
+	}
+	for _, want := range []string{
+		"| `GET /pets` | 12.00 ▲ 20.0% | 9.00 ▼ 10.0% |",
+		"| `GET /pets/{petId}` (new) | 5.00 |",
+		"- `GET /owners`",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Expected Markdown report to contain %q, got:\n%s", want, md.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := exportBenchmarkHTML(&html, summary); err != nil {
+		t.Fatalf("exportBenchmarkHTML: %v", err)
+	}
+	for _, want := range []string{
+		`<span class="delta worse">▲ 20.0%</span>`,
+		`<span class="delta better">▼ 10.0%</span>`,
+		`<code>GET /pets/{petId}</code> <span class="new">new</span>`,
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("Expected HTML report to contain %q, got:\n%s", want, html.String())
+		}
+	}
+
+	// Without a baseline the reports carry no annotations
+	summary.Baseline = nil
+	md.Reset()
+	if err := exportBenchmarkMarkdown(&md, summary); err != nil {
+		t.Fatalf("exportBenchmarkMarkdown: %v", err)
+	}
+	if strings.ContainsAny(md.String(), "▲▼") || strings.Contains(md.String(), "(new)") {
+		t.Errorf("Expected no annotations without a baseline, got:\n%s", md.String())
+	}
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Destination is one place results are written to
+type Destination struct {
+	Format Format
+	Path   string // empty for stdout
+}
+
+// ParseDestination parses a --tee value of the form "format:path", or a bare
+// path whose format is taken from its extension
+func ParseDestination(s string) (Destination, error) {
+	if prefix, path, ok := strings.Cut(s, ":"); ok {
+		if format, err := ParseFormat(prefix); err == nil {
+			if path == "" {
+				return Destination{}, fmt.Errorf("invalid destination '%s': missing file path", s)
+			}
+			return Destination{Format: format, Path: path}, nil
+		}
+	}
+	if s == "" {
+		return Destination{}, fmt.Errorf("invalid destination: missing file path")
+	}
+
+	name := strings.TrimSuffix(strings.ToLower(s), ".gz")
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	format, err := ParseFormat(ext)
+	if err != nil {
+		return Destination{}, fmt.Errorf("invalid destination '%s': use format:path or a .json/.csv/.md/.html file name (optionally .gz)", s)
+	}
+	return Destination{Format: format, Path: s}, nil
+}
+
+// Destinations combines the -o/--output-file pair with any --tee values and
+// expands file name placeholders using vars (see ExpandPath). The -o
+// destination is written to stdout when no output file is given.
+func Destinations(format, file string, tees []string, vars map[string]string) ([]Destination, error) {
+	var dests []Destination
+	if format != "" {
+		f, err := ParseFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, Destination{Format: f, Path: file})
+	}
+	for _, tee := range tees {
+		dest, err := ParseDestination(tee)
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, dest)
+	}
+	for i := range dests {
+		if dests[i].Path == "" {
+			if dests[i].Format == FormatAllure {
+				return nil, fmt.Errorf("allure output is a directory of result files: set it with --output-file")
+			}
+			continue
+		}
+		path, err := ExpandPath(dests[i].Path, vars)
+		if err != nil {
+			return nil, err
+		}
+		dests[i].Path = path
+	}
+	return dests, nil
+}
+
+// WritesStdout reports whether any destination writes to stdout: without a
+// path, or with one that resolves to stdout, such as /dev/stdout, /dev/fd/1
+// or the file stdout is redirected to
+func WritesStdout(dests []Destination) bool {
+	for _, dest := range dests {
+		if dest.Path == "" || isStdout(dest.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStdout reports whether path is the process's stdout
+func isStdout(path string) bool {
+	switch filepath.Clean(path) {
+	case "/dev/stdout", "/dev/fd/1", "/proc/self/fd/1":
+		return true
+	case os.DevNull:
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	stdout, err := os.Stdout.Stat()
+	return err == nil && os.SameFile(info, stdout)
+}
@@ -0,0 +1,30 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWritesStdout(t *testing.T) {
+	dir := t.TempDir()
+	redirected := filepath.Join(dir, "events.ndjson")
+	f, err := os.Create(redirected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+	os.Stdout = f
+
+	for _, path := range []string{"", "/dev/stdout", "/dev/fd/1", redirected} {
+		if !WritesStdout([]Destination{{Format: FormatJSON, Path: path}}) {
+			t.Errorf("Expected %q to write to stdout", path)
+		}
+	}
+	for _, path := range []string{filepath.Join(dir, "results.json"), os.DevNull} {
+		if WritesStdout([]Destination{{Format: FormatJSON, Path: path}}) {
+			t.Errorf("Expected %q not to write to stdout", path)
+		}
+	}
+}
@@ -1,13 +1,19 @@
 package output
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
+	"github.com/moamenhredeen/oas/internal/mask"
 	"github.com/moamenhredeen/oas/internal/models"
 )
 
@@ -17,59 +23,156 @@ type Format string
 const (
 	FormatJSON Format = "json"
 	FormatCSV  Format = "csv"
+
+	// FormatHistogramCSV writes one row per latency histogram bucket per
+	// endpoint (benchmarks only)
+	FormatHistogramCSV Format = "csv-histogram"
+
+	// FormatDetailCSV writes one row per validation error of each failing
+	// test, for triaging failures in a spreadsheet (tests only)
+	FormatDetailCSV Format = "csv-detail"
+
+	// FormatAllure writes a directory of Allure result files, one per
+	// operation (tests only)
+	FormatAllure Format = "allure"
+
+	// FormatSonar writes a SonarQube generic test execution report (tests only)
+	FormatSonar Format = "sonar"
+
+	// FormatMarkdown and FormatHTML write a human-readable report, annotated
+	// with the changes from a baseline run if one is set (benchmarks only)
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
 )
 
 // ExportTestSummary exports test results to the specified format
 func ExportTestSummary(summary models.TestSummary, format Format, filePath string) error {
-	w, closer, err := getWriter(filePath)
-	if err != nil {
-		return err
-	}
-	if closer != nil {
-		defer closer.Close()
-	}
-
+	summary = mask.Value(summary)
+	var buf bytes.Buffer
 	switch format {
 	case FormatJSON:
-		return exportTestJSON(w, summary)
+		if err := exportTestJSON(&buf, summary); err != nil {
+			return err
+		}
 	case FormatCSV:
-		return exportTestCSV(w, summary)
+		if err := exportTestCSV(&buf, summary); err != nil {
+			return err
+		}
+	case FormatDetailCSV:
+		if err := exportTestDetailCSV(&buf, summary); err != nil {
+			return err
+		}
+	case FormatAllure:
+		return exportTestAllure(summary, filePath)
+	case FormatSonar:
+		if err := exportTestSonar(&buf, summary); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
+	return writeOutput(buf.Bytes(), filePath)
 }
 
 // ExportBenchmarkSummary exports benchmark results to the specified format
 func ExportBenchmarkSummary(summary models.BenchmarkSummary, format Format, filePath string) error {
-	w, closer, err := getWriter(filePath)
-	if err != nil {
-		return err
-	}
-	if closer != nil {
-		defer closer.Close()
-	}
-
+	summary = mask.Value(summary)
+	var buf bytes.Buffer
 	switch format {
 	case FormatJSON:
-		return exportBenchmarkJSON(w, summary)
+		if err := exportBenchmarkJSON(&buf, summary); err != nil {
+			return err
+		}
 	case FormatCSV:
-		return exportBenchmarkCSV(w, summary)
+		if err := exportBenchmarkCSV(&buf, summary); err != nil {
+			return err
+		}
+	case FormatHistogramCSV:
+		if err := exportBenchmarkHistogramCSV(&buf, summary); err != nil {
+			return err
+		}
+	case FormatMarkdown:
+		if err := exportBenchmarkMarkdown(&buf, summary); err != nil {
+			return err
+		}
+	case FormatHTML:
+		if err := exportBenchmarkHTML(&buf, summary); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
+	return writeOutput(buf.Bytes(), filePath)
 }
 
-// getWriter returns an io.Writer for output (stdout or file)
-func getWriter(filePath string) (io.Writer, io.Closer, error) {
+// writeOutput writes rendered output to filePath, or to stdout if it is
+// empty. Files whose name ends in .gz are gzip-compressed.
+func writeOutput(data []byte, filePath string) error {
 	if filePath == "" {
-		return os.Stdout, nil, nil
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if strings.HasSuffix(strings.ToLower(filePath), ".gz") {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Name = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		if _, err := zw.Write(data); err != nil {
+			return fmt.Errorf("failed to compress output: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress output: %w", err)
+		}
+		data = buf.Bytes()
+	}
+	return writeFileAtomic(filePath, data)
+}
+
+// Rewritable reports whether results can be written to filePath repeatedly,
+// each write replacing the previous one: a regular file, possibly behind a
+// symlink, or a file that doesn't exist yet. Writes to anything else, such
+// as /dev/stderr or a FIFO, reach the reader one after another.
+func Rewritable(filePath string) bool {
+	info, err := os.Stat(filePath)
+	return os.IsNotExist(err) || (err == nil && info.Mode().IsRegular())
+}
+
+// writeFileAtomic writes data to a temporary file next to filePath and renames
+// it into place, so readers never see a truncated file even if the process is
+// killed mid-write. Only regular files are replaced that way: anything else,
+// such as /dev/stdout, a FIFO or a symlink, is written in place so the data
+// reaches its reader or the link target.
+func writeFileAtomic(filePath string, data []byte) error {
+	if info, err := os.Lstat(filePath); err == nil && !info.Mode().IsRegular() {
+		if err := os.WriteFile(filePath, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		return nil
 	}
 
-	f, err := os.Create(filePath)
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create output file: %w", err)
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
 	}
-	return f, f, nil
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
 }
 
 // exportTestJSON exports test results as JSON
@@ -87,7 +190,8 @@ func exportTestCSV(w io.Writer, summary models.TestSummary) error {
 	// Write header
 	header := []string{
 		"method", "path", "operation_id", "passed", "status_code",
-		"response_time_ms", "error",
+		"response_time_ms", "error", "runs", "flakiness_pct", "request_id",
+		"address_family", "retries", "retry_time_ms", "spec", "skip_reason",
 	}
 	if err := cw.Write(header); err != nil {
 		return err
@@ -103,11 +207,22 @@ func exportTestCSV(w io.Writer, summary models.TestSummary) error {
 			strconv.Itoa(r.StatusCode),
 			fmt.Sprintf("%.2f", float64(r.ResponseTime.Milliseconds())),
 			r.Error,
+			strconv.Itoa(max(1, r.Runs)),
+			fmt.Sprintf("%.2f", r.Flakiness),
+			r.RequestID,
+			r.AddressFamily,
+			strconv.Itoa(r.Retries),
+			fmt.Sprintf("%.2f", float64(r.RetryTime.Milliseconds())),
+			r.Spec,
+			"",
 		}
 		if err := cw.Write(row); err != nil {
 			return err
 		}
 	}
+	if err := writeSkippedRows(cw, summary.SkippedOperations, header); err != nil {
+		return err
+	}
 
 	return cw.Error()
 }
@@ -129,6 +244,10 @@ func exportBenchmarkCSV(w io.Writer, summary models.BenchmarkSummary) error {
 		"method", "path", "operation_id", "iterations", "concurrency",
 		"min_ms", "max_ms", "avg_ms", "p50_ms", "p90_ms", "p99_ms",
 		"requests_per_sec", "success_count", "error_count", "error_rate",
+		"corrected_p50_ms", "corrected_p99_ms",
+		"cold_p50_ms", "cold_p99_ms",
+		"new_conns", "reused_conns", "slowest_request_id", "address_family",
+		"skip_reason",
 	}
 	if err := cw.Write(header); err != nil {
 		return err
@@ -136,6 +255,18 @@ func exportBenchmarkCSV(w io.Writer, summary models.BenchmarkSummary) error {
 
 	// Write rows
 	for _, r := range summary.Results {
+		// Corrected latency is only measured for rate-limited runs
+		var correctedP50, correctedP99 string
+		if r.Corrected != nil {
+			correctedP50 = fmt.Sprintf("%.2f", float64(r.Corrected.P50Time.Microseconds())/1000)
+			correctedP99 = fmt.Sprintf("%.2f", float64(r.Corrected.P99Time.Microseconds())/1000)
+		}
+		// Cold latency is only measured in cold/hot comparison mode
+		var coldP50, coldP99 string
+		if r.Cold != nil {
+			coldP50 = fmt.Sprintf("%.2f", float64(r.Cold.P50Time.Microseconds())/1000)
+			coldP99 = fmt.Sprintf("%.2f", float64(r.Cold.P99Time.Microseconds())/1000)
+		}
 		row := []string{
 			r.Method,
 			r.Path,
@@ -152,11 +283,137 @@ func exportBenchmarkCSV(w io.Writer, summary models.BenchmarkSummary) error {
 			strconv.Itoa(r.SuccessCount),
 			strconv.Itoa(r.ErrorCount),
 			fmt.Sprintf("%.2f", r.ErrorRate),
+			correctedP50,
+			correctedP99,
+			coldP50,
+			coldP99,
+			strconv.Itoa(r.NewConns),
+			strconv.Itoa(r.ReusedConns),
+			r.SlowestRequestID,
+			r.AddressFamily,
+			"",
 		}
 		if err := cw.Write(row); err != nil {
 			return err
 		}
 	}
+	if err := writeSkippedRows(cw, summary.SkippedOperations, header); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+// Severities of csv-detail rows
+const (
+	severityError   = "error"   // the operation failed every run
+	severityWarning = "warning" // the operation is flaky, it passed some runs
+)
+
+// exportTestDetailCSV exports a row per validation error of each failing
+// test. Failures without validation errors, such as a refused connection, get
+// a single row with the error.
+func exportTestDetailCSV(w io.Writer, summary models.TestSummary) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{
+		"method", "path", "operation_id", "spec", "status_code",
+		"field", "severity", "message", "failed_case", "request_id",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range summary.Results {
+		if r.Passed {
+			continue
+		}
+		severity := severityError
+		if r.Flaky {
+			severity = severityWarning
+		}
+		row := func(field, message string) []string {
+			return []string{
+				r.Method, r.Path, r.OperationID, r.Spec, strconv.Itoa(r.StatusCode),
+				field, severity, message, r.FailedCase, r.RequestID,
+			}
+		}
+		rows := [][]string{row("", r.Error)}
+		if len(r.ValidationErrors) > 0 {
+			rows = rows[:0]
+			for _, ve := range r.ValidationErrors {
+				rows = append(rows, row(ve.Field, ve.Message))
+			}
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+	}
+
+	return cw.Error()
+}
+
+// writeSkippedRows appends a row per skipped operation with only the
+// operation columns, the spec column (if the header has one) and the trailing
+// skip_reason column filled in
+func writeSkippedRows(cw *csv.Writer, skipped []models.SkippedOperation, header []string) error {
+	width := len(header)
+	specCol := slices.Index(header, "spec")
+	for _, s := range skipped {
+		row := make([]string, width)
+		row[0], row[1], row[2] = s.Method, s.Path, s.OperationID
+		if specCol >= 0 {
+			row[specCol] = s.Spec
+		}
+		row[width-1] = s.Reason
+		if s.Detail != "" {
+			row[width-1] += ": " + s.Detail
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportBenchmarkHistogramCSV exports the latency histogram of each endpoint
+// as CSV, one row per bucket
+func exportBenchmarkHistogramCSV(w io.Writer, summary models.BenchmarkSummary) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{
+		"method", "path", "operation_id",
+		"le_ms", "count", "cumulative_count", "cumulative_pct",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range summary.Results {
+		total := 0
+		for _, bucket := range r.Histogram {
+			total += bucket.Count
+		}
+
+		cumulative := 0
+		for _, bucket := range r.Histogram {
+			cumulative += bucket.Count
+			row := []string{
+				r.Method,
+				r.Path,
+				r.OperationID,
+				fmt.Sprintf("%.2f", float64(bucket.UpperBound.Microseconds())/1000),
+				strconv.Itoa(bucket.Count),
+				strconv.Itoa(cumulative),
+				fmt.Sprintf("%.2f", float64(cumulative)/float64(total)*100),
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
 
 	return cw.Error()
 }
@@ -168,7 +425,19 @@ func ParseFormat(s string) (Format, error) {
 		return FormatJSON, nil
 	case "csv":
 		return FormatCSV, nil
+	case "csv-histogram":
+		return FormatHistogramCSV, nil
+	case "csv-detail":
+		return FormatDetailCSV, nil
+	case "allure":
+		return FormatAllure, nil
+	case "sonar":
+		return FormatSonar, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
 	default:
-		return "", fmt.Errorf("invalid format '%s': must be 'json' or 'csv'", s)
+		return "", fmt.Errorf("invalid format '%s': must be 'json', 'csv', 'csv-histogram', 'csv-detail', 'allure', 'sonar', 'markdown' or 'html'", s)
 	}
 }
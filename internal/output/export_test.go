@@ -0,0 +1,156 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestExportTestDetailCSV(t *testing.T) {
+	var summary models.TestSummary
+	summary.AddResult(models.TestResult{Method: "GET", Path: "/pets", OperationID: "listPets", Passed: true, StatusCode: 200})
+	summary.AddResult(models.TestResult{
+		Method: "POST", Path: "/pets", OperationID: "createPets", StatusCode: 201,
+		Error: "validation failed: ...",
+		ValidationErrors: []models.ValidationError{
+			{Field: "body.id", Message: "expected integer, got string"},
+			{Field: "header.Location", Message: "required header missing"},
+		},
+	})
+	summary.AddResult(models.TestResult{Method: "GET", Path: "/owners", Error: "request failed: connection refused"})
+	summary.AddResult(models.TestResult{
+		Method: "GET", Path: "/pets/{petId}", StatusCode: 500, Flaky: true, Runs: 5, PassCount: 4,
+		Error:            "validation failed: ...",
+		ValidationErrors: []models.ValidationError{{Field: "status", Message: "unexpected status code 500"}},
+	})
+
+	var buf bytes.Buffer
+	if err := exportTestDetailCSV(&buf, summary); err != nil {
+		t.Fatalf("exportTestDetailCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"method", "path", "operation_id", "spec", "status_code", "field", "severity", "message", "failed_case", "request_id"},
+		{"POST", "/pets", "createPets", "", "201", "body.id", "error", "expected integer, got string", "", ""},
+		{"POST", "/pets", "createPets", "", "201", "header.Location", "error", "required header missing", "", ""},
+		{"GET", "/owners", "", "", "0", "", "error", "request failed: connection refused", "", ""},
+		{"GET", "/pets/{petId}", "", "", "500", "status", "warning", "unexpected status code 500", "", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("Row %d column %s: expected %q, got %q", i, want[0][j], want[i][j], rows[i][j])
+			}
+		}
+	}
+}
+
+func TestExportMasksFields(t *testing.T) {
+	// A secret that equals a number in the report, and one JSON escapes
+	mask.Register("8080", `pa"ss<word`)
+
+	var summary models.TestSummary
+	summary.AddResult(models.TestResult{
+		Method: "GET", Path: "/pets", StatusCode: 401, Runs: 8080,
+		Error: `login as admin:pa"ss<word failed`,
+	})
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "report.json")
+	if err := ExportTestSummary(summary, FormatJSON, jsonPath); err != nil {
+		t.Fatalf("ExportTestSummary: %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got models.TestSummary
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Masked JSON is invalid: %v\n%s", err, data)
+	}
+	if got.Results[0].Runs != 8080 {
+		t.Errorf("Expected the number 8080 to survive masking, got %d", got.Results[0].Runs)
+	}
+	if want := "login as admin:**** failed"; got.Results[0].Error != want {
+		t.Errorf("Expected error %q, got %q", want, got.Results[0].Error)
+	}
+
+	csvPath := filepath.Join(dir, "report.csv")
+	if err := ExportTestSummary(summary, FormatCSV, csvPath); err != nil {
+		t.Fatalf("ExportTestSummary: %v", err)
+	}
+	f, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Masked CSV is invalid: %v", err)
+	}
+	if rows[1][6] != "login as admin:**** failed" || rows[1][7] != "8080" {
+		t.Errorf("Expected masked error and runs 8080, got %v", rows[1])
+	}
+}
+
+func TestWriteFileAtomicFollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "results.json")
+	link := filepath.Join(dir, "latest.json")
+	if err := os.WriteFile(target, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if err := writeFileAtomic(link, []byte("new")); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected %s to stay a symlink", link)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("Expected the link target to be written, got %q", data)
+	}
+
+	// Regular and new files are still replaced atomically
+	fresh := filepath.Join(dir, "fresh.json")
+	if err := writeFileAtomic(fresh, []byte("data")); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+	if data, _ := os.ReadFile(fresh); string(data) != "data" {
+		t.Errorf("Expected a new file with the data, got %q", data)
+	}
+}
+
+func TestRewritable(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "results.json")
+	if err := os.WriteFile(regular, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{regular, filepath.Join(dir, "new.json")} {
+		if !Rewritable(path) {
+			t.Errorf("Expected %s to be rewritable", path)
+		}
+	}
+	for _, path := range []string{os.DevNull, dir} {
+		if Rewritable(path) {
+			t.Errorf("Expected %s not to be rewritable", path)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// sonarExecutions is SonarQube's generic test execution report
+type sonarExecutions struct {
+	XMLName xml.Name    `xml:"testExecutions"`
+	Version int         `xml:"version,attr"`
+	Files   []sonarFile `xml:"file"`
+}
+
+type sonarFile struct {
+	Path      string          `xml:"path,attr"`
+	TestCases []sonarTestCase `xml:"testCase"`
+}
+
+type sonarTestCase struct {
+	Name     string        `xml:"name,attr"`
+	Duration int64         `xml:"duration,attr"` // milliseconds
+	Failure  *sonarMessage `xml:"failure,omitempty"`
+	Error    *sonarMessage `xml:"error,omitempty"`
+	Skipped  *sonarMessage `xml:"skipped,omitempty"`
+}
+
+type sonarMessage struct {
+	Message string `xml:"message,attr"`
+	Detail  string `xml:",chardata"`
+}
+
+// exportTestSonar exports test results as a SonarQube generic test execution
+// report, with the spec file as the test file of its operations. Contract
+// violations are failures, tests that got no response to judge are errors,
+// and operations the run didn't exercise are skipped.
+func exportTestSonar(w io.Writer, summary models.TestSummary) error {
+	report := sonarExecutions{Version: 1}
+	files := make(map[string]int)
+	add := func(spec string, tc sonarTestCase) error {
+		if spec == "" {
+			spec = summary.Spec
+		}
+		if spec == "" {
+			return fmt.Errorf("sonar output needs the spec file of the results")
+		}
+		i, ok := files[spec]
+		if !ok {
+			i = len(report.Files)
+			files[spec] = i
+			report.Files = append(report.Files, sonarFile{Path: spec})
+		}
+		report.Files[i].TestCases = append(report.Files[i].TestCases, tc)
+		return nil
+	}
+
+	for _, r := range summary.Results {
+		tc := sonarTestCase{
+			Name:     r.Method + " " + r.Path,
+			Duration: (r.ResponseTime + r.RetryTime).Milliseconds(),
+		}
+		if !r.Passed {
+			var detail []string
+			for _, ve := range r.ValidationErrors {
+				detail = append(detail, ve.Field+": "+ve.Message)
+			}
+			msg := &sonarMessage{Message: r.Error, Detail: strings.Join(detail, "\n")}
+			if len(r.ValidationErrors) > 0 {
+				tc.Failure = msg
+			} else {
+				tc.Error = msg
+			}
+		}
+		if err := add(r.Spec, tc); err != nil {
+			return err
+		}
+	}
+	for _, op := range summary.SkippedOperations {
+		tc := sonarTestCase{
+			Name:    op.Method + " " + op.Path,
+			Skipped: &sonarMessage{Message: op.Reason, Detail: op.Detail},
+		}
+		if err := add(op.Spec, tc); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
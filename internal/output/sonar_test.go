@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestExportTestSonar(t *testing.T) {
+	summary := models.TestSummary{
+		Spec:              "api/openapi.yaml",
+		SkippedOperations: []models.SkippedOperation{{Method: "DELETE", Path: "/pets/{id}", Reason: models.SkipMutating}},
+	}
+	summary.AddResult(models.TestResult{Method: "GET", Path: "/pets", Passed: true, ResponseTime: 42 * time.Millisecond})
+	summary.AddResult(models.TestResult{
+		Method: "POST", Path: "/pets", Error: "validation failed: body.id: expected integer",
+		ValidationErrors: []models.ValidationError{{Field: "body.id", Message: "expected integer"}},
+	})
+	summary.AddResult(models.TestResult{Method: "GET", Path: "/owners", Error: "request failed: connection refused"})
+
+	var buf bytes.Buffer
+	if err := exportTestSonar(&buf, summary); err != nil {
+		t.Fatalf("exportTestSonar: %v", err)
+	}
+	var report sonarExecutions
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, buf.String())
+	}
+
+	if report.Version != 1 || len(report.Files) != 1 || report.Files[0].Path != "api/openapi.yaml" {
+		t.Fatalf("Expected one file for the spec, got %+v", report)
+	}
+	cases := report.Files[0].TestCases
+	if len(cases) != 4 {
+		t.Fatalf("Expected 4 test cases, got %+v", cases)
+	}
+	if cases[0].Name != "GET /pets" || cases[0].Duration != 42 || cases[0].Failure != nil || cases[0].Error != nil {
+		t.Errorf("Unexpected passed case: %+v", cases[0])
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Detail != "body.id: expected integer" {
+		t.Errorf("Expected a failure with the validation errors, got %+v", cases[1])
+	}
+	if cases[2].Error == nil || cases[2].Failure != nil {
+		t.Errorf("Expected an error for a request failure, got %+v", cases[2])
+	}
+	if cases[3].Skipped == nil || cases[3].Skipped.Message != models.SkipMutating {
+		t.Errorf("Expected a skipped case, got %+v", cases[3])
+	}
+}
+
+func TestExportTestSonarMultiSpec(t *testing.T) {
+	var summary models.TestSummary
+	for _, spec := range []string{"a.yaml", "b.yaml"} {
+		var other models.TestSummary
+		other.AddResult(models.TestResult{Method: "GET", Path: "/health", Passed: true})
+		summary.AddSpec(spec, 1, other)
+	}
+
+	var buf bytes.Buffer
+	if err := exportTestSonar(&buf, summary); err != nil {
+		t.Fatalf("exportTestSonar: %v", err)
+	}
+	var report sonarExecutions
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid XML: %v", err)
+	}
+	if len(report.Files) != 2 || report.Files[0].Path != "a.yaml" || report.Files[1].Path != "b.yaml" {
+		t.Errorf("Expected a file per spec, got %+v", report.Files)
+	}
+
+	if err := exportTestSonar(&buf, models.TestSummary{Results: []models.TestResult{{Method: "GET", Path: "/"}}}); err == nil {
+		t.Error("Expected an error without a spec file")
+	}
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// PathVars builds the placeholder values available to output file names.
+// Empty values are left out so that using them is reported as an error.
+func PathVars(now time.Time, gitSHA, profile, specVersion, runID string) map[string]string {
+	vars := map[string]string{
+		"timestamp": now.Format("20060102-150405"),
+		"date":      now.Format("2006-01-02"),
+		"run_id":    runID,
+	}
+	for name, value := range map[string]string{
+		"git_sha":      gitSHA,
+		"profile":      profile,
+		"spec_version": specVersion,
+	} {
+		if value != "" {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// ExpandPath replaces {name} placeholders in an output file name, e.g.
+// "results-{timestamp}-{spec_version}.json". Values are sanitized so they
+// cannot introduce path separators.
+func ExpandPath(path string, vars map[string]string) (string, error) {
+	var missing []string
+	expanded := placeholderPattern.ReplaceAllStringFunc(path, func(m string) string {
+		name := m[1 : len(m)-1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, m)
+			return m
+		}
+		return sanitizePathValue(value)
+	})
+	if len(missing) > 0 {
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, "{"+name+"}")
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown or unavailable placeholder %s in '%s': available are %s",
+			strings.Join(missing, ", "), path, strings.Join(names, ", "))
+	}
+	return expanded, nil
+}
+
+// sanitizePathValue makes a placeholder value safe to use inside a file name
+func sanitizePathValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ', '*', '?', '"', '<', '>', '|':
+			return '-'
+		}
+		return r
+	}, value)
+}
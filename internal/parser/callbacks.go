@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Callback is a request the API sends back to a URL taken from the request
+// (or response) that triggered it
+type Callback struct {
+	Name       string // callback name in the triggering operation
+	Expression string // runtime expression of the URL, e.g. {$request.body#/callbackUrl}
+	Method     string
+	Operation  *v3.Operation
+}
+
+// Webhook is a request the API sends on its own initiative to a URL
+// registered out of band (OpenAPI 3.1 webhooks)
+type Webhook struct {
+	Name      string
+	Method    string
+	Operation *v3.Operation
+}
+
+// Callbacks returns the callbacks the operation declares, in spec order
+func (d *OperationDetails) Callbacks() []Callback {
+	if d == nil || d.Operation == nil || d.Operation.Callbacks == nil {
+		return nil
+	}
+
+	var callbacks []Callback
+	for pair := d.Operation.Callbacks.First(); pair != nil; pair = pair.Next() {
+		if pair.Value() == nil || pair.Value().Expression == nil {
+			continue
+		}
+		for expr := pair.Value().Expression.First(); expr != nil; expr = expr.Next() {
+			if expr.Value() == nil {
+				continue
+			}
+			for op := expr.Value().GetOperations().First(); op != nil; op = op.Next() {
+				callbacks = append(callbacks, Callback{
+					Name:       pair.Key(),
+					Expression: expr.Key(),
+					Method:     strings.ToUpper(op.Key()),
+					Operation:  op.Value(),
+				})
+			}
+		}
+	}
+	return callbacks
+}
+
+// GetWebhooks returns the webhooks the spec declares, in spec order
+func (p *Parser) GetWebhooks() ([]Webhook, error) {
+	model, errs := p.document.BuildV3Model()
+	if errs != nil {
+		return nil, fmt.Errorf("failed to build v3 model: %v", errs)
+	}
+	if model.Model.Webhooks == nil {
+		return nil, nil
+	}
+
+	var webhooks []Webhook
+	for pair := model.Model.Webhooks.First(); pair != nil; pair = pair.Next() {
+		if pair.Value() == nil {
+			continue
+		}
+		for op := pair.Value().GetOperations().First(); op != nil; op = op.Next() {
+			webhooks = append(webhooks, Webhook{
+				Name:      pair.Key(),
+				Method:    strings.ToUpper(op.Key()),
+				Operation: op.Value(),
+			})
+		}
+	}
+	return webhooks, nil
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestCallbacks(t *testing.T) {
+	p, err := ParseFile("../../tests/callback-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/subscriptions", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	callbacks := opDetails.Callbacks()
+	if len(callbacks) != 1 {
+		t.Fatalf("Expected 1 callback, got %d", len(callbacks))
+	}
+	cb := callbacks[0]
+	if cb.Name != "onEvent" || cb.Expression != "{$request.body#/callbackUrl}" || cb.Method != "POST" || cb.Operation == nil {
+		t.Errorf("Unexpected callback %+v", cb)
+	}
+
+	p, err = ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err = p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if callbacks := opDetails.Callbacks(); len(callbacks) != 0 {
+		t.Errorf("Expected no callbacks, got %+v", callbacks)
+	}
+}
+
+func TestGetWebhooks(t *testing.T) {
+	p, err := ParseFile("../../tests/callback-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	webhooks, err := p.GetWebhooks()
+	if err != nil {
+		t.Fatalf("Failed to get webhooks: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].Name != "newPet" || webhooks[0].Method != "POST" {
+		t.Errorf("Expected the newPet POST webhook, got %+v", webhooks)
+	}
+}
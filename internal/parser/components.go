@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// NamedSchema is a schema declared under components/schemas
+type NamedSchema struct {
+	Name   string
+	Schema *base.Schema
+}
+
+// NamedSecurityScheme is a scheme declared under components/securitySchemes
+type NamedSecurityScheme struct {
+	Name   string
+	Scheme *v3.SecurityScheme
+}
+
+// GetSchemas returns the component schemas in spec order
+func (p *Parser) GetSchemas() ([]NamedSchema, error) {
+	model, errs := p.document.BuildV3Model()
+	if errs != nil {
+		return nil, fmt.Errorf("failed to build v3 model: %v", errs)
+	}
+
+	components := model.Model.Components
+	if components == nil || components.Schemas == nil {
+		return nil, nil
+	}
+
+	var schemas []NamedSchema
+	for pair := components.Schemas.First(); pair != nil; pair = pair.Next() {
+		proxy := pair.Value()
+		if proxy == nil {
+			continue
+		}
+		schema := proxy.Schema()
+		if schema == nil {
+			return nil, fmt.Errorf("failed to build schema %s: %v", pair.Key(), proxy.GetBuildError())
+		}
+		schemas = append(schemas, NamedSchema{Name: pair.Key(), Schema: schema})
+	}
+	return schemas, nil
+}
+
+// GetSecuritySchemes returns the component security schemes in spec order
+func (p *Parser) GetSecuritySchemes() ([]NamedSecurityScheme, error) {
+	model, errs := p.document.BuildV3Model()
+	if errs != nil {
+		return nil, fmt.Errorf("failed to build v3 model: %v", errs)
+	}
+
+	components := model.Model.Components
+	if components == nil || components.SecuritySchemes == nil {
+		return nil, nil
+	}
+
+	var schemes []NamedSecurityScheme
+	for pair := components.SecuritySchemes.First(); pair != nil; pair = pair.Next() {
+		if pair.Value() != nil {
+			schemes = append(schemes, NamedSecurityScheme{Name: pair.Key(), Scheme: pair.Value()})
+		}
+	}
+	return schemes, nil
+}
+
+// grpcGatewaySchemas are schemas protoc-gen-openapiv2 and protoc-gen-openapi
+// add to specs generated from protobuf definitions
+var grpcGatewaySchemas = []string{"rpcStatus", "googlerpcStatus", "protobufAny", "GoogleProtobufAny", "GoogleRpcStatus"}
+
+// IsGRPCGateway reports whether the spec looks generated from protobuf
+// definitions for grpc-gateway, whose operations are named Service_Method
+func (p *Parser) IsGRPCGateway() bool {
+	schemas, err := p.GetSchemas()
+	if err != nil {
+		return false
+	}
+	for _, s := range schemas {
+		if slices.Contains(grpcGatewaySchemas, s.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTags returns the tags declared at the top level of the spec, followed by
+// tags that operations use without declaring them (with only a name)
+func (p *Parser) GetTags() ([]*base.Tag, error) {
+	model, errs := p.document.BuildV3Model()
+	if errs != nil {
+		return nil, fmt.Errorf("failed to build v3 model: %v", errs)
+	}
+
+	var tags []*base.Tag
+	seen := make(map[string]bool)
+	for _, tag := range model.Model.Tags {
+		if tag != nil && !seen[tag.Name] {
+			seen[tag.Name] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	if model.Model.Paths == nil || model.Model.Paths.PathItems == nil {
+		return tags, nil
+	}
+	for pair := model.Model.Paths.PathItems.First(); pair != nil; pair = pair.Next() {
+		if pair.Value() == nil {
+			continue
+		}
+		for op := range pair.Value().GetOperations().ValuesFromOldest() {
+			for _, name := range op.Tags {
+				if !seen[name] {
+					seen[name] = true
+					tags = append(tags, &base.Tag{Name: name})
+				}
+			}
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestGetSchemas(t *testing.T) {
+	p, err := ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	schemas, err := p.GetSchemas()
+	if err != nil {
+		t.Fatalf("Failed to get schemas: %v", err)
+	}
+
+	var names []string
+	for _, s := range schemas {
+		names = append(names, s.Name)
+		if s.Schema == nil {
+			t.Errorf("Expected schema %s to be built", s.Name)
+		}
+	}
+	if len(names) != 3 || names[0] != "Pet" || names[1] != "Pets" || names[2] != "Error" {
+		t.Errorf("Expected Pet, Pets and Error in spec order, got %v", names)
+	}
+}
+
+func TestGetSecuritySchemes(t *testing.T) {
+	p, err := ParseFile("../../tests/auth-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	schemes, err := p.GetSecuritySchemes()
+	if err != nil {
+		t.Fatalf("Failed to get security schemes: %v", err)
+	}
+	if len(schemes) != 1 || schemes[0].Name != "bearerAuth" {
+		t.Fatalf("Expected the bearerAuth scheme, got %+v", schemes)
+	}
+	if schemes[0].Scheme.Type != "http" || schemes[0].Scheme.Scheme != "bearer" {
+		t.Errorf("Expected an HTTP bearer scheme, got %+v", schemes[0].Scheme)
+	}
+
+	p, err = ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if schemes, err := p.GetSecuritySchemes(); err != nil || len(schemes) != 0 {
+		t.Errorf("Expected no security schemes, got %+v (%v)", schemes, err)
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	p, err := ParseFile("../../tests/auth-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	tags, err := p.GetTags()
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Name != "auth" || tags[0].Description == "" {
+		t.Errorf("Expected the declared auth tag once, got %+v", tags)
+	}
+
+	// Tags only used by operations are returned by name
+	p, err = ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	tags, err = p.GetTags()
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Name != "pets" {
+		t.Errorf("Expected the pets tag, got %+v", tags)
+	}
+}
+
+func TestIsGRPCGateway(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "../../tests/grpc-gateway-api.json", want: true},
+		{file: "../../tests/pet-store.json", want: false},
+	}
+
+	for _, tt := range tests {
+		p, err := ParseFile(tt.file)
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+		if got := p.IsGRPCGateway(); got != tt.want {
+			t.Errorf("IsGRPCGateway(%s) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
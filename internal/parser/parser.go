@@ -2,10 +2,14 @@ package parser
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/moamenhredeen/oas/internal/models"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
@@ -14,14 +18,32 @@ type Parser struct {
 	document libopenapi.Document
 }
 
-// ParseFile parses an OpenAPI specification file and returns a Parser instance
+// ParseFile parses an OpenAPI specification file and returns a Parser
+// instance. Swagger 2.0 documents are converted to OpenAPI 3.0. $refs to other
+// files are resolved relative to the spec file.
 func ParseFile(filePath string) (*Parser, error) {
+	return ParseFileWithRefs(filePath, RefConfig{})
+}
+
+// ParseFileWithRefs parses an OpenAPI specification file, resolving $refs to
+// other documents as configured by refs
+func ParseFileWithRefs(filePath string, refs RefConfig) (*Parser, error) {
+	config, err := documentConfig(filePath, refs)
+	if err != nil {
+		return nil, err
+	}
 	specBytes, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read OpenAPI file: %w", err)
 	}
+	if converted, ok, err := convertSwagger2(specBytes); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert Swagger 2.0 document: %w", err)
+		}
+		specBytes = converted
+	}
 
-	document, err := libopenapi.NewDocument(specBytes)
+	document, err := libopenapi.NewDocumentWithConfiguration(specBytes, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
 	}
@@ -29,6 +51,48 @@ func ParseFile(filePath string) (*Parser, error) {
 	return &Parser{document: document}, nil
 }
 
+// ParseResult is the outcome of parsing one spec file with ParseFiles
+type ParseResult struct {
+	File   string
+	Parser *Parser // nil if the spec failed to parse
+	Err    error
+}
+
+// ParseFiles parses specs concurrently, at most workers at a time, and builds
+// their models so that every broken spec is reported up front instead of when
+// it is first used. Results are in the order of files.
+func ParseFiles(files []string, workers int, refs RefConfig) []ParseResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]ParseResult, len(files))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, workers)
+	for i, file := range files {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			results[i] = ParseResult{File: file}
+			p, err := ParseFileWithRefs(file, refs)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			if _, errs := p.document.BuildV3Model(); errs != nil {
+				results[i].Err = fmt.Errorf("failed to build v3 model: %v", errs)
+				return
+			}
+			results[i].Parser = p
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
 // GetServerURLs returns the server URLs from the OpenAPI spec
 func (p *Parser) GetServerURLs() ([]string, error) {
 	model, errs := p.document.BuildV3Model()
@@ -51,6 +115,33 @@ func (p *Parser) GetServerURLs() ([]string, error) {
 	return urls, nil
 }
 
+// JoinURL joins a server URL and an operation path with exactly one slash,
+// so server URLs ending in "/" don't produce "//" in request paths
+func JoinURL(serverURL, path string) string {
+	return strings.TrimRight(serverURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// WithBasePath replaces the path of a server URL with basePath, e.g. to send
+// requests through a gateway that serves the API under another prefix
+func WithBasePath(serverURL, basePath string) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	u.Path = strings.TrimRight("/"+strings.Trim(basePath, "/"), "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// SpecVersion returns the API version declared in the spec's info section
+func (p *Parser) SpecVersion() string {
+	model, errs := p.document.BuildV3Model()
+	if errs != nil || model.Model.Info == nil {
+		return ""
+	}
+	return model.Model.Info.Version
+}
+
 // GetOperations extracts all operations from the OpenAPI spec
 func (p *Parser) GetOperations(serverURL string) ([]models.Operation, error) {
 	model, errs := p.document.BuildV3Model()
@@ -60,6 +151,7 @@ func (p *Parser) GetOperations(serverURL string) ([]models.Operation, error) {
 
 	var operations []models.Operation
 	paths := model.Model.Paths
+	schemes := declaredSecuritySchemes(model.Model.Components)
 
 	if paths == nil || paths.PathItems == nil {
 		return operations, nil
@@ -73,18 +165,23 @@ func (p *Parser) GetOperations(serverURL string) ([]models.Operation, error) {
 			continue
 		}
 
-		// Process each HTTP method
-		methods := map[string]*v3.Operation{
-			"GET":     pathItemValue.Get,
-			"POST":    pathItemValue.Post,
-			"PUT":     pathItemValue.Put,
-			"PATCH":   pathItemValue.Patch,
-			"DELETE":  pathItemValue.Delete,
-			"HEAD":    pathItemValue.Head,
-			"OPTIONS": pathItemValue.Options,
+		// Process each HTTP method, in a fixed order
+		methods := []struct {
+			name string
+			op   *v3.Operation
+		}{
+			{"GET", pathItemValue.Get},
+			{"POST", pathItemValue.Post},
+			{"PUT", pathItemValue.Put},
+			{"PATCH", pathItemValue.Patch},
+			{"DELETE", pathItemValue.Delete},
+			{"HEAD", pathItemValue.Head},
+			{"OPTIONS", pathItemValue.Options},
+			{"TRACE", pathItemValue.Trace},
 		}
 
-		for method, op := range methods {
+		for _, m := range methods {
+			method, op := m.name, m.op
 			if op == nil {
 				continue
 			}
@@ -95,13 +192,33 @@ func (p *Parser) GetOperations(serverURL string) ([]models.Operation, error) {
 				tags = append(tags, op.Tags...)
 			}
 
+			summary, description := op.Summary, op.Description
+			if summary == "" {
+				summary = pathItemValue.Summary
+			}
+			if description == "" {
+				description = pathItemValue.Description
+			}
+
+			// Operation security overrides the spec's, even when empty
+			security := securitySchemes(model.Model.Security)
+			if op.Security != nil {
+				security = securitySchemes(op.Security)
+			}
+
 			operations = append(operations, models.Operation{
 				Path:        pathItem,
 				Method:      method,
 				OperationID: operationID,
 				Tags:        tags,
 				ServerURL:   serverURL,
-				FullPath:    serverURL + pathItem,
+				FullPath:    JoinURL(serverURL, pathItem),
+				Deprecated:  op.Deprecated != nil && *op.Deprecated,
+				Unsupported: unsupportedReason(method, op, security, schemes),
+				Summary:     summary,
+				Description: description,
+				Parameters:  parameterNames(pathItemValue.Parameters, op.Parameters),
+				Security:    security,
 			})
 		}
 	}
@@ -109,6 +226,125 @@ func (p *Parser) GetOperations(serverURL string) ([]models.Operation, error) {
 	return operations, nil
 }
 
+// parameterNames lists the names of an operation's parameters, path-level
+// ones included
+func parameterNames(pathParams, opParams []*v3.Parameter) []string {
+	var names []string
+	for _, param := range mergeParameters(pathParams, opParams) {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+// mergeParameters combines the parameters declared on a path item, shared by
+// all its operations, with an operation's own. Operation parameters come
+// first and override path-level ones with the same name and location.
+func mergeParameters(pathParams, opParams []*v3.Parameter) []*v3.Parameter {
+	var merged []*v3.Parameter
+	seen := make(map[string]bool)
+	for _, params := range [][]*v3.Parameter{opParams, pathParams} {
+		for _, param := range params {
+			if param == nil || seen[param.In+":"+param.Name] {
+				continue
+			}
+			seen[param.In+":"+param.Name] = true
+			merged = append(merged, param)
+		}
+	}
+	return merged
+}
+
+// securitySchemes lists the scheme names of each security requirement
+func securitySchemes(requirements []*base.SecurityRequirement) [][]string {
+	var schemes [][]string
+	for _, req := range requirements {
+		if req == nil {
+			continue
+		}
+		names := []string{}
+		if req.Requirements != nil {
+			for pair := req.Requirements.First(); pair != nil; pair = pair.Next() {
+				names = append(names, pair.Key())
+			}
+		}
+		schemes = append(schemes, names)
+	}
+	return schemes
+}
+
+// declaredSecuritySchemes maps the names of the component security schemes
+// to their types
+func declaredSecuritySchemes(components *v3.Components) map[string]string {
+	schemes := make(map[string]string)
+	if components == nil || components.SecuritySchemes == nil {
+		return schemes
+	}
+	for pair := components.SecuritySchemes.First(); pair != nil; pair = pair.Next() {
+		if pair.Value() != nil {
+			schemes[pair.Key()] = pair.Value().Type
+		}
+	}
+	return schemes
+}
+
+// unsupportedReason explains why no valid request can be generated for an
+// operation. TRACE requests are never sent, as servers commonly disable them
+// for security. Operations whose every security requirement needs a scheme
+// the spec doesn't declare, or a client certificate (mutualTLS), can't be
+// authenticated. Request bodies are generated as JSON, so bodies offering no
+// JSON media type (multipart uploads, binary streams, forms) are unsupported.
+func unsupportedReason(method string, op *v3.Operation, security [][]string, schemes map[string]string) string {
+	if method == "TRACE" {
+		return "TRACE requests are not sent"
+	}
+	if reason := unsupportedSecurity(security, schemes); reason != "" {
+		return reason
+	}
+	if method != "POST" && method != "PUT" && method != "PATCH" {
+		return ""
+	}
+	if op.RequestBody == nil || op.RequestBody.Content == nil || op.RequestBody.Content.Len() == 0 {
+		return ""
+	}
+
+	var contentTypes []string
+	for pair := op.RequestBody.Content.First(); pair != nil; pair = pair.Next() {
+		if strings.Contains(pair.Key(), "json") {
+			return ""
+		}
+		contentTypes = append(contentTypes, pair.Key())
+	}
+	return "unsupported request content type " + strings.Join(contentTypes, ", ")
+}
+
+// unsupportedSecurity explains why none of the alternative security
+// requirements of an operation can be met, or returns "" if one can
+func unsupportedSecurity(security [][]string, schemes map[string]string) string {
+	var reason string
+	for _, requirement := range security {
+		problem := ""
+		for _, name := range requirement {
+			schemeType, declared := schemes[name]
+			switch {
+			case !declared:
+				problem = "undeclared security scheme " + name
+			case schemeType == "mutualTLS":
+				problem = "unsupported mutualTLS security scheme " + name
+			}
+			if problem != "" {
+				break
+			}
+		}
+		if problem == "" {
+			return ""
+		}
+		if reason == "" {
+			reason = problem
+		}
+	}
+	return reason
+}
+
 // GetOperationDetails returns detailed information about a specific operation
 type OperationDetails struct {
 	Operation   *v3.Operation
@@ -159,6 +395,8 @@ func (p *Parser) GetOperationDetails(path, method string) (*OperationDetails, er
 		operation = pathItem.Head
 	case "OPTIONS":
 		operation = pathItem.Options
+	case "TRACE":
+		operation = pathItem.Trace
 	default:
 		return nil, fmt.Errorf("unsupported method: %s", method)
 	}
@@ -167,17 +405,11 @@ func (p *Parser) GetOperationDetails(path, method string) (*OperationDetails, er
 		return nil, fmt.Errorf("operation not found: %s %s", method, path)
 	}
 
-	// Extract parameters
-	var parameters []*v3.Parameter
-	if operation.Parameters != nil {
-		parameters = append(parameters, operation.Parameters...)
-	}
-
 	details := &OperationDetails{
 		Operation:  operation,
 		Path:       path,
 		Method:     method,
-		Parameters: parameters,
+		Parameters: mergeParameters(pathItem.Parameters, operation.Parameters),
 		Responses:  operation.Responses,
 	}
 
@@ -187,3 +419,29 @@ func (p *Parser) GetOperationDetails(path, method string) (*OperationDetails, er
 
 	return details, nil
 }
+
+// ItemOperation finds the operation with the given method on the items of a
+// collection path, e.g. DELETE /pets/{petId} for /pets, and returns it with
+// the name of its id parameter
+func (p *Parser) ItemOperation(collectionPath, method string) (*OperationDetails, string, bool) {
+	operations, err := p.GetOperations("")
+	if err != nil {
+		return nil, "", false
+	}
+	prefix := strings.TrimSuffix(collectionPath, "/") + "/{"
+	for _, op := range operations {
+		if op.Method != method || !strings.HasPrefix(op.Path, prefix) {
+			continue
+		}
+		rest := op.Path[len(prefix):]
+		if !strings.HasSuffix(rest, "}") || strings.Contains(rest, "/") {
+			continue
+		}
+		details, err := p.GetOperationDetails(op.Path, op.Method)
+		if err != nil {
+			return nil, "", false
+		}
+		return details, strings.TrimSuffix(rest, "}"), true
+	}
+	return nil, "", false
+}
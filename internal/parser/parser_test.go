@@ -2,6 +2,8 @@ package parser
 
 import (
 	"testing"
+
+	"github.com/moamenhredeen/oas/internal/models"
 )
 
 func TestParseFile(t *testing.T) {
@@ -44,6 +46,84 @@ func TestGetServerURLs(t *testing.T) {
 	}
 }
 
+func TestParseFiles(t *testing.T) {
+	files := []string{
+		"../../tests/pet-store.json",
+		"../../tests/missing.json",
+		"../../tests/upload-api.json",
+	}
+
+	results := ParseFiles(files, 2, RefConfig{})
+	if len(results) != len(files) {
+		t.Fatalf("Expected %d results, got %d", len(files), len(results))
+	}
+	for i, result := range results {
+		if result.File != files[i] {
+			t.Errorf("Expected result %d for %s, got %s", i, files[i], result.File)
+		}
+	}
+
+	if results[0].Err != nil || results[0].Parser == nil {
+		t.Errorf("Expected pet-store.json to parse, got %v", results[0].Err)
+	}
+	if results[1].Err == nil || results[1].Parser != nil {
+		t.Error("Expected an error for a missing spec file")
+	}
+	if results[2].Err != nil || results[2].Parser == nil {
+		t.Errorf("Expected upload-api.json to parse, got %v", results[2].Err)
+	}
+}
+
+func TestSpecVersion(t *testing.T) {
+	p, err := ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	if version := p.SpecVersion(); version != "1.0.0" {
+		t.Errorf("Expected spec version 1.0.0, got %q", version)
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		serverURL, path, want string
+	}{
+		{"http://api.example.com/v1", "/pets", "http://api.example.com/v1/pets"},
+		{"http://api.example.com/v1/", "/pets", "http://api.example.com/v1/pets"},
+		{"http://api.example.com/", "/pets/{petId}", "http://api.example.com/pets/{petId}"},
+		{"http://api.example.com", "pets", "http://api.example.com/pets"},
+	}
+	for _, tt := range tests {
+		if got := JoinURL(tt.serverURL, tt.path); got != tt.want {
+			t.Errorf("JoinURL(%q, %q) = %q, want %q", tt.serverURL, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWithBasePath(t *testing.T) {
+	tests := []struct {
+		serverURL, basePath, want string
+	}{
+		{"https://api.example.com/v1", "/api/v2", "https://api.example.com/api/v2"},
+		{"https://api.example.com", "api/v2/", "https://api.example.com/api/v2"},
+		{"https://api.example.com:8443/v1/", "/", "https://api.example.com:8443"},
+	}
+	for _, tt := range tests {
+		got, err := WithBasePath(tt.serverURL, tt.basePath)
+		if err != nil {
+			t.Fatalf("WithBasePath(%q, %q) failed: %v", tt.serverURL, tt.basePath, err)
+		}
+		if got != tt.want {
+			t.Errorf("WithBasePath(%q, %q) = %q, want %q", tt.serverURL, tt.basePath, got, tt.want)
+		}
+	}
+
+	if _, err := WithBasePath("http://[::1", "/v2"); err == nil {
+		t.Error("Expected an error for an invalid server URL")
+	}
+}
+
 func TestGetOperations(t *testing.T) {
 	p, err := ParseFile("../../tests/pet-store.json")
 	if err != nil {
@@ -142,3 +222,169 @@ func TestGetOperationsWithAuthAPI(t *testing.T) {
 	}
 }
 
+func TestGetOperationsContext(t *testing.T) {
+	p, err := ParseFile("../../tests/auth-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	operations, err := p.GetOperations("http://api.example.com/v1")
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+
+	byID := make(map[string]models.Operation)
+	for _, op := range operations {
+		byID[op.OperationID] = op
+	}
+
+	profile := byID["getProfile"]
+	if profile.Summary != "Get user profile" {
+		t.Errorf("Expected the operation summary, got %q", profile.Summary)
+	}
+	if len(profile.Security) != 1 || len(profile.Security[0]) != 1 || profile.Security[0][0] != "bearerAuth" {
+		t.Errorf("Expected bearerAuth security, got %v", profile.Security)
+	}
+	if login := byID["login"]; len(login.Security) != 0 {
+		t.Errorf("Expected no security for login, got %v", login.Security)
+	}
+
+	p, err = ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	operations, err = p.GetOperations("http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+	for _, op := range operations {
+		if op.OperationID == "showPetById" && (len(op.Parameters) != 1 || op.Parameters[0] != "petId") {
+			t.Errorf("Expected the petId parameter, got %v", op.Parameters)
+		}
+	}
+}
+
+func TestGetOperationsUnsupportedAndDeprecated(t *testing.T) {
+	p, err := ParseFile("../../tests/upload-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	operations, err := p.GetOperations("http://uploads.example.com/v1")
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+
+	byID := make(map[string]int)
+	for i, op := range operations {
+		byID[op.OperationID] = i
+	}
+
+	if op := operations[byID["listFiles"]]; !op.Deprecated || op.Unsupported != "" {
+		t.Errorf("Expected listFiles to be deprecated and supported, got %+v", op)
+	}
+	if op := operations[byID["uploadFile"]]; op.Deprecated || op.Unsupported == "" {
+		t.Errorf("Expected multipart uploadFile to be unsupported, got %+v", op)
+	}
+	if op := operations[byID["updateFile"]]; op.Unsupported != "" {
+		t.Errorf("Expected updateFile with a JSON alternative to be supported, got %q", op.Unsupported)
+	}
+	for id, reason := range map[string]string{
+		"traceFiles": "TRACE requests are not sent",
+		"deleteFile": "undeclared security scheme signedUrl",
+		"getAudit":   "unsupported mutualTLS security scheme clientCert",
+		"clearAudit": "", // bearerAuth is an alternative to the client certificate
+	} {
+		if op := operations[byID[id]]; op.OperationID != id || op.Unsupported != reason {
+			t.Errorf("Expected %s to be unsupported for %q, got %q", id, reason, op.Unsupported)
+		}
+	}
+}
+
+func TestGetOperationDetailsPathItemParameters(t *testing.T) {
+	p, err := ParseFile("../../tests/upload-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// Path-level parameters apply to every operation of the path item
+	details, err := p.GetOperationDetails("/folders/{folderId}", "DELETE")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if len(details.Parameters) != 2 || details.Parameters[0].Name != "folderId" || details.Parameters[1].Name != "tenant" {
+		t.Fatalf("Expected the path-level folderId and tenant parameters, got %d parameters", len(details.Parameters))
+	}
+
+	// An operation parameter with the same name and location overrides one
+	details, err = p.GetOperationDetails("/folders/{folderId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if len(details.Parameters) != 2 {
+		t.Fatalf("Expected folderId and the overriding tenant parameter, got %d parameters", len(details.Parameters))
+	}
+	tenant := details.Parameters[0]
+	if tenant.Name != "tenant" || tenant.Required == nil || !*tenant.Required {
+		t.Errorf("Expected the operation's required tenant parameter first, got %s", tenant.Name)
+	}
+	if details.Parameters[1].Name != "folderId" {
+		t.Errorf("Expected the path-level folderId parameter, got %s", details.Parameters[1].Name)
+	}
+}
+
+func TestItemOperation(t *testing.T) {
+	p, err := ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	details, param, ok := p.ItemOperation("/pets", "GET")
+	if !ok || details.Path != "/pets/{petId}" || param != "petId" {
+		t.Errorf("Expected GET /pets/{petId} with petId, got %v %q %v", details, param, ok)
+	}
+	if _, _, ok := p.ItemOperation("/pets", "DELETE"); ok {
+		t.Error("Expected no DELETE operation for /pets items")
+	}
+}
+
+func TestParseOpenAPI31Keywords(t *testing.T) {
+	p, err := ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	operations, err := p.GetOperations("http://sensors.example.com/v1")
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+	if len(operations) != 3 {
+		t.Fatalf("Expected 3 operations, got %d", len(operations))
+	}
+	webhooks, err := p.GetWebhooks()
+	if err != nil || len(webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, got %+v (%v)", webhooks, err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/readings", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	schema := opDetails.Responses.Codes.GetOrZero("200").Content.GetOrZero("application/json").Schema.Schema()
+	props := schema.Properties
+	if kind := props.GetOrZero("kind").Schema(); kind.Const == nil || kind.Const.Value != "temperature" {
+		t.Errorf("Expected the const of kind to be kept, got %+v", kind.Const)
+	}
+	if value := props.GetOrZero("value").Schema(); value.ExclusiveMinimum == nil || !value.ExclusiveMinimum.IsB() || value.ExclusiveMinimum.B != -273.15 {
+		t.Errorf("Expected a numeric exclusiveMinimum, got %+v", value.ExclusiveMinimum)
+	}
+	if location := props.GetOrZero("location").Schema(); len(location.PrefixItems) != 2 {
+		t.Errorf("Expected 2 prefixItems, got %d", len(location.PrefixItems))
+	}
+	if sensor := props.GetOrZero("sensor").Schema(); len(sensor.Type) != 2 || len(sensor.Examples) != 1 {
+		t.Errorf("Expected a type list and examples, got %v and %d examples", sensor.Type, len(sensor.Examples))
+	}
+	if schema.DependentRequired == nil || len(schema.DependentRequired.GetOrZero("unit")) != 1 {
+		t.Errorf("Expected dependentRequired to be kept")
+	}
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pb33f/libopenapi/datamodel"
+)
+
+// remoteRefTimeout bounds the download of one remote $ref document
+const remoteRefTimeout = 30 * time.Second
+
+// RefConfig controls how $refs to other documents are resolved, e.g.
+// "./schemas/pet.yaml#/Pet" in a spec split across files
+type RefConfig struct {
+	// Base is the directory or http(s) URL relative references are resolved
+	// against. The default is the directory of the spec file.
+	Base string
+
+	// Remote allows references to http(s) URLs. A Base URL implies it.
+	Remote bool
+}
+
+// documentConfig returns the libopenapi configuration resolving the external
+// references of the spec at filePath
+func documentConfig(filePath string, refs RefConfig) (*datamodel.DocumentConfiguration, error) {
+	specPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve spec path: %w", err)
+	}
+	config := &datamodel.DocumentConfiguration{
+		BasePath:              filepath.Dir(specPath),
+		SpecFilePath:          specPath,
+		AllowRemoteReferences: refs.Remote,
+		// Unresolved references are reported by BuildV3Model
+		Logger: slog.New(slog.DiscardHandler),
+	}
+
+	if isRemote(refs.Base) {
+		base, err := url.Parse(refs.Base)
+		if err != nil || base.Host == "" {
+			return nil, fmt.Errorf("invalid $ref base URL '%s'", refs.Base)
+		}
+		// Relative refs are appended to the base URL's path, so it must
+		// name a directory
+		base.Path = strings.TrimSuffix(base.Path, "/")
+		config.BaseURL = base
+		config.BasePath, config.SpecFilePath = "", ""
+		config.AllowRemoteReferences = true
+	} else if refs.Base != "" {
+		dir, err := filepath.Abs(refs.Base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $ref base directory '%s': %w", refs.Base, err)
+		}
+		config.BasePath = dir
+	}
+
+	if config.AllowRemoteReferences {
+		client := &http.Client{Timeout: remoteRefTimeout}
+		config.RemoteURLHandler = client.Get
+	}
+	return config, nil
+}
+
+// isRemote reports whether a $ref base is an http(s) URL
+func isRemote(base string) bool {
+	lower := strings.ToLower(base)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// checkSplitPet checks that the Pet returned by GET /pets/{petId} of the
+// split spec was resolved from schemas/pet.yaml, and its owner from
+// schemas/owner.yaml
+func checkSplitPet(t *testing.T, p *Parser) {
+	t.Helper()
+	opDetails, err := p.GetOperationDetails("/pets/{petId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	pet := opDetails.Responses.Codes.GetOrZero("200").Content.GetOrZero("application/json").Schema.Schema()
+	if pet == nil || pet.Properties == nil {
+		t.Fatalf("Expected the Pet schema from schemas/pet.yaml, got %+v", pet)
+	}
+	owner := pet.Properties.GetOrZero("owner").Schema()
+	if owner == nil || owner.Properties == nil || owner.Properties.GetOrZero("name").Schema().MinLength == nil {
+		t.Fatalf("Expected the Owner schema from schemas/owner.yaml, got %+v", owner)
+	}
+}
+
+func TestParseFileExternalRefs(t *testing.T) {
+	p, err := ParseFile("../../tests/split/openapi.yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	checkSplitPet(t, p)
+
+	opDetails, err := p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if len(opDetails.Parameters) != 1 || opDetails.Parameters[0].Name != "limit" || !*opDetails.Parameters[0].Required {
+		t.Errorf("Expected the limit parameter from parameters/limit.yaml, got %+v", opDetails.Parameters)
+	}
+
+	// Path-level parameters are inherited through external refs too
+	opDetails, err = p.GetOperationDetails("/pets/{petId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if len(opDetails.Parameters) != 1 || opDetails.Parameters[0].Name != "petId" || opDetails.Parameters[0].In != "path" {
+		t.Errorf("Expected the path-level petId parameter from parameters/pets.yaml, got %+v", opDetails.Parameters)
+	}
+}
+
+func TestParseFileRefBaseDirectory(t *testing.T) {
+	spec, err := os.ReadFile("../../tests/split/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The spec is moved away from the files its refs point to
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(path, spec, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ParseFileWithRefs(path, RefConfig{Base: "../../tests/split"})
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	checkSplitPet(t, p)
+}
+
+func TestParseFileRemoteRefs(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("../../tests/split")))
+	defer server.Close()
+
+	spec, err := os.ReadFile("../../tests/split/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	// Relative refs resolved against a base URL
+	relative := filepath.Join(dir, "relative.yaml")
+	if err := os.WriteFile(relative, spec, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseFileWithRefs(relative, RefConfig{Base: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	checkSplitPet(t, p)
+
+	// Absolute refs to URLs, resolved only when remote refs are allowed
+	absolute := filepath.Join(dir, "absolute.yaml")
+	ownerSpec := `openapi: 3.0.3
+info:
+  title: Owners
+  version: 1.0.0
+paths:
+  /owner:
+    get:
+      responses:
+        '200':
+          description: The owner
+          content:
+            application/json:
+              schema:
+                $ref: '` + server.URL + `/schemas/owner.yaml#/Owner'
+`
+	if err := os.WriteFile(absolute, []byte(ownerSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ownerSchema := func(refs RefConfig) *base.Schema {
+		p, err := ParseFileWithRefs(absolute, refs)
+		if err != nil {
+			return nil
+		}
+		opDetails, err := p.GetOperationDetails("/owner", "GET")
+		if err != nil {
+			return nil
+		}
+		return opDetails.Responses.Codes.GetOrZero("200").Content.GetOrZero("application/json").Schema.Schema()
+	}
+	if owner := ownerSchema(RefConfig{Remote: true}); owner == nil || owner.Properties.GetOrZero("name") == nil {
+		t.Errorf("Expected the Owner schema from the server, got %+v", owner)
+	}
+	if owner := ownerSchema(RefConfig{}); owner != nil {
+		t.Errorf("Expected remote refs to stay unresolved without RefConfig.Remote, got %+v", owner)
+	}
+}
@@ -0,0 +1,707 @@
+package parser
+
+import (
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// convertSwagger2 upgrades a Swagger 2.0 document to OpenAPI 3.0, so legacy
+// specs can be tested without converting them first. It reports false for
+// documents that aren't Swagger 2.0, which are parsed as they are.
+func convertSwagger2(spec []byte) ([]byte, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(spec, &doc); err != nil || len(doc.Content) == 0 {
+		return nil, false, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, false, nil
+	}
+	if version := mapGet(root, "swagger"); version == nil || !strings.HasPrefix(version.Value, "2") {
+		return nil, false, nil
+	}
+
+	c := &swaggerConverter{
+		root:       root,
+		consumes:   stringList(mapGet(root, "consumes")),
+		produces:   stringList(mapGet(root, "produces")),
+		parameters: mapGet(root, "parameters"),
+	}
+	data, err := yaml.Marshal(c.convert())
+	if err != nil {
+		return nil, true, err
+	}
+	return data, true, nil
+}
+
+// swaggerConverter converts the parts of a Swagger 2.0 document
+type swaggerConverter struct {
+	root       *yaml.Node
+	consumes   []string   // default request media types
+	produces   []string   // default response media types
+	parameters *yaml.Node // global parameters, referenced as #/parameters/name
+}
+
+// convert builds the OpenAPI 3.0 document
+func (c *swaggerConverter) convert() *yaml.Node {
+	out := newMap()
+	mapSet(out, "openapi", str("3.0.3"))
+	if info := mapGet(c.root, "info"); info != nil {
+		mapSet(out, "info", info)
+	}
+	if servers := c.servers(); len(servers.Content) > 0 {
+		mapSet(out, "servers", servers)
+	}
+	for _, key := range []string{"tags", "externalDocs", "security"} {
+		if node := mapGet(c.root, key); node != nil {
+			mapSet(out, key, node)
+		}
+	}
+	copyExtensions(out, c.root)
+	mapSet(out, "paths", c.paths())
+	if components := c.components(); len(components.Content) > 0 {
+		mapSet(out, "components", components)
+	}
+	rewriteRefs(out, c.bodyParameterNames())
+	return out
+}
+
+// servers combines host, basePath and schemes into server URLs, one per
+// scheme (https if none is declared)
+func (c *swaggerConverter) servers() *yaml.Node {
+	servers := newSeq()
+	host := scalarValue(mapGet(c.root, "host"))
+	basePath := scalarValue(mapGet(c.root, "basePath"))
+	if host == "" {
+		if basePath != "" {
+			server := newMap()
+			mapSet(server, "url", str(basePath))
+			servers.Content = append(servers.Content, server)
+		}
+		return servers
+	}
+
+	schemes := stringList(mapGet(c.root, "schemes"))
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	for _, scheme := range schemes {
+		server := newMap()
+		mapSet(server, "url", str(scheme+"://"+host+basePath))
+		servers.Content = append(servers.Content, server)
+	}
+	return servers
+}
+
+// components moves definitions, global parameters and responses and
+// security definitions to components. Global body parameters become request
+// bodies; global form parameters are inlined where they are used.
+func (c *swaggerConverter) components() *yaml.Node {
+	components := newMap()
+
+	if definitions := mapGet(c.root, "definitions"); definitions != nil && definitions.Kind == yaml.MappingNode {
+		schemas := newMap()
+		for i := 0; i+1 < len(definitions.Content); i += 2 {
+			mapSet(schemas, definitions.Content[i].Value, convertSchema(definitions.Content[i+1]))
+		}
+		mapSet(components, "schemas", schemas)
+	}
+
+	if c.parameters != nil && c.parameters.Kind == yaml.MappingNode {
+		parameters, requestBodies := newMap(), newMap()
+		for i := 0; i+1 < len(c.parameters.Content); i += 2 {
+			name, param := c.parameters.Content[i].Value, c.parameters.Content[i+1]
+			switch scalarValue(mapGet(param, "in")) {
+			case "body":
+				mapSet(requestBodies, name, c.requestBody(param, c.consumes))
+			case "formData":
+			default:
+				mapSet(parameters, name, convertParameter(param))
+			}
+		}
+		if len(parameters.Content) > 0 {
+			mapSet(components, "parameters", parameters)
+		}
+		if len(requestBodies.Content) > 0 {
+			mapSet(components, "requestBodies", requestBodies)
+		}
+	}
+
+	if responses := mapGet(c.root, "responses"); responses != nil && responses.Kind == yaml.MappingNode {
+		converted := newMap()
+		for i := 0; i+1 < len(responses.Content); i += 2 {
+			mapSet(converted, responses.Content[i].Value, c.response(responses.Content[i+1], c.produces))
+		}
+		mapSet(components, "responses", converted)
+	}
+
+	if definitions := mapGet(c.root, "securityDefinitions"); definitions != nil && definitions.Kind == yaml.MappingNode {
+		schemes := newMap()
+		for i := 0; i+1 < len(definitions.Content); i += 2 {
+			mapSet(schemes, definitions.Content[i].Value, convertSecurityScheme(definitions.Content[i+1]))
+		}
+		mapSet(components, "securitySchemes", schemes)
+	}
+	return components
+}
+
+// bodyParameterNames returns the names of the global body parameters, whose
+// references point to request bodies after the conversion
+func (c *swaggerConverter) bodyParameterNames() map[string]bool {
+	names := make(map[string]bool)
+	if c.parameters == nil || c.parameters.Kind != yaml.MappingNode {
+		return names
+	}
+	for i := 0; i+1 < len(c.parameters.Content); i += 2 {
+		if scalarValue(mapGet(c.parameters.Content[i+1], "in")) == "body" {
+			names[c.parameters.Content[i].Value] = true
+		}
+	}
+	return names
+}
+
+// paths converts the path items and their operations
+func (c *swaggerConverter) paths() *yaml.Node {
+	paths := newMap()
+	source := mapGet(c.root, "paths")
+	if source == nil || source.Kind != yaml.MappingNode {
+		return paths
+	}
+
+	for i := 0; i+1 < len(source.Content); i += 2 {
+		path, item := source.Content[i].Value, source.Content[i+1]
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		shared := c.splitParameters(mapGet(item, "parameters"))
+
+		converted := newMap()
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			key, value := item.Content[j].Value, item.Content[j+1]
+			switch key {
+			case "get", "put", "post", "delete", "options", "head", "patch":
+				mapSet(converted, key, c.operation(value, shared))
+			case "parameters":
+				if len(shared.params.Content) > 0 {
+					mapSet(converted, "parameters", shared.params)
+				}
+			default:
+				// $ref and extensions
+				mapSet(converted, key, value)
+			}
+		}
+		mapSet(paths, path, converted)
+	}
+	return paths
+}
+
+// parameterSet is a Swagger 2.0 parameter list split by where the parameters
+// go in OpenAPI 3.0
+type parameterSet struct {
+	params *yaml.Node   // path, query, header and cookie parameters
+	body   *yaml.Node   // the body parameter, or a reference to a global one
+	form   []*yaml.Node // formData parameters, with references resolved
+}
+
+// splitParameters converts a parameter list, separating the parameters that
+// become a request body
+func (c *swaggerConverter) splitParameters(list *yaml.Node) parameterSet {
+	set := parameterSet{params: newSeq()}
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return set
+	}
+	for _, param := range list.Content {
+		if ref := scalarValue(mapGet(param, "$ref")); ref != "" {
+			resolved := c.globalParameter(ref)
+			switch scalarValue(mapGet(resolved, "in")) {
+			case "body":
+				set.body = param
+			case "formData":
+				set.form = append(set.form, resolved)
+			default:
+				set.params.Content = append(set.params.Content, param)
+			}
+			continue
+		}
+		switch scalarValue(mapGet(param, "in")) {
+		case "body":
+			set.body = param
+		case "formData":
+			set.form = append(set.form, param)
+		default:
+			set.params.Content = append(set.params.Content, convertParameter(param))
+		}
+	}
+	return set
+}
+
+// globalParameter returns the global parameter a #/parameters/ reference
+// points to, or nil
+func (c *swaggerConverter) globalParameter(ref string) *yaml.Node {
+	name, ok := strings.CutPrefix(ref, "#/parameters/")
+	if !ok || c.parameters == nil {
+		return nil
+	}
+	return mapGet(c.parameters, unescapePointer(name))
+}
+
+// operation converts an operation, merging in the parameters of its path
+// item that become a request body
+func (c *swaggerConverter) operation(op *yaml.Node, shared parameterSet) *yaml.Node {
+	if op.Kind != yaml.MappingNode {
+		return op
+	}
+	consumes, produces := c.consumes, c.produces
+	if node := mapGet(op, "consumes"); node != nil {
+		consumes = stringList(node)
+	}
+	if node := mapGet(op, "produces"); node != nil {
+		produces = stringList(node)
+	}
+	own := c.splitParameters(mapGet(op, "parameters"))
+
+	converted := newMap()
+	for i := 0; i+1 < len(op.Content); i += 2 {
+		key, value := op.Content[i].Value, op.Content[i+1]
+		switch key {
+		case "consumes", "produces", "schemes":
+		case "parameters":
+			if len(own.params.Content) > 0 {
+				mapSet(converted, "parameters", own.params)
+			}
+		case "responses":
+			responses := newMap()
+			if value.Kind == yaml.MappingNode {
+				for j := 0; j+1 < len(value.Content); j += 2 {
+					mapSet(responses, value.Content[j].Value, c.response(value.Content[j+1], produces))
+				}
+			}
+			mapSet(converted, "responses", responses)
+		default:
+			mapSet(converted, key, value)
+		}
+	}
+
+	body, form := own.body, append(append([]*yaml.Node{}, shared.form...), own.form...)
+	if body == nil {
+		body = shared.body
+	}
+	switch {
+	case body != nil && mapGet(body, "$ref") != nil:
+		ref := newMap()
+		mapSet(ref, "$ref", mapGet(body, "$ref"))
+		mapSet(converted, "requestBody", ref)
+	case body != nil:
+		mapSet(converted, "requestBody", c.requestBody(body, consumes))
+	case len(form) > 0:
+		mapSet(converted, "requestBody", formBody(form, consumes))
+	}
+	return converted
+}
+
+// requestBody converts a body parameter into a request body with the schema
+// under each consumed media type
+func (c *swaggerConverter) requestBody(param *yaml.Node, consumes []string) *yaml.Node {
+	if len(consumes) == 0 {
+		consumes = []string{"application/json"}
+	}
+	body := newMap()
+	if description := mapGet(param, "description"); description != nil {
+		mapSet(body, "description", description)
+	}
+	if required := mapGet(param, "required"); required != nil {
+		mapSet(body, "required", required)
+	}
+	content := newMap()
+	schema := convertSchema(mapGet(param, "schema"))
+	for _, mediaType := range consumes {
+		media := newMap()
+		if schema != nil {
+			mapSet(media, "schema", schema)
+		}
+		mapSet(content, mediaType, media)
+	}
+	mapSet(body, "content", content)
+	copyExtensions(body, param)
+	return body
+}
+
+// formBody converts formData parameters into an object schema sent as a
+// form: multipart if the operation consumes it or uploads files, URL-encoded
+// otherwise
+func formBody(fields []*yaml.Node, consumes []string) *yaml.Node {
+	schema := newMap()
+	mapSet(schema, "type", str("object"))
+	properties, required := newMap(), newSeq()
+	hasFile := false
+	for _, field := range fields {
+		name := scalarValue(mapGet(field, "name"))
+		if name == "" {
+			continue
+		}
+		if scalarValue(mapGet(field, "type")) == "file" {
+			hasFile = true
+		}
+		property := parameterSchema(field)
+		if description := mapGet(field, "description"); description != nil {
+			mapSet(property, "description", description)
+		}
+		mapSet(properties, name, property)
+		if scalarValue(mapGet(field, "required")) == "true" {
+			required.Content = append(required.Content, str(name))
+		}
+	}
+	mapSet(schema, "properties", properties)
+	if len(required.Content) > 0 {
+		mapSet(schema, "required", required)
+	}
+
+	var mediaTypes []string
+	for _, mediaType := range consumes {
+		if mediaType == "multipart/form-data" || mediaType == "application/x-www-form-urlencoded" {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/x-www-form-urlencoded"}
+		if hasFile {
+			mediaTypes = []string{"multipart/form-data"}
+		}
+	}
+
+	body := newMap()
+	if len(required.Content) > 0 {
+		mapSet(body, "required", boolean(true))
+	}
+	content := newMap()
+	for _, mediaType := range mediaTypes {
+		media := newMap()
+		mapSet(media, "schema", schema)
+		mapSet(content, mediaType, media)
+	}
+	mapSet(body, "content", content)
+	return body
+}
+
+// response converts a response: its schema is served under each produced
+// media type, with the matching example
+func (c *swaggerConverter) response(resp *yaml.Node, produces []string) *yaml.Node {
+	if resp.Kind != yaml.MappingNode || mapGet(resp, "$ref") != nil {
+		return resp
+	}
+	if len(produces) == 0 {
+		produces = []string{"application/json"}
+	}
+	converted := newMap()
+	description := mapGet(resp, "description")
+	if description == nil {
+		description = str("")
+	}
+	mapSet(converted, "description", description)
+
+	if headers := mapGet(resp, "headers"); headers != nil && headers.Kind == yaml.MappingNode {
+		convertedHeaders := newMap()
+		for i := 0; i+1 < len(headers.Content); i += 2 {
+			header := newMap()
+			if description := mapGet(headers.Content[i+1], "description"); description != nil {
+				mapSet(header, "description", description)
+			}
+			mapSet(header, "schema", parameterSchema(headers.Content[i+1]))
+			mapSet(convertedHeaders, headers.Content[i].Value, header)
+		}
+		mapSet(converted, "headers", convertedHeaders)
+	}
+
+	if schema := mapGet(resp, "schema"); schema != nil {
+		examples := mapGet(resp, "examples")
+		content := newMap()
+		for _, mediaType := range produces {
+			media := newMap()
+			mapSet(media, "schema", convertSchema(schema))
+			if example := mapGet(examples, mediaType); example != nil {
+				mapSet(media, "example", example)
+			}
+			mapSet(content, mediaType, media)
+		}
+		mapSet(converted, "content", content)
+	}
+	copyExtensions(converted, resp)
+	return converted
+}
+
+// parameterKeys are the keys of a Swagger 2.0 parameter that describe its
+// value and move to its schema in OpenAPI 3.0
+var parameterKeys = map[string]bool{
+	"type": true, "format": true, "items": true, "default": true, "enum": true,
+	"maximum": true, "exclusiveMaximum": true, "minimum": true, "exclusiveMinimum": true,
+	"maxLength": true, "minLength": true, "pattern": true, "maxItems": true,
+	"minItems": true, "uniqueItems": true, "multipleOf": true,
+}
+
+// convertParameter converts a path, query, header or cookie parameter,
+// moving its type to a schema and its collectionFormat to style and explode
+func convertParameter(param *yaml.Node) *yaml.Node {
+	if param.Kind != yaml.MappingNode || mapGet(param, "$ref") != nil {
+		return param
+	}
+	converted := newMap()
+	for i := 0; i+1 < len(param.Content); i += 2 {
+		key := param.Content[i].Value
+		if parameterKeys[key] || key == "collectionFormat" {
+			continue
+		}
+		mapSet(converted, key, param.Content[i+1])
+	}
+	mapSet(converted, "schema", parameterSchema(param))
+
+	if scalarValue(mapGet(param, "type")) == "array" {
+		in := scalarValue(mapGet(param, "in"))
+		switch scalarValue(mapGet(param, "collectionFormat")) {
+		case "multi":
+			mapSet(converted, "style", str("form"))
+			mapSet(converted, "explode", boolean(true))
+		case "ssv":
+			mapSet(converted, "style", str("spaceDelimited"))
+			mapSet(converted, "explode", boolean(false))
+		case "pipes":
+			mapSet(converted, "style", str("pipeDelimited"))
+			mapSet(converted, "explode", boolean(false))
+		default: // csv
+			if in == "query" || in == "cookie" {
+				mapSet(converted, "style", str("form"))
+				mapSet(converted, "explode", boolean(false))
+			}
+		}
+	}
+	return converted
+}
+
+// parameterSchema builds the schema of a parameter or header from its type
+// keys. A file becomes a binary string.
+func parameterSchema(param *yaml.Node) *yaml.Node {
+	schema := newMap()
+	for i := 0; i+1 < len(param.Content); i += 2 {
+		if key := param.Content[i].Value; parameterKeys[key] {
+			mapSet(schema, key, param.Content[i+1])
+		}
+	}
+	return convertSchema(schema)
+}
+
+// convertSchema converts a schema and the schemas nested in it: a file type
+// becomes a binary string, x-nullable becomes nullable and a discriminator
+// property name becomes a discriminator object
+func convertSchema(schema *yaml.Node) *yaml.Node {
+	if schema == nil || schema.Kind != yaml.MappingNode {
+		return schema
+	}
+	converted := newMap()
+	for i := 0; i+1 < len(schema.Content); i += 2 {
+		key, value := schema.Content[i].Value, schema.Content[i+1]
+		switch key {
+		case "type":
+			if value.Value == "file" {
+				mapSet(converted, "type", str("string"))
+				mapSet(converted, "format", str("binary"))
+				continue
+			}
+		case "format":
+			if mapGet(converted, "format") != nil {
+				continue
+			}
+		case "x-nullable":
+			key = "nullable"
+		case "discriminator":
+			if value.Kind == yaml.ScalarNode {
+				discriminator := newMap()
+				mapSet(discriminator, "propertyName", value)
+				value = discriminator
+			}
+		case "items", "additionalProperties", "not":
+			value = convertSchema(value)
+		case "allOf", "anyOf", "oneOf":
+			if value.Kind == yaml.SequenceNode {
+				list := newSeq()
+				for _, item := range value.Content {
+					list.Content = append(list.Content, convertSchema(item))
+				}
+				value = list
+			}
+		case "properties":
+			if value.Kind == yaml.MappingNode {
+				properties := newMap()
+				for j := 0; j+1 < len(value.Content); j += 2 {
+					mapSet(properties, value.Content[j].Value, convertSchema(value.Content[j+1]))
+				}
+				value = properties
+			}
+		}
+		mapSet(converted, key, value)
+	}
+	return converted
+}
+
+// convertSecurityScheme converts a security definition: basic becomes an
+// HTTP scheme, and the OAuth2 flow names follow OpenAPI 3.0
+func convertSecurityScheme(definition *yaml.Node) *yaml.Node {
+	scheme := newMap()
+	kind := scalarValue(mapGet(definition, "type"))
+	switch kind {
+	case "basic":
+		mapSet(scheme, "type", str("http"))
+		mapSet(scheme, "scheme", str("basic"))
+	case "apiKey":
+		mapSet(scheme, "type", str("apiKey"))
+		for _, key := range []string{"name", "in"} {
+			if node := mapGet(definition, key); node != nil {
+				mapSet(scheme, key, node)
+			}
+		}
+	case "oauth2":
+		mapSet(scheme, "type", str("oauth2"))
+		flowName := map[string]string{
+			"implicit":    "implicit",
+			"password":    "password",
+			"application": "clientCredentials",
+			"accessCode":  "authorizationCode",
+		}[scalarValue(mapGet(definition, "flow"))]
+		flow := newMap()
+		for _, key := range []string{"authorizationUrl", "tokenUrl"} {
+			if node := mapGet(definition, key); node != nil {
+				mapSet(flow, key, node)
+			}
+		}
+		scopes := mapGet(definition, "scopes")
+		if scopes == nil {
+			scopes = newMap()
+		}
+		mapSet(flow, "scopes", scopes)
+		flows := newMap()
+		if flowName != "" {
+			mapSet(flows, flowName, flow)
+		}
+		mapSet(scheme, "flows", flows)
+	default:
+		mapSet(scheme, "type", str(kind))
+	}
+	if description := mapGet(definition, "description"); description != nil {
+		mapSet(scheme, "description", description)
+	}
+	copyExtensions(scheme, definition)
+	return scheme
+}
+
+// rewriteRefs points the references of a converted document at components.
+// References to global body parameters point at request bodies.
+func rewriteRefs(node *yaml.Node, bodyParameters map[string]bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "$ref" && node.Content[i+1].Kind == yaml.ScalarNode {
+				ref := node.Content[i+1]
+				ref.Value = convertRef(ref.Value, bodyParameters)
+				continue
+			}
+			rewriteRefs(node.Content[i+1], bodyParameters)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			rewriteRefs(item, bodyParameters)
+		}
+	}
+}
+
+// convertRef converts a local Swagger 2.0 reference; references into other
+// files are kept
+func convertRef(ref string, bodyParameters map[string]bool) string {
+	if name, ok := strings.CutPrefix(ref, "#/parameters/"); ok {
+		if bodyParameters[unescapePointer(name)] {
+			return "#/components/requestBodies/" + name
+		}
+		return "#/components/parameters/" + name
+	}
+	for from, to := range map[string]string{
+		"#/definitions/": "#/components/schemas/",
+		"#/responses/":   "#/components/responses/",
+	} {
+		if rest, ok := strings.CutPrefix(ref, from); ok {
+			return to + rest
+		}
+	}
+	return ref
+}
+
+// unescapePointer decodes a JSON pointer segment
+func unescapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+}
+
+// copyExtensions copies the x- extensions of src to dst
+func copyExtensions(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		if key := src.Content[i].Value; strings.HasPrefix(key, "x-") && mapGet(dst, key) == nil {
+			mapSet(dst, key, src.Content[i+1])
+		}
+	}
+}
+
+func newMap() *yaml.Node { return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"} }
+
+func newSeq() *yaml.Node { return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"} }
+
+func str(s string) *yaml.Node { return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s} }
+
+func boolean(b bool) *yaml.Node {
+	value := "false"
+	if b {
+		value = "true"
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value}
+}
+
+// mapGet returns the value of key in a mapping node, or nil
+func mapGet(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mapSet sets key in a mapping node, replacing an existing value
+func mapSet(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, str(key), value)
+}
+
+// scalarValue returns the value of a scalar node, or "" for anything else
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// stringList returns the scalar values of a sequence node
+func stringList(node *yaml.Node) []string {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var values []string
+	for _, item := range node.Content {
+		if item.Kind == yaml.ScalarNode {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
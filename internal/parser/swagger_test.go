@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseSwagger2(t *testing.T) {
+	p, err := ParseFile("../../tests/swagger-petstore.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	servers, err := p.GetServerURLs()
+	if err != nil {
+		t.Fatalf("Failed to get server URLs: %v", err)
+	}
+	if len(servers) != 2 || servers[0] != "https://petstore.example.com/v1" || servers[1] != "http://petstore.example.com/v1" {
+		t.Errorf("Expected a server per scheme, got %v", servers)
+	}
+
+	operations, err := p.GetOperations(servers[0])
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+	if len(operations) != 5 {
+		t.Fatalf("Expected 5 operations, got %d", len(operations))
+	}
+	for _, op := range operations {
+		// Multipart bodies are not sent, whatever the spec version
+		if op.Unsupported != "" && op.OperationID != "uploadPhoto" {
+			t.Errorf("Expected %s %s to be supported, got %q", op.Method, op.Path, op.Unsupported)
+		}
+	}
+
+	list, err := p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if len(list.Parameters) != 2 || list.Parameters[0].Name != "limit" || list.Parameters[0].Schema == nil {
+		t.Fatalf("Expected the referenced limit parameter with a schema, got %+v", list.Parameters)
+	}
+	if max := list.Parameters[0].Schema.Schema().Maximum; max == nil || *max != 100 {
+		t.Errorf("Expected limit's maximum to move to its schema")
+	}
+	if tags := list.Parameters[1]; tags.Explode == nil || !*tags.Explode || tags.Style != "form" {
+		t.Errorf("Expected collectionFormat multi to become an exploded form, got %q %v", tags.Style, tags.Explode)
+	}
+	ok := list.Responses.Codes.GetOrZero("200")
+	if ok.Content.GetOrZero("application/json") == nil || ok.Headers.GetOrZero("X-Next") == nil {
+		t.Errorf("Expected the response schema under the produced media type and its header")
+	}
+	if items := ok.Content.GetOrZero("application/json").Schema.Schema().Items.A.Schema(); items.Properties.GetOrZero("name") == nil {
+		t.Errorf("Expected the $ref to the Pet definition to resolve")
+	}
+	if errSchema := list.Responses.Default.Content.GetOrZero("application/json").Schema.Schema(); errSchema.Properties.GetOrZero("code") == nil {
+		t.Errorf("Expected the shared Error response to resolve")
+	}
+
+	create, err := p.GetOperationDetails("/pets", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if create.RequestBody == nil || create.RequestBody.Content.GetOrZero("application/json") == nil {
+		t.Errorf("Expected the referenced body parameter to become a request body")
+	}
+
+	show, err := p.GetOperationDetails("/pets/{petId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	if len(show.Parameters) != 1 || show.Parameters[0].Name != "petId" {
+		t.Errorf("Expected the path-level petId parameter, got %+v", show.Parameters)
+	}
+	if example := show.Responses.Codes.GetOrZero("200").Content.GetOrZero("application/json").Example; example == nil {
+		t.Errorf("Expected the response example to be kept")
+	}
+
+	upload, err := p.GetOperationDetails("/pets/{petId}/photo", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	form := upload.RequestBody.Content.GetOrZero("multipart/form-data")
+	if form == nil {
+		t.Fatalf("Expected a multipart form request body")
+	}
+	file := form.Schema.Schema().Properties.GetOrZero("file").Schema()
+	if file.Format != "binary" || file.Type[0] != "string" {
+		t.Errorf("Expected the file field to become a binary string, got %v %s", file.Type, file.Format)
+	}
+
+	schemes, err := p.GetSecuritySchemes()
+	if err != nil {
+		t.Fatalf("Failed to get security schemes: %v", err)
+	}
+	byName := make(map[string]NamedSecurityScheme)
+	for _, s := range schemes {
+		byName[s.Name] = s
+	}
+	if s := byName["basic"].Scheme; s == nil || s.Type != "http" || s.Scheme != "basic" {
+		t.Errorf("Expected basic to become an HTTP scheme, got %+v", byName["basic"])
+	}
+	if s := byName["petstore_auth"].Scheme; s == nil || s.Flows == nil || s.Flows.AuthorizationCode == nil {
+		t.Errorf("Expected the accessCode flow to become authorizationCode, got %+v", byName["petstore_auth"])
+	}
+}
+
+func TestConvertSwagger2SkipsOpenAPI3(t *testing.T) {
+	if _, ok, _ := convertSwagger2([]byte(`{"openapi": "3.0.3", "info": {}, "paths": {}}`)); ok {
+		t.Errorf("Expected an OpenAPI 3 document to be left alone")
+	}
+	if _, ok, _ := convertSwagger2([]byte(`not: [valid`)); ok {
+		t.Errorf("Expected an invalid document to be left to the parser")
+	}
+}
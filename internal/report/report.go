@@ -0,0 +1,291 @@
+package report
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// Kinds of stored runs
+const (
+	KindTest      = "test"
+	KindBenchmark = "benchmark"
+)
+
+// Run is a test or benchmark summary exported with -o json
+type Run struct {
+	Name string    `json:"name"` // file path relative to the results directory
+	Time time.Time `json:"time"` // modification time of the file
+	Kind string    `json:"kind"` // KindTest or KindBenchmark
+
+	Test      *models.TestSummary      `json:"test,omitempty"`
+	Benchmark *models.BenchmarkSummary `json:"benchmark,omitempty"`
+}
+
+// Overview is the headline of a run shown in the run list
+type Overview struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+
+	Version   string `json:"version,omitempty"` // oas version that produced the run, if recorded
+	Endpoints int    `json:"endpoints"`
+
+	// Test runs
+	Passed   int     `json:"passed,omitempty"`
+	Failed   int     `json:"failed,omitempty"`
+	Coverage float64 `json:"coverage_pct,omitempty"`
+
+	// Benchmark runs
+	AvgMs     float64 `json:"avg_ms,omitempty"`
+	ReqPerSec float64 `json:"req_per_sec,omitempty"`
+	Errors    int     `json:"errors,omitempty"`
+}
+
+// Point is the outcome of one endpoint in one run
+type Point struct {
+	Run  string    `json:"run"`
+	Time time.Time `json:"time"`
+
+	Ms     float64 `json:"ms"`               // response time of a test, average latency of a benchmark
+	P99Ms  float64 `json:"p99_ms,omitempty"` // benchmarks only
+	Failed bool    `json:"failed"`           // failed test, or benchmark with errors
+}
+
+// Trend is the history of one endpoint across runs, oldest first
+type Trend struct {
+	Endpoint string  `json:"endpoint"`
+	Points   []Point `json:"points"`
+}
+
+// Statuses of a Change
+const (
+	ChangeAdded   = "added"   // only in the newer run
+	ChangeRemoved = "removed" // only in the older run
+	ChangeFixed   = "fixed"   // failed before, passes now
+	ChangeBroken  = "broken"  // passed before, fails now
+	ChangeSame    = "same"    // same outcome in both runs
+)
+
+// Change compares one endpoint between two runs
+type Change struct {
+	Endpoint string  `json:"endpoint"`
+	Status   string  `json:"status"` // one of the Change* constants
+	Base     *Point  `json:"base,omitempty"`
+	Head     *Point  `json:"head,omitempty"`
+	DeltaPct float64 `json:"delta_pct"` // change of Ms from base to head
+}
+
+// LoadDir reads the JSON results (optionally .json.gz) under dir, oldest
+// first. Other JSON files, such as badges, are returned as ignored.
+func LoadDir(dir string) (runs []Run, ignored []string, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		lower := strings.ToLower(d.Name())
+		if d.IsDir() || !(strings.HasSuffix(lower, ".json") || strings.HasSuffix(lower, ".json.gz")) {
+			return nil
+		}
+		name, _ := filepath.Rel(dir, path)
+		name = filepath.ToSlash(name)
+		run, ok, err := loadRun(path)
+		if err != nil || !ok {
+			ignored = append(ignored, name)
+			return nil
+		}
+		run.Name = name
+		runs = append(runs, run)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if !runs[i].Time.Equal(runs[j].Time) {
+			return runs[i].Time.Before(runs[j].Time)
+		}
+		return runs[i].Name < runs[j].Name
+	})
+	return runs, ignored, nil
+}
+
+// LoadBenchmark reads a benchmark summary exported with -o json (optionally
+// .json.gz), e.g. the baseline of a new run
+func LoadBenchmark(path string) (*models.BenchmarkSummary, error) {
+	run, ok, err := loadRun(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark results %s: %w", path, err)
+	}
+	if !ok || run.Kind != KindBenchmark {
+		return nil, fmt.Errorf("%s is not a benchmark summary exported with -o json", path)
+	}
+	return run.Benchmark, nil
+}
+
+// loadRun reads one results file, reporting false if it isn't a summary
+func loadRun(path string) (Run, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Run{}, false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Run{}, false, err
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return Run{}, false, err
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return Run{}, false, err
+		}
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Run{}, false, err
+	}
+	run := Run{Time: info.ModTime()}
+	switch {
+	case probe["total_tests"] != nil:
+		run.Kind = KindTest
+		run.Test = &models.TestSummary{}
+		err = json.Unmarshal(data, run.Test)
+	case probe["total_endpoints"] != nil:
+		run.Kind = KindBenchmark
+		run.Benchmark = &models.BenchmarkSummary{}
+		err = json.Unmarshal(data, run.Benchmark)
+	default:
+		return Run{}, false, nil
+	}
+	return run, err == nil, err
+}
+
+// Overview returns the headline of the run
+func (r Run) Overview() Overview {
+	o := Overview{Name: r.Name, Time: r.Time, Kind: r.Kind}
+	switch {
+	case r.Test != nil:
+		o.Endpoints = r.Test.TotalTests
+		o.Passed = r.Test.Passed
+		o.Failed = r.Test.Failed
+		o.Coverage = r.Test.Coverage
+		if r.Test.Build != nil {
+			o.Version = r.Test.Build.Version
+		}
+	case r.Benchmark != nil:
+		o.Endpoints = r.Benchmark.TotalEndpoints
+		o.AvgMs = ms(r.Benchmark.OverallAvgTime)
+		o.ReqPerSec = r.Benchmark.OverallReqsPerSec
+		o.Errors = r.Benchmark.TotalErrors
+		if r.Benchmark.Build != nil {
+			o.Version = r.Benchmark.Build.Version
+		}
+	}
+	return o
+}
+
+// Points returns the outcome of each endpoint of the run, keyed by
+// "METHOD /path" (prefixed with the spec in multi-spec runs)
+func (r Run) Points() map[string]Point {
+	points := make(map[string]Point)
+	switch {
+	case r.Test != nil:
+		for _, res := range r.Test.Results {
+			endpoint := res.Method + " " + res.Path
+			if res.Spec != "" {
+				endpoint = res.Spec + ": " + endpoint
+			}
+			points[endpoint] = Point{Run: r.Name, Time: r.Time, Ms: ms(res.ResponseTime), Failed: !res.Passed}
+		}
+	case r.Benchmark != nil:
+		for _, res := range r.Benchmark.Results {
+			points[res.Method+" "+res.Path] = Point{
+				Run:    r.Name,
+				Time:   r.Time,
+				Ms:     ms(res.AvgTime),
+				P99Ms:  ms(res.P99Time),
+				Failed: res.ErrorCount > 0,
+			}
+		}
+	}
+	return points
+}
+
+// Trends returns the history of every endpoint across the runs of a kind,
+// sorted by endpoint
+func Trends(runs []Run, kind string) []Trend {
+	byEndpoint := make(map[string][]Point)
+	for _, r := range runs {
+		if r.Kind != kind {
+			continue
+		}
+		for endpoint, p := range r.Points() {
+			byEndpoint[endpoint] = append(byEndpoint[endpoint], p)
+		}
+	}
+	trends := make([]Trend, 0, len(byEndpoint))
+	for endpoint, points := range byEndpoint {
+		trends = append(trends, Trend{Endpoint: endpoint, Points: points})
+	}
+	sort.Slice(trends, func(i, j int) bool { return trends[i].Endpoint < trends[j].Endpoint })
+	return trends
+}
+
+// Compare lists the changes of every endpoint from base to head, broken
+// endpoints first. Both runs must be of the same kind.
+func Compare(base, head Run) ([]Change, error) {
+	if base.Kind != head.Kind {
+		return nil, fmt.Errorf("cannot compare %s run %s with %s run %s", base.Kind, base.Name, head.Kind, head.Name)
+	}
+	basePoints, headPoints := base.Points(), head.Points()
+	var changes []Change
+	for endpoint, b := range basePoints {
+		c := Change{Endpoint: endpoint, Base: &b, Status: ChangeRemoved}
+		if h, ok := headPoints[endpoint]; ok {
+			c.Head = &h
+			switch {
+			case b.Failed && !h.Failed:
+				c.Status = ChangeFixed
+			case !b.Failed && h.Failed:
+				c.Status = ChangeBroken
+			default:
+				c.Status = ChangeSame
+			}
+			if b.Ms > 0 {
+				c.DeltaPct = (h.Ms - b.Ms) / b.Ms * 100
+			}
+		}
+		changes = append(changes, c)
+	}
+	for endpoint, h := range headPoints {
+		if _, ok := basePoints[endpoint]; !ok {
+			changes = append(changes, Change{Endpoint: endpoint, Head: &h, Status: ChangeAdded})
+		}
+	}
+
+	order := map[string]int{ChangeBroken: 0, ChangeFixed: 1, ChangeAdded: 2, ChangeRemoved: 3, ChangeSame: 4}
+	sort.Slice(changes, func(i, j int) bool {
+		if order[changes[i].Status] != order[changes[j].Status] {
+			return order[changes[i].Status] < order[changes[j].Status]
+		}
+		return changes[i].Endpoint < changes[j].Endpoint
+	})
+	return changes, nil
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
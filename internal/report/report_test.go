@@ -0,0 +1,178 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// writeRun stores a summary as an exported results file with the given age
+func writeRun(t *testing.T, dir, name string, summary interface{}, age time.Duration) {
+	t.Helper()
+	data, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testSummary(results ...models.TestResult) models.TestSummary {
+	var s models.TestSummary
+	for _, r := range results {
+		s.AddResult(r)
+	}
+	return s
+}
+
+func resultsDir(t *testing.T) string {
+	dir := t.TempDir()
+	writeRun(t, dir, "old.json", testSummary(
+		models.TestResult{Method: "GET", Path: "/pets", Passed: true, ResponseTime: 10 * time.Millisecond},
+		models.TestResult{Method: "POST", Path: "/pets", Passed: false, ResponseTime: 5 * time.Millisecond},
+		models.TestResult{Method: "GET", Path: "/owners", Passed: true},
+	), 2*time.Hour)
+	writeRun(t, dir, "new.json", testSummary(
+		models.TestResult{Method: "GET", Path: "/pets", Passed: false, ResponseTime: 15 * time.Millisecond},
+		models.TestResult{Method: "POST", Path: "/pets", Passed: true, ResponseTime: 5 * time.Millisecond},
+		models.TestResult{Method: "GET", Path: "/pets/{id}", Passed: true},
+	), time.Hour)
+	writeRun(t, dir, "bench.json", models.BenchmarkSummary{TotalEndpoints: 1, Build: &models.BuildInfo{Version: "1.2.3"}, Results: []models.BenchmarkResult{
+		{Method: "GET", Path: "/pets", AvgTime: 2 * time.Millisecond, P99Time: 9 * time.Millisecond},
+	}}, 0)
+	writeRun(t, dir, "badge.json", map[string]string{"label": "p99"}, 0)
+	return dir
+}
+
+func TestLoadDir(t *testing.T) {
+	runs, ignored, err := LoadDir(resultsDir(t))
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if len(runs) != 3 || runs[0].Name != "old.json" || runs[1].Name != "new.json" || runs[2].Kind != KindBenchmark {
+		t.Fatalf("Expected old, new and bench runs in time order, got %+v", runs)
+	}
+	if len(ignored) != 1 || ignored[0] != "badge.json" {
+		t.Errorf("Expected badge.json to be ignored, got %v", ignored)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	runs, _, err := LoadDir(resultsDir(t))
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	changes, err := Compare(runs[0], runs[1])
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+
+	want := []struct{ endpoint, status string }{
+		{"GET /pets", ChangeBroken},
+		{"POST /pets", ChangeFixed},
+		{"GET /pets/{id}", ChangeAdded},
+		{"GET /owners", ChangeRemoved},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for i, w := range want {
+		if changes[i].Endpoint != w.endpoint || changes[i].Status != w.status {
+			t.Errorf("Change %d: expected %s %s, got %s %s", i, w.endpoint, w.status, changes[i].Endpoint, changes[i].Status)
+		}
+	}
+	if changes[0].DeltaPct != 50 {
+		t.Errorf("Expected GET /pets to be 50%% slower, got %v", changes[0].DeltaPct)
+	}
+
+	if _, err := Compare(runs[0], runs[2]); err == nil {
+		t.Error("Expected an error comparing a test run with a benchmark run")
+	}
+}
+
+func TestTrends(t *testing.T) {
+	runs, _, err := LoadDir(resultsDir(t))
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	trends := Trends(runs, KindTest)
+	if len(trends) != 4 {
+		t.Fatalf("Expected 4 endpoints, got %+v", trends)
+	}
+	pets := trends[1]
+	if pets.Endpoint != "GET /pets" || len(pets.Points) != 2 || pets.Points[0].Run != "old.json" || !pets.Points[1].Failed {
+		t.Errorf("Unexpected trend of GET /pets: %+v", pets)
+	}
+
+	bench := Trends(runs, KindBenchmark)
+	if len(bench) != 1 || bench[0].Points[0].Ms != 2 || bench[0].Points[0].P99Ms != 9 {
+		t.Errorf("Unexpected benchmark trends: %+v", bench)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(NewHandler(resultsDir(t)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/runs")
+	if err != nil {
+		t.Fatalf("GET /api/runs: %v", err)
+	}
+	var overviews []Overview
+	json.NewDecoder(resp.Body).Decode(&overviews)
+	resp.Body.Close()
+	if len(overviews) != 3 || overviews[0].Failed != 1 || overviews[0].Endpoints != 3 {
+		t.Errorf("Unexpected overviews: %+v", overviews)
+	}
+	if len(overviews) == 3 && (overviews[0].Version != "" || overviews[2].Version != "1.2.3") {
+		t.Errorf("Expected the version of the benchmark run only, got %q and %q", overviews[0].Version, overviews[2].Version)
+	}
+
+	for path, status := range map[string]int{
+		"/":                          http.StatusOK,
+		"/api/run?name=new.json":     http.StatusOK,
+		"/api/run?name=missing.json": http.StatusNotFound,
+		"/api/compare?base=old.json&head=new.json":   http.StatusOK,
+		"/api/compare?base=old.json&head=bench.json": http.StatusBadRequest,
+		"/api/trends?kind=benchmark":                 http.StatusOK,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("GET %s: expected %d, got %d", path, status, resp.StatusCode)
+		}
+	}
+}
+
+func TestLoadBenchmark(t *testing.T) {
+	dir := resultsDir(t)
+
+	summary, err := LoadBenchmark(filepath.Join(dir, "bench.json"))
+	if err != nil {
+		t.Fatalf("LoadBenchmark: %v", err)
+	}
+	if len(summary.Results) != 1 || summary.Results[0].P99Time != 9*time.Millisecond {
+		t.Errorf("Expected the stored benchmark results, got %+v", summary.Results)
+	}
+
+	for _, name := range []string{"new.json", "badge.json", "missing.json"} {
+		if _, err := LoadBenchmark(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Expected %s to be rejected as a baseline", name)
+		}
+	}
+}
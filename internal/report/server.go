@@ -0,0 +1,125 @@
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Handler serves the results under a directory as a small web app. The
+// directory is read again on every request, so runs stored while the
+// report is open show up on reload.
+type Handler struct {
+	dir string
+	mux *http.ServeMux
+}
+
+// NewHandler creates a handler for the results under dir
+func NewHandler(dir string) *Handler {
+	h := &Handler{dir: dir, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /{$}", h.handleIndex)
+	h.mux.HandleFunc("GET /api/runs", h.handleRuns)
+	h.mux.HandleFunc("GET /api/run", h.handleRun)
+	h.mux.HandleFunc("GET /api/trends", h.handleTrends)
+	h.mux.HandleFunc("GET /api/compare", h.handleCompare)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// handleIndex serves the report page
+func (h *Handler) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+// handleRuns lists the overview of every run, oldest first
+func (h *Handler) handleRuns(w http.ResponseWriter, r *http.Request) {
+	runs, ok := h.load(w)
+	if !ok {
+		return
+	}
+	overviews := make([]Overview, 0, len(runs))
+	for _, run := range runs {
+		overviews = append(overviews, run.Overview())
+	}
+	writeJSON(w, overviews)
+}
+
+// handleRun serves the full summary of the run named by ?name=
+func (h *Handler) handleRun(w http.ResponseWriter, r *http.Request) {
+	runs, ok := h.load(w)
+	if !ok {
+		return
+	}
+	if run, found := find(runs, r.URL.Query().Get("name")); found {
+		writeJSON(w, run)
+		return
+	}
+	http.Error(w, "run not found", http.StatusNotFound)
+}
+
+// handleTrends serves the per-endpoint history of the runs of ?kind=
+// (default test)
+func (h *Handler) handleTrends(w http.ResponseWriter, r *http.Request) {
+	runs, ok := h.load(w)
+	if !ok {
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	if kind == "" {
+		kind = KindTest
+	}
+	writeJSON(w, Trends(runs, kind))
+}
+
+// handleCompare serves the changes from the run ?base= to the run ?head=
+func (h *Handler) handleCompare(w http.ResponseWriter, r *http.Request) {
+	runs, ok := h.load(w)
+	if !ok {
+		return
+	}
+	base, baseFound := find(runs, r.URL.Query().Get("base"))
+	head, headFound := find(runs, r.URL.Query().Get("head"))
+	if !baseFound || !headFound {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return
+	}
+	changes, err := Compare(base, head)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, changes)
+}
+
+// load reads the results directory, answering with an error if it fails
+func (h *Handler) load(w http.ResponseWriter) ([]Run, bool) {
+	runs, _, err := LoadDir(h.dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return runs, true
+}
+
+// find returns the run with the given name
+func find(runs []Run, name string) (Run, bool) {
+	for _, run := range runs {
+		if run.Name == name {
+			return run, true
+		}
+	}
+	return Run{}, false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,81 @@
+package scaffold
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/generator"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Data formats written by WriteData
+const (
+	DataJSON   = "json"   // a single indented JSON array
+	DataNDJSON = "ndjson" // one JSON document per line
+)
+
+// LookupSchema returns the component schema with the given name
+func LookupSchema(p *parser.Parser, name string) (*base.Schema, error) {
+	schemas, err := p.GetSchemas()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(schemas))
+	for _, s := range schemas {
+		if s.Name == name {
+			return s.Schema, nil
+		}
+		names = append(names, s.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("schema %s not found, the spec declares no component schemas", name)
+	}
+	return nil, fmt.Errorf("schema %s not found, expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// Data generates count instances of a schema. Each instance gets its own
+// generator seeded from seed, so identifier fields aren't correlated across
+// instances and the same seed yields the same data.
+func Data(schema *base.Schema, count int, seed int64) ([]interface{}, error) {
+	values := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		g := generator.NewGenerator()
+		g.Seed(seed + int64(i))
+		val, err := g.GenerateValue(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate instance %d: %w", i+1, err)
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
+// WriteData writes generated instances in the given format
+func WriteData(w io.Writer, values []interface{}, format string) error {
+	switch format {
+	case DataJSON:
+		if values == nil {
+			values = []interface{}{}
+		}
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case DataNDJSON:
+		bw := bufio.NewWriter(w)
+		enc := json.NewEncoder(bw)
+		for _, val := range values {
+			if err := enc.Encode(val); err != nil {
+				return err
+			}
+		}
+		return bw.Flush()
+	}
+	return fmt.Errorf("unknown format %q, expected %s or %s", format, DataJSON, DataNDJSON)
+}
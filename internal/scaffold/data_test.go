@@ -0,0 +1,65 @@
+package scaffold
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestData(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/complex-schemas.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if _, err := LookupSchema(p, "Missing"); err == nil || !strings.Contains(err.Error(), "User") {
+		t.Errorf("Expected an error listing the schemas, got %v", err)
+	}
+	schema, err := LookupSchema(p, "User")
+	if err != nil {
+		t.Fatalf("LookupSchema: %v", err)
+	}
+
+	values, err := Data(schema, 5, 42)
+	if err != nil {
+		t.Fatalf("Data: %v", err)
+	}
+	if len(values) != 5 {
+		t.Fatalf("Expected 5 instances, got %d", len(values))
+	}
+	for i, val := range values {
+		user, ok := val.(map[string]interface{})
+		if !ok || user["email"] == nil || user["name"] == nil {
+			t.Errorf("Instance %d lacks required fields: %v", i, val)
+		}
+	}
+	again, _ := Data(schema, 5, 42)
+	if !reflect.DeepEqual(values, again) {
+		t.Errorf("Expected the same seed to generate the same data")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteData(&buf, values, DataNDJSON); err != nil {
+		t.Fatalf("WriteData: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 NDJSON lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("Invalid NDJSON line: %s", line)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteData(&buf, nil, DataJSON); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected an empty JSON array, got %q (%v)", buf.String(), err)
+	}
+	if err := WriteData(&buf, values, "csv"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,85 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/generator"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// Example is a generated request of an operation: its parameter values by
+// location and its body
+type Example struct {
+	OperationID string                       `json:"operation_id,omitempty"`
+	Method      string                       `json:"method"`
+	Path        string                       `json:"path"`
+	Summary     string                       `json:"summary,omitempty"`
+	Parameters  map[string]map[string]string `json:"parameters,omitempty"` // path, query, header and cookie values by name
+	ContentType string                       `json:"content_type,omitempty"`
+	Body        json.RawMessage              `json:"body,omitempty"`
+}
+
+// Examples generates an example request for each operation. Parameters use
+// the generator's configured values before generated ones, and identifiers
+// correlate across operations like they do in a test run.
+func Examples(p *parser.Parser, operations []models.Operation, g *generator.Generator) ([]Example, error) {
+	examples := make([]Example, 0, len(operations))
+	for _, op := range operations {
+		details, err := p.GetOperationDetails(op.Path, op.Method)
+		if err != nil {
+			return nil, err
+		}
+
+		example := Example{
+			OperationID: op.OperationID,
+			Method:      op.Method,
+			Path:        op.Path,
+			Summary:     op.Summary,
+		}
+		for _, param := range details.Parameters {
+			if param == nil {
+				continue
+			}
+			val, ok := g.LookupParameter(op.Path, op.Method, param)
+			if !ok {
+				val, err = g.GeneratePathParameter(param)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: failed to generate parameter %s: %w", op.Method, op.Path, param.Name, err)
+				}
+			}
+			if example.Parameters == nil {
+				example.Parameters = make(map[string]map[string]string)
+			}
+			if example.Parameters[param.In] == nil {
+				example.Parameters[param.In] = make(map[string]string)
+			}
+			example.Parameters[param.In][param.Name] = val
+		}
+		if details.RequestBody != nil {
+			body, contentType, err := g.GenerateRequestBodyForPath(op.Path, details.RequestBody)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: failed to generate request body: %w", op.Method, op.Path, err)
+			}
+			example.Body, example.ContentType = body, contentType
+		}
+		examples = append(examples, example)
+	}
+	return examples, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExampleFileName returns the file an example is written to: its operation
+// id, or method and path when it has none
+func ExampleFileName(example Example) string {
+	name := example.OperationID
+	if name == "" {
+		name = example.Method + example.Path
+	}
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+	return name + ".json"
+}
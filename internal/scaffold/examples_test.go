@@ -0,0 +1,56 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/generator"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestExamples(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/complex-schemas.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	operations, err := p.GetOperations("")
+	if err != nil {
+		t.Fatalf("GetOperations: %v", err)
+	}
+
+	g := generator.NewGenerator()
+	g.Seed(1)
+	g.SetParamValues(map[string]interface{}{"userId": 5})
+	examples, err := Examples(p, operations, g)
+	if err != nil {
+		t.Fatalf("Examples: %v", err)
+	}
+	if len(examples) != len(operations) {
+		t.Fatalf("Expected %d examples, got %d", len(operations), len(examples))
+	}
+
+	byName := make(map[string]Example)
+	for _, e := range examples {
+		byName[ExampleFileName(e)] = e
+	}
+	create, ok := byName["createUser.json"]
+	if !ok {
+		t.Fatalf("Expected createUser.json, got %v", byName)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(create.Body, &body); err != nil || create.ContentType != "application/json" {
+		t.Fatalf("Expected a JSON body, got %q (%s): %v", create.Body, create.ContentType, err)
+	}
+	if _, ok := body["email"]; !ok {
+		t.Errorf("Expected the body to have an email, got %v", body)
+	}
+	if got := byName["getUserOrders.json"].Parameters["path"]["userId"]; got != "5" {
+		t.Errorf("Expected the configured userId 5, got %q", got)
+	}
+}
+
+func TestExampleFileName(t *testing.T) {
+	if got := ExampleFileName(Example{Method: "GET", Path: "/pets/{petId}"}); got != "GET_pets_petId.json" {
+		t.Errorf("Expected GET_pets_petId.json, got %s", got)
+	}
+}
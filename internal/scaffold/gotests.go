@@ -0,0 +1,180 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"text/template"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// GoTestsOptions configures the generated test file
+type GoTestsOptions struct {
+	Package  string // package clause of the file, e.g. petstore_test
+	SpecPath string // spec file as the test opens it, relative to the file's directory
+	Mock     bool   // test against the spec's mock server instead of a deployed one
+}
+
+// goTestCase is a row of the generated test table
+type goTestCase struct {
+	Name       string
+	Method     string
+	Path       string
+	WantStatus int
+}
+
+// GoTests generates a _test.go file with a table-driven test per operation.
+// Each row runs the operation through the tester and checks its documented
+// success status, as a starting point for real assertions.
+func GoTests(p *parser.Parser, operations []models.Operation, opts GoTestsOptions) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "api_test"
+	}
+
+	cases := make([]goTestCase, 0, len(operations))
+	for _, op := range operations {
+		details, err := p.GetOperationDetails(op.Path, op.Method)
+		if err != nil {
+			return nil, err
+		}
+		name := op.OperationID
+		if name == "" {
+			name = op.Method + " " + op.Path
+		}
+		cases = append(cases, goTestCase{
+			Name:       name,
+			Method:     op.Method,
+			Path:       op.Path,
+			WantStatus: successStatus(details),
+		})
+	}
+
+	var buf bytes.Buffer
+	err := goTestsTemplate.Execute(&buf, struct {
+		GoTestsOptions
+		Cases []goTestCase
+	}{opts, cases})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tests: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format tests: %w", err)
+	}
+	return src, nil
+}
+
+// successStatus is the lowest documented 2xx status of an operation, or 0 if
+// it documents none
+func successStatus(details *parser.OperationDetails) int {
+	if details.Responses == nil || details.Responses.Codes == nil {
+		return 0
+	}
+	status := 0
+	for pair := details.Responses.Codes.First(); pair != nil; pair = pair.Next() {
+		code, err := strconv.Atoi(pair.Key())
+		if err == nil && code >= 200 && code < 300 && (status == 0 || code < status) {
+			status = code
+		}
+	}
+	return status
+}
+
+var goTestsTemplate = template.Must(template.New("gotests").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`// Generated by oas generate go-tests as a starting point: replace the status
+// checks with assertions on the responses your clients rely on.
+
+package {{.Package}}
+
+import (
+{{- if not .Mock}}
+	"os"
+{{- end}}
+	"testing"
+{{if .Mock}}
+	"github.com/moamenhredeen/oas/internal/mock"
+{{- end}}
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/moamenhredeen/oas/internal/tester"
+)
+
+// specFile is the spec the tests exercise
+const specFile = {{quote .SpecPath}}
+
+{{if .Mock -}}
+// TestOperations tests every operation of the spec against its mock server
+{{- else -}}
+// TestOperations tests every operation of the spec against the server in
+// OAS_BASE_URL, or the spec's first server URL
+{{- end}}
+func TestOperations(t *testing.T) {
+	p, err := parser.ParseFile(specFile)
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+{{if .Mock}}
+	ts, err := mock.NewTestServer(p, tester.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to start mock server: %v", err)
+	}
+	defer ts.Close()
+	operations, tst := ts.Operations, ts.Tester
+{{else}}
+	baseURL := os.Getenv("OAS_BASE_URL")
+	if baseURL == "" {
+		urls, err := p.GetServerURLs()
+		if err != nil || len(urls) == 0 {
+			t.Skip("The spec has no server URL, set OAS_BASE_URL")
+		}
+		baseURL = urls[0]
+	}
+	operations, err := p.GetOperations(baseURL)
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+	tst := tester.NewTesterWithConfig(tester.DefaultConfig())
+{{end}}
+	byRoute := make(map[string]models.Operation, len(operations))
+	for _, op := range operations {
+		byRoute[op.Method+" "+op.Path] = op
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int // 0 accepts any status the spec documents
+	}{
+{{- range .Cases}}
+		{ {{- quote .Name}}, {{quote .Method}}, {{quote .Path}}, {{.WantStatus -}} },
+{{- end}}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, ok := byRoute[tt.method+" "+tt.path]
+			if !ok {
+				t.Fatalf("%s %s is not in the spec", tt.method, tt.path)
+			}
+			result, err := tst.TestOperation(op, p)
+			if err != nil {
+				t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+			}
+			if !result.Passed {
+				t.Errorf("%s %s: %s", tt.method, tt.path, result.Error)
+				for _, ve := range result.ValidationErrors {
+					t.Errorf("  %s: %s", ve.Field, ve.Message)
+				}
+			}
+			if tt.wantStatus != 0 && result.StatusCode != tt.wantStatus {
+				t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.wantStatus, result.StatusCode)
+			}
+		})
+	}
+}
+`))
@@ -0,0 +1,43 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	oasparser "github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestGoTests(t *testing.T) {
+	p, err := oasparser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	operations, err := p.GetOperations("")
+	if err != nil {
+		t.Fatalf("GetOperations: %v", err)
+	}
+
+	for _, mock := range []bool{false, true} {
+		src, err := GoTests(p, operations, GoTestsOptions{Package: "petstore_test", SpecPath: "pet-store.json", Mock: mock})
+		if err != nil {
+			t.Fatalf("GoTests: %v", err)
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), "petstore_test.go", src, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("Generated file doesn't parse: %v", err)
+		}
+		if file.Name.Name != "petstore_test" {
+			t.Errorf("Expected package petstore_test, got %s", file.Name.Name)
+		}
+		for _, want := range []string{`{"listPets", "GET", "/pets", 200}`, `{"createPets", "POST", "/pets", 201}`, `const specFile = "pet-store.json"`} {
+			if !strings.Contains(string(src), want) {
+				t.Errorf("Expected %s in the generated file (mock %v)", want, mock)
+			}
+		}
+		if got := strings.Contains(string(src), "mock.NewTestServer"); got != mock {
+			t.Errorf("Expected mock server %v, got %v", mock, got)
+		}
+	}
+}
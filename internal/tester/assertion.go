@@ -0,0 +1,227 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Assertion is a parsed response assertion such as `$.items | length >= 1`
+// or `$.status == "available"`. An assertion without an operator checks that
+// the path exists.
+type Assertion struct {
+	Expr     string
+	path     []pathStep
+	length   bool        // apply `| length` to the selected value
+	operator string      // comparison operator, empty for an existence check
+	expected interface{} // literal the selected value is compared against
+}
+
+// pathStep is a single field or index selector in a JSONPath expression
+type pathStep struct {
+	field string
+	index int
+	isIdx bool
+}
+
+// assertionOperators lists comparison operators, longest first so that >= is
+// matched before >
+var assertionOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// ParseAssertion parses an assertion expression. The path is read first, so
+// a quoted field such as ['a==b'] may contain operator characters.
+func ParseAssertion(expr string) (Assertion, error) {
+	a := Assertion{Expr: expr}
+	path, rest, err := parsePath(strings.TrimSpace(expr))
+	if err != nil {
+		return a, fmt.Errorf("invalid assertion '%s': %w", expr, err)
+	}
+	a.path = path
+	rest = strings.TrimSpace(rest)
+
+	// The length filter
+	if filter, ok := strings.CutPrefix(rest, "|"); ok {
+		filter = strings.TrimSpace(filter)
+		name := filter
+		if end := strings.IndexAny(filter, " =!<>"); end >= 0 {
+			name = filter[:end]
+		}
+		if name != "length" {
+			return a, fmt.Errorf("invalid assertion '%s': unsupported filter %s", expr, name)
+		}
+		a.length = true
+		rest = strings.TrimSpace(filter[len(name):])
+	}
+	if rest == "" {
+		return a, nil
+	}
+
+	// The comparison
+	for _, op := range assertionOperators {
+		if strings.HasPrefix(rest, op) {
+			a.operator = op
+			break
+		}
+	}
+	if a.operator == "" {
+		return a, fmt.Errorf("invalid assertion '%s': expected an operator at %s", expr, rest)
+	}
+	literal := strings.TrimSpace(rest[len(a.operator):])
+	if err := json.Unmarshal([]byte(literal), &a.expected); err != nil {
+		return a, fmt.Errorf("invalid assertion '%s': bad literal %s", expr, literal)
+	}
+	return a, nil
+}
+
+// parsePath parses a JSONPath subset at the start of s: $, .field, [n] and
+// ['field']. It returns the path and the rest of s after it.
+func parsePath(s string) ([]pathStep, string, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, "", fmt.Errorf("path must start with $")
+	}
+	s = s[1:]
+
+	var steps []pathStep
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[ |=!<>")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, "", fmt.Errorf("empty field name")
+			}
+			steps = append(steps, pathStep{field: s[:end]})
+			s = s[end:]
+		case '[':
+			// A quoted field ends at its closing quote, whatever it contains
+			if strings.HasPrefix(s, "['") {
+				end := strings.Index(s[2:], "']")
+				if end < 0 {
+					return nil, "", fmt.Errorf("unterminated ['")
+				}
+				steps = append(steps, pathStep{field: s[2 : 2+end]})
+				s = s[2+end+2:]
+				continue
+			}
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, "", fmt.Errorf("unterminated [")
+			}
+			inner := s[1:end]
+			s = s[end+1:]
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid index [%s]", inner)
+			}
+			steps = append(steps, pathStep{index: n, isIdx: true})
+		case ' ', '|', '=', '!', '<', '>':
+			return steps, s, nil
+		default:
+			return nil, "", fmt.Errorf("unexpected character %q", s[0])
+		}
+	}
+	return steps, "", nil
+}
+
+// Evaluate checks the assertion against a decoded JSON body and returns a
+// description of the failure, or an empty string if it holds
+func (a Assertion) Evaluate(body interface{}) string {
+	value, ok := selectPath(body, a.path)
+	if !ok {
+		return "path not found"
+	}
+
+	if a.length {
+		switch v := value.(type) {
+		case []interface{}:
+			value = float64(len(v))
+		case map[string]interface{}:
+			value = float64(len(v))
+		case string:
+			value = float64(len(v))
+		default:
+			return fmt.Sprintf("cannot take length of %v", value)
+		}
+	}
+
+	if a.operator == "" {
+		return ""
+	}
+
+	if compare(value, a.operator, a.expected) {
+		return ""
+	}
+	return fmt.Sprintf("got %s", formatValue(value))
+}
+
+// selectPath walks the decoded JSON body along the parsed path
+func selectPath(body interface{}, path []pathStep) (interface{}, bool) {
+	current := body
+	for _, step := range path {
+		if step.isIdx {
+			list, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			idx := step.index
+			if idx < 0 {
+				idx += len(list)
+			}
+			if idx < 0 || idx >= len(list) {
+				return nil, false
+			}
+			current = list[idx]
+			continue
+		}
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[step.field]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// compare applies a comparison operator; ordering operators require numbers
+func compare(actual interface{}, operator string, expected interface{}) bool {
+	switch operator {
+	case "==":
+		return reflect.DeepEqual(actual, expected)
+	case "!=":
+		return !reflect.DeepEqual(actual, expected)
+	}
+
+	a, aok := actual.(float64)
+	e, eok := expected.(float64)
+	if !aok || !eok {
+		return false
+	}
+	switch operator {
+	case ">=":
+		return a >= e
+	case "<=":
+		return a <= e
+	case ">":
+		return a > e
+	case "<":
+		return a < e
+	}
+	return false
+}
+
+// formatValue renders a value as JSON for failure messages
+func formatValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
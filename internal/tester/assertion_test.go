@@ -0,0 +1,59 @@
+package tester
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAssertionInvalid(t *testing.T) {
+	invalid := []string{
+		"items | length >= 1",
+		"$.items | count >= 1",
+		"$.status == available",
+		"$.items[x]",
+		"$.status available",
+		"$['status' == 1",
+	}
+
+	for _, expr := range invalid {
+		if _, err := ParseAssertion(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
+
+func TestAssertionEvaluate(t *testing.T) {
+	var body interface{}
+	if err := json.Unmarshal([]byte(`{"status": "available", "items": [{"id": 1}, {"id": 2}], "count": 2, "a==b": "x", "a]b": 1}`), &body); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+
+	tests := []struct {
+		expr string
+		pass bool
+	}{
+		{`$.items | length >= 1`, true},
+		{`$.items | length > 2`, false},
+		{`$.status == "available"`, true},
+		{`$.status != "available"`, false},
+		{`$.items[1].id == 2`, true},
+		{`$['count'] < 3`, true},
+		{`$['a==b'] == "x"`, true},
+		{`$['a==b']`, true},
+		{`$['a]b']==1`, true},
+		{`$.count|length`, false},
+		{`$.items[0]`, true},
+		{`$.missing`, false},
+	}
+
+	for _, tt := range tests {
+		a, err := ParseAssertion(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		failure := a.Evaluate(body)
+		if (failure == "") != tt.pass {
+			t.Errorf("Assertion %q: expected pass=%v, got failure %q", tt.expr, tt.pass, failure)
+		}
+	}
+}
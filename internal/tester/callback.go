@@ -0,0 +1,371 @@
+package tester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// DefaultCallbackTimeout is how long a test waits for the callbacks of an
+// operation after its response
+const DefaultCallbackTimeout = 10 * time.Second
+
+// callbackExpression matches the callback URL expressions whose value comes
+// from the request, so that the listener URL can be put there
+var callbackExpression = regexp.MustCompile(`^\{\$request\.(body#(/[^}]*)|query\.([^}]+)|header\.([^}]+))\}`)
+
+// callbackDelivery is a request received by the callback listener
+type callbackDelivery struct {
+	method string
+	body   []byte
+}
+
+// expectedCallback is a callback URL registered with one request. Callbacks
+// sharing a URL expression (e.g. onSuccess and onFailure) share the URL, and a
+// delivery matching any of them is accepted.
+type expectedCallback struct {
+	token      string
+	candidates []parser.Callback
+	deliveries chan callbackDelivery
+}
+
+// callbackListener receives the callbacks and webhooks sent by the API under
+// test
+type callbackListener struct {
+	server    *http.Server
+	baseURL   string
+	validator *Validator
+
+	mu       sync.Mutex
+	waiting  map[string]*expectedCallback // by token
+	webhooks []parser.Webhook
+	received []models.TestResult // webhook deliveries not yet reported
+}
+
+// StartCallbacks starts a listener for callbacks and webhooks. Operations
+// with callbacks then get the listener URL in place of their callback URL and
+// their test waits for the callback and validates it. Webhooks declared by
+// the spec are accepted at <url>/webhooks/<name> and reported with the
+// results. It returns the URL the API has to reach.
+func (t *Tester) StartCallbacks(p *parser.Parser) (string, error) {
+	webhooks, err := p.GetWebhooks()
+	if err != nil {
+		return "", err
+	}
+
+	listen := t.config.CallbackListen
+	if listen == "" {
+		listen = "127.0.0.1:0"
+	}
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return "", fmt.Errorf("failed to start callback listener: %w", err)
+	}
+
+	baseURL := t.config.CallbackURL
+	if baseURL == "" {
+		baseURL = "http://" + ln.Addr().String()
+	}
+
+	l := &callbackListener{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		validator: t.validator,
+		waiting:   make(map[string]*expectedCallback),
+		webhooks:  webhooks,
+	}
+	l.server = &http.Server{Handler: l, ReadHeaderTimeout: 10 * time.Second}
+	go l.server.Serve(ln)
+
+	t.callbacks = l
+	return l.baseURL, nil
+}
+
+// StopCallbacks shuts the callback listener down
+func (t *Tester) StopCallbacks() {
+	if t.callbacks == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	t.callbacks.server.Shutdown(ctx)
+	t.callbacks = nil
+}
+
+// ServeHTTP accepts callbacks at /callbacks/<token> and webhooks at
+// /webhooks/<name>
+func (l *callbackListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := strings.Trim(r.URL.Path, "/")
+
+	if token, ok := strings.CutPrefix(path, "callbacks/"); ok {
+		token, _, _ = strings.Cut(token, "/")
+		l.mu.Lock()
+		expected := l.waiting[token]
+		delete(l.waiting, token)
+		l.mu.Unlock()
+		if expected == nil {
+			http.NotFound(w, r)
+			return
+		}
+		expected.deliveries <- callbackDelivery{method: r.Method, body: body}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if name, ok := strings.CutPrefix(path, "webhooks/"); ok {
+		result, known := l.validateWebhook(name, r.Method, body)
+		l.mu.Lock()
+		l.received = append(l.received, result)
+		l.mu.Unlock()
+		if !known {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// expect registers the callbacks of an operation, returning the overrides
+// that put the listener URL where each callback URL is taken from. Callbacks
+// whose URL doesn't come from the request can't be redirected and are left
+// out.
+func (l *callbackListener) expect(callbacks []parser.Callback, overrides RequestOverrides) (RequestOverrides, []*expectedCallback, error) {
+	byExpression := make(map[string]*expectedCallback)
+	var expected []*expectedCallback
+	for _, cb := range callbacks {
+		if e, ok := byExpression[cb.Expression]; ok {
+			e.candidates = append(e.candidates, cb)
+			continue
+		}
+		match := callbackExpression.FindStringSubmatch(cb.Expression)
+		if match == nil {
+			continue
+		}
+
+		token, err := newUUID()
+		if err != nil {
+			return overrides, nil, fmt.Errorf("failed to generate callback token: %w", err)
+		}
+		url := l.baseURL + "/callbacks/" + token
+
+		switch {
+		case strings.HasPrefix(match[1], "body#"):
+			field := strings.ReplaceAll(strings.Trim(match[2], "/"), "/", ".")
+			if field == "" {
+				continue
+			}
+			overrides.Body = withBodyOverride(overrides.Body, field, url)
+		case match[3] != "":
+			overrides.Params = withOverride(overrides.Params, match[3], url)
+		case match[4] != "":
+			overrides.Headers = withOverride(overrides.Headers, match[4], url)
+		}
+
+		e := &expectedCallback{
+			token:      token,
+			candidates: []parser.Callback{cb},
+			deliveries: make(chan callbackDelivery, 1),
+		}
+		byExpression[cb.Expression] = e
+		expected = append(expected, e)
+	}
+
+	l.mu.Lock()
+	for _, e := range expected {
+		l.waiting[e.token] = e
+	}
+	l.mu.Unlock()
+	return overrides, expected, nil
+}
+
+// withOverride returns a copy of values with name set, leaving the caller's
+// map (e.g. a shared test case) untouched
+func withOverride(values map[string]string, name, value string) map[string]string {
+	out := make(map[string]string, len(values)+1)
+	for k, v := range values {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+// withBodyOverride is withOverride for body fields
+func withBodyOverride(values map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values)+1)
+	for k, v := range values {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+// await waits until timeout for the expected callbacks and validates them.
+// It returns the validation errors and the number of callbacks received.
+func (l *callbackListener) await(expected []*expectedCallback, timeout time.Duration) ([]models.ValidationError, int) {
+	var errors []models.ValidationError
+	received := 0
+	deadline := time.Now().Add(timeout)
+
+	for _, e := range expected {
+		if delivery, ok := receive(e.deliveries, time.Until(deadline)); ok {
+			received++
+			errors = append(errors, l.validateCallback(e.candidates, delivery)...)
+		} else {
+			errors = append(errors, models.ValidationError{
+				Field:   "callback." + e.candidates[0].Name,
+				Message: fmt.Sprintf("callback not received within %v", timeout),
+			})
+		}
+	}
+	return errors, received
+}
+
+// forget stops accepting the expected callbacks of a finished request
+func (l *callbackListener) forget(expected []*expectedCallback) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range expected {
+		delete(l.waiting, e.token)
+	}
+}
+
+// receive waits up to wait for a delivery. A delivery that has already
+// arrived is returned even when the wait is over.
+func receive(deliveries chan callbackDelivery, wait time.Duration) (callbackDelivery, bool) {
+	select {
+	case delivery := <-deliveries:
+		return delivery, true
+	default:
+	}
+	if wait <= 0 {
+		return callbackDelivery{}, false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case delivery := <-deliveries:
+		return delivery, true
+	case <-timer.C:
+		return callbackDelivery{}, false
+	}
+}
+
+// validateCallback validates a delivery against the callbacks it may be,
+// accepting it if any of them matches
+func (l *callbackListener) validateCallback(candidates []parser.Callback, delivery callbackDelivery) []models.ValidationError {
+	var first []models.ValidationError
+	for i, cb := range candidates {
+		errors := l.validateDelivery("callback."+cb.Name, cb.Method, cb.Operation, delivery)
+		if len(errors) == 0 {
+			return nil
+		}
+		if i == 0 {
+			first = errors
+		}
+	}
+	return first
+}
+
+// validateWebhook validates a webhook delivery against the webhook of that
+// name, recording it as a test result. The boolean result is false when the
+// spec declares no such webhook.
+func (l *callbackListener) validateWebhook(name, method string, body []byte) (models.TestResult, bool) {
+	result := models.TestResult{Path: "/webhooks/" + name, Method: method}
+
+	var webhook *parser.Webhook
+	for i := range l.webhooks {
+		if l.webhooks[i].Name == name && (l.webhooks[i].Method == method || webhook == nil) {
+			webhook = &l.webhooks[i]
+		}
+	}
+	if webhook == nil {
+		result.Error = fmt.Sprintf("unknown webhook %q", name)
+		return maskResult(result), false
+	}
+	if webhook.Operation != nil {
+		result.OperationID = webhook.Operation.OperationId
+	}
+
+	result.ValidationErrors = l.validateDelivery("webhook."+name, webhook.Method, webhook.Operation, callbackDelivery{method: method, body: body})
+	if len(result.ValidationErrors) == 0 {
+		result.Passed = true
+	} else {
+		var msgs []string
+		for _, ve := range result.ValidationErrors {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", ve.Field, ve.Message))
+		}
+		result.Error = fmt.Sprintf("validation failed: %s", strings.Join(msgs, "; "))
+		result.ErrorClass = models.ErrorValidation
+	}
+	return maskResult(result), true
+}
+
+// validateDelivery checks the method and JSON body of a received request
+// against the operation describing it
+func (l *callbackListener) validateDelivery(field, method string, op *v3.Operation, delivery callbackDelivery) []models.ValidationError {
+	if delivery.method != method {
+		return []models.ValidationError{{
+			Field:   field,
+			Message: fmt.Sprintf("expected %s request, got %s", method, delivery.method),
+		}}
+	}
+
+	schema := requestBodySchema(op)
+	if schema == nil {
+		return nil
+	}
+	var data interface{}
+	if err := decodeJSON(bytes.NewReader(delivery.body), &data); err != nil {
+		return []models.ValidationError{{
+			Field:   field,
+			Message: fmt.Sprintf("failed to parse JSON body: %v", err),
+		}}
+	}
+	return l.validator.validateValue(field, data, schema)
+}
+
+// requestBodySchema returns the JSON request body schema of an operation
+func requestBodySchema(op *v3.Operation) *base.Schema {
+	if op == nil {
+		return nil
+	}
+	return jsonBodySchema(op.RequestBody)
+}
+
+// jsonBodySchema returns the schema of a request body's JSON content
+func jsonBodySchema(body *v3.RequestBody) *base.Schema {
+	if body == nil || body.Content == nil {
+		return nil
+	}
+	for pair := body.Content.First(); pair != nil; pair = pair.Next() {
+		if strings.Contains(pair.Key(), "json") && pair.Value() != nil && pair.Value().Schema != nil {
+			return pair.Value().Schema.Schema()
+		}
+	}
+	return nil
+}
+
+// webhookResults returns the webhook deliveries received since the last call
+func (l *callbackListener) webhookResults() []models.TestResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	results := l.received
+	l.received = nil
+	return results
+}
@@ -0,0 +1,373 @@
+package tester
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// Case is one set of parameter values an operation is tested with
+type Case struct {
+	Label  string                 // describes the case in results, e.g. "status=sold"
+	Params map[string]string      // fixed path and query parameter values
+	Body   map[string]interface{} // fixed JSON body fields, by dot-separated name
+	Seed   int64                  // seed for the generated values (0 = keep the current one)
+
+	RequiredOnly bool // leave out optional query parameters
+}
+
+// enumParameter is a path or query parameter, or a JSON body field, with a
+// fixed set of values
+type enumParameter struct {
+	name   string
+	values []string
+	body   []interface{} // typed values of a body field; nil for parameters
+}
+
+// label names the parameter in case labels, e.g. "body.status" for a field
+func (p enumParameter) label() string {
+	if p.body != nil {
+		return "body." + p.name
+	}
+	return p.name
+}
+
+// set fixes the parameter to its i-th value in a case
+func (p enumParameter) set(c *Case, i int) {
+	if p.body != nil {
+		if c.Body == nil {
+			c.Body = make(map[string]interface{})
+		}
+		c.Body[p.name] = p.body[i]
+		return
+	}
+	if c.Params == nil {
+		c.Params = make(map[string]string)
+	}
+	c.Params[p.name] = p.values[i]
+}
+
+// DefaultMaxEnumValues is the largest enum tested value by value; larger
+// enums are covered by the generator's random picks
+const DefaultMaxEnumValues = 10
+
+// maxBodyEnumDepth limits how deep nested objects are searched for enum
+// fields
+const maxBodyEnumDepth = 3
+
+// enumParameters returns the enum-valued path and query parameters of an
+// operation, then the enum-valued fields of its JSON request body. Parameters
+// with a configured value keep that value, and enums with more than
+// Config.MaxEnumValues values are left to the generator.
+func (t *Tester) enumParameters(opDetails *parser.OperationDetails) []enumParameter {
+	var params []enumParameter
+	for _, param := range opDetails.Parameters {
+		if param == nil || (param.In != "path" && param.In != "query") || param.Schema == nil {
+			continue
+		}
+		if _, ok := t.requestBuilder.generator.LookupParameter(opDetails.Path, opDetails.Method, param); ok {
+			continue
+		}
+		schema := param.Schema.Schema()
+		if schema == nil || len(schema.Enum) == 0 || !t.fewEnumValues(schema) {
+			continue
+		}
+
+		p := enumParameter{name: param.Name}
+		for _, node := range schema.Enum {
+			if node != nil && node.Value != "" {
+				p.values = append(p.values, node.Value)
+			}
+		}
+		if len(p.values) > 0 {
+			params = append(params, p)
+		}
+	}
+
+	switch opDetails.Method {
+	case "POST", "PUT", "PATCH":
+		if schema := jsonBodySchema(opDetails.RequestBody); schema != nil {
+			params = t.bodyEnumFields(params, schema, "", maxBodyEnumDepth)
+		}
+	}
+	return params
+}
+
+// fewEnumValues reports whether a schema's enum is small enough to test each
+// value of
+func (t *Tester) fewEnumValues(schema *base.Schema) bool {
+	return t.config.MaxEnumValues <= 0 || len(schema.Enum) <= t.config.MaxEnumValues
+}
+
+// bodyEnumFields appends the enum-valued fields of an object schema and its
+// nested objects, named by dot-separated path. Read-only fields are skipped
+// since servers ignore them in requests.
+func (t *Tester) bodyEnumFields(params []enumParameter, schema *base.Schema, prefix string, depth int) []enumParameter {
+	if schema.Properties == nil || depth == 0 {
+		return params
+	}
+	for pair := schema.Properties.First(); pair != nil; pair = pair.Next() {
+		if pair.Value() == nil {
+			continue
+		}
+		field := pair.Value().Schema()
+		if field == nil || (field.ReadOnly != nil && *field.ReadOnly) {
+			continue
+		}
+		name := prefix + pair.Key()
+		if len(field.Enum) == 0 {
+			params = t.bodyEnumFields(params, field, name+".", depth-1)
+			continue
+		}
+		if !t.fewEnumValues(field) {
+			continue
+		}
+
+		p := enumParameter{name: name}
+		for _, node := range field.Enum {
+			var value interface{}
+			if node == nil || node.Decode(&value) != nil || value == nil {
+				continue
+			}
+			p.values = append(p.values, node.Value)
+			p.body = append(p.body, value)
+		}
+		if len(p.values) > 0 {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// casesFor returns the cases an operation is tested with, or nil when it is
+// tested with a single generated request. With Config.OptionalParams each
+// case is tested with and without the optional query parameters, and with
+// Config.Cases each parameter combination is repeated with that many
+// differently seeded requests.
+func (t *Tester) casesFor(op models.Operation, p *parser.Parser) []Case {
+	cases := t.enumCases(op, p)
+	if t.config.OptionalParams > 0 && t.hasOptionalQuery(op, p) {
+		if len(cases) == 0 {
+			cases = []Case{{}}
+		}
+		cases = optionalParamCases(cases)
+	}
+	if t.config.Cases <= 1 {
+		return cases
+	}
+	if len(cases) == 0 {
+		cases = []Case{{}}
+	}
+	return seededCases(cases, t.config.Cases, caseSeed(t.config.Seed, op))
+}
+
+// hasOptionalQuery reports whether an operation has optional query
+// parameters
+func (t *Tester) hasOptionalQuery(op models.Operation, p *parser.Parser) bool {
+	opDetails, err := p.GetOperationDetails(op.Path, op.Method)
+	if err != nil {
+		return false
+	}
+	for _, param := range opDetails.Parameters {
+		if param != nil && param.In == "query" && !isRequired(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalParamCases doubles each case into one sending only the required
+// query parameters and one also sending the optional ones, so both code
+// paths of the API are exercised
+func optionalParamCases(cases []Case) []Case {
+	split := make([]Case, 0, len(cases)*2)
+	for _, c := range cases {
+		for _, requiredOnly := range []bool{true, false} {
+			s := c
+			s.RequiredOnly = requiredOnly
+			s.Label = "optional params"
+			if requiredOnly {
+				s.Label = "required params"
+			}
+			if c.Label != "" {
+				s.Label = c.Label + ", " + s.Label
+			}
+			split = append(split, s)
+		}
+	}
+	return split
+}
+
+// enumCases returns the enum parameter combinations an operation is tested
+// with, or nil when enum cases are disabled or it has no enum parameters
+func (t *Tester) enumCases(op models.Operation, p *parser.Parser) []Case {
+	if !t.config.EnumCases && !t.config.Pairwise {
+		return nil
+	}
+	opDetails, err := p.GetOperationDetails(op.Path, op.Method)
+	if err != nil {
+		return nil
+	}
+
+	params := t.enumParameters(opDetails)
+	if len(params) == 0 {
+		return nil
+	}
+	if t.config.Pairwise && len(params) > 1 {
+		return pairwiseCases(params)
+	}
+	return eachValueCases(params)
+}
+
+// caseSeed derives an operation's first case seed from the run's base seed,
+// so an operation's cases are reproducible regardless of which other
+// operations the run includes
+func caseSeed(base int64, op models.Operation) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(op.Method + " " + op.Path))
+	return base ^ int64(h.Sum64())
+}
+
+// seededCases repeats each case n times with consecutive seeds starting at
+// seed. Zero is skipped since it means "keep the current seed".
+func seededCases(cases []Case, n int, seed int64) []Case {
+	seeded := make([]Case, 0, len(cases)*n)
+	for _, c := range cases {
+		for i := 0; i < n; i++ {
+			if seed == 0 {
+				seed++
+			}
+			s := c
+			s.Seed = seed
+			s.Label = fmt.Sprintf("seed %d", seed)
+			if c.Label != "" {
+				s.Label = c.Label + ", " + s.Label
+			}
+			seeded = append(seeded, s)
+			seed++
+		}
+	}
+	return seeded
+}
+
+// eachValueCases returns a case per enum value of each parameter, leaving the
+// other parameters generated
+func eachValueCases(params []enumParameter) []Case {
+	var cases []Case
+	for _, p := range params {
+		for i, value := range p.values {
+			c := Case{Label: p.label() + "=" + value}
+			p.set(&c, i)
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
+
+// pairwiseCases returns cases covering every pair of values across two
+// parameters, built greedily: each case starts from an uncovered pair and
+// fills the remaining parameters with the values covering the most
+// uncovered pairs. This needs far fewer cases than all combinations.
+func pairwiseCases(params []enumParameter) []Case {
+	type pair struct{ p1, v1, p2, v2 int }
+	uncovered := make(map[pair]bool)
+	var order []pair
+	for i := range params {
+		for j := i + 1; j < len(params); j++ {
+			for vi := range params[i].values {
+				for vj := range params[j].values {
+					pr := pair{i, vi, j, vj}
+					uncovered[pr] = true
+					order = append(order, pr)
+				}
+			}
+		}
+	}
+
+	var cases []Case
+	for _, start := range order {
+		if !uncovered[start] {
+			continue
+		}
+
+		choice := make([]int, len(params))
+		for i := range choice {
+			choice[i] = -1
+		}
+		choice[start.p1], choice[start.p2] = start.v1, start.v2
+
+		for k := range params {
+			if choice[k] >= 0 {
+				continue
+			}
+			best, bestGain := 0, -1
+			for v := range params[k].values {
+				gain := 0
+				for m, vm := range choice {
+					if vm < 0 || m == k {
+						continue
+					}
+					pr := pair{m, vm, k, v}
+					if k < m {
+						pr = pair{k, v, m, vm}
+					}
+					if uncovered[pr] {
+						gain++
+					}
+				}
+				if gain > bestGain {
+					best, bestGain = v, gain
+				}
+			}
+			choice[k] = best
+		}
+
+		var c Case
+		var labels []string
+		for i, v := range choice {
+			params[i].set(&c, v)
+			labels = append(labels, params[i].label()+"="+params[i].values[v])
+			for j := i + 1; j < len(choice); j++ {
+				delete(uncovered, pair{i, v, j, choice[j]})
+			}
+		}
+		c.Label = strings.Join(labels, ", ")
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// testOperationCases tests an operation once per case, reporting the first
+// failing case. Without cases a single generated request is tested.
+func (t *Tester) testOperationCases(op models.Operation, p *parser.Parser) models.TestResult {
+	cases := t.casesFor(op, p)
+	if len(cases) == 0 {
+		return t.testOperationRepeated(op, p, Case{})
+	}
+
+	var reported models.TestResult
+	failed := 0
+	for i, c := range cases {
+		result := t.testOperationRepeated(op, p, c)
+		switch {
+		case !result.Passed && failed == 0:
+			reported = result
+			reported.FailedCase = c.Label
+			reported.Error = fmt.Sprintf("[%s] %s", c.Label, result.Error)
+			failed++
+		case !result.Passed:
+			failed++
+		case i == 0:
+			reported = result
+		}
+	}
+
+	reported.Cases = len(cases)
+	reported.FailedCases = failed
+	reported.Passed = failed == 0
+	return reported
+}
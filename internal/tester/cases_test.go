@@ -0,0 +1,146 @@
+package tester
+
+import (
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestEachValueCases(t *testing.T) {
+	cases := eachValueCases([]enumParameter{
+		{name: "sort", values: []string{"name", "date"}},
+		{name: "order", values: []string{"asc", "desc"}},
+	})
+
+	if len(cases) != 4 {
+		t.Fatalf("Expected 4 cases, got %d", len(cases))
+	}
+	if cases[0].Label != "sort=name" || cases[0].Params["sort"] != "name" || len(cases[0].Params) != 1 {
+		t.Errorf("Unexpected first case %+v", cases[0])
+	}
+	if cases[3].Label != "order=desc" {
+		t.Errorf("Unexpected last case %+v", cases[3])
+	}
+}
+
+func TestPairwiseCases(t *testing.T) {
+	params := []enumParameter{
+		{name: "a", values: []string{"1", "2", "3"}},
+		{name: "b", values: []string{"x", "y", "z"}},
+		{name: "c", values: []string{"p", "q"}},
+		{name: "d", values: []string{"on", "off"}},
+	}
+	cases := pairwiseCases(params)
+
+	// Every pair of values across two parameters must appear in some case
+	for i := range params {
+		for j := i + 1; j < len(params); j++ {
+			for _, vi := range params[i].values {
+				for _, vj := range params[j].values {
+					covered := false
+					for _, c := range cases {
+						if c.Params[params[i].name] == vi && c.Params[params[j].name] == vj {
+							covered = true
+							break
+						}
+					}
+					if !covered {
+						t.Errorf("Pair %s=%s, %s=%s not covered", params[i].name, vi, params[j].name, vj)
+					}
+				}
+			}
+		}
+	}
+
+	// Fewer cases than the 36 full combinations
+	if len(cases) >= 36 || len(cases) < 9 {
+		t.Errorf("Expected between 9 and 35 cases, got %d", len(cases))
+	}
+	for _, c := range cases {
+		if len(c.Params) != len(params) {
+			t.Errorf("Expected every parameter set in %+v", c)
+		}
+	}
+}
+
+func TestSeededCases(t *testing.T) {
+	cases := seededCases([]Case{
+		{Label: "sort=name", Params: map[string]string{"sort": "name"}},
+		{Label: "sort=date", Params: map[string]string{"sort": "date"}},
+	}, 3, -1)
+
+	if len(cases) != 6 {
+		t.Fatalf("Expected 6 cases, got %d", len(cases))
+	}
+	seen := make(map[int64]bool)
+	for _, c := range cases {
+		if c.Seed == 0 {
+			t.Errorf("Case %q has the reserved zero seed", c.Label)
+		}
+		if seen[c.Seed] {
+			t.Errorf("Seed %d used twice", c.Seed)
+		}
+		seen[c.Seed] = true
+	}
+	if cases[0].Label != "sort=name, seed -1" || cases[0].Params["sort"] != "name" {
+		t.Errorf("Unexpected first case %+v", cases[0])
+	}
+	if cases[5].Label != "sort=date, seed 5" || cases[5].Params["sort"] != "date" {
+		t.Errorf("Unexpected last case %+v", cases[5])
+	}
+
+	plain := seededCases([]Case{{}}, 2, 7)
+	if plain[0].Label != "seed 7" || plain[1].Label != "seed 8" {
+		t.Errorf("Unexpected labels %q, %q", plain[0].Label, plain[1].Label)
+	}
+}
+
+func TestOptionalParamCases(t *testing.T) {
+	cases := optionalParamCases([]Case{
+		{Label: "sort=name", Params: map[string]string{"sort": "name"}},
+		{Label: "sort=date", Params: map[string]string{"sort": "date"}},
+	})
+
+	if len(cases) != 4 {
+		t.Fatalf("Expected 4 cases, got %d", len(cases))
+	}
+	if cases[0].Label != "sort=name, required params" || !cases[0].RequiredOnly || cases[0].Params["sort"] != "name" {
+		t.Errorf("Unexpected first case %+v", cases[0])
+	}
+	if cases[1].Label != "sort=name, optional params" || cases[1].RequiredOnly {
+		t.Errorf("Unexpected second case %+v", cases[1])
+	}
+
+	plain := optionalParamCases([]Case{{}})
+	if plain[0].Label != "required params" || plain[1].Label != "optional params" {
+		t.Errorf("Unexpected labels %q, %q", plain[0].Label, plain[1].Label)
+	}
+}
+
+func TestBodyEnumCases(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/readings", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	tester := NewTesterWithConfig(Config{EnumCases: true, MaxEnumValues: DefaultMaxEnumValues})
+	cases := eachValueCases(tester.enumParameters(opDetails))
+	if len(cases) != 5 {
+		t.Fatalf("Expected 5 cases, got %+v", cases)
+	}
+	if cases[0].Label != "body.quality=raw" || cases[0].Body["quality"] != "raw" || cases[0].Params != nil {
+		t.Errorf("Unexpected first case %+v", cases[0])
+	}
+	if cases[4].Label != "body.calibration.level=3" || cases[4].Body["calibration.level"] != 3 {
+		t.Errorf("Expected a typed nested field value, got %+v", cases[4])
+	}
+
+	tester = NewTesterWithConfig(Config{EnumCases: true, MaxEnumValues: 2})
+	if params := tester.enumParameters(opDetails); len(params) != 1 || params[0].name != "quality" {
+		t.Errorf("Expected only the enum within --enum-cases-max, got %+v", params)
+	}
+}
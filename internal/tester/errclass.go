@@ -0,0 +1,56 @@
+package tester
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// ClassifyError buckets the error of an HTTP client request by its cause.
+// Errors that are none of connection, DNS, TLS or timeout are ErrorOther.
+func ClassifyError(err error) models.ErrorClass {
+	var (
+		dnsErr     *net.DNSError
+		netErr     net.Error
+		recordErr  tls.RecordHeaderError
+		certErr    *tls.CertificateVerificationError
+		alertErr   tls.AlertError
+		authErr    x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+		opErr      *net.OpError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &dnsErr):
+		return models.ErrorDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return models.ErrorTimeout
+	case errors.As(err, &recordErr), errors.As(err, &certErr), errors.As(err, &alertErr),
+		errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &invalidErr),
+		strings.Contains(err.Error(), "tls: "):
+		// Most handshake failures are plain errors prefixed "tls: "
+		return models.ErrorTLS
+	case errors.As(err, &opErr), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, net.ErrClosed):
+		return models.ErrorConnection
+	}
+	return models.ErrorOther
+}
+
+// failureClass returns the class of a test failing on its response: the
+// status class for an error status, validation otherwise
+func failureClass(status int) models.ErrorClass {
+	if class := models.StatusErrorClass(status); class != "" {
+		return class
+	}
+	return models.ErrorValidation
+}
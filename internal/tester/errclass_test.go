@@ -0,0 +1,56 @@
+package tester
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestClassifyError(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer secure.Close()
+
+	// A listener closed before the request leaves a port nothing accepts on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refused := "http://" + listener.Addr().String()
+	listener.Close()
+
+	request := func(client *http.Client, target string) error {
+		resp, err := client.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want models.ErrorClass
+	}{
+		{"refused", request(http.DefaultClient, refused), models.ErrorConnection},
+		{"timeout", request(&http.Client{Timeout: 50 * time.Millisecond}, slow.URL), models.ErrorTimeout},
+		{"untrusted certificate", request(http.DefaultClient, secure.URL), models.ErrorTLS},
+		{"dns", &url.Error{Op: "Get", URL: "http://api.invalid", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "api.invalid", IsNotFound: true}}}, models.ErrorDNS},
+		{"other", fmt.Errorf("request failed: %w", errors.New("unsupported protocol scheme")), models.ErrorOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("%s: expected %q for %v, got %q", tt.name, tt.want, tt.err, got)
+		}
+	}
+}
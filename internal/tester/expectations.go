@@ -0,0 +1,114 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// MethodExpectation is what a method's responses must look like when the spec
+// doesn't document the status code returned, following HTTP conventions
+// rather than failing every undocumented status
+type MethodExpectation struct {
+	Statuses  []string // Accepted undocumented statuses ("201" or a range like "2XX")
+	EmptyBody bool     // The response must not have a body, whatever the spec says
+}
+
+// DefaultMethodExpectations returns the conventional expectations: POST
+// creates (2XX), DELETE accepts an already missing resource (404) and HEAD
+// responds without a body
+func DefaultMethodExpectations() map[string]MethodExpectation {
+	return map[string]MethodExpectation{
+		http.MethodPost:   {Statuses: []string{"2XX"}},
+		http.MethodDelete: {Statuses: []string{"2XX", "404"}},
+		http.MethodHead:   {Statuses: []string{"2XX"}, EmptyBody: true},
+	}
+}
+
+// ValidateExpectations rejects malformed statuses
+func ValidateExpectations(expectations map[string]MethodExpectation) error {
+	for method, exp := range expectations {
+		for _, status := range exp.Statuses {
+			if !validStatusPattern(status) {
+				return fmt.Errorf("invalid expected status '%s' for %s: must be a status code or a range like 2XX", status, strings.ToUpper(method))
+			}
+		}
+	}
+	return nil
+}
+
+// validStatusPattern reports whether s is a status code or a status range
+func validStatusPattern(s string) bool {
+	if len(s) != 3 || s[0] < '1' || s[0] > '5' {
+		return false
+	}
+	if strings.EqualFold(s[1:], "XX") {
+		return true
+	}
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// matchesStatus reports whether status matches any of the status codes or
+// ranges in patterns
+func matchesStatus(patterns []string, status int) bool {
+	code := strconv.Itoa(status)
+	for _, p := range patterns {
+		if p == code || strings.EqualFold(p, code[:1]+"XX") {
+			return true
+		}
+	}
+	return false
+}
+
+// expectationFor returns the expectation configured for a method. Methods
+// match case-insensitively, since configuration keys are lowercased when loaded.
+func (v *Validator) expectationFor(method string) (MethodExpectation, bool) {
+	for m, exp := range v.expectations {
+		if strings.EqualFold(m, method) {
+			return exp, true
+		}
+	}
+	return MethodExpectation{}, false
+}
+
+// checkUndocumentedStatus applies a method's expectation to a status code the
+// spec doesn't document. ok is false if no statuses are configured for the
+// method, leaving the decision to the default rules.
+func (v *Validator) checkUndocumentedStatus(method string, statusCode int) (errors []models.ValidationError, ok bool) {
+	exp, found := v.expectationFor(method)
+	if !found || len(exp.Statuses) == 0 {
+		return nil, false
+	}
+	if matchesStatus(exp.Statuses, statusCode) {
+		return nil, true
+	}
+	return []models.ValidationError{{
+		Field:   "status_code",
+		Message: fmt.Sprintf("unexpected status code %d for %s, expected %s", statusCode, method, strings.Join(exp.Statuses, " or ")),
+	}}, true
+}
+
+// checkEmptyBody fails a response with a body if the method's expectation
+// forbids one. The body is restored for later validation.
+func (v *Validator) checkEmptyBody(resp *http.Response, method string) []models.ValidationError {
+	exp, found := v.expectationFor(method)
+	if !found || !exp.EmptyBody || resp.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return nil
+	}
+	return []models.ValidationError{{
+		Field:   "body",
+		Message: fmt.Sprintf("%s response must not have a body, got %d bytes", method, len(body)),
+	}}
+}
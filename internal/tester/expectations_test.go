@@ -0,0 +1,85 @@
+package tester
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+func TestMethodExpectationsUndocumentedStatus(t *testing.T) {
+	v := NewValidator()
+	v.SetExpectations(DefaultMethodExpectations())
+
+	tests := []struct {
+		method string
+		status int
+		valid  bool
+	}{
+		{"DELETE", http.StatusNoContent, true},
+		{"DELETE", http.StatusNotFound, true},
+		{"DELETE", http.StatusConflict, false},
+		{"POST", http.StatusCreated, true},
+		{"POST", http.StatusFound, false},
+		{"GET", http.StatusNotFound, false}, // no expectation, HTTP semantics
+		{"GET", http.StatusFound, true},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+		errors, err := v.ValidateResponse(resp, &parser.OperationDetails{Method: tt.method})
+		if err != nil {
+			t.Fatalf("Validation error: %v", err)
+		}
+		if valid := len(errors) == 0; valid != tt.valid {
+			t.Errorf("%s %d: expected valid=%v, got errors %v", tt.method, tt.status, tt.valid, errors)
+		}
+	}
+}
+
+func TestMethodExpectationsHeadBody(t *testing.T) {
+	v := NewValidator()
+	v.SetExpectations(DefaultMethodExpectations())
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("hello"))}
+	errors, _ := v.ValidateResponse(resp, &parser.OperationDetails{Method: "HEAD"})
+	if len(errors) != 1 || errors[0].Field != "body" {
+		t.Fatalf("Expected a body error for HEAD, got %v", errors)
+	}
+
+	// The body is left for later checks
+	if body, _ := io.ReadAll(resp.Body); string(body) != "hello" {
+		t.Errorf("Expected the body to be restored, got %q", body)
+	}
+}
+
+func TestMethodExpectationsDocumentedStatus(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/pets", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	// The spec's default response documents every status, so the expectation
+	// for POST isn't consulted
+	v := NewValidatorWithStrictness(StrictnessLenient)
+	v.SetExpectations(map[string]MethodExpectation{"post": {Statuses: []string{"201"}}})
+	resp := &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+	errors, _ := v.ValidateResponse(resp, opDetails)
+	if len(errors) != 0 {
+		t.Errorf("Expected the documented default response to apply, got %v", errors)
+	}
+}
+
+func TestValidateExpectations(t *testing.T) {
+	if err := ValidateExpectations(DefaultMethodExpectations()); err != nil {
+		t.Errorf("Expected defaults to be valid, got %v", err)
+	}
+	if err := ValidateExpectations(map[string]MethodExpectation{"delete": {Statuses: []string{"2X4"}}}); err == nil {
+		t.Error("Expected malformed status to be rejected")
+	}
+}
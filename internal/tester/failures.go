@@ -0,0 +1,89 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/mask"
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SafeFileName turns name into a file name of letters, digits, '.', '_' and
+// '-'. A name that had to be changed gets a short hash of the original, so
+// that names differing only in replaced characters, such as /a/b and /a_b,
+// don't collide.
+func SafeFileName(name string) string {
+	safe := strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "._")
+	if safe == name {
+		return safe
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%s-%08x", safe, h.Sum32())
+}
+
+// FailureFileName returns the file a failing operation is saved to: its
+// operation id, or method and path when it has none
+func FailureFileName(op models.Operation) string {
+	name := op.OperationID
+	if name == "" {
+		name = op.Method + op.Path
+	}
+	return SafeFileName(name) + ".txt"
+}
+
+// caseFileName returns the name, without extension, of the files a case of
+// an operation is saved to: that of the operation, followed by the case
+// label for cases other than the default one, e.g. listPets.status_sold
+func caseFileName(op models.Operation, c Case) string {
+	name := strings.TrimSuffix(FailureFileName(op), ".txt")
+	if c.Label != "" {
+		name += "." + strings.Trim(unsafeFileChars.ReplaceAllString(c.Label, "_"), "_")
+	}
+	return name
+}
+
+// saveFailure writes the request and response of a failing test case to the
+// configured directory, with registered secrets masked. resp is nil when no
+// response was received. Write errors are ignored; saving is a debugging aid
+// and must not change the test outcome.
+func (t *Tester) saveFailure(op models.Operation, c Case, req *http.Request, resp *http.Response, respBody []byte, failure string) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s %s\n", req.Method, req.URL)
+	req.Header.Write(&buf)
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			if len(data) > 0 {
+				fmt.Fprintf(&buf, "\n%s\n", data)
+			}
+		}
+	}
+
+	buf.WriteString("\n--- Response ---\n")
+	if resp != nil {
+		fmt.Fprintf(&buf, "%s %s\n", resp.Proto, resp.Status)
+		resp.Header.Write(&buf)
+		if len(respBody) > 0 {
+			fmt.Fprintf(&buf, "\n%s\n", respBody)
+		}
+	} else {
+		buf.WriteString("(no response)\n")
+	}
+
+	fmt.Fprintf(&buf, "\n--- Failure ---\n%s\n", failure)
+
+	path := filepath.Join(t.config.SaveFailuresDir, caseFileName(op, c)+".txt")
+	os.WriteFile(path, mask.Bytes(buf.Bytes()), 0o644)
+}
@@ -0,0 +1,337 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// introspectionQuery fetches the query type of a GraphQL schema with enough
+// of every type to generate queries against it
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    types {
+      kind
+      name
+      fields { name args { name type { ...TypeRef } } type { ...TypeRef } }
+      enumValues { name }
+    }
+  }
+}
+fragment TypeRef on __Type { kind name ofType { kind name ofType { kind name ofType { kind name } } } }`
+
+// graphQLTypeRef is a possibly wrapped (NON_NULL, LIST) reference to a type
+type graphQLTypeRef struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	OfType *graphQLTypeRef `json:"ofType"`
+}
+
+// named unwraps NON_NULL and LIST wrappers
+func (r graphQLTypeRef) named() graphQLTypeRef {
+	for r.OfType != nil && (r.Kind == "NON_NULL" || r.Kind == "LIST") {
+		r = *r.OfType
+	}
+	return r
+}
+
+type graphQLField struct {
+	Name string `json:"name"`
+	Args []struct {
+		Name string         `json:"name"`
+		Type graphQLTypeRef `json:"type"`
+	} `json:"args"`
+	Type graphQLTypeRef `json:"type"`
+}
+
+type graphQLType struct {
+	Kind       string         `json:"kind"`
+	Name       string         `json:"name"`
+	Fields     []graphQLField `json:"fields"`
+	EnumValues []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+type graphQLSchema struct {
+	QueryType struct {
+		Name string `json:"name"`
+	} `json:"queryType"`
+	Types []graphQLType `json:"types"`
+}
+
+// graphQLResponse is the envelope of every GraphQL response
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphQLQuery is a generated query selecting a single field of the query type
+type graphQLQuery struct {
+	field    string
+	nonNull  bool
+	document string
+}
+
+// isGraphQL reports whether an operation is a GraphQL endpoint queried with
+// POST requests
+func isGraphQL(op models.Operation) bool {
+	return op.Method == http.MethodPost && strings.HasSuffix(strings.TrimRight(op.Path, "/"), "/graphql")
+}
+
+// testGraphQL introspects the schema behind a GraphQL operation and sends one
+// query per field of the query type, checking that each returns data without
+// errors. Every query is a case of the result. Mutations are never sent.
+func (t *Tester) testGraphQL(op models.Operation, p *parser.Parser) models.TestResult {
+	result := models.TestResult{Path: op.Path, Method: op.Method, OperationID: op.OperationID}
+
+	opDetails, err := p.GetOperationDetails(op.Path, op.Method)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to get operation details: %v", err)
+		return result
+	}
+
+	var introspection struct {
+		Data struct {
+			Schema *graphQLSchema `json:"__schema"`
+		} `json:"data"`
+	}
+	resp, _, err := t.sendGraphQL(opDetails, op.ServerURL, introspectionQuery)
+	if err == nil {
+		result.StatusCode = resp.status
+		err = resp.check("__schema", true)
+	}
+	if err == nil {
+		err = json.Unmarshal(resp.body, &introspection)
+	}
+	if err == nil && introspection.Data.Schema == nil {
+		err = fmt.Errorf("no schema returned")
+	}
+	if err != nil {
+		result.Error = fmt.Sprintf("introspection failed: %v", err)
+		result.ErrorClass = graphQLFailureClass(result.StatusCode, err)
+		return maskResult(result)
+	}
+
+	queries := graphQLQueries(introspection.Data.Schema)
+	if len(queries) == 0 {
+		result.Error = "the schema has no query fields that can be queried without input objects"
+		return result
+	}
+
+	var total time.Duration
+	for _, q := range queries {
+		resp, elapsed, err := t.sendGraphQL(opDetails, op.ServerURL, q.document)
+		total += elapsed
+		if err == nil {
+			result.StatusCode = resp.status
+			err = resp.check(q.field, q.nonNull)
+		}
+		if err != nil {
+			result.FailedCases++
+			if result.FailedCase == "" {
+				result.FailedCase = q.field
+				result.ErrorClass = graphQLFailureClass(resp.status, err)
+			}
+			result.ValidationErrors = append(result.ValidationErrors, models.ValidationError{
+				Field:   "graphql." + q.field,
+				Message: err.Error(),
+			})
+		}
+	}
+	result.Cases = len(queries)
+	result.ResponseTime = total / time.Duration(len(queries))
+
+	if result.FailedCases == 0 {
+		result.Passed = true
+	} else {
+		var msgs []string
+		for _, ve := range result.ValidationErrors {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", ve.Field, ve.Message))
+		}
+		result.Error = fmt.Sprintf("validation failed: %s", strings.Join(msgs, "; "))
+	}
+	return maskResult(result)
+}
+
+// graphQLFailureClass classifies a failed GraphQL request; status is zero
+// when no response was received
+func graphQLFailureClass(status int, err error) models.ErrorClass {
+	if status == 0 {
+		return ClassifyError(err)
+	}
+	return failureClass(status)
+}
+
+// graphQLReply is a buffered GraphQL response
+type graphQLReply struct {
+	status int
+	body   []byte
+}
+
+// check verifies that a reply is a successful GraphQL response carrying the
+// queried field
+func (r graphQLReply) check(field string, nonNull bool) error {
+	if r.status != http.StatusOK {
+		return fmt.Errorf("expected status 200, got %d", r.status)
+	}
+	var envelope graphQLResponse
+	if err := json.Unmarshal(r.body, &envelope); err != nil {
+		return fmt.Errorf("failed to parse JSON response: %v", err)
+	}
+	if len(envelope.Errors) > 0 {
+		return fmt.Errorf("query returned errors: %s", envelope.Errors[0].Message)
+	}
+	value, ok := envelope.Data[field]
+	if !ok {
+		return fmt.Errorf("response data has no %s field", field)
+	}
+	if nonNull && string(value) == "null" {
+		return fmt.Errorf("non-null field %s is null", field)
+	}
+	return nil
+}
+
+// sendGraphQL posts a query to a GraphQL operation, with the headers and
+// credentials of any other request
+func (t *Tester) sendGraphQL(opDetails *parser.OperationDetails, serverURL, query string) (graphQLReply, time.Duration, error) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return graphQLReply{}, 0, err
+	}
+	req, err := t.requestBuilder.BuildRequestWithOverrides(opDetails, serverURL, RequestOverrides{RawBody: body})
+	if err != nil {
+		return graphQLReply{}, 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := t.client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		return graphQLReply{}, elapsed, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return graphQLReply{}, elapsed, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return graphQLReply{status: resp.StatusCode, body: data}, elapsed, nil
+}
+
+// graphQLQueries generates a query for each field of the query type. Required
+// arguments get a placeholder value; fields requiring input objects are
+// skipped. Object results select their scalar fields.
+func graphQLQueries(schema *graphQLSchema) []graphQLQuery {
+	types := make(map[string]graphQLType, len(schema.Types))
+	for _, typ := range schema.Types {
+		types[typ.Name] = typ
+	}
+	queryType, ok := types[schema.QueryType.Name]
+	if !ok {
+		return nil
+	}
+
+	var queries []graphQLQuery
+	for _, field := range queryType.Fields {
+		if strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+		args, ok := graphQLArguments(field, types)
+		if !ok {
+			continue
+		}
+		queries = append(queries, graphQLQuery{
+			field:    field.Name,
+			nonNull:  field.Type.Kind == "NON_NULL",
+			document: fmt.Sprintf("query { %s%s%s }", field.Name, args, graphQLSelection(field.Type, types)),
+		})
+	}
+	return queries
+}
+
+// graphQLArguments renders placeholder values for the required arguments of
+// a field. It reports false when an argument can't be generated.
+func graphQLArguments(field graphQLField, types map[string]graphQLType) (string, bool) {
+	var args []string
+	for _, arg := range field.Args {
+		if arg.Type.Kind != "NON_NULL" {
+			continue
+		}
+		value, ok := graphQLPlaceholder(arg.Type.named(), types)
+		if !ok {
+			return "", false
+		}
+		if arg.Type.OfType != nil && arg.Type.OfType.Kind == "LIST" {
+			value = "[" + value + "]"
+		}
+		args = append(args, arg.Name+": "+value)
+	}
+	if len(args) == 0 {
+		return "", true
+	}
+	return "(" + strings.Join(args, ", ") + ")", true
+}
+
+// graphQLPlaceholder returns a literal of a scalar or enum type
+func graphQLPlaceholder(ref graphQLTypeRef, types map[string]graphQLType) (string, bool) {
+	switch ref.Kind {
+	case "ENUM":
+		if values := types[ref.Name].EnumValues; len(values) > 0 {
+			return values[0].Name, true
+		}
+		return "", false
+	case "SCALAR":
+		switch ref.Name {
+		case "Int":
+			return "1", true
+		case "Float":
+			return "1.0", true
+		case "Boolean":
+			return "true", true
+		default: // String, ID and custom scalars
+			return `"1"`, true
+		}
+	default:
+		return "", false
+	}
+}
+
+// graphQLSelection selects the scalar and enum fields without required
+// arguments of an object or interface type, or __typename when it has none
+func graphQLSelection(ref graphQLTypeRef, types map[string]graphQLType) string {
+	named := ref.named()
+	switch named.Kind {
+	case "OBJECT", "INTERFACE":
+	case "UNION":
+		return " { __typename }"
+	default:
+		return ""
+	}
+
+	var fields []string
+	for _, field := range types[named.Name].Fields {
+		kind := field.Type.named().Kind
+		if kind != "SCALAR" && kind != "ENUM" {
+			continue
+		}
+		if args, ok := graphQLArguments(field, types); args != "" || !ok {
+			continue
+		}
+		fields = append(fields, field.Name)
+	}
+	if len(fields) == 0 {
+		fields = []string{"__typename"}
+	}
+	return " { " + strings.Join(fields, " ") + " }"
+}
@@ -0,0 +1,110 @@
+package tester
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// testGraphQLSchema is the introspection result of:
+//
+//	type Query { users: [User!]!  user(id: ID!): User  search(filter: Filter!): [User]  version: String! }
+//	type User { id: ID!  name: String  role: Role  friends(first: Int!): [User] }
+//	enum Role { ADMIN MEMBER }
+const testGraphQLSchema = `{
+  "queryType": {"name": "Query"},
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "users", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "User"}}}}},
+      {"name": "user", "args": [{"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}], "type": {"kind": "OBJECT", "name": "User"}},
+      {"name": "search", "args": [{"name": "filter", "type": {"kind": "NON_NULL", "ofType": {"kind": "INPUT_OBJECT", "name": "Filter"}}}], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}},
+      {"name": "version", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+      {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "role", "args": [], "type": {"kind": "ENUM", "name": "Role"}},
+      {"name": "friends", "args": [{"name": "first", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "Int"}}}], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "User"}}}
+    ]},
+    {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "MEMBER"}]},
+    {"kind": "INPUT_OBJECT", "name": "Filter"}
+  ]
+}`
+
+func TestGraphQLQueries(t *testing.T) {
+	var schema graphQLSchema
+	if err := json.Unmarshal([]byte(testGraphQLSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := graphQLQueries(&schema)
+	want := []graphQLQuery{
+		{field: "users", nonNull: true, document: "query { users { id name role } }"},
+		{field: "user", document: `query { user(id: "1") { id name role } }`},
+		{field: "version", nonNull: true, document: "query { version }"},
+	}
+	if len(queries) != len(want) {
+		t.Fatalf("Expected %d queries, got %+v", len(want), queries)
+	}
+	for i := range want {
+		if queries[i] != want[i] {
+			t.Errorf("Query %d: expected %+v, got %+v", i, want[i], queries[i])
+		}
+	}
+}
+
+func TestIntegrationGraphQL(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/graphql-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		userReply  string
+		wantPassed bool
+	}{
+		{name: "all queries answer", userReply: `{"data": {"user": null}}`, wantPassed: true},
+		{name: "query errors", userReply: `{"data": {"user": null}, "errors": [{"message": "boom"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Query string `json:"query"`
+				}
+				json.NewDecoder(r.Body).Decode(&req)
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case strings.Contains(req.Query, "__schema"):
+					w.Write([]byte(`{"data": {"__schema": ` + testGraphQLSchema + `}}`))
+				case strings.Contains(req.Query, "users"):
+					w.Write([]byte(`{"data": {"users": [{"id": "1", "name": "Ada", "role": "ADMIN"}]}}`))
+				case strings.Contains(req.Query, "user("):
+					w.Write([]byte(tt.userReply))
+				default:
+					w.Write([]byte(`{"data": {"version": "1.0"}}`))
+				}
+			}))
+			defer server.Close()
+
+			op := models.Operation{Path: "/graphql", Method: "POST", ServerURL: server.URL}
+			testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, GraphQL: true})
+			result := testRunner.TestOperations([]models.Operation{op}, p, nil).Results[0]
+
+			if result.Passed != tt.wantPassed || result.Cases != 3 {
+				t.Fatalf("Expected passed=%v with 3 cases, got %+v", tt.wantPassed, result)
+			}
+			if !tt.wantPassed && (result.FailedCase != "user" || result.ValidationErrors[0].Field != "graphql.user") {
+				t.Errorf("Expected the user query to fail, got %+v", result)
+			}
+		})
+	}
+}
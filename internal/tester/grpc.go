@@ -0,0 +1,110 @@
+package tester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/grpcgw"
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/normalize"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// compareGRPC calls the gRPC method a grpc-gateway operation is transcoded to
+// with the parameters of the HTTP request just sent, and checks that the
+// gateway returned the status and message the method did. Only GET
+// operations are compared, since calling a method again repeats its side
+// effects, and operations without a matching method are left alone.
+func (t *Tester) compareGRPC(op models.Operation, req *http.Request, statusCode int, body []byte, result *models.TestResult) []models.ValidationError {
+	if op.Method != http.MethodGet || op.OperationID == "" {
+		return nil
+	}
+	method, err := t.config.GRPC.Method(op.OperationID)
+	if err != nil {
+		return nil
+	}
+
+	params := pathParams(op.Path, req.URL.Path)
+	for name, values := range req.URL.Query() {
+		params[name] = append(params[name], values...)
+	}
+
+	ctx, cancel := context.WithTimeout(t.config.Context, t.config.Timeout)
+	defer cancel()
+	resp, err := t.config.GRPC.Invoke(ctx, method, params)
+	if err != nil {
+		return []models.ValidationError{{Field: "grpc", Message: err.Error()}}
+	}
+	result.GRPCStatus = resp.Code.String()
+	result.GRPCResponseTime = resp.ResponseTime
+
+	if want := grpcgw.HTTPStatus(resp.Code); want != statusCode {
+		return []models.ValidationError{{
+			Field:   "grpc.status",
+			Message: fmt.Sprintf("HTTP returned %d, but %s returned %s (%d through the gateway)", statusCode, method.FullName(), resp.Code, want),
+		}}
+	}
+	if resp.Message == nil {
+		return nil
+	}
+
+	decoded, err := grpcgw.DecodeResponse(method, body)
+	if err != nil {
+		return []models.ValidationError{{
+			Field:   "grpc.body",
+			Message: fmt.Sprintf("HTTP response is not a valid %s: %v", method.Output().FullName(), err),
+		}}
+	}
+	if proto.Equal(decoded, resp.Message) {
+		return nil
+	}
+
+	// Name the differing fields of both messages' JSON, which clients see,
+	// after normalizing it
+	diffs := normalize.Diff("body", t.normalizedJSON(resp.Message), t.normalizedJSON(decoded))
+	errors := make([]models.ValidationError, 0, len(diffs))
+	for _, diff := range diffs {
+		errors = append(errors, models.ValidationError{
+			Field:   "grpc.body",
+			Message: fmt.Sprintf("HTTP response differs from the %s response: %s", method.FullName(), diff),
+		})
+	}
+	return errors
+}
+
+// normalizedJSON converts a message to its generic JSON form and applies the
+// configured normalization rules
+func (t *Tester) normalizedJSON(m proto.Message) interface{} {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+	return t.config.Normalizer.Apply(v)
+}
+
+// pathParams extracts the values of a path template's parameters from a
+// request path. The template is matched against the end of the path, since
+// the server URL may add a prefix.
+func pathParams(template, path string) map[string][]string {
+	params := make(map[string][]string)
+	names := strings.Split(strings.Trim(template, "/"), "/")
+	values := strings.Split(strings.Trim(path, "/"), "/")
+	if len(values) < len(names) {
+		return params
+	}
+	values = values[len(values)-len(names):]
+	for i, name := range names {
+		if strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") {
+			params[strings.Trim(name, "{}")] = []string{values[i]}
+		}
+	}
+	return params
+}
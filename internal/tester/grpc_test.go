@@ -0,0 +1,26 @@
+package tester
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathParams(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		want     map[string][]string
+	}{
+		{"/v1/messages/{message_id}", "/v1/messages/m1", map[string][]string{"message_id": {"m1"}}},
+		{"/v1/messages/{message_id}", "/api/v1/messages/m1", map[string][]string{"message_id": {"m1"}}},
+		{"/v1/{parent}/books/{book}", "/v1/shelf-1/books/7", map[string][]string{"parent": {"shelf-1"}, "book": {"7"}}},
+		{"/v1/messages", "/v1/messages", map[string][]string{}},
+		{"/v1/messages/{message_id}", "/v1", map[string][]string{}},
+	}
+
+	for _, tt := range tests {
+		if got := pathParams(tt.template, tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pathParams(%s, %s) = %v, want %v", tt.template, tt.path, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,23 @@
 package tester
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/moamenhredeen/oas/internal/auth"
 	"github.com/moamenhredeen/oas/internal/models"
 	"github.com/moamenhredeen/oas/internal/parser"
 )
@@ -223,6 +234,9 @@ func TestIntegrationValidationErrors(t *testing.T) {
 	if len(result.ValidationErrors) == 0 {
 		t.Error("Expected validation errors")
 	}
+	if result.ErrorClass != models.ErrorServer {
+		t.Errorf("Expected error class 5xx, got %q", result.ErrorClass)
+	}
 }
 
 func TestIntegrationWithPaginationAPI(t *testing.T) {
@@ -273,3 +287,906 @@ func TestIntegrationWithPaginationAPI(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegrationMaxFailures(t *testing.T) {
+	// Close the server up front so every request fails to connect
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	operations, err := p.GetOperations(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+
+	if len(operations) < 2 {
+		t.Fatalf("Expected at least two operations, got %d", len(operations))
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, MaxFailures: 1})
+	summary := testRunner.TestOperations(operations, p, nil)
+
+	if summary.TotalTests != 1 {
+		t.Errorf("Expected 1 test to run, got %d", summary.TotalTests)
+	}
+
+	if !summary.Stopped {
+		t.Error("Expected run to be marked as stopped")
+	}
+
+	if summary.Skipped != len(operations)-1 {
+		t.Errorf("Expected %d skipped operations, got %d", len(operations)-1, summary.Skipped)
+	}
+}
+
+func TestIntegrationDeadline(t *testing.T) {
+	// The server hangs until the test ends, far past the deadline
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	operations, err := p.GetOperations(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, Context: ctx})
+	summary := testRunner.TestOperations(operations, p, nil)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the run to stop at the deadline, took %v", elapsed)
+	}
+	if !summary.DeadlineExceeded || !summary.Stopped {
+		t.Errorf("Expected run to be marked as stopped at the deadline, got %+v", summary)
+	}
+	if summary.TotalTests != 1 {
+		t.Errorf("Expected 1 test to run, got %d", summary.TotalTests)
+	}
+	if summary.Skipped != len(operations)-1 {
+		t.Errorf("Expected %d skipped operations, got %d", len(operations)-1, summary.Skipped)
+	}
+	for _, skipped := range summary.SkippedOperations {
+		if skipped.Reason != models.SkipDeadline {
+			t.Errorf("Expected skip reason %s, got %s", models.SkipDeadline, skipped.Reason)
+		}
+	}
+}
+
+func TestIntegrationRetryOnStatus(t *testing.T) {
+	// Every method is unavailable for the first two attempts
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.Method]++
+		if calls[r.Method] <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-next", "/pets?limit=10")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "Fluffy"}})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	operations := []models.Operation{
+		{Path: "/pets", Method: "GET", ServerURL: server.URL},
+		{Path: "/pets", Method: "POST", ServerURL: server.URL},
+	}
+
+	testRunner := NewTesterWithConfig(Config{
+		Timeout: 30 * time.Second,
+		Retry: RetryPolicy{
+			Attempts: 3,
+			On:       map[string][]string{"get": {"503"}},
+			Backoff:  time.Millisecond,
+		},
+	})
+	summary := testRunner.TestOperations(operations, p, nil)
+
+	get := summary.Results[0]
+	if !get.Passed || get.Retries != 2 {
+		t.Errorf("Expected GET to pass after 2 retries, got passed=%v retries=%d: %s", get.Passed, get.Retries, get.Error)
+	}
+	if get.RetryTime <= 0 {
+		t.Errorf("Expected the retry time to be recorded, got %v", get.RetryTime)
+	}
+
+	post := summary.Results[1]
+	if post.Retries != 0 || post.StatusCode != http.StatusServiceUnavailable || calls["POST"] != 1 {
+		t.Errorf("Expected POST not to be retried, got %d retries after %d calls", post.Retries, calls["POST"])
+	}
+}
+
+func TestIntegrationRepeatDetectsFlakiness(t *testing.T) {
+	// Alternate between a valid response and one with the wrong content type
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls%2 == 0 {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("oops"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-next", "/pets?limit=10")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "Fluffy"}})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	op := models.Operation{
+		Path:      "/pets",
+		Method:    "GET",
+		ServerURL: server.URL,
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, Repeat: 4})
+	summary := testRunner.TestOperations([]models.Operation{op}, p, nil)
+
+	if len(summary.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(summary.Results))
+	}
+
+	result := summary.Results[0]
+	if !result.Flaky {
+		t.Error("Expected operation to be marked flaky")
+	}
+
+	if result.Runs != 4 || result.PassCount != 2 {
+		t.Errorf("Expected 2/4 passing runs, got %d/%d", result.PassCount, result.Runs)
+	}
+
+	if result.Flakiness != 50 {
+		t.Errorf("Expected 50%% flakiness, got %.2f", result.Flakiness)
+	}
+
+	if summary.Flaky != 1 {
+		t.Errorf("Expected 1 flaky operation in summary, got %d", summary.Flaky)
+	}
+}
+
+func TestIntegrationPrefetchIDs(t *testing.T) {
+	var itemPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/pets" {
+			w.Header().Set("x-next", "/pets?limit=10")
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 42, "name": "Fluffy"}})
+			return
+		}
+		itemPath = r.URL.Path
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "name": "Fluffy"})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	op := models.Operation{
+		Path:      "/pets/{petId}",
+		Method:    "GET",
+		ServerURL: server.URL,
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, PrefetchIDs: true})
+	if _, err := testRunner.TestOperation(op, p); err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+
+	if itemPath != "/pets/42" {
+		t.Errorf("Expected request to /pets/42, got %s", itemPath)
+	}
+}
+
+func TestIntegrationPrefetchBareID(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets": {"get": {"responses": {"200": {"description": "ok"}}}},
+    "/pets/{id}": {"get": {"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}], "responses": {"200": {"description": "ok"}}}}
+  }
+}`
+	path := filepath.Join(t.TempDir(), "pets.json")
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	var itemPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/pets" {
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 42, "name": "Fluffy"}})
+			return
+		}
+		itemPath = r.URL.Path
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42})
+	}))
+	defer server.Close()
+
+	op := models.Operation{Path: "/pets/{id}", Method: "GET", ServerURL: server.URL}
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, PrefetchIDs: true})
+	if _, err := testRunner.TestOperation(op, p); err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+
+	if itemPath != "/pets/42" {
+		t.Errorf("Expected request to /pets/42, got %s", itemPath)
+	}
+}
+
+func TestIntegrationSessionLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth/login":
+			var creds map[string]string
+			json.NewDecoder(r.Body).Decode(&creds)
+			if creds["username"] != "alice" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			json.NewEncoder(w).Encode(map[string]string{"token": "unused"})
+		case "/auth/profile":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "username": "alice"})
+		}
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/auth-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("Failed to create cookie jar: %v", err)
+	}
+
+	login := LoginConfig{OperationID: "login", Body: `{"username": "alice", "password": "secret"}`}
+	if err := Login(p, server.URL, login, jar, 30*time.Second); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	op := models.Operation{
+		Path:      "/auth/profile",
+		Method:    "GET",
+		ServerURL: server.URL,
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, CookieJar: jar})
+	result, err := testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected session to authorize request, got status %d", result.StatusCode)
+	}
+}
+
+func TestIntegrationSessionLoginHostHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth/login":
+			// Scoped to the virtual host, not the address the client dialed
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", Domain: "api.example.com"})
+			json.NewEncoder(w).Encode(map[string]string{"token": "unused"})
+		case "/auth/profile":
+			if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "username": "alice"})
+		}
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/auth-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	cookies, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("Failed to create cookie jar: %v", err)
+	}
+	jar := HostCookieJar(cookies, "api.example.com")
+
+	login := LoginConfig{OperationID: "login", Body: `{"username": "alice"}`, HostHeader: "api.example.com"}
+	if err := Login(p, server.URL, login, jar, 30*time.Second); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	op := models.Operation{
+		Path:      "/auth/profile",
+		Method:    "GET",
+		ServerURL: server.URL,
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, CookieJar: jar, HostHeader: "api.example.com"})
+	result, err := testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected session to authorize request, got status %d", result.StatusCode)
+	}
+}
+
+func TestIntegrationHostHeader(t *testing.T) {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "Fluffy"}})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	op := models.Operation{
+		Path:      "/pets",
+		Method:    "GET",
+		ServerURL: server.URL,
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, HostHeader: "api.example.com"})
+	if _, err := testRunner.TestOperation(op, p); err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+
+	if host != "api.example.com" {
+		t.Errorf("Expected Host api.example.com, got %s", host)
+	}
+}
+
+func TestIntegrationAddressFamily(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "Fluffy"}})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	op := models.Operation{
+		Path:      "/pets",
+		Method:    "GET",
+		ServerURL: server.URL, // listens on 127.0.0.1
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, AddressFamily: AddressFamilyIPv4})
+	result, err := testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+	if result.AddressFamily != AddressFamilyIPv4 {
+		t.Errorf("Expected address family ipv4, got %q", result.AddressFamily)
+	}
+
+	testRunner = NewTesterWithConfig(Config{Timeout: 30 * time.Second, AddressFamily: AddressFamilyIPv6})
+	result, err = testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+	if result.Error == "" {
+		t.Error("Expected an IPv4 address to be unreachable over IPv6")
+	}
+}
+
+func TestIntegrationNegativeErrorContract(t *testing.T) {
+	errorBody := `{"code": 400, "message": "q is required"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("q") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(errorBody))
+			return
+		}
+		w.Write([]byte(`["result"]`))
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/error-cases.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	op := models.Operation{Path: "/errors/search", Method: "GET", ServerURL: server.URL}
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, NegativeTests: true})
+
+	result, err := testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("Expected a documented error body to pass, got: %s", result.Error)
+	}
+
+	// An error body violating the 4XX schema is reported
+	errorBody = `{"error": "bad request"}`
+	result, err = testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, ve := range result.ValidationErrors {
+		fields[ve.Field] = true
+	}
+	if result.Passed || !fields["negative.body.code"] || !fields["negative.body.message"] {
+		t.Errorf("Expected error contract violations, got %+v", result.ValidationErrors)
+	}
+}
+
+func TestIntegrationNegativeSigV4(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Orders", "version": "1.0.0"},
+  "paths": {
+    "/orders": {
+      "post": {
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"type": "object", "properties": {"item": {"type": "string"}}}}}},
+        "responses": {"201": {"description": "created"}, "4XX": {"description": "rejected"}}
+      }
+    }
+  }
+}`
+	path := filepath.Join(t.TempDir(), "orders.json")
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// The s3 service signs the payload hash, so the server can tell whether
+	// the body it received is the one that was signed
+	var mu sync.Mutex
+	var mismatches, rejected int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			mismatches++
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !json.Valid(body) {
+			rejected++
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	signer := auth.NewSigV4("us-east-1", "s3", auth.AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, NegativeTests: true, Authenticator: signer})
+	op := models.Operation{Path: "/orders", Method: "POST", ServerURL: server.URL}
+	result, err := testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("Expected the operation to pass, got: %s", result.Error)
+	}
+	if mismatches != 0 || rejected != 1 {
+		t.Errorf("Expected the invalid body to be signed and rejected, got %d signature mismatches and %d rejections", mismatches, rejected)
+	}
+}
+
+func TestIntegrationSaveFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "boom"}`))
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	dir := t.TempDir()
+	op := models.Operation{Path: "/pets", Method: "GET", OperationID: "listPets", ServerURL: server.URL}
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, SaveFailuresDir: dir})
+
+	result, err := testRunner.TestOperation(op, p)
+	if err != nil {
+		t.Fatalf("Test operation failed: %v", err)
+	}
+	if result.Passed {
+		t.Fatal("Expected the operation to fail")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "listPets.txt"))
+	if err != nil {
+		t.Fatalf("Expected a saved failure: %v", err)
+	}
+	saved := string(data)
+	for _, want := range []string{"GET " + server.URL + "/pets", "500 Internal Server Error", `{"error": "boom"}`, result.Error} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("Expected saved failure to contain %q, got:\n%s", want, saved)
+		}
+	}
+}
+
+func TestIntegrationSaveFailuresPerCase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pagination-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	dir := t.TempDir()
+	op := models.Operation{Path: "/items", Method: "GET", ServerURL: server.URL}
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, EnumCases: true, SaveFailuresDir: dir})
+	testRunner.TestOperations([]models.Operation{op}, p, nil)
+
+	name := strings.TrimSuffix(FailureFileName(op), ".txt")
+	for _, sort := range []string{"name", "date", "price"} {
+		data, err := os.ReadFile(filepath.Join(dir, name+".sort_"+sort+".txt"))
+		if err != nil {
+			t.Errorf("Expected a saved failure for sort=%s: %v", sort, err)
+			continue
+		}
+		if !strings.Contains(string(data), "sort="+sort) {
+			t.Errorf("Expected the saved failure of sort=%s to hold its request, got:\n%s", sort, data)
+		}
+	}
+}
+
+func TestFailureFileName(t *testing.T) {
+	if name := FailureFileName(models.Operation{OperationID: "listPets"}); name != "listPets.txt" {
+		t.Errorf("Expected listPets.txt, got %s", name)
+	}
+	if name := FailureFileName(models.Operation{Method: "GET", Path: "/pets/{petId}"}); !strings.HasPrefix(name, "GET_pets_petId-") {
+		t.Errorf("Expected GET_pets_petId-<hash>.txt, got %s", name)
+	}
+
+	// Paths that only differ in replaced characters get their own files
+	slash := FailureFileName(models.Operation{Method: "GET", Path: "/a/b"})
+	underscore := FailureFileName(models.Operation{Method: "GET", Path: "/a_b"})
+	if slash == underscore {
+		t.Errorf("Expected /a/b and /a_b to be saved apart, both got %s", slash)
+	}
+}
+
+func TestIntegrationEnumCases(t *testing.T) {
+	var mu sync.Mutex
+	var sorts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sort := r.URL.Query().Get("sort")
+		mu.Lock()
+		sorts = append(sorts, sort)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "100")
+		w.Header().Set("X-Page", "1")
+		if sort == "price" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{}})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pagination-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	op := models.Operation{Path: "/items", Method: "GET", ServerURL: server.URL}
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, EnumCases: true})
+	summary := testRunner.TestOperations([]models.Operation{op}, p, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sorts) != 3 || sorts[0] != "name" || sorts[1] != "date" || sorts[2] != "price" {
+		t.Errorf("Expected a request per sort value, got %v", sorts)
+	}
+
+	result := summary.Results[0]
+	if result.Passed || result.Cases != 3 || result.FailedCases != 1 || result.FailedCase != "sort=price" {
+		t.Errorf("Expected sort=price to fail 1 of 3 cases, got %+v", result)
+	}
+}
+
+func TestIntegrationSeededCases(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "100")
+		w.Header().Set("X-Page", "1")
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{}})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pagination-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	op := models.Operation{Path: "/items", Method: "GET", ServerURL: server.URL}
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, Cases: 4, Seed: 99})
+	summary := testRunner.TestOperations([]models.Operation{op}, p, nil)
+
+	if requests != 4 {
+		t.Errorf("Expected 4 requests, got %d", requests)
+	}
+	if summary.Seed != 99 {
+		t.Errorf("Expected the base seed in the summary, got %d", summary.Seed)
+	}
+	result := summary.Results[0]
+	if !result.Passed || result.Cases != 4 || result.FailedCases != 0 {
+		t.Errorf("Expected 4 passing cases, got %+v", result)
+	}
+}
+
+func TestIntegrationCallbacks(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/callback-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		payload   string // sent to the callback URL, empty for none
+		status    int    // of the response, 201 if 0
+		wantPass  bool
+		wantField string
+	}{
+		{name: "valid callback", payload: `{"id": 1, "event": "created"}`, wantPass: true},
+		{name: "invalid payload", payload: `{"id": "one", "event": "created"}`, wantField: "callback.onEvent.id"},
+		{name: "no callback", wantField: "callback.onEvent"},
+		{name: "rejected request", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					CallbackURL string `json:"callbackUrl"`
+				}
+				json.NewDecoder(r.Body).Decode(&body)
+				if tt.payload != "" {
+					resp, err := http.Post(body.CallbackURL, "application/json", strings.NewReader(tt.payload))
+					if err != nil {
+						t.Errorf("Callback failed: %v", err)
+					} else {
+						resp.Body.Close()
+					}
+				}
+				w.WriteHeader(cmp.Or(tt.status, http.StatusCreated))
+			}))
+			defer server.Close()
+
+			testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second, CallbackTimeout: 200 * time.Millisecond})
+			if _, err := testRunner.StartCallbacks(p); err != nil {
+				t.Fatalf("Failed to start callbacks: %v", err)
+			}
+			defer testRunner.StopCallbacks()
+
+			op := models.Operation{Path: "/subscriptions", Method: "POST", ServerURL: server.URL}
+			summary := testRunner.TestOperations([]models.Operation{op}, p, nil)
+			result := summary.Results[0]
+
+			if result.Passed != tt.wantPass {
+				t.Fatalf("Expected passed=%v, got %+v", tt.wantPass, result)
+			}
+			if tt.wantField != "" {
+				if len(result.ValidationErrors) == 0 || result.ValidationErrors[0].Field != tt.wantField {
+					t.Errorf("Expected a %s error, got %+v", tt.wantField, result.ValidationErrors)
+				}
+			}
+			// The callback URL is retired whether or not the test awaited it
+			if n := len(testRunner.callbacks.waiting); n != 0 {
+				t.Errorf("Expected no callbacks left waiting, got %d", n)
+			}
+		})
+	}
+}
+
+func TestIntegrationWebhooks(t *testing.T) {
+	p, err := parser.ParseFile("../../tests/callback-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	testRunner := NewTesterWithConfig(Config{Timeout: 30 * time.Second})
+	url, err := testRunner.StartCallbacks(p)
+	if err != nil {
+		t.Fatalf("Failed to start callbacks: %v", err)
+	}
+	defer testRunner.StopCallbacks()
+
+	for _, path := range []string{"/webhooks/newPet", "/webhooks/unknown"} {
+		resp, err := http.Post(url+path, "application/json", strings.NewReader(`{"name": "Rex"}`))
+		if err != nil {
+			t.Fatalf("Webhook failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	summary := testRunner.TestOperations(nil, p, nil)
+	if summary.TotalTests != 2 {
+		t.Fatalf("Expected 2 webhook results, got %d", summary.TotalTests)
+	}
+	if r := summary.Results[0]; !r.Passed || r.OperationID != "newPetWebhook" {
+		t.Errorf("Expected newPet to pass, got %+v", r)
+	}
+	if r := summary.Results[1]; r.Passed {
+		t.Errorf("Expected the unknown webhook to fail, got %+v", r)
+	}
+}
+
+func TestIntegrationVerifyLive(t *testing.T) {
+	// The deployment serves GET /pets only; item routes were never deployed
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method)
+		if r.URL.Path != "/pets" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodOptions:
+			w.Header().Set("Allow", "GET, OPTIONS")
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "Fluffy"}})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	operations := []models.Operation{
+		{Path: "/pets", Method: "GET", ServerURL: server.URL},
+		{Path: "/pets", Method: "POST", ServerURL: server.URL},
+		{Path: "/pets/{petId}", Method: "GET", ServerURL: server.URL},
+	}
+
+	summary := NewTesterWithConfig(Config{Timeout: 5 * time.Second}).VerifyLive(operations, p, nil)
+
+	want := []string{models.LivePresent, models.LiveMethodNotAllowed, models.LiveMissing}
+	for i, check := range summary.Checks {
+		if check.Outcome != want[i] {
+			t.Errorf("%s %s: expected %s, got %s (%v)", check.Method, check.Path, want[i], check.Outcome, check.Probes)
+		}
+	}
+	if !summary.Drifted() {
+		t.Error("Expected the summary to report drift")
+	}
+	for _, method := range sent {
+		if method == http.MethodPost {
+			t.Error("Expected no POST request to be sent")
+		}
+	}
+}
+
+func TestIntegrationSnapshots(t *testing.T) {
+	id, price := json.Number("1"), 10
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Next", "/pets?page=2")
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"id": id, "name": "Fluffy", "price": price, "createdAt": time.Now().Format(time.RFC3339Nano)},
+		})
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	dir := t.TempDir()
+	op := models.Operation{Path: "/pets", Method: "GET", OperationID: "listPets", ServerURL: server.URL}
+	run := func(update bool) models.TestResult {
+		config := Config{Timeout: 30 * time.Second, Snapshot: SnapshotConfig{Dir: dir, Update: update, Ignore: []string{"createdAt"}}}
+		return NewTesterWithConfig(config).TestOperations([]models.Operation{op}, p, nil).Results[0]
+	}
+
+	if result := run(false); !result.Passed || result.Snapshot != SnapshotCreated {
+		t.Fatalf("Expected the first run to create the snapshot, got %+v", result)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "listPets.json"))
+	if err != nil {
+		t.Fatalf("Expected a snapshot file: %v", err)
+	}
+	if !strings.Contains(string(data), `"createdAt": "<ignored>"`) {
+		t.Errorf("Expected createdAt to be ignored, got:\n%s", data)
+	}
+
+	// The timestamp differs, but is ignored
+	if result := run(false); !result.Passed || result.Snapshot != SnapshotMatched {
+		t.Errorf("Expected the snapshot to match, got %+v", result)
+	}
+
+	price = 12
+	result := run(false)
+	if result.Passed || result.Snapshot != SnapshotChanged {
+		t.Fatalf("Expected the changed price to fail, got %+v", result)
+	}
+	if ve := result.ValidationErrors; len(ve) != 1 || ve[0].Field != "snapshot" || ve[0].Message != "body[0].price: expected 10, got 12" {
+		t.Errorf("Unexpected snapshot errors %+v", ve)
+	}
+
+	if result := run(true); !result.Passed || result.Snapshot != SnapshotUpdated {
+		t.Errorf("Expected the snapshot to be updated, got %+v", result)
+	}
+	if result := run(false); !result.Passed || result.Snapshot != SnapshotMatched {
+		t.Errorf("Expected the updated snapshot to match, got %+v", result)
+	}
+
+	// Ids beyond float64 precision are compared exactly
+	id = "9007199254740993"
+	run(true)
+	id = "9007199254740992"
+	result = run(false)
+	if ve := result.ValidationErrors; len(ve) != 1 || ve[0].Message != "body[0].id: expected 9007199254740993, got 9007199254740992" {
+		t.Errorf("Unexpected snapshot errors %+v", ve)
+	}
+}
@@ -0,0 +1,50 @@
+package tester
+
+import (
+	"context"
+	"net"
+)
+
+// Address families accepted by Config.AddressFamily
+const (
+	AddressFamilyAny  = ""
+	AddressFamilyIPv4 = "ipv4"
+	AddressFamilyIPv6 = "ipv6"
+)
+
+// DialContextFunc matches net.Dialer.DialContext and http.Transport.DialContext
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// RestrictAddressFamily wraps a dial function so connections only use the
+// given address family, for validating each side of a dual-stack deployment.
+// Any other family leaves the dial function unchanged.
+func RestrictAddressFamily(dial DialContextFunc, family string) DialContextFunc {
+	var suffix string
+	switch family {
+	case AddressFamilyIPv4:
+		suffix = "4"
+	case AddressFamilyIPv6:
+		suffix = "6"
+	default:
+		return dial
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network == "tcp" || network == "udp" {
+			network += suffix
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// AddressFamilyOf reports the family of a connection's remote address
+func AddressFamilyOf(addr net.Addr) string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	if tcpAddr.IP.To4() != nil {
+		return AddressFamilyIPv4
+	}
+	return AddressFamilyIPv6
+}
@@ -0,0 +1,277 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// PluginTimeout is how long an exec plugin may take to check a response
+// before it is stopped
+var PluginTimeout = 10 * time.Second
+
+// Exchange is a tested request and its response, as seen by validator plugins
+type Exchange struct {
+	Method      string              `json:"method"`
+	Path        string              `json:"path"` // path template from the spec, e.g. /pets/{petId}
+	OperationID string              `json:"operation_id,omitempty"`
+	URL         string              `json:"url"`
+	StatusCode  int                 `json:"status_code"`
+	Headers     map[string][]string `json:"headers"`
+	Body        string              `json:"body"`
+}
+
+// ValidatorPlugin is a custom response check, such as PII detection or
+// money-rounding rules, run on every response after the spec validation.
+// Validate returns the problems found; an error means the check itself could
+// not be done.
+type ValidatorPlugin interface {
+	Name() string
+	Validate(ex Exchange) ([]models.ValidationError, error)
+	Close() error
+}
+
+// ValidatorFactory creates a compiled-in validator from the options of its
+// [validators.<name>] config section
+type ValidatorFactory func(options map[string]interface{}) (ValidatorPlugin, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]ValidatorFactory)
+)
+
+// RegisterValidator makes a compiled-in validator available by name, usually
+// from the init function of the package implementing it. Names match
+// case-insensitively; registering a name twice panics.
+func RegisterValidator(name string, factory ValidatorFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	key := strings.ToLower(name)
+	if _, ok := registry[key]; ok {
+		panic(fmt.Sprintf("validator %s registered twice", name))
+	}
+	registry[key] = factory
+}
+
+// LoadValidators creates the validators of the [validators] config section,
+// keyed by name. A validator with a command is an exec plugin; any other is
+// looked up among the compiled-in validators. Validators run in name order.
+func LoadValidators(config map[string]interface{}) ([]ValidatorPlugin, error) {
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var plugins []ValidatorPlugin
+	for _, name := range names {
+		options, ok := config[name].(map[string]interface{})
+		if !ok {
+			CloseValidators(plugins)
+			return nil, fmt.Errorf("invalid validator %s: expected a [validators.%s] section", name, name)
+		}
+
+		var plugin ValidatorPlugin
+		var err error
+		if command, ok := options["command"]; ok {
+			plugin, err = startExecValidator(name, command)
+		} else {
+			registryMu.Lock()
+			factory, found := registry[strings.ToLower(name)]
+			registryMu.Unlock()
+			if !found {
+				CloseValidators(plugins)
+				return nil, fmt.Errorf("unknown validator %s: set a command for an exec plugin", name)
+			}
+			plugin, err = factory(options)
+		}
+		if err != nil {
+			CloseValidators(plugins)
+			return nil, fmt.Errorf("validator %s: %w", name, err)
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins, nil
+}
+
+// CloseValidators stops the validators, e.g. the processes of exec plugins
+func CloseValidators(plugins []ValidatorPlugin) {
+	for _, p := range plugins {
+		p.Close()
+	}
+}
+
+// runValidators checks a response with every validator plugin. Problems are
+// reported under the validator's name unless it names a field itself.
+func (t *Tester) runValidators(op models.Operation, req *http.Request, resp *http.Response, body []byte) []models.ValidationError {
+	if len(t.config.Validators) == 0 {
+		return nil
+	}
+	ex := Exchange{
+		Method:      op.Method,
+		Path:        op.Path,
+		OperationID: op.OperationID,
+		URL:         req.URL.String(),
+		StatusCode:  resp.StatusCode,
+		Headers:     resp.Header,
+		Body:        string(body),
+	}
+
+	var errors []models.ValidationError
+	for _, p := range t.config.Validators {
+		found, err := p.Validate(ex)
+		if err != nil {
+			errors = append(errors, models.ValidationError{
+				Field:   p.Name(),
+				Message: fmt.Sprintf("validator failed: %v", err),
+			})
+			continue
+		}
+		for _, ve := range found {
+			if ve.Field == "" {
+				ve.Field = p.Name()
+			}
+			errors = append(errors, ve)
+		}
+	}
+	return errors
+}
+
+// execValidator is a validator plugin running as a separate process. It
+// receives one Exchange per line on stdin and answers each with one line on
+// stdout: {"errors": [{"field": "...", "message": "..."}]}. The process is
+// started once and must exit when stdin is closed; its stderr is passed
+// through.
+type execValidator struct {
+	name string
+	cmd  *exec.Cmd
+
+	mu     sync.Mutex
+	stdin  io.WriteCloser
+	stdout *json.Decoder
+	failed error // set once the process can't be used anymore
+}
+
+// execResponse is the answer of an exec plugin to an exchange
+type execResponse struct {
+	Errors []models.ValidationError `json:"errors"`
+}
+
+// startExecValidator starts the process of an exec plugin. command is a
+// command line split on whitespace, or a list of arguments.
+func startExecValidator(name string, command interface{}) (*execValidator, error) {
+	var args []string
+	switch c := command.(type) {
+	case string:
+		args = strings.Fields(c)
+	case []interface{}:
+		for _, arg := range c {
+			args = append(args, fmt.Sprint(arg))
+		}
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("command must be a non-empty string or list")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", args[0], err)
+	}
+	return &execValidator{
+		name:   name,
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: json.NewDecoder(stdout),
+	}, nil
+}
+
+// Name returns the name the plugin is configured under
+func (v *execValidator) Name() string {
+	return v.name
+}
+
+// Validate sends an exchange to the plugin and waits for its answer. A plugin
+// that answers garbage, or doesn't read the exchange and answer within
+// PluginTimeout, is stopped, failing the checks of every later response.
+func (v *execValidator) Validate(ex Exchange) ([]models.ValidationError, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.failed != nil {
+		return nil, v.failed
+	}
+
+	line, err := json.Marshal(ex)
+	if err != nil {
+		return nil, err
+	}
+	// A plugin that stops reading blocks the write once the pipe is full, so
+	// it counts against the timeout like the answer
+	done := make(chan error, 1)
+	var resp execResponse
+	var written bool
+	go func() {
+		if _, err := v.stdin.Write(append(line, '\n')); err != nil {
+			done <- err
+			return
+		}
+		written = true
+		done <- v.stdout.Decode(&resp)
+	}()
+	select {
+	case err := <-done:
+		if !written {
+			return nil, v.fail(fmt.Errorf("plugin exited: %w", err))
+		}
+		if err == io.EOF {
+			return nil, v.fail(fmt.Errorf("plugin exited"))
+		}
+		if err != nil {
+			return nil, v.fail(fmt.Errorf("invalid answer: %w", err))
+		}
+	case <-time.After(PluginTimeout):
+		return nil, v.fail(fmt.Errorf("no answer within %v", PluginTimeout))
+	}
+	return resp.Errors, nil
+}
+
+// fail stops the plugin after an error it can't recover from
+func (v *execValidator) fail(err error) error {
+	v.failed = err
+	v.cmd.Process.Kill()
+	v.stdin.Close() // unblocks a pending write
+	return err
+}
+
+// Close closes the plugin's stdin and waits for it to exit
+func (v *execValidator) Close() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.stdin.Close()
+	done := make(chan error, 1)
+	go func() { done <- v.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(PluginTimeout):
+		v.cmd.Process.Kill()
+		return <-done
+	}
+}
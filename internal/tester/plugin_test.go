@@ -0,0 +1,139 @@
+package tester
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// TestHelperPlugin is not a real test: it is the exec plugin started by the
+// plugin tests, flagging response bodies that contain an email address
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("OAS_HELPER_PLUGIN") != "1" {
+		t.Skip("helper process")
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var ex Exchange
+		json.Unmarshal(scanner.Bytes(), &ex)
+		var resp execResponse
+		if strings.Contains(ex.Body, "@") {
+			resp.Errors = []models.ValidationError{{Field: "body", Message: "contains an email address"}}
+		}
+		line, _ := json.Marshal(resp)
+		fmt.Println(string(line))
+	}
+	os.Exit(0)
+}
+
+func helperPluginConfig(t *testing.T) map[string]interface{} {
+	t.Setenv("OAS_HELPER_PLUGIN", "1")
+	return map[string]interface{}{
+		"pii": map[string]interface{}{
+			"command": []interface{}{os.Args[0], "-test.run=^TestHelperPlugin$"},
+		},
+	}
+}
+
+func TestExecValidator(t *testing.T) {
+	plugins, err := LoadValidators(helperPluginConfig(t))
+	if err != nil {
+		t.Fatalf("LoadValidators: %v", err)
+	}
+	defer CloseValidators(plugins)
+
+	errs, err := plugins[0].Validate(Exchange{Method: "GET", Path: "/users", Body: `{"email":"jane@example.com"}`})
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Message != "contains an email address" {
+		t.Errorf("Expected the email to be flagged, got %v", errs)
+	}
+
+	errs, err = plugins[0].Validate(Exchange{Method: "GET", Path: "/users", Body: `{"name":"Jane"}`})
+	if err != nil || len(errs) != 0 {
+		t.Errorf("Expected a clean body to pass, got %v, %v", errs, err)
+	}
+}
+
+func TestExecValidatorExited(t *testing.T) {
+	plugins, err := LoadValidators(map[string]interface{}{
+		"broken": map[string]interface{}{"command": "true"},
+	})
+	if err != nil {
+		t.Fatalf("LoadValidators: %v", err)
+	}
+	defer CloseValidators(plugins)
+
+	if _, err := plugins[0].Validate(Exchange{Method: "GET", Path: "/"}); err == nil {
+		t.Error("Expected an error from a plugin that exited")
+	}
+}
+
+func TestExecValidatorNotReading(t *testing.T) {
+	defer func(timeout time.Duration) { PluginTimeout = timeout }(PluginTimeout)
+	PluginTimeout = 100 * time.Millisecond
+
+	plugins, err := LoadValidators(map[string]interface{}{
+		"stuck": map[string]interface{}{"command": "sleep 60"},
+	})
+	if err != nil {
+		t.Fatalf("LoadValidators: %v", err)
+	}
+	defer CloseValidators(plugins)
+
+	// Larger than a pipe buffer, so the write blocks
+	body := strings.Repeat("x", 1<<20)
+	done := make(chan error, 1)
+	go func() {
+		_, err := plugins[0].Validate(Exchange{Method: "GET", Path: "/", Body: body})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected an error from a plugin that doesn't read its input")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Validate blocked on a plugin that doesn't read its input")
+	}
+}
+
+type stubValidator struct{ limit int }
+
+func (v stubValidator) Name() string { return "size" }
+func (v stubValidator) Close() error { return nil }
+func (v stubValidator) Validate(ex Exchange) ([]models.ValidationError, error) {
+	if len(ex.Body) > v.limit {
+		return []models.ValidationError{{Message: "body too large"}}, nil
+	}
+	return nil, nil
+}
+
+func TestCompiledInValidator(t *testing.T) {
+	RegisterValidator("Size", func(options map[string]interface{}) (ValidatorPlugin, error) {
+		limit, _ := options["limit"].(int64)
+		return stubValidator{limit: int(limit)}, nil
+	})
+
+	plugins, err := LoadValidators(map[string]interface{}{
+		"size": map[string]interface{}{"limit": int64(4)},
+	})
+	if err != nil {
+		t.Fatalf("LoadValidators: %v", err)
+	}
+	errs, _ := plugins[0].Validate(Exchange{Body: "12345"})
+	if len(errs) != 1 {
+		t.Errorf("Expected the compiled-in validator to flag the body, got %v", errs)
+	}
+
+	if _, err := LoadValidators(map[string]interface{}{"missing": map[string]interface{}{}}); err == nil {
+		t.Error("Expected an unknown validator without a command to be rejected")
+	}
+}
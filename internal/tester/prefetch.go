@@ -0,0 +1,102 @@
+package tester
+
+import (
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// collectionFields lists common envelope fields holding a page of items
+var collectionFields = []string{"items", "data", "results", "content", "records"}
+
+// prefetchIDs seeds the request builder with a real identifier for an item
+// path such as /pets/{petId}, harvested from its collection endpoint GET /pets.
+// Failures are ignored: the parameter then falls back to a generated value.
+func (t *Tester) prefetchIDs(opDetails *parser.OperationDetails, serverURL string, p *parser.Parser) {
+	idx := strings.LastIndex(opDetails.Path, "/")
+	if idx < 0 {
+		return
+	}
+	last := opDetails.Path[idx+1:]
+	if !strings.HasPrefix(last, "{") || !strings.HasSuffix(last, "}") {
+		return
+	}
+	paramName := strings.Trim(last, "{}")
+	collectionPath := opDetails.Path[:idx]
+
+	t.mu.Lock()
+	if t.prefetched[collectionPath] {
+		t.mu.Unlock()
+		return
+	}
+	t.prefetched[collectionPath] = true
+	t.mu.Unlock()
+
+	collection, err := p.GetOperationDetails(collectionPath, "GET")
+	if err != nil {
+		return
+	}
+
+	req, err := t.requestBuilder.BuildRequest(collection, serverURL)
+	if err != nil {
+		return
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return
+	}
+
+	var body interface{}
+	if err := decodeJSON(resp.Body, &body); err != nil {
+		return
+	}
+
+	if ids := harvestIDs(body, paramName); len(ids) > 0 {
+		t.requestBuilder.SeedEntityValue(opDetails.Path, paramName, ids[0])
+	}
+}
+
+// harvestIDs extracts identifiers from a collection response. The response may
+// be a bare array or an object wrapping the array in a common envelope field.
+// Each item contributes its "id" field, or a field named after the parameter.
+func harvestIDs(body interface{}, paramName string) []interface{} {
+	items, ok := body.([]interface{})
+	if !ok {
+		obj, isObj := body.(map[string]interface{})
+		if !isObj {
+			return nil
+		}
+		for _, field := range collectionFields {
+			if list, isList := obj[field].([]interface{}); isList {
+				items = list
+				break
+			}
+		}
+	}
+
+	var ids []interface{}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, field := range []string{"id", paramName} {
+			if id, exists := obj[field]; exists && id != nil {
+				// Render integral float64 ids without a fraction; exact
+				// json.Number ids keep their digits
+				if f, isFloat := id.(float64); isFloat && f == float64(int64(f)) {
+					id = int64(f)
+				}
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,53 @@
+package tester
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a named bundle of test settings
+type Profile struct {
+	Name          string
+	Strictness    Strictness // How thoroughly responses are validated
+	NegativeTests bool       // Also send invalid requests and expect 4xx responses
+	MinCoverage   float64    // Minimum percentage of spec operations that must be exercised
+}
+
+// profiles holds the built-in test profiles
+var profiles = map[string]Profile{
+	"smoke": {
+		Name:       "smoke",
+		Strictness: StrictnessLenient,
+	},
+	"standard": {
+		Name:       "standard",
+		Strictness: StrictnessStandard,
+	},
+	"strict": {
+		Name:          "strict",
+		Strictness:    StrictnessStrict,
+		NegativeTests: true,
+		MinCoverage:   100,
+	},
+}
+
+// ParseProfile looks up a built-in profile by name
+func ParseProfile(name string) (Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Profile{}, fmt.Errorf("invalid profile '%s': must be one of %s", name, strings.Join(names, ", "))
+	}
+	return profile, nil
+}
+
+// Apply copies the profile settings into a tester configuration
+func (p Profile) Apply(config *Config) {
+	config.Strictness = p.Strictness
+	config.NegativeTests = p.NegativeTests
+}
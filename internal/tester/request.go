@@ -2,57 +2,260 @@ package tester
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/moamenhredeen/oas/internal/auth"
 	"github.com/moamenhredeen/oas/internal/generator"
 	"github.com/moamenhredeen/oas/internal/parser"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
+// DefaultUserAgent is sent when no User-Agent is configured
+const DefaultUserAgent = "oas-test-tool/1.0"
+
+// DefaultAccept is sent when an operation declares no response media types
+const DefaultAccept = "application/json"
+
 // RequestBuilder builds HTTP requests from OpenAPI operations
 type RequestBuilder struct {
-	generator *generator.Generator
+	ctx           context.Context
+	generator     *generator.Generator
+	scripts       *Scripts
+	authenticator auth.Authenticator
+
+	correlationHeader string
+	userAgent         string
+	hostHeader        string
+	accept            string
+
+	optionalParams float64
 }
 
 // NewRequestBuilder creates a new request builder
 func NewRequestBuilder() *RequestBuilder {
 	return &RequestBuilder{
+		ctx:       context.Background(),
 		generator: generator.NewGenerator(),
+		userAgent: DefaultUserAgent,
+	}
+}
+
+// SetContext configures the context of built requests, cancelling them
+// when it is done
+func (rb *RequestBuilder) SetContext(ctx context.Context) {
+	if ctx != nil {
+		rb.ctx = ctx
+	}
+}
+
+// SetParamValues configures fixed parameter values consulted before generation
+func (rb *RequestBuilder) SetParamValues(values map[string]interface{}) {
+	rb.generator.SetParamValues(values)
+}
+
+// SetSeed restarts random value generation from the given seed
+func (rb *RequestBuilder) SetSeed(seed int64) {
+	rb.generator.Seed(seed)
+}
+
+// SetUnicode switches free-form string values to unicode edge cases
+func (rb *RequestBuilder) SetUnicode(enabled bool) {
+	rb.generator.SetUnicode(enabled)
+}
+
+// SetScripts configures request mutation scripts applied after each request is built
+func (rb *RequestBuilder) SetScripts(scripts *Scripts) {
+	rb.scripts = scripts
+}
+
+// SetAuthenticator configures credentials applied as the last step of request building
+func (rb *RequestBuilder) SetAuthenticator(authenticator auth.Authenticator) {
+	rb.authenticator = authenticator
+}
+
+// SetCorrelationHeader configures a header that carries a unique id on every request
+func (rb *RequestBuilder) SetCorrelationHeader(name string) {
+	rb.correlationHeader = name
+}
+
+// SetUserAgent configures the User-Agent header; an empty value keeps the default
+func (rb *RequestBuilder) SetUserAgent(userAgent string) {
+	if userAgent != "" {
+		rb.userAgent = userAgent
+	}
+}
+
+// SetHostHeader overrides the Host header, so requests can target an IP or load
+// balancer while presenting a specific virtual host
+func (rb *RequestBuilder) SetHostHeader(host string) {
+	rb.hostHeader = host
+}
+
+// SetAccept overrides the Accept header derived from each operation's
+// documented responses; an empty value keeps the derived header
+func (rb *RequestBuilder) SetAccept(accept string) {
+	rb.accept = accept
+}
+
+// SetOptionalParams configures the probability with which each optional query
+// parameter is sent; 0 sends required query parameters only, 1 sends all
+func (rb *RequestBuilder) SetOptionalParams(probability float64) {
+	rb.optionalParams = probability
+}
+
+// acceptHeader lists the media types declared for an operation's responses in
+// spec order, so the server is asked for a representation the spec documents
+func acceptHeader(responses *v3.Responses) string {
+	var types []string
+	seen := make(map[string]bool)
+	add := func(response *v3.Response) {
+		if response == nil || response.Content == nil {
+			return
+		}
+		for pair := response.Content.First(); pair != nil; pair = pair.Next() {
+			if mediaType := pair.Key(); !seen[mediaType] {
+				seen[mediaType] = true
+				types = append(types, mediaType)
+			}
+		}
+	}
+
+	if responses != nil {
+		if responses.Codes != nil {
+			for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+				add(pair.Value())
+			}
+		}
+		add(responses.Default)
 	}
+	if len(types) == 0 {
+		return DefaultAccept
+	}
+	return strings.Join(types, ", ")
+}
+
+// ServerName returns the TLS server name (SNI) for a Host header value
+func ServerName(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// SeedEntityValue fixes the value used for the entity identifier parameter
+// name of path for the rest of the run
+func (rb *RequestBuilder) SeedEntityValue(path, name string, value interface{}) {
+	rb.generator.SetEntityValue(path, name, value)
+}
+
+// parameterValue returns the configured value for a parameter, falling back to a generated one
+func (rb *RequestBuilder) parameterValue(opDetails *parser.OperationDetails, param *v3.Parameter) (string, error) {
+	if val, ok := rb.generator.LookupParameter(opDetails.Path, opDetails.Method, param); ok {
+		return val, nil
+	}
+	if param.In == "query" {
+		return rb.generator.GenerateQueryParameter(param)
+	}
+	return rb.generator.GeneratePathParameterForPath(opDetails.Path, param)
+}
+
+// isRequired reports whether a parameter is declared required
+func isRequired(param *v3.Parameter) bool {
+	return param.Required != nil && *param.Required
 }
 
 // BuildRequest builds an HTTP request from an OpenAPI operation
 func (rb *RequestBuilder) BuildRequest(opDetails *parser.OperationDetails, serverURL string) (*http.Request, error) {
+	return rb.BuildRequestWithParams(opDetails, serverURL, nil)
+}
+
+// BuildRequestWithParams builds an HTTP request using the given values for
+// path and query parameters, e.g. to address a resource created by an earlier
+// request or to test a specific enum value. Parameters without a value are
+// generated as usual.
+func (rb *RequestBuilder) BuildRequestWithParams(opDetails *parser.OperationDetails, serverURL string, params map[string]string) (*http.Request, error) {
+	return rb.BuildRequestWithOverrides(opDetails, serverURL, RequestOverrides{Params: params})
+}
+
+// RequestOverrides are values used instead of generated ones when building a
+// request
+type RequestOverrides struct {
+	Params  map[string]string      // path and query parameter values
+	Body    map[string]interface{} // JSON body fields, by dot-separated name
+	Headers map[string]string      // header values
+	RawBody []byte                 // JSON body sent instead of a generated one, e.g. a GraphQL query
+
+	RequiredOnly bool // leave out optional query parameters without a fixed or configured value
+}
+
+// BuildRequestWithOverrides builds an HTTP request like BuildRequestWithParams,
+// additionally setting body fields and headers, e.g. to register a callback
+// URL. Overrides are applied before scripts and authentication.
+func (rb *RequestBuilder) BuildRequestWithOverrides(opDetails *parser.OperationDetails, serverURL string, overrides RequestOverrides) (*http.Request, error) {
+	req, err := rb.buildUnsigned(opDetails, serverURL, overrides)
+	if err != nil {
+		return nil, err
+	}
+	if err := rb.authenticate(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// buildUnsigned builds a request up to, but not including, authentication
+func (rb *RequestBuilder) buildUnsigned(opDetails *parser.OperationDetails, serverURL string, overrides RequestOverrides) (*http.Request, error) {
+	params := overrides.Params
 	if opDetails == nil {
 		return nil, fmt.Errorf("operation details is nil")
 	}
 
 	// Build URL with path parameters
 	fullPath := opDetails.Path
+	for name, val := range params {
+		fullPath = strings.ReplaceAll(fullPath, "{"+name+"}", url.PathEscape(val))
+	}
 	if opDetails.Parameters != nil {
 		for _, param := range opDetails.Parameters {
 			if param != nil && param.In == "path" {
-				val, err := rb.generator.GeneratePathParameter(param)
+				if _, ok := params[param.Name]; ok {
+					continue
+				}
+				val, err := rb.parameterValue(opDetails, param)
 				if err != nil {
 					return nil, fmt.Errorf("failed to generate path parameter %s: %w", param.Name, err)
 				}
 				// Replace {paramName} with value
-				fullPath = strings.ReplaceAll(fullPath, "{"+param.Name+"}", val)
+				fullPath = strings.ReplaceAll(fullPath, "{"+param.Name+"}", url.PathEscape(val))
 			}
 		}
 	}
 
 	// Build full URL
-	fullURL := serverURL + fullPath
+	fullURL := parser.JoinURL(serverURL, fullPath)
 
 	// Add query parameters
 	if opDetails.Parameters != nil {
 		queryParams := url.Values{}
 		for _, param := range opDetails.Parameters {
 			if param != nil && param.In == "query" {
-				val, err := rb.generator.GenerateQueryParameter(param)
+				if val, ok := params[param.Name]; ok {
+					queryParams.Add(param.Name, val)
+					continue
+				}
+				if !isRequired(param) && (overrides.RequiredOnly || !rb.generator.Chance(rb.optionalParams)) {
+					// Optional parameters with a configured value are always sent
+					if _, ok := rb.generator.LookupParameter(opDetails.Path, opDetails.Method, param); !ok {
+						continue
+					}
+				}
+				val, err := rb.parameterValue(opDetails, param)
 				if err != nil {
 					return nil, fmt.Errorf("failed to generate query parameter %s: %w", param.Name, err)
 				}
@@ -69,32 +272,51 @@ func (rb *RequestBuilder) BuildRequest(opDetails *parser.OperationDetails, serve
 	var err error
 
 	// Handle request body for POST, PUT, PATCH
-	if opDetails.RequestBody != nil && (opDetails.Method == "POST" || opDetails.Method == "PUT" || opDetails.Method == "PATCH") {
-		bodyBytes, contentType, err := rb.generator.GenerateRequestBody(opDetails.RequestBody)
+	if overrides.RawBody != nil {
+		req, err = http.NewRequestWithContext(rb.ctx, opDetails.Method, fullURL, bytes.NewReader(overrides.RawBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+	} else if opDetails.RequestBody != nil && (opDetails.Method == "POST" || opDetails.Method == "PUT" || opDetails.Method == "PATCH") {
+		bodyBytes, contentType, err := rb.generator.GenerateRequestBodyForPath(opDetails.Path, opDetails.RequestBody)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate request body: %w", err)
 		}
-		req, err = http.NewRequest(opDetails.Method, fullURL, bytes.NewBuffer(bodyBytes))
+		for name, value := range overrides.Body {
+			bodyBytes, err = setBodyField(bodyBytes, name, value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set body field %s: %w", name, err)
+			}
+		}
+		req, err = http.NewRequestWithContext(rb.ctx, opDetails.Method, fullURL, bytes.NewBuffer(bodyBytes))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 		req.Header.Set("Content-Type", contentType)
 	} else {
-		req, err = http.NewRequest(opDetails.Method, fullURL, nil)
+		req, err = http.NewRequestWithContext(rb.ctx, opDetails.Method, fullURL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 	}
 
 	// Set default headers
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "oas-test-tool/1.0")
+	accept := rb.accept
+	if accept == "" {
+		accept = acceptHeader(opDetails.Responses)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", rb.userAgent)
+	if rb.hostHeader != "" {
+		req.Host = rb.hostHeader
+	}
 
 	// Add header parameters
 	if opDetails.Parameters != nil {
 		for _, param := range opDetails.Parameters {
 			if param != nil && param.In == "header" {
-				val, err := rb.generator.GeneratePathParameter(param)
+				val, err := rb.parameterValue(opDetails, param)
 				if err != nil {
 					return nil, fmt.Errorf("failed to generate header parameter %s: %w", param.Name, err)
 				}
@@ -102,6 +324,92 @@ func (rb *RequestBuilder) BuildRequest(opDetails *parser.OperationDetails, serve
 			}
 		}
 	}
+	for name, val := range overrides.Headers {
+		req.Header.Set(name, val)
+	}
+
+	// Tag the request so it can be found in server logs and traces
+	if rb.correlationHeader != "" {
+		id, err := newUUID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate correlation id: %w", err)
+		}
+		req.Header.Set(rb.correlationHeader, id)
+	}
+
+	// Apply request mutation scripts
+	if err := rb.scripts.apply(req, opDetails); err != nil {
+		return nil, err
+	}
 
 	return req, nil
 }
+
+// authenticate applies the credentials to a finished request. It must run
+// last so signatures cover the final request.
+func (rb *RequestBuilder) authenticate(req *http.Request) error {
+	if rb.authenticator == nil {
+		return nil
+	}
+	if err := rb.authenticator.Apply(req); err != nil {
+		return fmt.Errorf("failed to authenticate request: %w", err)
+	}
+	return nil
+}
+
+// BuildNegativeRequest builds a request that violates the operation's input
+// contract: required query and header parameters are dropped and any request
+// body is replaced with malformed JSON. The boolean result is false when the
+// operation has no inputs that can be invalidated.
+func (rb *RequestBuilder) BuildNegativeRequest(opDetails *parser.OperationDetails, serverURL string) (*http.Request, bool, error) {
+	req, err := rb.buildUnsigned(opDetails, serverURL, RequestOverrides{})
+	if err != nil {
+		return nil, false, err
+	}
+
+	invalidated := false
+	query := req.URL.Query()
+	for _, param := range opDetails.Parameters {
+		if param == nil || param.Required == nil || !*param.Required {
+			continue
+		}
+		switch param.In {
+		case "query":
+			query.Del(param.Name)
+			invalidated = true
+		case "header":
+			req.Header.Del(param.Name)
+			invalidated = true
+		}
+	}
+	req.URL.RawQuery = query.Encode()
+
+	if req.Body != nil && req.Body != http.NoBody {
+		malformed := []byte("{")
+		req.Body = io.NopCloser(bytes.NewReader(malformed))
+		req.ContentLength = int64(len(malformed))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(malformed)), nil
+		}
+		req.Header.Set("Content-Type", "application/json")
+		invalidated = true
+	}
+
+	// Sign the invalid request, not the valid one it was made from, so the
+	// server rejects the input rather than the signature
+	if err := rb.authenticate(req); err != nil {
+		return nil, false, err
+	}
+	return req, invalidated, nil
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
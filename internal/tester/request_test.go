@@ -1,6 +1,9 @@
 package tester
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"strings"
 	"testing"
 
@@ -74,6 +77,33 @@ func TestBuildRequestWithPathParameter(t *testing.T) {
 	}
 }
 
+func TestBuildRequestEscapesPathParameter(t *testing.T) {
+	rb := NewRequestBuilder()
+	rb.SetParamValues(map[string]interface{}{"petId": "a b/c?d"})
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/pets/{petId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	req, err := rb.BuildRequest(opDetails, "http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+
+	if got, want := req.URL.EscapedPath(), "/v1/pets/a%20b%2Fc%3Fd"; got != want {
+		t.Errorf("Expected path %s, got %s", want, got)
+	}
+	if req.URL.RawQuery != "" {
+		t.Errorf("Expected no query, got %s", req.URL.RawQuery)
+	}
+}
+
 func TestBuildRequestPOST(t *testing.T) {
 	rb := NewRequestBuilder()
 
@@ -137,6 +167,55 @@ func TestBuildRequestWithQueryParameters(t *testing.T) {
 	}
 }
 
+func TestBuildRequestOptionalQueryParameters(t *testing.T) {
+	rb := NewRequestBuilder()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	listPets, err := p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	req, err := rb.BuildRequest(listPets, "http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if req.URL.RawQuery != "" {
+		t.Errorf("Expected the optional limit to be left out by default, got %q", req.URL.RawQuery)
+	}
+
+	rb.SetOptionalParams(1)
+	req, err = rb.BuildRequest(listPets, "http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if !req.URL.Query().Has("limit") {
+		t.Errorf("Expected the optional limit to be sent, got %q", req.URL.RawQuery)
+	}
+
+	req, err = rb.BuildRequestWithOverrides(listPets, "http://petstore.swagger.io/v1", RequestOverrides{RequiredOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if req.URL.RawQuery != "" {
+		t.Errorf("Expected a required-only request to leave out limit, got %q", req.URL.RawQuery)
+	}
+
+	req, err = rb.BuildRequestWithOverrides(listPets, "http://petstore.swagger.io/v1", RequestOverrides{
+		Params:       map[string]string{"limit": "5"},
+		RequiredOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if limit := req.URL.Query().Get("limit"); limit != "5" {
+		t.Errorf("Expected the fixed limit to be sent, got %q", limit)
+	}
+}
+
 func TestBuildRequestHeaders(t *testing.T) {
 	rb := NewRequestBuilder()
 
@@ -166,3 +245,216 @@ func TestBuildRequestHeaders(t *testing.T) {
 		t.Error("Expected User-Agent header")
 	}
 }
+
+func TestBuildRequestPathItemParameters(t *testing.T) {
+	rb := NewRequestBuilder()
+
+	p, err := parser.ParseFile("../../tests/upload-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	getFolder, err := p.GetOperationDetails("/folders/{folderId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	req, err := rb.BuildRequest(getFolder, "http://uploads.example.com/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if !strings.HasPrefix(req.URL.Path, "/v1/folders/") || strings.Contains(req.URL.Path, "{") {
+		t.Errorf("Expected the path-level folderId to be filled in, got %s", req.URL.Path)
+	}
+	if tenant := req.URL.Query().Get("tenant"); tenant != "acme" {
+		t.Errorf("Expected the operation's tenant enum value, got %q", tenant)
+	}
+}
+
+func TestBuildRequestAccept(t *testing.T) {
+	rb := NewRequestBuilder()
+
+	p, err := parser.ParseFile("../../tests/upload-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	getFile, err := p.GetOperationDetails("/files/{fileId}", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+	updateFile, err := p.GetOperationDetails("/files/{fileId}", "PUT")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	req, err := rb.BuildRequest(getFile, "http://uploads.example.com/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	want := "application/octet-stream, application/json, application/problem+json"
+	if accept := req.Header.Get("Accept"); accept != want {
+		t.Errorf("Expected Accept %q, got %q", want, accept)
+	}
+
+	// Responses without content fall back to JSON
+	req, err = rb.BuildRequest(updateFile, "http://uploads.example.com/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if accept := req.Header.Get("Accept"); accept != DefaultAccept {
+		t.Errorf("Expected Accept %q, got %q", DefaultAccept, accept)
+	}
+
+	rb.SetAccept("application/xml")
+	req, err = rb.BuildRequest(getFile, "http://uploads.example.com/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if accept := req.Header.Get("Accept"); accept != "application/xml" {
+		t.Errorf("Expected the configured Accept header, got %q", accept)
+	}
+}
+
+func TestBuildRequestCorrelationHeader(t *testing.T) {
+	rb := NewRequestBuilder()
+	rb.SetCorrelationHeader("X-Request-Id")
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	first, err := rb.BuildRequest(opDetails, "http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	second, err := rb.BuildRequest(opDetails, "http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+
+	id := first.Header.Get("X-Request-Id")
+	if len(id) != 36 {
+		t.Errorf("Expected a UUID in X-Request-Id, got %q", id)
+	}
+	if id == second.Header.Get("X-Request-Id") {
+		t.Error("Expected a different correlation id per request")
+	}
+}
+
+func TestBuildNegativeRequest(t *testing.T) {
+	rb := NewRequestBuilder()
+
+	p, err := parser.ParseFile("../../tests/complex-schemas.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// POST /users has a request body that can be invalidated
+	opDetails, err := p.GetOperationDetails("/users", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	req, ok, err := rb.BuildNegativeRequest(opDetails, "http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to build negative request: %v", err)
+	}
+
+	if !ok {
+		t.Fatal("Expected POST /users to produce a negative request")
+	}
+
+	if req.ContentLength != 1 {
+		t.Errorf("Expected malformed body of length 1, got %d", req.ContentLength)
+	}
+
+	// GET /pets has only an optional query parameter
+	p, err = parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err = p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	_, ok, err = rb.BuildNegativeRequest(opDetails, "http://petstore.swagger.io/v1")
+	if err != nil {
+		t.Fatalf("Failed to build negative request: %v", err)
+	}
+
+	if ok {
+		t.Error("Expected GET /pets to have no negative request")
+	}
+}
+
+func TestBuildRequestWithScripts(t *testing.T) {
+	scripts, err := CompileScripts(map[string][]string{
+		"createUser": {
+			`header.X-Signature = sha256(body)`,
+			`body.source = "oas"`,
+			`query.trace = method + " " + path`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to compile scripts: %v", err)
+	}
+
+	rb := NewRequestBuilder()
+	rb.SetScripts(scripts)
+
+	p, err := parser.ParseFile("../../tests/complex-schemas.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/users", "POST")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	req, err := rb.BuildRequest(opDetails, "http://api.example.com")
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+
+	if !strings.Contains(string(body), `"source":"oas"`) {
+		t.Errorf("Expected body field set by script, got %s", body)
+	}
+
+	// The signature is computed over the final body
+	sum := sha256.Sum256(body)
+	if req.Header.Get("X-Signature") != hex.EncodeToString(sum[:]) {
+		t.Error("Expected X-Signature header to match the final body")
+	}
+
+	if req.URL.Query().Get("trace") != "POST /users" {
+		t.Errorf("Expected trace query parameter, got %q", req.URL.Query().Get("trace"))
+	}
+}
+
+func TestCompileScriptsInvalid(t *testing.T) {
+	invalid := []string{
+		"header.X-Test",
+		"cookie.session = 'x'",
+		"header.X-Test = unknownFunc()",
+	}
+
+	for _, source := range invalid {
+		if _, err := CompileScripts(map[string][]string{"op": {source}}); err == nil {
+			t.Errorf("Expected error for %q", source)
+		}
+	}
+}
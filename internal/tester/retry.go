@@ -0,0 +1,107 @@
+package tester
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/auth"
+)
+
+// DefaultRetryBackoff is the delay before the first retry
+const DefaultRetryBackoff = 200 * time.Millisecond
+
+// DefaultRetryStatuses are the statuses retried unless configured otherwise
+var DefaultRetryStatuses = []string{"502", "503", "504"}
+
+// idempotentMethods may be sent again without side effects (RFC 9110)
+var idempotentMethods = []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE"}
+
+// RetryPolicy decides which responses are retried. Statuses are configured
+// per method, so e.g. GET can be retried on 503 while POST never is.
+type RetryPolicy struct {
+	Attempts int                 // Retries after the first attempt (0 = never retry)
+	On       map[string][]string // Retried statuses ("503" or a range like "5XX") by method; other methods are never retried
+	Backoff  time.Duration       // Delay before the first retry, doubled for each further one (default DefaultRetryBackoff)
+}
+
+// IdempotentRetries retries the idempotent methods on statuses
+func IdempotentRetries(statuses []string) map[string][]string {
+	on := make(map[string][]string, len(idempotentMethods))
+	for _, method := range idempotentMethods {
+		on[method] = statuses
+	}
+	return on
+}
+
+// Validate rejects malformed statuses
+func (p RetryPolicy) Validate() error {
+	for method, statuses := range p.On {
+		for _, status := range statuses {
+			if !validStatusPattern(status) {
+				return fmt.Errorf("invalid retry status '%s' for %s: must be a status code or a range like 5XX", status, strings.ToUpper(method))
+			}
+		}
+	}
+	return nil
+}
+
+// retries reports whether a response with status to a method is retried.
+// Methods match case-insensitively, since configuration keys are lowercased
+// when loaded.
+func (p RetryPolicy) retries(method string, status int) bool {
+	for m, statuses := range p.On {
+		if strings.EqualFold(m, method) && matchesStatus(statuses, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry (1-based)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.Backoff
+	if delay <= 0 {
+		delay = DefaultRetryBackoff
+	}
+	return delay << (retry - 1)
+}
+
+// doWithRetries sends a request, retrying it as the retry policy allows. It
+// returns the final response along with the number of retries and the time
+// spent on the attempts before it, including backoff.
+func (t *Tester) doWithRetries(req *http.Request) (*http.Response, int, time.Duration, error) {
+	policy := t.config.Retry
+	start := time.Now()
+	resp, err := t.client.Do(req)
+
+	retries := 0
+	var retryTime time.Duration
+	for ; retries < policy.Attempts; retries++ {
+		if err != nil || !policy.retries(req.Method, resp.StatusCode) {
+			break
+		}
+
+		retry, ok := auth.ReplayRequest(req)
+		if !ok {
+			break
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		// Back off, unless the run is cancelled in the meantime
+		timer := time.NewTimer(policy.backoff(retries + 1))
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, retries, time.Since(start), req.Context().Err()
+		}
+
+		retryTime = time.Since(start)
+		resp, err = t.client.Do(retry)
+	}
+	return resp, retries, retryTime, err
+}
@@ -0,0 +1,49 @@
+package tester
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyRetries(t *testing.T) {
+	policy := RetryPolicy{On: map[string][]string{
+		"get": {"503", "429"},
+		"PUT": {"5xx"},
+	}}
+
+	tests := []struct {
+		method string
+		status int
+		want   bool
+	}{
+		{"GET", 503, true},
+		{"GET", 429, true},
+		{"GET", 500, false},
+		{"PUT", 502, true},
+		{"PUT", 404, false},
+		{"POST", 503, false},
+	}
+	for _, tt := range tests {
+		if got := policy.retries(tt.method, tt.status); got != tt.want {
+			t.Errorf("retries(%s, %d) = %v, want %v", tt.method, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	if err := (RetryPolicy{On: IdempotentRetries([]string{"503", "5XX"})}).Validate(); err != nil {
+		t.Errorf("Expected valid policy, got %v", err)
+	}
+	for _, status := range []string{"50", "abc", "6XX", "5X3"} {
+		if err := (RetryPolicy{On: map[string][]string{"GET": {status}}}).Validate(); err == nil {
+			t.Errorf("Expected status %q to be rejected", status)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond}
+	if got := policy.backoff(3); got != 400*time.Millisecond {
+		t.Errorf("Expected the third retry to back off 400ms, got %v", got)
+	}
+}
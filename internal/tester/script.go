@@ -0,0 +1,214 @@
+package tester
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// Script targets, applied in this order so that headers (e.g. signatures) see
+// the final body and query string
+const (
+	targetBody   = "body"
+	targetQuery  = "query"
+	targetHeader = "header"
+)
+
+var targetOrder = map[string]int{targetBody: 0, targetQuery: 1, targetHeader: 2}
+
+// script is a compiled assignment such as `header.X-Timestamp = string(unix())`
+type script struct {
+	source  string
+	target  string // body, query or header
+	name    string // field, parameter or header name
+	program *vm.Program
+}
+
+// Scripts holds compiled request mutation scripts keyed by operation id or
+// "METHOD /path". Expressions are compiled once so they are cheap enough to
+// run on every benchmark request.
+type Scripts struct {
+	byKey map[string][]script
+}
+
+// scriptEnv is the environment available to script expressions
+type scriptEnv struct {
+	Method string `expr:"method"`
+	Path   string `expr:"path"`
+	URL    string `expr:"url"`
+	Body   string `expr:"body"`
+}
+
+// scriptFuncs are the helper functions available to script expressions
+var scriptFuncs = []expr.Option{
+	expr.Function("now", func(params ...any) (any, error) {
+		return time.Now(), nil
+	}, new(func() time.Time)),
+	expr.Function("unix", func(params ...any) (any, error) {
+		return time.Now().Unix(), nil
+	}, new(func() int64)),
+	expr.Function("uuid", func(params ...any) (any, error) {
+		return newUUID()
+	}, new(func() string)),
+	expr.Function("env", func(params ...any) (any, error) {
+		return os.Getenv(params[0].(string)), nil
+	}, new(func(string) string)),
+	expr.Function("sha256", func(params ...any) (any, error) {
+		sum := sha256.Sum256([]byte(params[0].(string)))
+		return hex.EncodeToString(sum[:]), nil
+	}, new(func(string) string)),
+	expr.Function("hmacSHA256", func(params ...any) (any, error) {
+		mac := hmac.New(sha256.New, []byte(params[0].(string)))
+		mac.Write([]byte(params[1].(string)))
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}, new(func(string, string) string)),
+	expr.Function("base64", func(params ...any) (any, error) {
+		return base64.StdEncoding.EncodeToString([]byte(params[0].(string))), nil
+	}, new(func(string) string)),
+}
+
+// CompileScripts parses and compiles scripts of the form "<target>.<name> = <expr>"
+func CompileScripts(raw map[string][]string) (*Scripts, error) {
+	scripts := &Scripts{byKey: make(map[string][]script)}
+	for key, sources := range raw {
+		var compiled []script
+		for _, source := range sources {
+			s, err := compileScript(source)
+			if err != nil {
+				return nil, fmt.Errorf("script for %s: %w", key, err)
+			}
+			compiled = append(compiled, s)
+		}
+		sort.SliceStable(compiled, func(i, j int) bool {
+			return targetOrder[compiled[i].target] < targetOrder[compiled[j].target]
+		})
+		scripts.byKey[strings.ToLower(key)] = compiled
+	}
+	return scripts, nil
+}
+
+// compileScript compiles a single assignment
+func compileScript(source string) (script, error) {
+	lhs, rhs, ok := strings.Cut(source, "=")
+	if !ok {
+		return script{}, fmt.Errorf("invalid script '%s': expected <target>.<name> = <expression>", source)
+	}
+	target, name, ok := strings.Cut(strings.TrimSpace(lhs), ".")
+	if _, known := targetOrder[target]; !ok || !known || name == "" {
+		return script{}, fmt.Errorf("invalid script '%s': target must be body.<field>, query.<name> or header.<name>", source)
+	}
+
+	options := append([]expr.Option{expr.Env(scriptEnv{})}, scriptFuncs...)
+	program, err := expr.Compile(strings.TrimSpace(rhs), options...)
+	if err != nil {
+		return script{}, fmt.Errorf("invalid script '%s': %w", source, err)
+	}
+	return script{source: source, target: target, name: name, program: program}, nil
+}
+
+// forOperation returns the scripts configured for an operation
+func (s *Scripts) forOperation(opDetails *parser.OperationDetails) []script {
+	if s == nil {
+		return nil
+	}
+	var scripts []script
+	if opDetails.Operation != nil && opDetails.Operation.OperationId != "" {
+		scripts = append(scripts, s.byKey[strings.ToLower(opDetails.Operation.OperationId)]...)
+	}
+	scripts = append(scripts, s.byKey[strings.ToLower(opDetails.Method+" "+opDetails.Path)]...)
+	return scripts
+}
+
+// apply runs the operation's scripts against a built request
+func (s *Scripts) apply(req *http.Request, opDetails *parser.OperationDetails) error {
+	scripts := s.forOperation(opDetails)
+	if len(scripts) == 0 {
+		return nil
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	for _, sc := range scripts {
+		env := scriptEnv{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			URL:    req.URL.String(),
+			Body:   string(body),
+		}
+		out, err := expr.Run(sc.program, env)
+		if err != nil {
+			return fmt.Errorf("script '%s' failed: %w", sc.source, err)
+		}
+
+		switch sc.target {
+		case targetBody:
+			body, err = setBodyField(body, sc.name, out)
+			if err != nil {
+				return fmt.Errorf("script '%s' failed: %w", sc.source, err)
+			}
+		case targetQuery:
+			query := req.URL.Query()
+			query.Set(sc.name, fmt.Sprintf("%v", out))
+			req.URL.RawQuery = query.Encode()
+		case targetHeader:
+			req.Header.Set(sc.name, fmt.Sprintf("%v", out))
+		}
+	}
+
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	return nil
+}
+
+// setBodyField sets a (dot-separated) field in a JSON object body
+func setBodyField(body []byte, name string, value interface{}) ([]byte, error) {
+	obj := map[string]interface{}{}
+	if len(body) > 0 {
+		// Keep numbers exact, so large integers elsewhere in the body survive
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("request body is not a JSON object: %w", err)
+		}
+	}
+
+	parts := strings.Split(name, ".")
+	current := obj
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+
+	return json.Marshal(obj)
+}
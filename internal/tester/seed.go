@@ -0,0 +1,155 @@
+package tester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// seededResource is a resource created by a seed fixture, deleted after the run
+type seededResource struct {
+	delete    *parser.OperationDetails // DELETE operation on the item path
+	param     string                   // id parameter of the item path
+	id        string
+	serverURL string
+}
+
+// SeedResult reports the seed fixtures sent before a run
+type SeedResult struct {
+	Created   int      // fixtures the API accepted
+	Unmatched []string // fixture keys that match no operation of the spec
+}
+
+// SeedFixtures sends the configured seed fixtures (Config.Fixtures) before the
+// run, so list endpoints return data instead of empty arrays that trivially
+// pass validation. The id of each created resource, read from the response or
+// the fixture, is deleted by CleanupFixtures, and the first id of a collection
+// is used for its item path parameters. A fixture the API rejects stops the
+// seeding with an error; the resources created until then are still deleted.
+func (t *Tester) SeedFixtures(operations []models.Operation, p *parser.Parser) (SeedResult, error) {
+	var result SeedResult
+	matched := make(map[string]bool)
+	for _, op := range operations {
+		key, bodies, ok := t.fixturesFor(op)
+		if !ok {
+			continue
+		}
+		matched[key] = true
+		details, err := p.GetOperationDetails(op.Path, op.Method)
+		if err != nil {
+			return result, fmt.Errorf("seed %s: %w", key, err)
+		}
+		deleteOp, paramName, deletable := p.ItemOperation(op.Path, http.MethodDelete)
+		if !deletable {
+			_, paramName, _ = p.ItemOperation(op.Path, http.MethodGet)
+		}
+
+		for i, body := range bodies {
+			id, err := t.sendFixture(details, op.ServerURL, []byte(body), paramName)
+			if err != nil {
+				return result, fmt.Errorf("seed %s #%d: %w", key, i+1, err)
+			}
+			result.Created++
+			if id == "" {
+				continue
+			}
+			if paramName != "" && i == 0 {
+				t.requestBuilder.SeedEntityValue(op.Path, paramName, id)
+			}
+			if deletable {
+				t.mu.Lock()
+				t.seeded = append(t.seeded, seededResource{delete: deleteOp, param: paramName, id: id, serverURL: op.ServerURL})
+				t.mu.Unlock()
+			}
+		}
+	}
+
+	for key := range t.config.Fixtures {
+		if !matched[key] {
+			result.Unmatched = append(result.Unmatched, key)
+		}
+	}
+	sort.Strings(result.Unmatched)
+	return result, nil
+}
+
+// fixturesFor returns the seed fixtures configured for an operation, keyed by
+// operation id or "METHOD /path" like assertions
+func (t *Tester) fixturesFor(op models.Operation) (string, []string, bool) {
+	for key, bodies := range t.config.Fixtures {
+		if (op.OperationID != "" && strings.EqualFold(key, op.OperationID)) ||
+			strings.EqualFold(key, op.Method+" "+op.Path) {
+			return key, bodies, true
+		}
+	}
+	return "", nil, false
+}
+
+// sendFixture sends one fixture body and returns the id of the created
+// resource, if the response or the fixture names one
+func (t *Tester) sendFixture(details *parser.OperationDetails, serverURL string, body []byte, paramName string) (string, error) {
+	req, err := t.requestBuilder.BuildRequestWithOverrides(details, serverURL, RequestOverrides{RawBody: body})
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req = t.forOperation(req, models.Operation{Method: details.Method, Path: details.Path})
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("%s %s returned status %d", details.Method, details.Path, resp.StatusCode)
+	}
+
+	for _, data := range [][]byte{respBody, body} {
+		var obj interface{}
+		if decodeJSON(bytes.NewReader(data), &obj) != nil {
+			continue
+		}
+		if ids := harvestIDs([]interface{}{obj}, paramName); len(ids) > 0 {
+			return fmt.Sprint(ids[0]), nil
+		}
+	}
+	return "", nil
+}
+
+// CleanupFixtures deletes the resources created by SeedFixtures, newest first.
+// It runs even after the run was cancelled. A 404 counts as deleted.
+func (t *Tester) CleanupFixtures() (deleted, failed int) {
+	t.mu.Lock()
+	seeded := t.seeded
+	t.seeded = nil
+	t.mu.Unlock()
+
+	ctx := context.WithoutCancel(t.config.Context)
+	for i := len(seeded) - 1; i >= 0; i-- {
+		r := seeded[i]
+		req, err := t.requestBuilder.BuildRequestWithParams(r.delete, r.serverURL, map[string]string{r.param: r.id})
+		if err != nil {
+			failed++
+			continue
+		}
+		req = t.forOperation(req.WithContext(ctx), models.Operation{Method: r.delete.Method, Path: r.delete.Path})
+		resp, err := t.client.Do(req)
+		if err != nil {
+			failed++
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 || resp.StatusCode == http.StatusNotFound {
+			deleted++
+		} else {
+			failed++
+		}
+	}
+	return deleted, failed
+}
@@ -0,0 +1,100 @@
+package tester
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+const seedSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {"operationId": "createPet", "requestBody": {"content": {"application/json": {"schema": {"type": "object"}}}}, "responses": {"201": {"description": "created"}}}
+    },
+    "/pets/{petId}": {
+      "get": {"operationId": "showPet", "parameters": [{"name": "petId", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "ok"}}},
+      "delete": {"operationId": "deletePet", "parameters": [{"name": "petId", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"204": {"description": "deleted"}}}
+    }
+  }
+}`
+
+func TestSeedFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pets.json")
+	if err := os.WriteFile(path, []byte(seedSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		n := len(requests)
+		mu.Unlock()
+		switch r.Method {
+		case http.MethodPost:
+			var pet map[string]interface{}
+			json.Unmarshal(body, &pet)
+			pet["id"] = n * 10
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(pet)
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	operations, err := p.GetOperations(server.URL)
+	if err != nil {
+		t.Fatalf("GetOperations: %v", err)
+	}
+	config := DefaultConfig()
+	config.Fixtures = map[string][]string{
+		"createpet":    {`{"name": "Rex"}`, `{"name": "Tom"}`},
+		"POST /owners": {`{}`},
+	}
+	tst := NewTesterWithConfig(config)
+
+	result, err := tst.SeedFixtures(operations, p)
+	if err != nil {
+		t.Fatalf("SeedFixtures: %v", err)
+	}
+	if result.Created != 2 || len(result.Unmatched) != 1 || result.Unmatched[0] != "POST /owners" {
+		t.Errorf("Expected 2 created and POST /owners unmatched, got %+v", result)
+	}
+
+	// Item paths use the first seeded id
+	for _, op := range operations {
+		if op.OperationID == "showPet" {
+			if _, err := tst.TestOperation(op, p); err != nil {
+				t.Fatalf("TestOperation: %v", err)
+			}
+		}
+	}
+
+	deleted, failed := tst.CleanupFixtures()
+	if deleted != 2 || failed != 0 {
+		t.Errorf("Expected 2 deleted, got %d (%d failed)", deleted, failed)
+	}
+	want := "POST /pets, POST /pets, GET /pets/10, DELETE /pets/20, DELETE /pets/10"
+	if got := strings.Join(requests, ", "); got != want {
+		t.Errorf("Expected requests %s, got %s", want, got)
+	}
+}
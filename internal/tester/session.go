@@ -0,0 +1,123 @@
+package tester
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// LoginConfig describes a login request executed once before a run for
+// session-based APIs
+type LoginConfig struct {
+	OperationID string // operation that performs the login
+	Body        string // raw request body carrying the credentials
+	ContentType string // defaults to application/json
+	UserAgent   string // defaults to DefaultUserAgent
+	HostHeader  string // virtual host presented instead of the URL's host
+}
+
+// Login executes the login operation and stores the session cookies it sets in
+// jar. Clients sharing the jar then send the session with every request.
+func Login(p *parser.Parser, serverURL string, login LoginConfig, jar http.CookieJar, timeout time.Duration) error {
+	operations, err := p.GetOperations(serverURL)
+	if err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+
+	var opDetails *parser.OperationDetails
+	for _, op := range operations {
+		if op.OperationID == login.OperationID {
+			opDetails, err = p.GetOperationDetails(op.Path, op.Method)
+			if err != nil {
+				return fmt.Errorf("login: %w", err)
+			}
+			break
+		}
+	}
+	if opDetails == nil {
+		return fmt.Errorf("login: operation '%s' not found", login.OperationID)
+	}
+
+	rb := NewRequestBuilder()
+	rb.SetUserAgent(login.UserAgent)
+	rb.SetHostHeader(login.HostHeader)
+	req, err := rb.BuildRequest(opDetails, serverURL)
+	if err != nil {
+		return fmt.Errorf("login: failed to build request: %w", err)
+	}
+
+	// Replace any generated body with the configured credentials
+	if login.Body != "" {
+		body := []byte(login.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		contentType := login.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	client := &http.Client{Timeout: timeout, Jar: jar}
+	if login.HostHeader != "" {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{ServerName: ServerName(login.HostHeader)}
+		client.Transport = transport
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("login: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("login: %s %s returned status %d", opDetails.Method, opDetails.Path, resp.StatusCode)
+	}
+	if len(jar.Cookies(req.URL)) == 0 {
+		return fmt.Errorf("login: %s %s did not set a session cookie", opDetails.Method, opDetails.Path)
+	}
+	return nil
+}
+
+// hostJar keys the cookies of a jar on a virtual host instead of the host of
+// the request URL
+type hostJar struct {
+	http.CookieJar
+	host string
+}
+
+// HostCookieJar returns a jar that stores and sends cookies for host, the
+// Host header requests present, whatever host their URL targets. A server
+// behind an IP or load balancer address scopes its cookies to the virtual
+// host, e.g. with Domain=api.example.com, which a jar keyed on the URL would
+// reject. Without a host, jar is returned as is.
+func HostCookieJar(jar http.CookieJar, host string) http.CookieJar {
+	if host == "" {
+		return jar
+	}
+	return hostJar{CookieJar: jar, host: host}
+}
+
+func (j hostJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.CookieJar.SetCookies(j.url(u), cookies)
+}
+
+func (j hostJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.CookieJar.Cookies(j.url(u))
+}
+
+// url returns u with the virtual host
+func (j hostJar) url(u *url.URL) *url.URL {
+	v := *u
+	v.Host = j.host
+	return &v
+}
@@ -0,0 +1,89 @@
+package tester
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+// healthSegments are last path segments that conventionally name health,
+// liveness and readiness endpoints
+var healthSegments = []string{
+	"health", "healthz", "healthcheck", "_health",
+	"live", "livez", "liveness", "alive",
+	"ready", "readyz", "readiness",
+	"ping", "heartbeat", "status",
+}
+
+// IsHealthEndpoint reports whether an operation looks like a health check: a
+// GET whose last path segment, operation ID or tag names one
+func IsHealthEndpoint(op models.Operation) bool {
+	if op.Method != http.MethodGet || strings.Contains(op.Path, "{") {
+		return false
+	}
+
+	path := strings.TrimRight(op.Path, "/")
+	segment := strings.ToLower(path[strings.LastIndex(path, "/")+1:])
+	if slices.Contains(healthSegments, segment) {
+		return true
+	}
+
+	id := strings.ToLower(op.OperationID)
+	for _, word := range []string{"health", "liveness", "readiness"} {
+		if strings.Contains(id, word) {
+			return true
+		}
+	}
+	for _, tag := range op.Tags {
+		if strings.EqualFold(tag, "health") {
+			return true
+		}
+	}
+	return false
+}
+
+// SmokeOperations selects the operations of a smoke run, in spec order: the
+// health endpoints, the operations named in include (by operation ID or
+// path) and the first gets GET operations without path parameters. It also
+// returns the entries of include that match no operation.
+func SmokeOperations(operations []models.Operation, include []string, gets int) ([]models.Operation, []string) {
+	matched := make(map[string]bool)
+	selected := make([]bool, len(operations))
+	for i, op := range operations {
+		for _, name := range include {
+			if name == op.OperationID || name == op.Path {
+				selected[i] = true
+				matched[name] = true
+			}
+		}
+		if IsHealthEndpoint(op) {
+			selected[i] = true
+		}
+	}
+
+	for i, op := range operations {
+		if gets <= 0 {
+			break
+		}
+		if !selected[i] && op.Method == http.MethodGet && !strings.Contains(op.Path, "{") {
+			selected[i] = true
+			gets--
+		}
+	}
+
+	var ops []models.Operation
+	for i, op := range operations {
+		if selected[i] {
+			ops = append(ops, op)
+		}
+	}
+	var unmatched []string
+	for _, name := range include {
+		if !matched[name] {
+			unmatched = append(unmatched, name)
+		}
+	}
+	return ops, unmatched
+}
@@ -0,0 +1,56 @@
+package tester
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moamenhredeen/oas/internal/models"
+)
+
+func TestIsHealthEndpoint(t *testing.T) {
+	tests := []struct {
+		op   models.Operation
+		want bool
+	}{
+		{models.Operation{Method: "GET", Path: "/health"}, true},
+		{models.Operation{Method: "GET", Path: "/api/v1/healthz/"}, true},
+		{models.Operation{Method: "GET", Path: "/actuator/health"}, true},
+		{models.Operation{Method: "GET", Path: "/readyz"}, true},
+		{models.Operation{Method: "GET", Path: "/internal/check", OperationID: "getHealthStatus"}, true},
+		{models.Operation{Method: "GET", Path: "/internal/check", Tags: []string{"Health"}}, true},
+		{models.Operation{Method: "POST", Path: "/health"}, false},
+		{models.Operation{Method: "GET", Path: "/orders/{orderId}/status"}, false},
+		{models.Operation{Method: "GET", Path: "/pets"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsHealthEndpoint(tt.op); got != tt.want {
+			t.Errorf("IsHealthEndpoint(%s %s) = %v, want %v", tt.op.Method, tt.op.Path, got, tt.want)
+		}
+	}
+}
+
+func TestSmokeOperations(t *testing.T) {
+	operations := []models.Operation{
+		{Method: "GET", Path: "/pets", OperationID: "listPets"},
+		{Method: "POST", Path: "/pets", OperationID: "createPets"},
+		{Method: "GET", Path: "/pets/{petId}", OperationID: "showPetById"},
+		{Method: "GET", Path: "/owners", OperationID: "listOwners"},
+		{Method: "GET", Path: "/version", OperationID: "getVersion"},
+		{Method: "GET", Path: "/healthz", OperationID: "healthz"},
+	}
+
+	ops, unmatched := SmokeOperations(operations, []string{"showPetById", "missing"}, 1)
+
+	var ids []string
+	for _, op := range ops {
+		ids = append(ids, op.OperationID)
+	}
+	want := []string{"listPets", "showPetById", "healthz"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"missing"}) {
+		t.Errorf("Expected missing to be reported, got %v", unmatched)
+	}
+}
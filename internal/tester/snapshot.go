@@ -0,0 +1,134 @@
+package tester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/normalize"
+)
+
+// Snapshot outcomes recorded in test results
+const (
+	SnapshotCreated = "created" // no snapshot existed, the response was stored
+	SnapshotMatched = "matched" // the response matched its snapshot
+	SnapshotChanged = "changed" // the response differs from its snapshot
+	SnapshotUpdated = "updated" // the snapshot was rewritten (SnapshotConfig.Update)
+)
+
+// maxSnapshotDiffs is the number of differences reported per response
+const maxSnapshotDiffs = 10
+
+// SnapshotConfig configures golden-file snapshots of responses
+type SnapshotConfig struct {
+	Dir    string   // Directory of snapshot files; empty disables snapshots
+	Update bool     // Rewrite snapshots with the current responses instead of comparing
+	Ignore []string // Fields masked in snapshots only, in addition to Config.Normalizer's rules
+}
+
+// snapshot is the normalized response stored in a snapshot file
+type snapshot struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body"` // parsed JSON, or the body as text
+}
+
+// snapshotPath returns the snapshot file of an operation and case
+func (t *Tester) snapshotPath(op models.Operation, c Case) string {
+	return filepath.Join(t.config.Snapshot.Dir, caseFileName(op, c)+".json")
+}
+
+// normalizeSnapshot builds the snapshot of a response, normalized by the
+// snapshot normalizer
+func (t *Tester) normalizeSnapshot(status int, body []byte) snapshot {
+	snap := snapshot{Status: status}
+	var data interface{}
+	if err := decodeJSON(bytes.NewReader(body), &data); err != nil {
+		snap.Body = string(body)
+		return snap
+	}
+	snap.Body = t.snapshots.Apply(data)
+	return snap
+}
+
+// checkSnapshot compares a response with its snapshot, creating the snapshot
+// on the first run. A snapshot is only written for a response that passed
+// validation, so a broken response never becomes the reference.
+func (t *Tester) checkSnapshot(op models.Operation, c Case, status int, body []byte, valid bool, result *models.TestResult) []models.ValidationError {
+	path := t.snapshotPath(op, c)
+	current := t.normalizeSnapshot(status, body)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || t.config.Snapshot.Update {
+		if !valid {
+			return nil
+		}
+		if err := writeSnapshot(path, current); err != nil {
+			return []models.ValidationError{{Field: "snapshot", Message: err.Error()}}
+		}
+		result.Snapshot = SnapshotCreated
+		if t.config.Snapshot.Update {
+			result.Snapshot = SnapshotUpdated
+		}
+		return nil
+	}
+	if err != nil {
+		return []models.ValidationError{{Field: "snapshot", Message: fmt.Sprintf("reading snapshot: %v", err)}}
+	}
+
+	var stored snapshot
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&stored); err != nil {
+		return []models.ValidationError{{Field: "snapshot", Message: fmt.Sprintf("invalid snapshot %s: %v", path, err)}}
+	}
+
+	// Round-trip the current response so numbers compare like the stored ones
+	var expected, actual interface{}
+	roundTrip(stored, &expected)
+	roundTrip(current, &actual)
+
+	diffs := normalize.Diff("", expected, actual)
+	if len(diffs) == 0 {
+		result.Snapshot = SnapshotMatched
+		return nil
+	}
+
+	result.Snapshot = SnapshotChanged
+	errors := make([]models.ValidationError, 0, maxSnapshotDiffs+1)
+	for i, diff := range diffs {
+		if i == maxSnapshotDiffs {
+			errors = append(errors, models.ValidationError{Field: "snapshot", Message: fmt.Sprintf("and %d more differences", len(diffs)-maxSnapshotDiffs)})
+			break
+		}
+		errors = append(errors, models.ValidationError{Field: "snapshot", Message: diff})
+	}
+	return errors
+}
+
+// writeSnapshot stores a snapshot as indented JSON with sorted keys, so
+// snapshot changes read well in code review
+func writeSnapshot(path string, snap snapshot) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snap); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// roundTrip converts v to its generic JSON form
+func roundTrip(v interface{}, out *interface{}) {
+	data, _ := json.Marshal(v)
+	decodeJSON(bytes.NewReader(data), out)
+}
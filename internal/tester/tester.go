@@ -1,12 +1,25 @@
 package tester
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/moamenhredeen/oas/internal/auth"
+	"github.com/moamenhredeen/oas/internal/grpcgw"
+	"github.com/moamenhredeen/oas/internal/inventory"
+	"github.com/moamenhredeen/oas/internal/mask"
 	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/normalize"
 	"github.com/moamenhredeen/oas/internal/parser"
 )
 
@@ -32,29 +45,170 @@ type TestEvent struct {
 // OnTestEvent is a callback function for test events
 type OnTestEvent func(event TestEvent)
 
+// Config holds tester configuration
+type Config struct {
+	Timeout     time.Duration // Per-request timeout
+	MaxFailures int           // Stop the run after this many failures (0 = unlimited)
+	Repeat      int           // Number of times each operation is run (flakiness detection)
+	Retry       RetryPolicy   // Which responses are sent again before the result is recorded
+
+	Strictness    Strictness // How thoroughly responses are validated
+	NegativeTests bool       // Also send invalid requests and expect 4xx responses
+	MaxArrayItems int        // Items validated per response array, sampled evenly (0 = all)
+
+	Expectations map[string]MethodExpectation // Conventional responses by method for undocumented statuses
+
+	ParamValues map[string]interface{} // Fixed parameter values by name or JSON pointer
+	PrefetchIDs bool                   // Harvest real ids from collection endpoints for item paths
+	Unicode     bool                   // Generate unicode edge cases for free-form string fields
+	Assertions  map[string][]string    // Response assertions keyed by operation id or "METHOD /path"
+	Fixtures    map[string][]string    // JSON bodies sent before the run (see SeedFixtures), keyed like Assertions
+	Scripts     *Scripts               // Compiled request mutation scripts
+	Validators  []ValidatorPlugin      // Custom response checks run after the spec validation (see LoadValidators)
+
+	Authenticator auth.Authenticator    // Credentials applied to every request
+	DigestAuth    *auth.UserCredentials // Answer HTTP Digest challenges with these credentials
+	CookieJar     http.CookieJar        // Shared session cookies (see Login)
+
+	Inventory *inventory.Recorder // Records the hosts, endpoints and credentials requests exercise
+
+	CorrelationHeader string // Header carrying a unique id per request (e.g. X-Request-Id)
+	UserAgent         string // User-Agent sent with every request (default: DefaultUserAgent)
+	HostHeader        string // Host header (and TLS server name) presented instead of the URL's host
+	Accept            string // Accept header for every request (default: the operation's response media types)
+	AddressFamily     string // Restrict connections to AddressFamilyIPv4 or AddressFamilyIPv6
+
+	SaveFailuresDir string                // Write the request and response of failing tests to this directory
+	Snapshot        SnapshotConfig        // Compare responses with snapshots stored on the first run
+	Normalizer      *normalize.Normalizer // Applied to bodies before snapshot and gRPC comparisons
+
+	CallbackListen  string        // Address the callback listener binds (default 127.0.0.1:0)
+	CallbackURL     string        // Base URL the API reaches the callback listener at (default: its address)
+	CallbackTimeout time.Duration // How long to wait for the callbacks of an operation (default DefaultCallbackTimeout)
+
+	GRPC    *grpcgw.Client // Compare GET operations of a grpc-gateway with the gRPC methods behind them
+	GraphQL bool           // Introspect POST /graphql operations and test a query per field
+
+	EnumCases     bool  // Test each value of enum-valued parameters and body fields
+	Pairwise      bool  // Test pairwise combinations of enum-valued parameters and body fields
+	MaxEnumValues int   // Enums with more values are left to the generator (0 = no limit)
+	Cases         int   // Differently seeded requests per operation (0 or 1 = one)
+	Seed          int64 // Base seed the per-case seeds derive from
+
+	OptionalParams float64 // Probability of sending each optional query parameter (0 = required ones only)
+
+	Context context.Context // Cancels the run and its requests, e.g. at a deadline (default: never)
+}
+
+// DefaultConfig returns default tester configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       30 * time.Second,
+		MaxFailures:   0,
+		Repeat:        1,
+		MaxArrayItems: DefaultMaxArrayItems,
+		MaxEnumValues: DefaultMaxEnumValues,
+	}
+}
+
 // Tester executes API tests based on OpenAPI specifications
 type Tester struct {
+	config         Config
 	requestBuilder *RequestBuilder
 	validator      *Validator
 	client         *http.Client
+
+	mu         sync.Mutex
+	prefetched map[string]bool  // collection paths already harvested for ids
+	seeded     []seededResource // resources created by seed fixtures (see SeedFixtures)
+
+	callbacks *callbackListener     // receives callbacks and webhooks (see StartCallbacks)
+	snapshots *normalize.Normalizer // Config.Normalizer plus the snapshot-only masks
 }
 
 // NewTester creates a new tester instance with configurable timeout
 func NewTester(timeout time.Duration) *Tester {
-	if timeout <= 0 {
-		timeout = 30 * time.Second
+	config := DefaultConfig()
+	config.Timeout = timeout
+	return NewTesterWithConfig(config)
+}
+
+// NewTesterWithConfig creates a new tester instance from a full configuration
+func NewTesterWithConfig(config Config) *Tester {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.Repeat < 1 {
+		config.Repeat = 1
+	}
+	if config.CallbackTimeout <= 0 {
+		config.CallbackTimeout = DefaultCallbackTimeout
+	}
+	if config.Context == nil {
+		config.Context = context.Background()
+	}
+	requestBuilder := NewRequestBuilder()
+	requestBuilder.SetContext(config.Context)
+	if len(config.ParamValues) > 0 {
+		requestBuilder.SetParamValues(config.ParamValues)
+	}
+	requestBuilder.SetUnicode(config.Unicode)
+	requestBuilder.SetScripts(config.Scripts)
+	requestBuilder.SetAuthenticator(config.Authenticator)
+	requestBuilder.SetCorrelationHeader(config.CorrelationHeader)
+	requestBuilder.SetUserAgent(config.UserAgent)
+	requestBuilder.SetHostHeader(config.HostHeader)
+	requestBuilder.SetAccept(config.Accept)
+	requestBuilder.SetOptionalParams(config.OptionalParams)
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if config.HostHeader != "" || config.AddressFamily != AddressFamilyAny {
+		custom := http.DefaultTransport.(*http.Transport).Clone()
+		if config.HostHeader != "" {
+			// Present the virtual host during the TLS handshake as well
+			custom.TLSClientConfig = &tls.Config{ServerName: ServerName(config.HostHeader)}
+		}
+		custom.DialContext = RestrictAddressFamily(custom.DialContext, config.AddressFamily)
+		transport = custom
+	}
+	if config.Inventory != nil {
+		transport = config.Inventory.Transport(transport)
+	}
+	if config.DigestAuth != nil {
+		transport = auth.NewDigestTransport(*config.DigestAuth, transport)
+	}
+
+	validator := NewValidatorWithStrictness(config.Strictness)
+	validator.SetMaxArrayItems(config.MaxArrayItems)
+	validator.SetExpectations(config.Expectations)
+
+	// Callers validate the snapshot masks; invalid ones are left out
+	snapshots := config.Normalizer
+	if extended, err := config.Normalizer.WithMask(config.Snapshot.Ignore...); err == nil {
+		snapshots = extended
 	}
+
 	return &Tester{
-		requestBuilder: NewRequestBuilder(),
-		validator:      NewValidator(),
+		config:         config,
+		requestBuilder: requestBuilder,
+		validator:      validator,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   config.Timeout,
+			Transport: transport,
+			Jar:       config.CookieJar,
 		},
+		prefetched: make(map[string]bool),
+		snapshots:  snapshots,
 	}
 }
 
 // TestOperation tests a single API operation
 func (t *Tester) TestOperation(op models.Operation, parser *parser.Parser) (models.TestResult, error) {
+	return t.testOperationCase(op, parser, Case{})
+}
+
+// testOperationCase tests an operation with the case's parameter values
+func (t *Tester) testOperationCase(op models.Operation, parser *parser.Parser, c Case) (models.TestResult, error) {
 	result := models.TestResult{
 		Path:        op.Path,
 		Method:      op.Method,
@@ -69,26 +223,81 @@ func (t *Tester) TestOperation(op models.Operation, parser *parser.Parser) (mode
 		return result, nil
 	}
 
+	// Seed item paths with real ids from their collection endpoint
+	if t.config.PrefetchIDs {
+		t.prefetchIDs(opDetails, op.ServerURL, parser)
+	}
+
 	// Build request
-	req, err := t.requestBuilder.BuildRequest(opDetails, op.ServerURL)
+	if c.Seed != 0 {
+		t.requestBuilder.SetSeed(c.Seed)
+	}
+	overrides := RequestOverrides{Params: c.Params, Body: c.Body, RequiredOnly: c.RequiredOnly}
+	var callbacks []*expectedCallback
+	if t.callbacks != nil {
+		listener := t.callbacks
+		overrides, callbacks, err = listener.expect(opDetails.Callbacks(), overrides)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to register callbacks: %v", err)
+			return result, nil
+		}
+		// Stop accepting the callbacks however the test ends
+		defer listener.forget(callbacks)
+	}
+	req, err := t.requestBuilder.BuildRequestWithOverrides(opDetails, op.ServerURL, overrides)
 	if err != nil {
 		result.Error = fmt.Sprintf("failed to build request: %v", err)
 		return result, nil
 	}
+	if t.config.CorrelationHeader != "" {
+		result.RequestID = req.Header.Get(t.config.CorrelationHeader)
+	}
+	req = t.forOperation(req, op)
+
+	// Keep the exchange of failing tests for debugging
+	var resp *http.Response
+	var body []byte
+	if t.config.SaveFailuresDir != "" {
+		defer func() {
+			if !result.Passed {
+				t.saveFailure(op, c, req, resp, body, result.Error)
+			}
+		}()
+	}
+
+	// Record which address the connection used
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			result.RemoteAddr = info.Conn.RemoteAddr().String()
+			result.AddressFamily = AddressFamilyOf(info.Conn.RemoteAddr())
+		},
+	}))
 
 	// Execute request
 	startTime := time.Now()
-	resp, err := t.client.Do(req)
-	result.ResponseTime = time.Since(startTime)
+	var retryTime time.Duration
+	resp, result.Retries, retryTime, err = t.doWithRetries(req)
+	result.ResponseTime = time.Since(startTime) - retryTime
+	result.RetryTime = retryTime
 
 	if err != nil {
 		result.Error = fmt.Sprintf("request failed: %v", err)
+		result.ErrorClass = ClassifyError(err)
 		return result, nil
 	}
 	defer resp.Body.Close()
 
 	result.StatusCode = resp.StatusCode
 
+	// Buffer the body so it can be inspected after schema validation
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read response body: %v", err)
+		result.ErrorClass = ClassifyError(err)
+		return result, nil
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
 	// Validate response
 	validationErrors, err := t.validator.ValidateResponse(resp, opDetails)
 	if err != nil {
@@ -96,6 +305,40 @@ func (t *Tester) TestOperation(op models.Operation, parser *parser.Parser) (mode
 		return result, nil
 	}
 
+	// Evaluate configured assertions
+	validationErrors = append(validationErrors, t.evaluateAssertions(op, body)...)
+
+	// Run the custom validator plugins
+	validationErrors = append(validationErrors, t.runValidators(op, req, resp, body)...)
+
+	// Compare with the gRPC method behind a grpc-gateway operation
+	if t.config.GRPC != nil {
+		validationErrors = append(validationErrors, t.compareGRPC(op, req, resp.StatusCode, body, &result)...)
+	}
+
+	// Wait for the callbacks the request registered, unless it was rejected
+	if len(callbacks) > 0 && resp.StatusCode < 300 {
+		callbackErrors, received := t.callbacks.await(callbacks, t.config.CallbackTimeout)
+		validationErrors = append(validationErrors, callbackErrors...)
+		result.Callbacks = received
+	}
+
+	// Compare the response with its snapshot, storing it on the first run
+	if t.config.Snapshot.Dir != "" {
+		validationErrors = append(validationErrors, t.checkSnapshot(op, c, resp.StatusCode, body, len(validationErrors) == 0, &result)...)
+	}
+
+	// Negative test: invalid input should be rejected with a client error
+	if t.config.NegativeTests {
+		negativeErrors, err := t.runNegativeTest(opDetails, op.ServerURL)
+		if err != nil {
+			result.Error = fmt.Sprintf("negative test error: %v", err)
+			result.ErrorClass = ClassifyError(err)
+			return result, nil
+		}
+		validationErrors = append(validationErrors, negativeErrors...)
+	}
+
 	result.ValidationErrors = validationErrors
 
 	// Check if validation passed
@@ -107,9 +350,155 @@ func (t *Tester) TestOperation(op models.Operation, parser *parser.Parser) (mode
 			errorMsgs = append(errorMsgs, fmt.Sprintf("%s: %s", ve.Field, ve.Message))
 		}
 		result.Error = fmt.Sprintf("validation failed: %s", strings.Join(errorMsgs, "; "))
+		result.ErrorClass = failureClass(resp.StatusCode)
 	}
 
-	return result, nil
+	return maskResult(result), nil
+}
+
+// forOperation marks a request with the operation it exercises, for the
+// inventory
+func (t *Tester) forOperation(req *http.Request, op models.Operation) *http.Request {
+	if t.config.Inventory == nil {
+		return req
+	}
+	return req.WithContext(inventory.WithOperation(req.Context(), op))
+}
+
+// maskResult redacts registered secrets from a result's messages
+func maskResult(result models.TestResult) models.TestResult {
+	result.Error = mask.String(result.Error)
+	for i := range result.ValidationErrors {
+		result.ValidationErrors[i].Message = mask.String(result.ValidationErrors[i].Message)
+	}
+	return result
+}
+
+// assertionsFor returns the assertions configured for an operation. Keys match
+// case-insensitively, since configuration keys are lowercased when loaded.
+func (t *Tester) assertionsFor(op models.Operation) []string {
+	var exprs []string
+	for key, list := range t.config.Assertions {
+		if (op.OperationID != "" && strings.EqualFold(key, op.OperationID)) ||
+			strings.EqualFold(key, op.Method+" "+op.Path) {
+			exprs = append(exprs, list...)
+		}
+	}
+	return exprs
+}
+
+// evaluateAssertions checks the configured assertions against a response body
+func (t *Tester) evaluateAssertions(op models.Operation, body []byte) []models.ValidationError {
+	exprs := t.assertionsFor(op)
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	var errors []models.ValidationError
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return []models.ValidationError{{
+			Field:   "assertion",
+			Message: fmt.Sprintf("cannot evaluate assertions, response is not JSON: %v", err),
+		}}
+	}
+
+	for _, expr := range exprs {
+		assertion, err := ParseAssertion(expr)
+		if err != nil {
+			errors = append(errors, models.ValidationError{Field: "assertion", Message: err.Error()})
+			continue
+		}
+		if failure := assertion.Evaluate(data); failure != "" {
+			errors = append(errors, models.ValidationError{
+				Field:   "assertion",
+				Message: fmt.Sprintf("%s: %s", expr, failure),
+			})
+		}
+	}
+	return errors
+}
+
+// runNegativeTest sends a request with invalid input and expects a 4xx response
+// whose body honors the documented error contract
+func (t *Tester) runNegativeTest(opDetails *parser.OperationDetails, serverURL string) ([]models.ValidationError, error) {
+	req, ok, err := t.requestBuilder.BuildNegativeRequest(opDetails, serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	req = t.forOperation(req, models.Operation{Method: opDetails.Method, Path: opDetails.Path})
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 400 || resp.StatusCode >= 500 {
+		return []models.ValidationError{{
+			Field:   "negative",
+			Message: fmt.Sprintf("invalid request was not rejected with a 4xx status (got %d)", resp.StatusCode),
+		}}, nil
+	}
+
+	// Validate the error response against its schema (4xx, 4XX or default).
+	// Any 4xx status is accepted here, documented or not.
+	validationErrors, err := t.validator.ValidateResponse(resp, opDetails)
+	if err != nil {
+		return nil, err
+	}
+	var errors []models.ValidationError
+	for _, ve := range validationErrors {
+		if ve.Field == "status_code" {
+			continue
+		}
+		ve.Field = "negative." + ve.Field
+		errors = append(errors, ve)
+	}
+	return errors, nil
+}
+
+// testOperationRepeated runs an operation Repeat times and folds the runs into
+// a single result. The first failing run is reported; an operation that both
+// passes and fails across runs is marked flaky.
+func (t *Tester) testOperationRepeated(op models.Operation, parser *parser.Parser, c Case) models.TestResult {
+	var reported models.TestResult
+	passCount := 0
+	haveFailure := false
+
+	for run := 0; run < t.config.Repeat; run++ {
+		result, err := t.testOperationCase(op, parser, c)
+		if err != nil {
+			result.Error = fmt.Sprintf("test execution error: %v", err)
+			result.Passed = false
+		}
+		result = maskResult(result)
+
+		if result.Passed {
+			passCount++
+			if !haveFailure {
+				reported = result
+			}
+		} else if !haveFailure {
+			reported = result
+			haveFailure = true
+		}
+	}
+
+	if t.config.Repeat > 1 {
+		reported.Runs = t.config.Repeat
+		reported.PassCount = passCount
+		reported.Passed = passCount == t.config.Repeat
+		if passCount > 0 && passCount < t.config.Repeat {
+			reported.Flaky = true
+			reported.Flakiness = float64(t.config.Repeat-passCount) / float64(t.config.Repeat) * 100
+		}
+	}
+
+	return reported
 }
 
 // TestOperations tests multiple operations with optional live event reporting
@@ -117,18 +506,34 @@ func (t *Tester) TestOperations(operations []models.Operation, parser *parser.Pa
 	summary := models.TestSummary{
 		Results: make([]models.TestResult, 0, len(operations)),
 	}
+	if t.config.Cases > 1 {
+		summary.Seed = t.config.Seed
+	}
 	total := len(operations)
 
 	for i, op := range operations {
+		// Stop once the run's context is done, e.g. at its deadline
+		if err := t.config.Context.Err(); err != nil {
+			reason := models.SkipStopped
+			if errors.Is(err, context.DeadlineExceeded) {
+				reason = models.SkipDeadline
+			}
+			summary.Stopped = true
+			summary.Skipped = total - i
+			summary.SkippedOperations = append(summary.SkippedOperations, models.SkipOperations(operations[i:], reason)...)
+			break
+		}
+
 		// Report: test is starting
 		if onEvent != nil {
 			onEvent(TestEvent{Type: EventStarting, Operation: op, Index: i, Total: total})
 		}
 
-		result, err := t.TestOperation(op, parser)
-		if err != nil {
-			result.Error = fmt.Sprintf("test execution error: %v", err)
-			result.Passed = false
+		var result models.TestResult
+		if t.config.GraphQL && isGraphQL(op) {
+			result = t.testGraphQL(op, parser)
+		} else {
+			result = t.testOperationCases(op, parser)
 		}
 		summary.AddResult(result)
 
@@ -136,6 +541,23 @@ func (t *Tester) TestOperations(operations []models.Operation, parser *parser.Pa
 		if onEvent != nil {
 			onEvent(TestEvent{Type: EventCompleted, Operation: op, Result: &result, Index: i, Total: total})
 		}
+
+		// Stop early once the failure budget is exhausted
+		if t.config.MaxFailures > 0 && summary.Failed >= t.config.MaxFailures {
+			summary.Stopped = true
+			summary.Skipped = total - (i + 1)
+			summary.SkippedOperations = append(summary.SkippedOperations, models.SkipOperations(operations[i+1:], models.SkipStopped)...)
+			break
+		}
+	}
+
+	summary.DeadlineExceeded = errors.Is(t.config.Context.Err(), context.DeadlineExceeded)
+
+	// Report the webhooks the API sent during the run
+	if t.callbacks != nil {
+		for _, result := range t.callbacks.webhookResults() {
+			summary.AddResult(result)
+		}
 	}
 
 	return summary
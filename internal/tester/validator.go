@@ -3,22 +3,70 @@ package tester
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net"
 	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/moamenhredeen/oas/internal/models"
 	"github.com/moamenhredeen/oas/internal/parser"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
 )
 
+// Strictness controls how thoroughly responses are validated
+type Strictness int
+
+const (
+	// StrictnessStandard validates status codes, headers, content type and body
+	StrictnessStandard Strictness = iota
+	// StrictnessLenient validates status codes only
+	StrictnessLenient
+	// StrictnessStrict additionally rejects undocumented status codes and missing content types
+	StrictnessStrict
+)
+
+// DefaultMaxArrayItems is the number of items validated per response array
+const DefaultMaxArrayItems = 100
+
 // Validator validates HTTP responses against OpenAPI specifications
 type Validator struct {
+	strictness    Strictness
+	maxArrayItems int
+	expectations  map[string]MethodExpectation // by method, for undocumented statuses
 }
 
 // NewValidator creates a new validator
 func NewValidator() *Validator {
-	return &Validator{}
+	return &Validator{maxArrayItems: DefaultMaxArrayItems}
+}
+
+// NewValidatorWithStrictness creates a new validator with the given strictness
+func NewValidatorWithStrictness(strictness Strictness) *Validator {
+	return &Validator{strictness: strictness, maxArrayItems: DefaultMaxArrayItems}
+}
+
+// SetMaxArrayItems limits how many items of each response array are
+// validated; longer arrays are sampled evenly. 0 validates every item.
+func (v *Validator) SetMaxArrayItems(n int) {
+	v.maxArrayItems = n
+}
+
+// SetExpectations configures what responses of each method must look like
+// when the spec doesn't document their status code
+func (v *Validator) SetExpectations(expectations map[string]MethodExpectation) {
+	v.expectations = expectations
 }
 
 // ValidateResponse validates an HTTP response against the OpenAPI spec
@@ -31,8 +79,19 @@ func (v *Validator) ValidateResponse(resp *http.Response, opDetails *parser.Oper
 
 	statusCode := resp.StatusCode
 
-	// If no responses defined in spec, use HTTP semantics
+	var method string
+	if opDetails != nil {
+		method = opDetails.Method
+	} else if resp.Request != nil {
+		method = resp.Request.Method
+	}
+	errors = append(errors, v.checkEmptyBody(resp, method)...)
+
+	// If no responses defined in spec, use the method's expectation or HTTP semantics
 	if opDetails == nil || opDetails.Responses == nil {
+		if expected, ok := v.checkUndocumentedStatus(method, statusCode); ok {
+			return append(errors, expected...), nil
+		}
 		if statusCode >= 400 {
 			errors = append(errors, models.ValidationError{
 				Field:   "status_code",
@@ -59,17 +118,12 @@ func (v *Validator) ValidateResponse(resp *http.Response, opDetails *parser.Oper
 		}
 	}
 
-	// Check for default response
-	if !found && opDetails.Responses.Default != nil {
-		responseDef = opDetails.Responses.Default
-		found = true
-	}
-
-	// Check for status code ranges (2xx, 4xx, etc.)
+	// Check for status code ranges (2XX, 4XX, etc.), which take precedence
+	// over the default response
 	if !found && opDetails.Responses.Codes != nil {
-		statusRange := fmt.Sprintf("%dxx", statusCode/100)
+		statusRange := fmt.Sprintf("%dXX", statusCode/100)
 		for pair := opDetails.Responses.Codes.First(); pair != nil; pair = pair.Next() {
-			if pair.Key() == statusRange {
+			if strings.EqualFold(pair.Key(), statusRange) {
 				responseDef = pair.Value()
 				found = true
 				break
@@ -77,8 +131,17 @@ func (v *Validator) ValidateResponse(resp *http.Response, opDetails *parser.Oper
 		}
 	}
 
+	// Check for default response
+	if !found && opDetails.Responses.Default != nil {
+		responseDef = opDetails.Responses.Default
+		found = true
+	}
+
 	if !found {
-		// Not defined in spec - use HTTP semantics
+		// Not defined in spec - use the method's expectation or HTTP semantics
+		if expected, ok := v.checkUndocumentedStatus(method, statusCode); ok {
+			return append(errors, expected...), nil
+		}
 		if statusCode >= 400 {
 			errors = append(errors, models.ValidationError{
 				Field:   "status_code",
@@ -89,11 +152,21 @@ func (v *Validator) ValidateResponse(resp *http.Response, opDetails *parser.Oper
 				Field:   "status_code",
 				Message: fmt.Sprintf("unexpected status code %d, not defined in OpenAPI spec (informational)", statusCode),
 			})
+		} else if v.strictness == StrictnessStrict {
+			errors = append(errors, models.ValidationError{
+				Field:   "status_code",
+				Message: fmt.Sprintf("unexpected status code %d, not defined in OpenAPI spec (strict)", statusCode),
+			})
 		}
 		// 2xx and 3xx without definition = pass (assume success/redirect is OK)
 		return errors, nil
 	}
 
+	// Lenient validation stops once the status code is documented
+	if v.strictness == StrictnessLenient {
+		return errors, nil
+	}
+
 	// Validate headers
 	if responseDef.Headers != nil {
 		for pair := responseDef.Headers.First(); pair != nil; pair = pair.Next() {
@@ -129,6 +202,13 @@ func (v *Validator) ValidateResponse(resp *http.Response, opDetails *parser.Oper
 			})
 		}
 
+		if contentType == "" && v.strictness == StrictnessStrict {
+			errors = append(errors, models.ValidationError{
+				Field:   "content_type",
+				Message: "missing content type (strict)",
+			})
+		}
+
 		// Validate response body schema if JSON
 		if strings.Contains(contentType, "json") && responseDef.Content.Len() > 0 {
 			var schema *base.Schema
@@ -155,76 +235,463 @@ func (v *Validator) ValidateResponse(resp *http.Response, opDetails *parser.Oper
 
 // validateJSONSchema validates JSON response body against schema (simplified)
 func (v *Validator) validateJSONSchema(resp *http.Response, schema *base.Schema) []models.ValidationError {
-	var errors []models.ValidationError
-
-	// Read response body
+	// Read response body, keeping numbers exact
 	var bodyData interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&bodyData); err != nil {
-		errors = append(errors, models.ValidationError{
+	if err := decodeJSON(resp.Body, &bodyData); err != nil {
+		return []models.ValidationError{{
 			Field:   "body",
 			Message: fmt.Sprintf("failed to parse JSON response: %v", err),
-		})
-		return errors
+		}}
 	}
 
-	// Basic schema validation
-	if schema.Type != nil && len(schema.Type) > 0 {
-		schemaType := schema.Type[0]
-		switch schemaType {
-		case "object":
-			if _, ok := bodyData.(map[string]interface{}); !ok {
-				errors = append(errors, models.ValidationError{
-					Field:   "body",
-					Message: "expected object type, got different type",
-				})
-			}
-		case "array":
-			if _, ok := bodyData.([]interface{}); !ok {
-				errors = append(errors, models.ValidationError{
-					Field:   "body",
-					Message: "expected array type, got different type",
-				})
-			}
-		case "string":
-			if _, ok := bodyData.(string); !ok {
-				errors = append(errors, models.ValidationError{
-					Field:   "body",
-					Message: "expected string type, got different type",
-				})
-			}
-		case "integer", "number":
-			// Numbers can be float64 in JSON
-			if _, ok := bodyData.(float64); !ok {
+	return v.validateValue("body", bodyData, schema)
+}
+
+// ValidateValue validates a decoded JSON value, such as an event payload,
+// against a schema. Errors name fields below field (e.g. payload.user.id).
+func (v *Validator) ValidateValue(field string, data interface{}, schema *base.Schema) []models.ValidationError {
+	return v.validateValue(field, data, schema)
+}
+
+// validateValue validates a decoded JSON value against its schema: type,
+// required fields, enum membership, string formats and value constraints,
+// descending into object properties and array items
+func (v *Validator) validateValue(field string, data interface{}, schema *base.Schema) []models.ValidationError {
+	if schema == nil {
+		return nil
+	}
+	if data == nil {
+		if allowsNull(schema) {
+			return nil
+		}
+		return []models.ValidationError{{
+			Field:   field,
+			Message: fmt.Sprintf("expected %s type, got null", typeNames(schema.Type)),
+		}}
+	}
+
+	var errors []models.ValidationError
+
+	// Basic type validation; a value of the wrong type is not checked further
+	if !matchesAnyType(schema.Type, data) {
+		return []models.ValidationError{{
+			Field:   field,
+			Message: fmt.Sprintf("expected %s type, got different type", typeNames(schema.Type)),
+		}}
+	}
+
+	// Validate required fields for objects
+	if obj, ok := data.(map[string]interface{}); ok {
+		for _, requiredField := range schema.Required {
+			if _, exists := obj[requiredField]; !exists {
 				errors = append(errors, models.ValidationError{
-					Field:   "body",
-					Message: "expected number type, got different type",
+					Field:   fmt.Sprintf("%s.%s", field, requiredField),
+					Message: fmt.Sprintf("missing required field: %s", requiredField),
 				})
 			}
-		case "boolean":
-			if _, ok := bodyData.(bool); !ok {
-				errors = append(errors, models.ValidationError{
-					Field:   "body",
-					Message: "expected boolean type, got different type",
-				})
+		}
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema, data) {
+		allowed := make([]string, 0, len(schema.Enum))
+		for _, node := range schema.Enum {
+			if node != nil {
+				allowed = append(allowed, node.Value)
 			}
 		}
+		errors = append(errors, models.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("value %v is not one of the allowed values [%s]", data, strings.Join(allowed, ", ")),
+		})
+	}
+	if schema.Const != nil && !constMatches(schema.Const, data) {
+		errors = append(errors, models.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("value %v is not the constant %s", data, schema.Const.Value),
+		})
 	}
+	if str, ok := data.(string); ok && schema.Format != "" && !validFormat(schema.Format, str) {
+		errors = append(errors, models.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("value %q is not a valid %s", str, schema.Format),
+		})
+	}
+	errors = append(errors, validateConstraints(field, data, schema)...)
 
-	// Validate required fields for objects
-	if schema.Type != nil && len(schema.Type) > 0 && schema.Type[0] == "object" {
-		if obj, ok := bodyData.(map[string]interface{}); ok {
-			if schema.Required != nil {
-				for _, requiredField := range schema.Required {
-					if _, exists := obj[requiredField]; !exists {
+	for _, proxy := range schema.AllOf {
+		errors = append(errors, v.validateValue(field, data, proxy.Schema())...)
+	}
+
+	switch value := data.(type) {
+	case map[string]interface{}:
+		if schema.Properties != nil {
+			for pair := schema.Properties.First(); pair != nil; pair = pair.Next() {
+				if propValue, ok := value[pair.Key()]; ok && pair.Value() != nil {
+					errors = append(errors, v.validateValue(field+"."+pair.Key(), propValue, pair.Value().Schema())...)
+				}
+			}
+		}
+		if schema.DependentRequired != nil {
+			for pair := schema.DependentRequired.First(); pair != nil; pair = pair.Next() {
+				if _, ok := value[pair.Key()]; !ok {
+					continue
+				}
+				for _, dependent := range pair.Value() {
+					if _, ok := value[dependent]; !ok {
 						errors = append(errors, models.ValidationError{
-							Field:   fmt.Sprintf("body.%s", requiredField),
-							Message: fmt.Sprintf("missing required field: %s", requiredField),
+							Field:   fmt.Sprintf("%s.%s", field, dependent),
+							Message: fmt.Sprintf("missing field %s required when %s is present", dependent, pair.Key()),
 						})
 					}
 				}
 			}
 		}
+	case []interface{}:
+		// 3.1 prefixItems validate the leading items by position, items the
+		// rest
+		for i, proxy := range schema.PrefixItems {
+			if i < len(value) && proxy != nil {
+				errors = append(errors, v.validateValue(fmt.Sprintf("%s[%d]", field, i), value[i], proxy.Schema())...)
+			}
+		}
+		if schema.Items != nil && schema.Items.IsA() && schema.Items.A != nil {
+			itemSchema := schema.Items.A.Schema()
+			for _, i := range sampleIndexes(len(value), v.maxArrayItems) {
+				if i < len(schema.PrefixItems) {
+					continue
+				}
+				errors = append(errors, v.validateValue(fmt.Sprintf("%s[%d]", field, i), value[i], itemSchema)...)
+			}
+		}
 	}
 
 	return errors
 }
+
+// matchesType reports whether a decoded JSON value has the given schema type
+func matchesType(schemaType string, data interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := data.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := data.([]interface{})
+		return ok
+	case "string":
+		_, ok := data.(string)
+		return ok
+	case "integer":
+		return isIntegral(data)
+	case "number":
+		_, ok := numberValue(data)
+		return ok
+	case "boolean":
+		_, ok := data.(bool)
+		return ok
+	}
+	return true
+}
+
+// matchesAnyType reports whether a decoded JSON value has one of the schema's
+// types (3.1 allows a list such as ["string", "null"]); no types match anything
+func matchesAnyType(schemaTypes []string, data interface{}) bool {
+	if len(schemaTypes) == 0 {
+		return true
+	}
+	for _, schemaType := range schemaTypes {
+		if schemaType != "null" && matchesType(schemaType, data) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsNull reports whether null is a valid value for the schema: with
+// nullable: true (3.0), a "null" type (3.1), no type constraint at all, or an
+// anyOf/oneOf alternative allowing null
+func allowsNull(schema *base.Schema) bool {
+	if schema.Nullable != nil && *schema.Nullable {
+		return true
+	}
+	for _, schemaType := range schema.Type {
+		if schemaType == "null" {
+			return true
+		}
+	}
+
+	alternatives := append(append([]*base.SchemaProxy{}, schema.AnyOf...), schema.OneOf...)
+	for _, proxy := range alternatives {
+		if alt := proxy.Schema(); alt != nil && allowsNull(alt) {
+			return true
+		}
+	}
+	return len(schema.Type) == 0 && len(alternatives) == 0 && len(schema.AllOf) == 0
+}
+
+// typeNames names schema types in validation messages
+func typeNames(schemaTypes []string) string {
+	var names []string
+	for _, schemaType := range schemaTypes {
+		switch schemaType {
+		case "null":
+			continue
+		case "integer":
+			schemaType = "number"
+		}
+		names = append(names, schemaType)
+	}
+	if len(names) == 0 {
+		return "non-null"
+	}
+	return strings.Join(names, " or ")
+}
+
+// sampleIndexes returns the indexes of the array items to validate: all of
+// them, or limit evenly spaced ones (always including the first and last)
+// for arrays longer than limit. A limit of 0 validates every item.
+func sampleIndexes(length, limit int) []int {
+	if limit <= 0 || length <= limit {
+		indexes := make([]int, length)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes
+	}
+	if limit == 1 {
+		return []int{0}
+	}
+
+	indexes := make([]int, limit)
+	for i := range indexes {
+		indexes[i] = i * (length - 1) / (limit - 1)
+	}
+	return indexes
+}
+
+// validateConstraints checks numeric bounds and string length and pattern
+// constraints, reporting the actual value and the violated constraint
+func validateConstraints(field string, data interface{}, schema *base.Schema) []models.ValidationError {
+	var errors []models.ValidationError
+	fail := func(format string, args ...interface{}) {
+		errors = append(errors, models.ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n, ok := data.(json.Number); ok {
+		errors = append(errors, validateIntegerFormat(field, n, schema.Format)...)
+	}
+	if f, ok := numberValue(data); ok {
+		data = f
+	}
+
+	switch value := data.(type) {
+	case float64:
+		// In 3.0 exclusiveMinimum/exclusiveMaximum are booleans modifying
+		// minimum/maximum; in 3.1 they are bounds of their own
+		exclusiveMin := schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsA() && schema.ExclusiveMinimum.A
+		exclusiveMax := schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsA() && schema.ExclusiveMaximum.A
+		if schema.Minimum != nil {
+			if exclusiveMin && value <= *schema.Minimum {
+				fail("value %v is not greater than exclusive minimum %v", value, *schema.Minimum)
+			} else if value < *schema.Minimum {
+				fail("value %v is less than minimum %v", value, *schema.Minimum)
+			}
+		}
+		if schema.Maximum != nil {
+			if exclusiveMax && value >= *schema.Maximum {
+				fail("value %v is not less than exclusive maximum %v", value, *schema.Maximum)
+			} else if value > *schema.Maximum {
+				fail("value %v is greater than maximum %v", value, *schema.Maximum)
+			}
+		}
+		if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsB() && value <= schema.ExclusiveMinimum.B {
+			fail("value %v is not greater than exclusive minimum %v", value, schema.ExclusiveMinimum.B)
+		}
+		if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsB() && value >= schema.ExclusiveMaximum.B {
+			fail("value %v is not less than exclusive maximum %v", value, schema.ExclusiveMaximum.B)
+		}
+	case string:
+		length := int64(utf8.RuneCountInString(value))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail("value %q is shorter than minLength %d", value, *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("value %q is longer than maxLength %d", value, *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if re := compilePattern(schema.Pattern); re != nil && !re.MatchString(value) {
+				fail("value %q does not match pattern %s", value, schema.Pattern)
+			}
+		}
+	}
+
+	return errors
+}
+
+// patternCache holds compiled schema patterns; nil marks patterns Go's regexp
+// syntax can't compile (e.g. lookaheads), which are not checked
+var patternCache sync.Map
+
+// compilePattern compiles a schema pattern once
+func compilePattern(pattern string) *regexp.Regexp {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	patternCache.Store(pattern, re)
+	return re
+}
+
+// decodeJSON decodes JSON keeping numbers as json.Number, so integers beyond
+// 2^53, such as int64 ids, keep their digits
+func decodeJSON(r io.Reader, v *interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// numberValue returns a decoded JSON number as a float64
+func numberValue(data interface{}) (float64, bool) {
+	switch n := data.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			// Out of float64 range, but still a number
+			f, _ = strconv.ParseFloat(n.String(), 64)
+		}
+		return f, true
+	}
+	return 0, false
+}
+
+// isIntegral reports whether a decoded JSON value is an integer. JSON Schema
+// counts numbers with a zero fraction, such as 1.0, as integers.
+func isIntegral(data interface{}) bool {
+	if n, ok := data.(json.Number); ok {
+		if _, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+			return true
+		}
+		if _, ok := new(big.Int).SetString(n.String(), 10); ok {
+			return true
+		}
+	}
+	f, ok := numberValue(data)
+	return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+}
+
+// validateIntegerFormat checks that an exact JSON number fits the range of
+// its int32 or int64 format
+func validateIntegerFormat(field string, n json.Number, format string) []models.ValidationError {
+	var bits int
+	switch format {
+	case "int32":
+		bits = 32
+	case "int64":
+		bits = 64
+	default:
+		return nil
+	}
+	value, ok := new(big.Float).SetString(n.String())
+	if !ok || !value.IsInt() {
+		return nil
+	}
+	i, _ := value.Int(nil)
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	if i.Cmp(limit) >= 0 || i.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return []models.ValidationError{{
+			Field:   field,
+			Message: fmt.Sprintf("value %s is out of the %s range", n, format),
+		}}
+	}
+	return nil
+}
+
+// constMatches reports whether a decoded JSON value equals a schema's const
+func constMatches(node *yaml.Node, data interface{}) bool {
+	var want interface{}
+	if err := node.Decode(&want); err != nil {
+		return true
+	}
+	// Compare through JSON so YAML integers and exact JSON numbers match
+	// float64 numbers
+	wantJSON, err1 := json.Marshal(want)
+	gotJSON, err2 := json.Marshal(data)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	var wantValue, gotValue interface{}
+	if json.Unmarshal(wantJSON, &wantValue) != nil || json.Unmarshal(gotJSON, &gotValue) != nil {
+		return true
+	}
+	return reflect.DeepEqual(wantValue, gotValue)
+}
+
+// enumContains reports whether a decoded JSON scalar is one of the schema's
+// enum values. Non-scalar enum values are not compared and always match.
+func enumContains(schema *base.Schema, data interface{}) bool {
+	for _, node := range schema.Enum {
+		if node == nil || len(node.Content) > 0 {
+			return true
+		}
+		switch value := data.(type) {
+		case string:
+			if node.Value == value {
+				return true
+			}
+		case float64:
+			if n, err := strconv.ParseFloat(node.Value, 64); err == nil && n == value {
+				return true
+			}
+		case json.Number:
+			if node.Value == value.String() {
+				return true
+			}
+			f, err1 := strconv.ParseFloat(node.Value, 64)
+			n, err2 := value.Float64()
+			if err1 == nil && err2 == nil && f == n {
+				return true
+			}
+		case bool:
+			if node.Value == strconv.FormatBool(value) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks a string against a well-known OpenAPI format. Unknown
+// formats are accepted.
+func validFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	}
+	return true
+}
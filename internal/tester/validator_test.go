@@ -126,3 +126,444 @@ func TestValidateJSONSchema(t *testing.T) {
 
 	_ = errors
 }
+
+func TestValidateResponseStrictness(t *testing.T) {
+	// A documented status code with the wrong content type
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("x-next", "/pets?limit=10")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	validate := func(v *Validator) int {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		errors, err := v.ValidateResponse(resp, opDetails)
+		if err != nil {
+			t.Fatalf("Validation error: %v", err)
+		}
+		return len(errors)
+	}
+
+	if n := validate(NewValidatorWithStrictness(StrictnessLenient)); n != 0 {
+		t.Errorf("Expected lenient validation to pass, got %d errors", n)
+	}
+
+	if n := validate(NewValidatorWithStrictness(StrictnessStandard)); n == 0 {
+		t.Error("Expected standard validation to flag the content type")
+	}
+}
+
+func TestValidateResponseEnumAndFormat(t *testing.T) {
+	v := NewValidator()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[
+			{"id": "6f1c2a3b-4d5e-4f60-8a7b-9c0d1e2f3a4b", "name": "ok", "email": "ok@example.com",
+			 "address": {"street": "Main", "city": "Town", "zipCode": "12345", "country": "US"}},
+			{"id": "not-a-uuid", "name": "bad", "email": "not an email",
+			 "address": {"street": "Main", "city": "Town", "zipCode": "12345", "country": "XX"}}
+		]`))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	p, err := parser.ParseFile("../../tests/complex-schemas.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/users", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	errors, err := v.ValidateResponse(resp, opDetails)
+	if err != nil {
+		t.Fatalf("Validation error: %v", err)
+	}
+
+	fields := make(map[string]bool)
+	for _, e := range errors {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"body[1].id", "body[1].email", "body[1].address.country"} {
+		if !fields[field] {
+			t.Errorf("Expected validation error for %s, got %+v", field, errors)
+		}
+	}
+	if len(errors) != 3 {
+		t.Errorf("Expected 3 validation errors, got %d: %+v", len(errors), errors)
+	}
+}
+
+func TestValidFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{"date-time", "2026-01-15T14:30:05Z", true},
+		{"date-time", "2026-01-15 14:30", false},
+		{"date", "2026-01-15", true},
+		{"date", "15.01.2026", false},
+		{"uuid", "6F1C2A3B-4D5E-4F60-8A7B-9C0D1E2F3A4B", true},
+		{"uuid", "6f1c2a3b4d5e4f608a7b9c0d1e2f3a4b", false},
+		{"email", "user@example.com", true},
+		{"email", "User <user@example.com>", false},
+		{"uri", "https://example.com/x", true},
+		{"uri", "/relative", false},
+		{"ipv4", "10.0.0.1", true},
+		{"ipv4", "::1", false},
+		{"ipv6", "::1", true},
+		{"custom", "anything", true},
+	}
+
+	for _, tt := range tests {
+		if got := validFormat(tt.format, tt.value); got != tt.valid {
+			t.Errorf("validFormat(%q, %q) = %v, expected %v", tt.format, tt.value, got, tt.valid)
+		}
+	}
+}
+
+func TestValidateResponseConstraints(t *testing.T) {
+	v := NewValidator()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+			"user": {"id": "6f1c2a3b-4d5e-4f60-8a7b-9c0d1e2f3a4b", "name": "", "email": "ok@example.com",
+			         "address": {"street": "Main", "city": "Town", "zipCode": "ABCDE"}},
+			"orders": [{"id": "6f1c2a3b-4d5e-4f60-8a7b-9c0d1e2f3a4b", "total": -1,
+			            "items": [{"productId": "6f1c2a3b-4d5e-4f60-8a7b-9c0d1e2f3a4b", "quantity": 101, "price": 5}]}]
+		}`))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	p, err := parser.ParseFile("../../tests/complex-schemas.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	opDetails, err := p.GetOperationDetails("/users/{userId}/orders", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	errors, err := v.ValidateResponse(resp, opDetails)
+	if err != nil {
+		t.Fatalf("Validation error: %v", err)
+	}
+
+	expected := map[string]string{
+		"body.user.name":                   `value "" is shorter than minLength 1`,
+		"body.user.address.zipCode":        `value "ABCDE" does not match pattern ^[0-9]{5}(-[0-9]{4})?$`,
+		"body.orders[0].total":             "value -1 is less than minimum 0",
+		"body.orders[0].items[0].quantity": "value 101 is greater than maximum 100",
+	}
+	for _, e := range errors {
+		if msg, ok := expected[e.Field]; ok && msg == e.Message {
+			delete(expected, e.Field)
+		}
+	}
+	for field, msg := range expected {
+		t.Errorf("Expected %s: %s, got %+v", field, msg, errors)
+	}
+}
+
+func TestValidateResponseArrayItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-next", "/pets?page=2")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"id": 1, "name": "a"}, {"id": 2}, "not a pet", {"id": 4, "name": "d"}]`))
+	}))
+	defer server.Close()
+
+	p, err := parser.ParseFile("../../tests/pet-store.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/pets", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	validate := func(maxItems int) []string {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		v := NewValidator()
+		v.SetMaxArrayItems(maxItems)
+		errors, err := v.ValidateResponse(resp, opDetails)
+		if err != nil {
+			t.Fatalf("Validation error: %v", err)
+		}
+		var fields []string
+		for _, e := range errors {
+			fields = append(fields, e.Field)
+		}
+		return fields
+	}
+
+	if fields := validate(0); len(fields) != 2 || fields[0] != "body[1].name" || fields[1] != "body[2]" {
+		t.Errorf("Expected errors for body[1].name and body[2], got %v", fields)
+	}
+	// Sampling two items checks only the first and the last
+	if fields := validate(2); len(fields) != 0 {
+		t.Errorf("Expected no errors when sampling 2 items, got %v", fields)
+	}
+}
+
+func TestSampleIndexes(t *testing.T) {
+	tests := []struct {
+		length, limit int
+		expected      []int
+	}{
+		{3, 0, []int{0, 1, 2}},
+		{3, 5, []int{0, 1, 2}},
+		{10, 1, []int{0}},
+		{10, 3, []int{0, 4, 9}},
+		{1000, 4, []int{0, 333, 666, 999}},
+	}
+
+	for _, tt := range tests {
+		got := sampleIndexes(tt.length, tt.limit)
+		if len(got) != len(tt.expected) {
+			t.Errorf("sampleIndexes(%d, %d) = %v, expected %v", tt.length, tt.limit, got, tt.expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("sampleIndexes(%d, %d) = %v, expected %v", tt.length, tt.limit, got, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestValidateResponseNullable(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"nulls allowed", `{"name": "a", "nickname": null, "manager": null, "address": null, "score": "high"}`, nil},
+		{"values allowed", `{"name": "a", "nickname": "b", "manager": {}, "address": {"city": "c"}, "score": 3}`, nil},
+		{"null not allowed", `{"name": null, "nickname": null, "manager": null, "address": null}`, []string{"body.name"}},
+		{"union mismatch", `{"name": "a", "nickname": 1, "manager": null, "address": null, "score": true}`, []string{"body.nickname", "body.score"}},
+	}
+
+	p, err := parser.ParseFile("../../tests/nullable-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/profile", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			errors, err := NewValidator().ValidateResponse(resp, opDetails)
+			if err != nil {
+				t.Fatalf("Validation error: %v", err)
+			}
+			if len(errors) != len(tt.expected) {
+				t.Fatalf("Expected errors for %v, got %+v", tt.expected, errors)
+			}
+			for i, e := range errors {
+				if e.Field != tt.expected[i] {
+					t.Errorf("Expected error for %s, got %+v", tt.expected[i], e)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateResponseOpenAPI31Keywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"valid", `{"kind": "temperature", "value": 21.5, "location": [52.5, 13.4], "sensor": null, "unit": "C", "precision": 1}`, nil},
+		{"const mismatch", `{"kind": "humidity", "value": 21.5, "location": [52.5, 13.4], "sensor": "s-1"}`, []string{"body.kind"}},
+		{"exclusive bound", `{"kind": "temperature", "value": 1000, "location": [52.5, 13.4], "sensor": "s-1"}`, []string{"body.value"}},
+		{"prefix item", `{"kind": "temperature", "value": 1, "location": [52.5, 200], "sensor": "s-1"}`, []string{"body.location[1]"}},
+		{"dependent required", `{"kind": "temperature", "value": 1, "location": [52.5, 13.4], "sensor": "s-1", "unit": "C"}`, []string{"body.precision"}},
+	}
+
+	p, err := parser.ParseFile("../../tests/json-schema-api.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/readings", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			errors, err := NewValidator().ValidateResponse(resp, opDetails)
+			if err != nil {
+				t.Fatalf("Validation error: %v", err)
+			}
+			if len(errors) != len(tt.expected) {
+				t.Fatalf("Expected errors for %v, got %+v", tt.expected, errors)
+			}
+			for i, e := range errors {
+				if e.Field != tt.expected[i] {
+					t.Errorf("Expected error for %s, got %+v", tt.expected[i], e)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateResponseRangeBeforeDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code": "invalid"}`))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	p, err := parser.ParseFile("../../tests/error-cases.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	opDetails, err := p.GetOperationDetails("/errors/search", "GET")
+	if err != nil {
+		t.Fatalf("Failed to get operation details: %v", err)
+	}
+
+	// 422 matches the 4XX response and its schema, not the schemaless default
+	errors, err := NewValidator().ValidateResponse(resp, opDetails)
+	if err != nil {
+		t.Fatalf("Validation error: %v", err)
+	}
+	if len(errors) != 2 || errors[0].Field != "body.message" || errors[1].Field != "body.code" {
+		t.Errorf("Expected body.message and body.code errors, got %+v", errors)
+	}
+}
+
+func TestValidateResponseIntegerFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		body     string
+		expected []string
+	}{
+		{"large int64", "/pets/{petId}", http.StatusOK, `{"id": 9007199254740993, "name": "Rex"}`, nil},
+		{"int64 overflow", "/pets/{petId}", http.StatusOK, `{"id": 9223372036854775808, "name": "Rex"}`, []string{"body.id"}},
+		{"fractional integer", "/pets/{petId}", http.StatusOK, `{"id": 1.5, "name": "Rex"}`, []string{"body.id"}},
+		{"int32 overflow", "/pets", http.StatusInternalServerError, `{"code": 3000000000, "message": "down"}`, []string{"body.code"}},
+		{"int32 in range", "/pets", http.StatusInternalServerError, `{"code": -2147483648, "message": "down"}`, nil},
+	}
+
+	p, err := parser.ParseFile("../../tests/swagger-petstore.json")
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opDetails, err := p.GetOperationDetails(tt.path, "GET")
+			if err != nil {
+				t.Fatalf("Failed to get operation details: %v", err)
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			errors, err := NewValidator().ValidateResponse(resp, opDetails)
+			if err != nil {
+				t.Fatalf("Validation error: %v", err)
+			}
+			if len(errors) != len(tt.expected) {
+				t.Fatalf("Expected errors for %v, got %+v", tt.expected, errors)
+			}
+			for i, e := range errors {
+				if e.Field != tt.expected[i] {
+					t.Errorf("Expected error for %s, got %+v", tt.expected[i], e)
+				}
+			}
+		})
+	}
+}
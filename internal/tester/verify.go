@@ -0,0 +1,147 @@
+package tester
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/moamenhredeen/oas/internal/models"
+	"github.com/moamenhredeen/oas/internal/parser"
+)
+
+// OnLiveCheck is called with the outcome of each probed operation
+type OnLiveCheck func(check models.LiveCheck)
+
+// VerifyLive probes a deployed server for every documented operation to
+// catch drift between the spec and the deployment. Nothing is sent that could
+// change state: routes are probed with OPTIONS and HEAD, and only GET and HEAD
+// operations are sent as documented.
+//
+// A route the server answers with 404 on every probe is missing. An Allow
+// header settles whether the documented method is served; without one, a
+// 405 to a GET or HEAD operation means its method is not allowed, while
+// other methods can't be confirmed and are reported unverified.
+func (t *Tester) VerifyLive(operations []models.Operation, p *parser.Parser, onCheck OnLiveCheck) models.LiveSummary {
+	var summary models.LiveSummary
+	for _, op := range operations {
+		if t.config.Context.Err() != nil {
+			break
+		}
+		check := t.verifyOperation(op, p)
+		summary.AddCheck(check)
+		if onCheck != nil {
+			onCheck(check)
+		}
+	}
+	return summary
+}
+
+// verifyOperation probes the route of one operation
+func (t *Tester) verifyOperation(op models.Operation, p *parser.Parser) models.LiveCheck {
+	check := models.LiveCheck{Method: op.Method, Path: op.Path, OperationID: op.OperationID}
+
+	opDetails, err := p.GetOperationDetails(op.Path, op.Method)
+	if err != nil {
+		check.Outcome = models.LiveError
+		check.Error = fmt.Sprintf("failed to get operation details: %v", err)
+		return check
+	}
+	if t.config.PrefetchIDs {
+		t.prefetchIDs(opDetails, op.ServerURL, p)
+	}
+	req, err := t.requestBuilder.BuildRequest(opDetails, op.ServerURL)
+	if err != nil {
+		check.Outcome = models.LiveError
+		check.Error = fmt.Sprintf("failed to build request: %v", err)
+		return check
+	}
+
+	// OPTIONS shows whether the route exists and, with Allow, its methods
+	options, allow, err := t.probe(req, http.MethodOptions, &check)
+	if err != nil {
+		return check
+	}
+	routeExists := options != http.StatusNotFound
+
+	// GET and HEAD operations are safe to send as documented
+	if op.Method == http.MethodGet || op.Method == http.MethodHead {
+		status, _, err := t.probe(req, op.Method, &check)
+		if err != nil {
+			return check
+		}
+		switch {
+		case status == http.StatusMethodNotAllowed:
+			check.Outcome = models.LiveMethodNotAllowed
+		case status == http.StatusNotFound && !routeExists:
+			// A 404 is a missing item rather than a missing route if OPTIONS
+			// found the route
+			check.Outcome = models.LiveMissing
+		default:
+			check.Outcome = models.LivePresent
+		}
+		return check
+	}
+
+	if allow != "" {
+		check.Outcome = models.LiveMethodNotAllowed
+		if allowsMethod(allow, op.Method) {
+			check.Outcome = models.LivePresent
+		}
+		return check
+	}
+	if !routeExists {
+		// Servers that don't answer OPTIONS at all still tell a missing
+		// route from a disallowed method on HEAD
+		head, _, err := t.probe(req, http.MethodHead, &check)
+		if err != nil {
+			return check
+		}
+		if head == http.StatusNotFound {
+			check.Outcome = models.LiveMissing
+			return check
+		}
+	}
+	check.Outcome = models.LiveUnverified
+	return check
+}
+
+// probe sends a request to the operation's URL with another method and no
+// body, recording it in the check. On error the check's outcome is set.
+func (t *Tester) probe(req *http.Request, method string, check *models.LiveCheck) (int, string, error) {
+	probe := req.Clone(req.Context())
+	probe.Method = method
+	if method != req.Method {
+		probe.Body = http.NoBody
+		probe.GetBody = nil
+		probe.ContentLength = 0
+		probe.Header.Del("Content-Type")
+	}
+
+	resp, err := t.client.Do(probe)
+	if err != nil {
+		check.Outcome = models.LiveError
+		check.Error = fmt.Sprintf("%s failed: %v", method, err)
+		return 0, "", err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	allow := resp.Header.Get("Allow")
+	entry := fmt.Sprintf("%s %d", method, resp.StatusCode)
+	if allow != "" {
+		entry += fmt.Sprintf(" (Allow: %s)", allow)
+	}
+	check.Probes = append(check.Probes, entry)
+	return resp.StatusCode, allow, nil
+}
+
+// allowsMethod reports whether an Allow header lists a method
+func allowsMethod(allow, method string) bool {
+	for _, m := range strings.Split(allow, ",") {
+		if strings.EqualFold(strings.TrimSpace(m), method) {
+			return true
+		}
+	}
+	return false
+}